@@ -32,19 +32,45 @@ type InstallConfig struct {
 	Addition         map[string][]*PackageConfig `yaml:"addition"` // key: master, worker, etcd, loadbalance
 }
 
+type BastionConfig struct {
+	Ip                   string `yaml:"ip"`
+	Port                 int    `yaml:"port"`
+	Username             string `yaml:"username"`
+	Password             string `yaml:"password"`
+	PrivateKeyPath       string `yaml:"private-key-path"`
+	PrivateKeyPassphrase string `yaml:"private-key-passphrase,omitempty"`
+	CertificatePath      string `yaml:"certificate-path,omitempty"`
+	Fingerprint          string `yaml:"fingerprint,omitempty"`
+}
+
+// LoginConfig override ssh login of cluster for a node, fields not set are inherited from cluster
+type LoginConfig struct {
+	Username             string `yaml:"username,omitempty"`
+	Password             string `yaml:"password,omitempty"` // reference of password, or agent: to login by ssh-agent
+	PrivateKeyPath       string `yaml:"private-key-path,omitempty"`
+	PrivateKeyPassphrase string `yaml:"private-key-passphrase,omitempty"` // reference of passphrase of encrypted private key
+	CertificatePath      string `yaml:"certificate-path,omitempty"`       // openssh user certificate, such as id_rsa-cert.pub
+}
+
 type HostConfig struct {
-	Name string `yaml:"name"`
-	Ip   string `yaml:"ip"`
-	Port int    `yaml:"port"`
-	Arch string `yaml:"arch"` // amd64, aarch64, default amd64
+	Name        string         `yaml:"name"`
+	Ip          string         `yaml:"ip"`
+	Port        int            `yaml:"port"`
+	Arch        string         `yaml:"arch"`                  // amd64, aarch64, default amd64
+	Fingerprint string         `yaml:"fingerprint,omitempty"` // fingerprint of ssh host key, SHA256:xxx
+	Bastion     *BastionConfig `yaml:"bastion,omitempty"`     // override bastion of cluster
+	LoginConfig `yaml:",inline"`
 }
 
 type LoadBalance struct {
-	Name     string `yaml:"name"`
-	Ip       string `yaml:"ip"`
-	Port     int    `yaml:"port"`
-	Arch     string `yaml:"arch"` // amd64, aarch64, default amd64
-	BindPort int    `yaml:"bind-port"`
+	Name        string         `yaml:"name"`
+	Ip          string         `yaml:"ip"`
+	Port        int            `yaml:"port"`
+	Arch        string         `yaml:"arch"` // amd64, aarch64, default amd64
+	BindPort    int            `yaml:"bind-port"`
+	Fingerprint string         `yaml:"fingerprint,omitempty"`
+	Bastion     *BastionConfig `yaml:"bastion,omitempty"`
+	LoginConfig `yaml:",inline"`
 }
 
 type DnsConfig struct {
@@ -72,15 +98,35 @@ type Sans struct {
 }
 
 type OpenPorts struct {
-	Port     int    `yaml:"port"`
-	Protocol string `yaml:"protocol"` // tcp/udp
+	Port     int      `yaml:"port"`
+	Protocol string   `yaml:"protocol"`          // tcp/udp
+	Zone     string   `yaml:"zone,omitempty"`    // zone of firewalld, table of nftables or chain of iptables
+	Sources  []string `yaml:"sources,omitempty"` // CIDRs allowed to access port, default all
+}
+
+// NodeTuning is os settings of nodes, merged with default settings of role
+type NodeTuning struct {
+	Sysctl              map[string]string `yaml:"sysctl,omitempty"`
+	KernelModules       []string          `yaml:"kernel-modules,omitempty"`       // loaded when boot
+	DisableSwap         *bool             `yaml:"disable-swap,omitempty"`         // default true for worker
+	SELinux             string            `yaml:"selinux,omitempty"`              // enforcing, permissive or disabled
+	Ulimits             map[string]string `yaml:"ulimits,omitempty"`              // such as nofile: 1048576
+	TransparentHugepage string            `yaml:"transparent-hugepage,omitempty"` // always, madvise or never
+	NTPServers          []string          `yaml:"ntp-servers,omitempty"`          // servers of chrony
 }
 
 type DeployConfig struct {
+	APIVersion           string                  `yaml:"apiVersion"` // version of deploy config, such as eggo.isula.org/v1beta1
+	Kind                 string                  `yaml:"kind"`       // DeployConfig
 	ClusterID            string                  `yaml:"cluster-id"`
 	Username             string                  `yaml:"username"`
 	Password             string                  `yaml:"password"`
 	PrivateKeyPath       string                  `yaml:"private-key-path"`
+	PrivateKeyPassphrase string                  `yaml:"private-key-passphrase,omitempty"` // reference of passphrase of encrypted private key
+	CertificatePath      string                  `yaml:"certificate-path,omitempty"`       // openssh user certificate signed by ssh ca
+	Bastion              *BastionConfig          `yaml:"bastion,omitempty"`                // jump host to connect all nodes
+	HostKeyChecking      string                  `yaml:"host-key-checking,omitempty"`      // tofu, strict or off, default tofu
+	Local                bool                    `yaml:"local,omitempty"`                  // single node cluster on machine running eggo
 	Masters              []*HostConfig           `yaml:"masters"`
 	Workers              []*HostConfig           `yaml:"workers"`
 	Etcds                []*HostConfig           `yaml:"etcds"`
@@ -104,7 +150,38 @@ type DeployConfig struct {
 	RuntimeEndpoint      string                  `yaml:"runtime-endpoint"`
 	RegistryMirrors      []string                `yaml:"registry-mirrors"`
 	InsecureRegistries   []string                `yaml:"insecure-registries"`
+	RuntimeHandlers      []*RuntimeHandler       `yaml:"runtime-handlers,omitempty"`
+	Registries           []*RegistryConfig       `yaml:"registries,omitempty"`
+	RegistryCredentials  string                  `yaml:"registry-credentials,omitempty"` // local file in format of docker config.json
 	ConfigExtraArgs      []*ConfigExtraArgs      `yaml:"config-extra-args"`
-	OpenPorts            map[string][]*OpenPorts `yaml:"open-ports"` // key: master, worker, etcd, loadbalance
+	Firewall             string                  `yaml:"firewall,omitempty"`    // auto, none, firewalld, nftables, iptables or ufw, default auto
+	OpenPorts            map[string][]*OpenPorts `yaml:"open-ports"`            // key: master, worker, etcd, loadbalance
+	NodeTuning           map[string]*NodeTuning  `yaml:"node-tuning,omitempty"` // key: master, worker, etcd, loadbalance
 	InstallConfig        InstallConfig           `yaml:"install"`
 }
+
+// RuntimeHandler is sandbox runtime used by pods of RuntimeClass with same name, such as kata
+type RuntimeHandler struct {
+	Name        string           `yaml:"name"`                   // name of handler and RuntimeClass
+	RuntimeType string           `yaml:"runtime-type,omitempty"` // containerd: shim of runtime, such as io.containerd.kata.v2
+	Path        string           `yaml:"path,omitempty"`         // isulad: path of oci runtime, such as /usr/bin/kata-runtime
+	RuntimeArgs []string         `yaml:"runtime-args,omitempty"` // isulad: args of oci runtime
+	ConfigPath  string           `yaml:"config-path,omitempty"`  // containerd: config of runtime, such as configuration.toml of kata
+	Nodes       []string         `yaml:"nodes,omitempty"`        // names or ips of workers with handler, default all workers
+	Packages    []*PackageConfig `yaml:"packages,omitempty"`     // packages of runtime installed on nodes with handler
+}
+
+// RegistryConfig is hosts config of registry for containerd, such as certs.d/docker.io/hosts.toml
+type RegistryConfig struct {
+	Host       string   `yaml:"host"`                  // host of registry, such as docker.io or example.com:5000
+	Mirrors    []string `yaml:"mirrors,omitempty"`     // mirrors used to pull and resolve images of registry
+	CAFile     string   `yaml:"ca-file,omitempty"`     // local CA bundle to verify registry and mirrors
+	ClientCert string   `yaml:"client-cert,omitempty"` // local client certificate and key for mutual TLS
+	ClientKey  string   `yaml:"client-key,omitempty"`
+	SkipVerify bool     `yaml:"skip-verify,omitempty"` // skip verifing certificate of registry and mirrors
+}
+
+type UpgradeConfig struct {
+	KubernetesVersion string        `yaml:"kubernetes-version"`
+	InstallConfig     InstallConfig `yaml:"install"`
+}
@@ -0,0 +1,179 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo certs command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment"
+	"isula.org/eggo/pkg/clusterdeployment/binary/clustercerts"
+)
+
+const (
+	localNodeName = "local"
+)
+
+func residualTime(notAfter time.Time, now time.Time) string {
+	d := notAfter.Sub(now)
+	if d <= 0 {
+		return "expired"
+	}
+	const day = 24 * time.Hour
+	if d >= 365*day {
+		return fmt.Sprintf("%dy", d/(365*day))
+	}
+	if d >= day {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return fmt.Sprintf("%dh", d/time.Hour)
+}
+
+func showCertsExpirationTable(w io.Writer, ces []*api.CertExpiration, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tCERTIFICATE\tTYPE\tEXPIRES\tRESIDUAL TIME\tMESSAGE")
+	for _, ce := range ces {
+		node := ce.Node
+		if node == "" {
+			node = localNodeName
+		}
+		if ce.Message != "" {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\t%s\n", node, ce.Path, ce.Message)
+			continue
+		}
+		ctype := "leaf"
+		if ce.CA {
+			ctype = "ca"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", node, ce.Path, ctype,
+			ce.NotAfter.Format("Jan 02, 2006 15:04 MST"), residualTime(ce.NotAfter, now))
+	}
+	tw.Flush()
+}
+
+func showCertsExpiration(w io.Writer, ces []*api.CertExpiration, output string) error {
+	switch output {
+	case outputTable:
+		showCertsExpirationTable(w, ces, time.Now())
+	case outputJSON:
+		data, err := json.MarshalIndent(ces, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	default:
+		return fmt.Errorf("unsupported output format: %s", output)
+	}
+	return nil
+}
+
+func checkCertsExpiration(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.certsClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+	if opts.certsOutput != outputTable && opts.certsOutput != outputJSON {
+		return fmt.Errorf("unsupported output format: %s", opts.certsOutput)
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.certsClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+
+	ces, err := clusterdeployment.CheckCertsExpiration(toClusterdeploymentConfig(conf, nil))
+	if err != nil {
+		return err
+	}
+
+	return showCertsExpiration(os.Stdout, ces, opts.certsOutput)
+}
+
+func renewCerts(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.certsClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("please specify certificates to renew: %s, %s", clustercerts.TargetAll,
+			strings.Join(clustercerts.RenewTargets(), ", "))
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.certsClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+	if err = RunChecker(conf); err != nil {
+		return err
+	}
+
+	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
+	if err != nil {
+		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
+	}
+	defer func() {
+		if terr := holder.Remove(); terr != nil {
+			fmt.Printf("remove process place holder failed: %v", terr)
+		}
+	}()
+
+	if err = clusterdeployment.RenewCerts(toClusterdeploymentConfig(conf, nil), args); err != nil {
+		return err
+	}
+
+	fmt.Printf("renew certificates %s of cluster %s success\n", strings.Join(args, ","), conf.ClusterID)
+	return nil
+}
+
+func NewCertsCmd() *cobra.Command {
+	certsCmd := &cobra.Command{
+		Use:   "certs",
+		Short: "manage certificates of kubernetes cluster",
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check-expiration",
+		Short: "show expiration of certificates in eggo and on all nodes",
+		RunE:  checkCertsExpiration,
+	}
+	setupCertsCheckCmdOpts(checkCmd)
+
+	renewCmd := &cobra.Command{
+		Use:   "renew [all|" + strings.Join(clustercerts.RenewTargets(), "|") + "]...",
+		Short: "renew certificates with existed ca and restart services by rolling",
+		RunE:  renewCerts,
+	}
+	setupCertsRenewCmdOpts(renewCmd)
+
+	certsCmd.AddCommand(checkCmd)
+	certsCmd.AddCommand(renewCmd)
+
+	return certsCmd
+}
@@ -0,0 +1,48 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo certs command testcase
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestShowCertsExpiration(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	ces := []*api.CertExpiration{
+		{Path: "/etc/eggo/k8s-cluster/pki/ca.crt", CA: true, NotAfter: now.Add(10 * 365 * 24 * time.Hour)},
+		{Node: "192.168.0.2", Path: "/etc/kubernetes/pki/apiserver.crt", NotAfter: now.Add(30 * 24 * time.Hour)},
+		{Node: "192.168.0.3", Path: "/etc/kubernetes/kube-proxy.conf", NotAfter: now.Add(-time.Hour)},
+		{Node: "192.168.0.4", Message: "check certificates failed"},
+	}
+
+	var buf bytes.Buffer
+	showCertsExpirationTable(&buf, ces, now)
+	out := buf.String()
+	for _, expect := range []string{"local", "10y", "30d", "expired", "check certificates failed"} {
+		if !strings.Contains(out, expect) {
+			t.Fatalf("expect %q in table: %s", expect, out)
+		}
+	}
+
+	if err := showCertsExpiration(&buf, ces, "yaml"); err == nil {
+		t.Fatalf("expect error for unsupported output")
+	}
+}
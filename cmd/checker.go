@@ -8,17 +8,23 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/validation"
 
 	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/infrastructure"
+	"isula.org/eggo/pkg/clusterdeployment/runtime"
 	"isula.org/eggo/pkg/constants"
 	"isula.org/eggo/pkg/utils"
 	"isula.org/eggo/pkg/utils/endpoint"
 	chain "isula.org/eggo/pkg/utils/responsibilitychain"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/secrets"
 )
 
 type ClusterConfigResponsibility struct {
@@ -42,15 +48,25 @@ func (ccr *ClusterConfigResponsibility) Execute() error {
 	if errs := validation.IsDNS1123Subdomain(ccr.conf.ClusterID); len(errs) > 0 {
 		return fmt.Errorf("invalid cluster id: %v", errs)
 	}
-	// check certificate of ssh
-	if ccr.conf.PrivateKeyPath == "" {
-		if ccr.conf.Username == "" || ccr.conf.Password == "" {
-			return fmt.Errorf("no ceritificate of ssh set")
-		}
-	} else {
-		if !filepath.IsAbs(ccr.conf.PrivateKeyPath) {
-			return fmt.Errorf("cluster private key path: %s is not abosulate", ccr.conf.PrivateKeyPath)
+	// check certificate of ssh, local cluster is deployed without ssh
+	if ccr.conf.Local {
+		if err := checkLocalCluster(ccr.conf); err != nil {
+			return fmt.Errorf("invalid local cluster: %v", err)
 		}
+	} else if err := checkNodesLogin(ccr.conf); err != nil {
+		return err
+	}
+	if err := checkLogin(clusterLoginConfig(ccr.conf)); err != nil {
+		return fmt.Errorf("invalid cluster login: %v", err)
+	}
+	// check bastion of cluster
+	if err := checkBastion(ccr.conf.Bastion); err != nil {
+		return fmt.Errorf("invalid cluster bastion: %v", err)
+	}
+	// check host key checking mode
+	if !runner.ValidHostKeyCheckMode(ccr.conf.HostKeyChecking) {
+		return fmt.Errorf("invalid host key checking: %s, support: %s, %s, %s", ccr.conf.HostKeyChecking,
+			runner.HostKeyCheckTOFU, runner.HostKeyCheckStrict, runner.HostKeyCheckOff)
 	}
 	// check nodes of cluster
 	if len(ccr.conf.Masters) == 0 {
@@ -104,11 +120,147 @@ func (ccr *ClusterConfigResponsibility) Execute() error {
 			return fmt.Errorf("cni bin dir: %s is not abosulate", ccr.conf.CniBinDir)
 		}
 	}
-	// check RuntimeEndpoint
+	// check Runtime and RuntimeEndpoint
+	rt := runtime.GetRuntime(ccr.conf.Runtime)
+	if rt == nil {
+		return fmt.Errorf("unsupport container engine %s", ccr.conf.Runtime)
+	}
 	if ccr.conf.RuntimeEndpoint != "" {
 		if _, err := url.Parse(ccr.conf.RuntimeEndpoint); err != nil {
 			return fmt.Errorf("invalid runtime endpoint: %s, err: %v", ccr.conf.RuntimeEndpoint, err)
 		}
+		// kubelet use endpoint of docker itself
+		if other := runtime.GetRuntimeByEndpoint(ccr.conf.RuntimeEndpoint); other != "" &&
+			ccr.conf.RuntimeEndpoint != rt.GetRuntimeEndpoint() && !utils.IsDocker(ccr.conf.Runtime) {
+			return fmt.Errorf("runtime endpoint %s is endpoint of %s, not %s", ccr.conf.RuntimeEndpoint, other, ccr.conf.Runtime)
+		}
+	}
+	if err := checkRuntimeHandlers(ccr.conf, rt); err != nil {
+		return err
+	}
+	if err := checkRegistries(ccr.conf, rt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkLocalFile(name string, path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%s: %s must be absolute", name, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return nil
+}
+
+func checkRegistries(conf *DeployConfig, rt runtime.Runtime) error {
+	if len(conf.Registries) != 0 && !utils.IsContainerd(conf.Runtime) {
+		return fmt.Errorf("registries only support containerd, not %s", conf.Runtime)
+	}
+
+	hosts := make(map[string]bool)
+	for _, r := range conf.Registries {
+		if r == nil {
+			return errors.New("empty registry config")
+		}
+		// host is name of directory in certs.d, without scheme and path
+		if host, err := runtime.NormalizeRegistryHost(r.Host); err != nil || host != r.Host {
+			return fmt.Errorf("invalid registry host: %s", r.Host)
+		}
+		if hosts[r.Host] {
+			return fmt.Errorf("duplicate registry: %s", r.Host)
+		}
+		hosts[r.Host] = true
+
+		for _, m := range r.Mirrors {
+			if _, err := runtime.NormalizeRegistryHost(m); err != nil {
+				return fmt.Errorf("invalid mirror of registry %s: %s", r.Host, m)
+			}
+		}
+		if r.CAFile != "" {
+			if err := checkLocalFile("ca file of registry "+r.Host, r.CAFile); err != nil {
+				return err
+			}
+		}
+		if (r.ClientCert == "") != (r.ClientKey == "") {
+			return fmt.Errorf("client cert and key of registry %s must be set together", r.Host)
+		}
+		if r.ClientCert != "" {
+			if err := checkLocalFile("client cert of registry "+r.Host, r.ClientCert); err != nil {
+				return err
+			}
+			if err := checkLocalFile("client key of registry "+r.Host, r.ClientKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	if conf.RegistryCredentials != "" {
+		if _, ok := rt.(runtime.RegistryAuthRuntime); !ok {
+			return fmt.Errorf("container engine %s not support registry credentials", conf.Runtime)
+		}
+		if err := checkLocalFile("registry credentials", conf.RegistryCredentials); err != nil {
+			return err
+		}
+		if _, err := runtime.LoadRegistryCredentials(conf.RegistryCredentials); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkRuntimeHandlers(conf *DeployConfig, rt runtime.Runtime) error {
+	if len(conf.RuntimeHandlers) == 0 {
+		return nil
+	}
+	if _, ok := rt.(runtime.HandlerRuntime); !ok {
+		return fmt.Errorf("container engine %s not support runtime handlers", conf.Runtime)
+	}
+
+	workers := make(map[string]bool)
+	for _, w := range conf.Workers {
+		if w == nil {
+			continue
+		}
+		workers[w.Name] = true
+		workers[w.Ip] = true
+	}
+	names := make(map[string]bool)
+	for _, h := range conf.RuntimeHandlers {
+		if h == nil {
+			return errors.New("empty runtime handler")
+		}
+		// name of handler is used as name of RuntimeClass
+		if errs := validation.IsDNS1123Label(h.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid runtime handler name %s: %v", h.Name, errs)
+		}
+		if names[h.Name] {
+			return fmt.Errorf("duplicate runtime handler: %s", h.Name)
+		}
+		names[h.Name] = true
+
+		if utils.IsISulad(conf.Runtime) && (h.Path == "" || !filepath.IsAbs(h.Path)) {
+			return fmt.Errorf("path of runtime handler %s must be absolute", h.Name)
+		}
+		if utils.IsContainerd(conf.Runtime) && h.RuntimeType == "" {
+			return fmt.Errorf("empty runtime type for runtime handler: %s", h.Name)
+		}
+		if h.ConfigPath != "" && !filepath.IsAbs(h.ConfigPath) {
+			return fmt.Errorf("config path of runtime handler %s must be absolute", h.Name)
+		}
+		for _, n := range h.Nodes {
+			if !workers[n] {
+				return fmt.Errorf("node %s of runtime handler %s is not worker", n, h.Name)
+			}
+		}
+		for _, p := range h.Packages {
+			if err := checkPackageConfig(p); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -127,6 +279,120 @@ func (ccr *NodesResponsibility) Nexter() chain.Responsibility {
 	return ccr.next
 }
 
+func isLocalAddress(ip string) (bool, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false, err
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.String() == ip {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkLocalCluster all roles of local cluster must be on the machine running eggo
+func checkLocalCluster(conf *DeployConfig) error {
+	if len(conf.Masters) == 0 {
+		return fmt.Errorf("no master, master node is require for cluster")
+	}
+	if conf.LoadBalance.Ip != "" {
+		return fmt.Errorf("loadbalance is not supported")
+	}
+	var ip string
+	hosts := append([]*HostConfig{}, conf.Masters...)
+	hosts = append(hosts, conf.Workers...)
+	hosts = append(hosts, conf.Etcds...)
+	for _, h := range hosts {
+		if ip == "" {
+			ip = h.Ip
+		}
+		if h.Ip != ip {
+			return fmt.Errorf("only single node is supported, but get nodes: %s and %s", ip, h.Ip)
+		}
+	}
+	local, err := isLocalAddress(ip)
+	if err != nil {
+		return fmt.Errorf("get addresses of this machine failed: %v", err)
+	}
+	if !local {
+		return fmt.Errorf("node %s is not address of this machine", ip)
+	}
+	return nil
+}
+
+func loginSet(l LoginConfig) bool {
+	return l.PrivateKeyPath != "" || (l.Username != "" && l.Password != "")
+}
+
+// checkNodesLogin check that every node could login by private key or password,
+// set by cluster or the node itself
+func checkNodesLogin(conf *DeployConfig) error {
+	cluster := clusterLoginConfig(conf)
+	if loginSet(cluster) {
+		return nil
+	}
+	for _, h := range getAllHostConfigs(conf) {
+		if h.Ip == "" {
+			continue
+		}
+		if !loginSet(mergeLoginConfig(h.LoginConfig, cluster)) {
+			return fmt.Errorf("no ceritificate of ssh set for host: %s", h.Ip)
+		}
+	}
+	return nil
+}
+
+func checkLogin(l LoginConfig) error {
+	if err := secrets.ValidReference(l.Password); err != nil {
+		return fmt.Errorf("invalid password: %v", err)
+	}
+	if l.PrivateKeyPath != "" && !filepath.IsAbs(l.PrivateKeyPath) {
+		return fmt.Errorf("private key path: %s is not abosulate", l.PrivateKeyPath)
+	}
+	if err := secrets.ValidReference(l.PrivateKeyPassphrase); err != nil {
+		return fmt.Errorf("invalid private key passphrase: %v", err)
+	}
+	if _, ok := secrets.AgentSocket(l.PrivateKeyPassphrase); ok {
+		return fmt.Errorf("ssh-agent could not be used as private key passphrase")
+	}
+	if l.CertificatePath != "" && !filepath.IsAbs(l.CertificatePath) {
+		return fmt.Errorf("certificate path: %s is not abosulate", l.CertificatePath)
+	}
+	return nil
+}
+
+func checkBastion(b *BastionConfig) error {
+	if b == nil {
+		return nil
+	}
+	if b.Ip == "" {
+		return fmt.Errorf("bastion ip is null")
+	}
+	if ip := net.ParseIP(b.Ip); ip == nil {
+		if errs := validation.IsDNS1123Subdomain(b.Ip); len(errs) > 0 {
+			return fmt.Errorf("invalid bastion address: %s", b.Ip)
+		}
+	}
+	if b.Port != 0 && !endpoint.ValidPort(b.Port) {
+		return fmt.Errorf("invalid bastion port: %v", b.Port)
+	}
+	if err := checkLogin(LoginConfig{
+		Username:             b.Username,
+		Password:             b.Password,
+		PrivateKeyPath:       b.PrivateKeyPath,
+		PrivateKeyPassphrase: b.PrivateKeyPassphrase,
+		CertificatePath:      b.CertificatePath,
+	}); err != nil {
+		return fmt.Errorf("invalid bastion login: %v", err)
+	}
+	if b.Fingerprint != "" && !runner.ValidFingerprint(b.Fingerprint) {
+		return fmt.Errorf("invalid bastion fingerprint: %s", b.Fingerprint)
+	}
+	return nil
+}
+
 func checkHostconfig(h *HostConfig) error {
 	if h == nil {
 		return fmt.Errorf("empty hostconfig")
@@ -146,6 +412,15 @@ func checkHostconfig(h *HostConfig) error {
 	if !endpoint.ValidPort(h.Port) {
 		return fmt.Errorf("invalid host port: %v", h.Port)
 	}
+	if h.Fingerprint != "" && !runner.ValidFingerprint(h.Fingerprint) {
+		return fmt.Errorf("invalid host fingerprint: %s", h.Fingerprint)
+	}
+	if err := checkLogin(h.LoginConfig); err != nil {
+		return fmt.Errorf("host: %s, invalid login: %v", h.Name, err)
+	}
+	if err := checkBastion(h.Bastion); err != nil {
+		return fmt.Errorf("host: %s, %v", h.Name, err)
+	}
 	return nil
 }
 
@@ -198,6 +473,15 @@ func (ccr *NodesResponsibility) Execute() error {
 		if ccr.conf.LoadBalance.Port == 0 || ccr.conf.LoadBalance.BindPort == 0 {
 			return fmt.Errorf("loadbalance ip set, must set port and bindport")
 		}
+		if ccr.conf.LoadBalance.Fingerprint != "" && !runner.ValidFingerprint(ccr.conf.LoadBalance.Fingerprint) {
+			return fmt.Errorf("invalid loadbalance fingerprint: %s", ccr.conf.LoadBalance.Fingerprint)
+		}
+		if err := checkLogin(ccr.conf.LoadBalance.LoginConfig); err != nil {
+			return fmt.Errorf("invalid loadbalance login: %v", err)
+		}
+		if err := checkBastion(ccr.conf.LoadBalance.Bastion); err != nil {
+			return fmt.Errorf("invalid loadbalance bastion: %v", err)
+		}
 	}
 	if ccr.conf.LoadBalance.Port != 0 {
 		if !endpoint.ValidPort(ccr.conf.LoadBalance.Port) {
@@ -298,6 +582,183 @@ func (ccr *ApiSansResponsibility) Execute() error {
 	return nil
 }
 
+const (
+	nodeCIDRMaskSizeArg       = "--node-cidr-mask-size"
+	defaultNodeCIDRMaskSize   = 24
+	defaultNodeCIDRMaskSizeV6 = 64
+)
+
+// AddressResponsibility check addresses of cluster against each other, the values filled by
+// default are checked too, so it runs after all addresses are parsed
+type AddressResponsibility struct {
+	next chain.Responsibility
+	conf *DeployConfig
+}
+
+func (ccr *AddressResponsibility) SetNexter(nexter chain.Responsibility) {
+	ccr.next = nexter
+}
+
+func (ccr *AddressResponsibility) Nexter() chain.Responsibility {
+	return ccr.next
+}
+
+func cidrOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+func checkNodeAddresses(ccfg *api.ClusterConfig, podNet, serviceNet *net.IPNet) error {
+	addrs := make(map[string]string)
+	for _, n := range ccfg.Nodes {
+		addrs[n.Address] = fmt.Sprintf("node %s", n.Name)
+	}
+	if ccfg.LoadBalancer.IP != "" {
+		addrs[ccfg.LoadBalancer.IP] = "loadbalance"
+	}
+	for addr, owner := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		if podNet.Contains(ip) {
+			return fmt.Errorf("address %s of %s is in pod cidr: %s", addr, owner, podNet)
+		}
+		if serviceNet.Contains(ip) {
+			return fmt.Errorf("address %s of %s is in service cidr: %s", addr, owner, serviceNet)
+		}
+	}
+	return nil
+}
+
+func checkServiceAddresses(ccfg *api.ClusterConfig, serviceNet *net.IPNet) error {
+	addrs := []struct {
+		name string
+		addr string
+	}{
+		{"dns address", ccfg.ServiceCluster.DNSAddr},
+		{"dns vip", ccfg.WorkerConfig.KubeletConf.DNSVip},
+		{"service gateway", ccfg.ServiceCluster.Gateway},
+	}
+	for _, a := range addrs {
+		if a.addr == "" {
+			continue
+		}
+		if ip := net.ParseIP(a.addr); ip == nil || !serviceNet.Contains(ip) {
+			return fmt.Errorf("%s: %s is not in service cidr: %s", a.name, a.addr, serviceNet)
+		}
+	}
+	if ccfg.ServiceCluster.Gateway != "" && ccfg.ServiceCluster.Gateway == ccfg.ServiceCluster.DNSAddr {
+		return fmt.Errorf("dns address is the same as service gateway: %s", ccfg.ServiceCluster.Gateway)
+	}
+	return nil
+}
+
+// checkAPIServerEndpoint endpoint set by user must be loadbalance or one of masters, and
+// domain of endpoint must be covered by apiserver-cert-sans
+func checkAPIServerEndpoint(conf *DeployConfig) error {
+	if conf.ApiServerEndpoint == "" {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(conf.ApiServerEndpoint)
+	if err != nil {
+		return fmt.Errorf("invalid api server endpoint: %s", conf.ApiServerEndpoint)
+	}
+
+	if net.ParseIP(host) == nil {
+		if notInStrArray(conf.ApiServerCertSans.DNSNames, host) {
+			return fmt.Errorf("domain of api server endpoint: %s is not in apiserver-cert-sans", host)
+		}
+		return nil
+	}
+
+	if conf.LoadBalance.Ip != "" && host == conf.LoadBalance.Ip {
+		if port != "" && port != strconv.Itoa(conf.LoadBalance.BindPort) {
+			return fmt.Errorf("port of api server endpoint: %s differs from loadbalance bind port: %d",
+				conf.ApiServerEndpoint, conf.LoadBalance.BindPort)
+		}
+		return nil
+	}
+	for _, m := range conf.Masters {
+		if host == m.Ip {
+			return nil
+		}
+	}
+	return fmt.Errorf("api server endpoint: %s is neither loadbalance nor master", conf.ApiServerEndpoint)
+}
+
+func nodeCIDRMaskSize(ccfg *api.ClusterConfig, podNet *net.IPNet) (int, error) {
+	ones, bits := podNet.Mask.Size()
+	maskSize := defaultNodeCIDRMaskSize
+	if bits != 8*net.IPv4len {
+		maskSize = defaultNodeCIDRMaskSizeV6
+	}
+	if ccfg.ControlPlane.ManagerConf != nil {
+		if v, ok := ccfg.ControlPlane.ManagerConf.ExtraArgs[nodeCIDRMaskSizeArg]; ok {
+			size, err := strconv.Atoi(v)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s: %s", nodeCIDRMaskSizeArg, v)
+			}
+			maskSize = size
+		}
+	}
+	if maskSize < ones || maskSize > bits {
+		return 0, fmt.Errorf("%s: %d does not fit pod cidr: %s", nodeCIDRMaskSizeArg, maskSize, podNet)
+	}
+	return maskSize, nil
+}
+
+// checkPodCIDRCapacity return error if pod cidr cannot be divided to subnets for all nodes
+func checkPodCIDRCapacity(ccfg *api.ClusterConfig, podNet *net.IPNet, maskSize int) error {
+	ones, _ := podNet.Mask.Size()
+	nodes := 0
+	for _, n := range ccfg.Nodes {
+		if utils.IsType(n.Type, api.Master) || utils.IsType(n.Type, api.Worker) {
+			nodes++
+		}
+	}
+	// count of subnets overflows when it is large enough
+	if maskSize-ones < 31 && nodes > 1<<uint(maskSize-ones) {
+		return fmt.Errorf("pod cidr: %s with %s %d has subnets for %d nodes, but cluster has %d nodes",
+			podNet, nodeCIDRMaskSizeArg, maskSize, 1<<uint(maskSize-ones), nodes)
+	}
+	return nil
+}
+
+func (ccr *AddressResponsibility) Execute() error {
+	ccfg := toClusterdeploymentConfig(ccr.conf, nil)
+	_, podNet, err := net.ParseCIDR(ccfg.Network.PodCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid pod cidr: %s, err: %v", ccfg.Network.PodCIDR, err)
+	}
+	_, serviceNet, err := net.ParseCIDR(ccfg.ServiceCluster.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid service cidr: %s, err: %v", ccfg.ServiceCluster.CIDR, err)
+	}
+
+	if cidrOverlap(podNet, serviceNet) {
+		return fmt.Errorf("pod cidr: %s overlaps with service cidr: %s", podNet, serviceNet)
+	}
+	if err := checkNodeAddresses(ccfg, podNet, serviceNet); err != nil {
+		return err
+	}
+	if err := checkServiceAddresses(ccfg, serviceNet); err != nil {
+		return err
+	}
+	if err := checkAPIServerEndpoint(ccr.conf); err != nil {
+		return err
+	}
+	maskSize, err := nodeCIDRMaskSize(ccfg, podNet)
+	if err != nil {
+		return err
+	}
+	// nodes may be removed later, so only warn here
+	if err := checkPodCIDRCapacity(ccfg, podNet, maskSize); err != nil {
+		logrus.Warnf("%v", err)
+	}
+
+	return nil
+}
+
 type OpenPortResponsibility struct {
 	next chain.Responsibility
 	conf *DeployConfig
@@ -316,6 +777,9 @@ func (ccr *OpenPortResponsibility) Execute() error {
 		"udp": true,
 		"tcp": true,
 	}
+	if !infrastructure.IsValidFirewall(ccr.conf.Firewall) {
+		return fmt.Errorf("unsupport firewall: %s", ccr.conf.Firewall)
+	}
 	for name, v := range ccr.conf.OpenPorts {
 		for _, port := range v {
 			if !endpoint.ValidPort(port.Port) {
@@ -324,6 +788,109 @@ func (ccr *OpenPortResponsibility) Execute() error {
 			if _, ok := supportProtocal[port.Protocol]; !ok {
 				return fmt.Errorf("invalid protocol: %s for %s", port.Protocol, name)
 			}
+			if err := checkOpenPortZone(ccr.conf.Firewall, port.Zone); err != nil {
+				return fmt.Errorf("%v for %s", err, name)
+			}
+			for _, s := range port.Sources {
+				if _, _, err := net.ParseCIDR(s); err != nil && net.ParseIP(s) == nil {
+					return fmt.Errorf("invalid source: %s of port %d for %s", s, port.Port, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+var zoneRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// checkOpenPortZone zone is name of firewalld zone, nftables table or iptables chain, and ufw has no zone
+func checkOpenPortZone(firewall string, zone string) error {
+	if zone == "" {
+		return nil
+	}
+	if strings.ToLower(firewall) == "ufw" {
+		return fmt.Errorf("zone %s is not supported by ufw", zone)
+	}
+	if !zoneRegexp.MatchString(zone) {
+		return fmt.Errorf("invalid zone: %s", zone)
+	}
+	return nil
+}
+
+type NodeTuningResponsibility struct {
+	next chain.Responsibility
+	conf map[string]*NodeTuning
+}
+
+func (ccr *NodeTuningResponsibility) SetNexter(nexter chain.Responsibility) {
+	ccr.next = nexter
+}
+
+func (ccr *NodeTuningResponsibility) Nexter() chain.Responsibility {
+	return ccr.next
+}
+
+var (
+	sysctlKeyRegexp    = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+	sysctlValueRegexp  = regexp.MustCompile(`^[a-zA-Z0-9_.:,\- \t]+$`)
+	kernelModuleRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	// ulimits which can be set both in limits.conf and as DefaultLimit of systemd
+	supportUlimits = map[string]bool{
+		"as": true, "core": true, "cpu": true, "data": true, "fsize": true, "locks": true, "memlock": true,
+		"msgqueue": true, "nice": true, "nofile": true, "nproc": true, "rss": true, "rtprio": true,
+		"sigpending": true, "stack": true,
+	}
+)
+
+func (ccr *NodeTuningResponsibility) Execute() error {
+	for name, tuning := range ccr.conf {
+		if _, ok := toTypeInt[name]; !ok {
+			return fmt.Errorf("invalid role %s of node tuning", name)
+		}
+		if tuning == nil {
+			continue
+		}
+		if err := checkNodeTuning(tuning); err != nil {
+			return fmt.Errorf("%v for %s", err, name)
+		}
+	}
+
+	return nil
+}
+
+func checkNodeTuning(tuning *NodeTuning) error {
+	for k, v := range tuning.Sysctl {
+		if !sysctlKeyRegexp.MatchString(k) || !sysctlValueRegexp.MatchString(v) {
+			return fmt.Errorf("invalid sysctl: %s = %s", k, v)
+		}
+	}
+	for _, m := range tuning.KernelModules {
+		if !kernelModuleRegexp.MatchString(m) {
+			return fmt.Errorf("invalid kernel module: %s", m)
+		}
+	}
+	switch tuning.SELinux {
+	case "", "enforcing", "permissive", "disabled":
+	default:
+		return fmt.Errorf("invalid selinux mode: %s", tuning.SELinux)
+	}
+	for k, v := range tuning.Ulimits {
+		if !supportUlimits[k] {
+			return fmt.Errorf("unsupport ulimit: %s", k)
+		}
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil && v != "unlimited" {
+			return fmt.Errorf("invalid value %s of ulimit %s", v, k)
+		}
+	}
+	switch tuning.TransparentHugepage {
+	case "", "always", "madvise", "never":
+	default:
+		return fmt.Errorf("invalid transparent hugepage: %s", tuning.TransparentHugepage)
+	}
+	for _, s := range tuning.NTPServers {
+		if net.ParseIP(s) == nil && len(validation.IsDNS1123Subdomain(s)) != 0 {
+			return fmt.Errorf("invalid ntp server: %s", s)
 		}
 	}
 
@@ -526,12 +1093,20 @@ func RunChecker(conf *DeployConfig) error {
 		conf: conf.InstallConfig,
 		arch: arch,
 	}
-	openport := OpenPortResponsibility{
+	tuning := NodeTuningResponsibility{
 		next: &install,
+		conf: conf.NodeTuning,
+	}
+	openport := OpenPortResponsibility{
+		next: &tuning,
 		conf: conf,
 	}
-	sans := ApiSansResponsibility{
+	address := AddressResponsibility{
 		next: &openport,
+		conf: conf,
+	}
+	sans := ApiSansResponsibility{
+		next: &address,
 		conf: conf.ApiServerCertSans,
 	}
 	network := NetworkResponsibility{
@@ -17,9 +17,12 @@ package cmd
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"isula.org/eggo/pkg/api"
 )
 
 func TestRunChecker(t *testing.T) {
@@ -80,6 +83,54 @@ func TestRunChecker(t *testing.T) {
 	}
 	conf.LoadBalance.BindPort = tmpBindPort
 
+	// test invalid bastion
+	conf.Masters[0].Bastion = &BastionConfig{Ip: "._-^(!#%"}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid bastion failed: %v", err)
+	}
+	conf.Masters[0].Bastion = nil
+
+	// test invalid host key checking
+	conf.HostKeyChecking = "invalid"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid host key checking failed: %v", err)
+	}
+	conf.HostKeyChecking = ""
+	conf.Masters[0].Fingerprint = "MD5:aa:bb"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid fingerprint failed: %v", err)
+	}
+	conf.Masters[0].Fingerprint = ""
+
+	// test login of hosts
+	conf.Masters[0].CertificatePath = "id_rsa-cert.pub"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test relative certificate path failed: %v", err)
+	}
+	conf.Masters[0].CertificatePath = ""
+	conf.Masters[0].PrivateKeyPassphrase = "agent:"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test ssh-agent as passphrase failed: %v", err)
+	}
+	conf.Masters[0].PrivateKeyPassphrase = ""
+	tmpKeyPath := conf.PrivateKeyPath
+	conf.PrivateKeyPath = ""
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test no login of hosts failed: %v", err)
+	}
+	for _, h := range getAllHostConfigs(conf) {
+		h.Password = "agent:"
+	}
+	conf.LoadBalance.Password = "agent:"
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test login set by hosts failed: %v", err)
+	}
+	for _, h := range getAllHostConfigs(conf) {
+		h.Password = ""
+	}
+	conf.LoadBalance.Password = ""
+	conf.PrivateKeyPath = tmpKeyPath
+
 	// test invalid service cluster
 	tmpGateway := conf.Service.Gateway
 	conf.Service.Gateway = "192.168.0.777"
@@ -88,6 +139,83 @@ func TestRunChecker(t *testing.T) {
 	}
 	conf.Service.Gateway = tmpGateway
 
+	// test runtime endpoint of other runtime
+	conf.Runtime = "crio"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test runtime endpoint of other runtime failed: %v", err)
+	}
+	tmpRuntimeEndpoint := conf.RuntimeEndpoint
+	conf.RuntimeEndpoint = ""
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test default runtime endpoint failed: %v", err)
+	}
+	conf.Runtime = "unknown"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test unknown runtime failed: %v", err)
+	}
+	conf.Runtime, conf.RuntimeEndpoint = "iSulad", tmpRuntimeEndpoint
+
+	// test runtime handlers
+	conf.RuntimeHandlers = []*RuntimeHandler{{Name: "kata", Path: "/usr/bin/kata-runtime", Nodes: []string{conf.Workers[0].Ip}}}
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test valid runtime handler failed: %v", err)
+	}
+	conf.RuntimeHandlers[0].Path = ""
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test runtime handler without path failed: %v", err)
+	}
+	conf.RuntimeHandlers[0].Path = "/usr/bin/kata-runtime"
+	conf.RuntimeHandlers[0].Nodes = []string{"unknown"}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test runtime handler on unknown node failed: %v", err)
+	}
+	conf.RuntimeHandlers[0].Nodes = nil
+	conf.RuntimeHandlers = append(conf.RuntimeHandlers, &RuntimeHandler{Name: "kata", Path: "/usr/bin/kata-runtime"})
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test duplicate runtime handler failed: %v", err)
+	}
+	conf.RuntimeHandlers = conf.RuntimeHandlers[:1]
+	conf.Runtime = "docker"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test runtime handler of docker failed: %v", err)
+	}
+	conf.Runtime = "iSulad"
+	conf.RuntimeHandlers = nil
+
+	// test registries and credentials
+	conf.Registries = []*RegistryConfig{{Host: "example.com:5000", Mirrors: []string{"mirror.example.com"}}}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test registries of isulad failed: %v", err)
+	}
+	conf.Runtime, conf.RuntimeEndpoint = "containerd", ""
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test valid registries failed: %v", err)
+	}
+	conf.Registries[0].Host = "https://example.com/v2"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid registry host failed: %v", err)
+	}
+	conf.Registries[0].Host = "example.com:5000"
+	conf.Registries[0].ClientCert = "/etc/hosts"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test client cert without key failed: %v", err)
+	}
+	conf.Registries = nil
+	conf.Runtime, conf.RuntimeEndpoint = "iSulad", tmpRuntimeEndpoint
+	credentials := filepath.Join(tempdir, "auth.json")
+	if err = ioutil.WriteFile(credentials, []byte(`{"auths": {"example.com": {"auth": "YWRtaW46YWRtaW4="}}}`), 0600); err != nil {
+		t.Fatalf("write credentials failed: %v", err)
+	}
+	conf.RegistryCredentials = credentials
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test valid registry credentials failed: %v", err)
+	}
+	conf.RegistryCredentials = credentials + ".notexist"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test not exist registry credentials failed: %v", err)
+	}
+	conf.RegistryCredentials = ""
+
 	// test invalid network
 	tmpPodCIDR := conf.NetWork.PodCIDR
 	conf.NetWork.PodCIDR = "192.168.0.777"
@@ -126,10 +254,169 @@ func TestRunChecker(t *testing.T) {
 		}
 	}
 
+	// test firewall, zone and sources of open port
+	conf.Firewall = "pf"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid firewall failed: %v", err)
+	}
+	conf.Firewall = "firewalld"
+	conf.OpenPorts["worker"] = append(conf.OpenPorts["worker"], &OpenPorts{
+		Port: 10250, Protocol: "tcp", Zone: "internal", Sources: []string{"10.0.0.0/8", "fd00::1"},
+	})
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test valid zone and sources failed: %v", err)
+	}
+	conf.Firewall = "ufw"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test zone of ufw failed: %v", err)
+	}
+	conf.Firewall = ""
+	conf.OpenPorts["worker"][len(conf.OpenPorts["worker"])-1].Sources = []string{"10.0.0.0/33"}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid source failed: %v", err)
+	}
+	conf.OpenPorts["worker"] = conf.OpenPorts["worker"][:len(conf.OpenPorts["worker"])-1]
+
+	// test overlapped addresses
+	tmpServiceCIDR := conf.Service.CIDR
+	conf.Service.CIDR = "10.244.128.0/17"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test pod cidr overlaps with service cidr failed: %v", err)
+	}
+	conf.Service.CIDR = tmpServiceCIDR
+	conf.NetWork.PodCIDR = conf.Masters[0].Ip + "/24"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test node in pod cidr failed: %v", err)
+	}
+	conf.NetWork.PodCIDR = tmpPodCIDR
+	conf.Service.Gateway = "10.33.0.1"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test gateway out of service cidr failed: %v", err)
+	}
+	conf.Service.Gateway = tmpGateway
+	tmpDnsVip := conf.DnsVip
+	conf.DnsVip = "10.33.0.10"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test dns vip out of service cidr failed: %v", err)
+	}
+	conf.DnsVip = tmpDnsVip
+
+	// test api server endpoint
+	tmpEndpoint := conf.ApiServerEndpoint
+	conf.ApiServerEndpoint = "192.168.100.100:6443"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test endpoint of unknown address failed: %v", err)
+	}
+	conf.ApiServerEndpoint = conf.Masters[0].Ip + ":6443"
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test endpoint of master failed: %v", err)
+	}
+	conf.ApiServerEndpoint = "api.eggo.org:6443"
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test endpoint not in cert sans failed: %v", err)
+	}
+	tmpDNSNames := conf.ApiServerCertSans.DNSNames
+	conf.ApiServerCertSans.DNSNames = append([]string{"api.eggo.org"}, tmpDNSNames...)
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test endpoint in cert sans failed: %v", err)
+	}
+	conf.ApiServerCertSans.DNSNames = tmpDNSNames
+	conf.ApiServerEndpoint = tmpEndpoint
+
+	// test node cidr mask size
+	conf.ConfigExtraArgs = append(conf.ConfigExtraArgs, &ConfigExtraArgs{
+		Name:      "kube-controller-manager",
+		ExtraArgs: map[string]string{nodeCIDRMaskSizeArg: "8"},
+	})
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test invalid node cidr mask size failed: %v", err)
+	}
+	conf.ConfigExtraArgs = conf.ConfigExtraArgs[:len(conf.ConfigExtraArgs)-1]
+
+	// test node tuning
+	conf.NodeTuning = map[string]*NodeTuning{
+		"worker": {
+			Sysctl:              map[string]string{"net.ipv4.ip_local_port_range": "1024 65000"},
+			KernelModules:       []string{"ip_vs"},
+			SELinux:             "permissive",
+			Ulimits:             map[string]string{"nofile": "1048576", "memlock": "unlimited"},
+			TransparentHugepage: "never",
+			NTPServers:          []string{"ntp.example.com", "192.168.0.1"},
+		},
+	}
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test valid node tuning failed: %v", err)
+	}
+	invalidTunings := []*NodeTuning{
+		{Sysctl: map[string]string{"vm.swappiness": "0; reboot"}},
+		{KernelModules: []string{"ip_vs ip_vs_rr"}},
+		{SELinux: "off"},
+		{Ulimits: map[string]string{"maxlogins": "10"}},
+		{Ulimits: map[string]string{"nofile": "-1"}},
+		{TransparentHugepage: "on"},
+		{NTPServers: []string{"ntp_server"}},
+	}
+	for _, tuning := range invalidTunings {
+		conf.NodeTuning = map[string]*NodeTuning{"worker": tuning}
+		if err = RunChecker(conf); err == nil {
+			t.Fatalf("test invalid node tuning %+v failed", tuning)
+		}
+	}
+	conf.NodeTuning = map[string]*NodeTuning{"node": {}}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test node tuning of invalid role failed")
+	}
+	conf.NodeTuning = nil
+
 	// test invalid install config
 	conf.InstallConfig.PackageSrc.SrcPath["test-arch"] = "package-test-arch.tar.gz"
 	if err = RunChecker(conf); err == nil {
 		t.Fatalf("test invalid install config failed: %v", err)
 	}
 	delete(conf.InstallConfig.PackageSrc.SrcPath, "test-arch")
+
+	// test local cluster
+	conf.Local = true
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test local cluster with multiple nodes failed: %v", err)
+	}
+	local := &HostConfig{Name: "local", Ip: "127.0.0.1", Port: 22, Arch: "amd64"}
+	conf.Masters = []*HostConfig{local}
+	conf.Workers = []*HostConfig{local}
+	conf.Etcds = []*HostConfig{local}
+	conf.LoadBalance = LoadBalance{}
+	conf.ApiServerEndpoint = "127.0.0.1:6443"
+	conf.Password = ""
+	conf.PrivateKeyPath = ""
+	if err = RunChecker(conf); err != nil {
+		t.Fatalf("test local cluster failed: %v", err)
+	}
+	conf.Workers = []*HostConfig{{Name: "remote", Ip: "192.168.0.3", Port: 22, Arch: "amd64"}}
+	if err = RunChecker(conf); err == nil {
+		t.Fatalf("test local cluster with remote node failed: %v", err)
+	}
+}
+
+func TestCheckPodCIDRCapacity(t *testing.T) {
+	ccfg := toClusterdeploymentConfig(&DeployConfig{
+		Masters: []*HostConfig{{Name: "master", Ip: "192.168.0.1", Port: 22}},
+		Workers: []*HostConfig{{Name: "worker1", Ip: "192.168.0.2", Port: 22}, {Name: "worker2", Ip: "192.168.0.3", Port: 22}},
+	}, nil)
+	_, podNet, _ := net.ParseCIDR("10.244.0.0/23")
+
+	maskSize, err := nodeCIDRMaskSize(ccfg, podNet)
+	if err != nil || maskSize != defaultNodeCIDRMaskSize {
+		t.Fatalf("invalid default node cidr mask size: %d, %v", maskSize, err)
+	}
+	if err = checkPodCIDRCapacity(ccfg, podNet, maskSize); err == nil {
+		t.Fatalf("expect pod cidr is too small for 3 nodes")
+	}
+
+	api.WithControllerManagerExtrArgs(map[string]string{nodeCIDRMaskSizeArg: "25"})(ccfg)
+	if maskSize, err = nodeCIDRMaskSize(ccfg, podNet); err != nil || maskSize != 25 {
+		t.Fatalf("invalid node cidr mask size: %d, %v", maskSize, err)
+	}
+	if err = checkPodCIDRCapacity(ccfg, podNet, maskSize); err != nil {
+		t.Fatalf("check pod cidr capacity failed: %v", err)
+	}
 }
@@ -29,10 +29,16 @@ func cleanup(ccfg *api.ClusterConfig) error {
 	return clusterdeployment.RemoveCluster(ccfg)
 }
 
-func cleanupCluster(cmd *cobra.Command, args []string) error {
+func cleanupCluster(cmd *cobra.Command, args []string) (err error) {
 	if opts.debug {
 		initLog()
 	}
+	if err = startOutput("cleanup"); err != nil {
+		return err
+	}
+	defer func() {
+		finishOutput(err)
+	}()
 
 	if opts.cleanupConfig == "" && opts.cleanupClusterID == "" {
 		return fmt.Errorf("please specify cluster id")
@@ -41,7 +47,7 @@ func cleanupCluster(cmd *cobra.Command, args []string) error {
 	confPath := opts.cleanupConfig
 	if confPath == "" {
 		confPath = savedDeployConfigPath(opts.cleanupClusterID)
-		_, err := os.Stat(confPath)
+		_, err = os.Stat(confPath)
 		if os.IsNotExist(err) {
 			confPath = defaultDeployConfigPath()
 		} else if err != nil {
@@ -53,6 +59,7 @@ func cleanupCluster(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("load deploy config file %v failed: %v", confPath, err)
 	}
+	setOutputResult(conf.ClusterID, nil)
 
 	if err = checkCmdHooksParameter(opts.clusterPrehook, opts.clusterPosthook); err != nil {
 		return err
@@ -72,7 +79,7 @@ func cleanupCluster(cmd *cobra.Command, args []string) error {
 	}
 	defer func() {
 		if terr := holder.Remove(); terr != nil {
-			fmt.Printf("remove process place holder failed: %v", terr)
+			fmt.Fprintf(infoWriter(), "remove process place holder failed: %v\n", terr)
 		}
 	}()
 
@@ -30,9 +30,11 @@ import (
 
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/clusterdeployment/binary/coredns"
+	"isula.org/eggo/pkg/clusterdeployment/runtime"
 	"isula.org/eggo/pkg/constants"
 	"isula.org/eggo/pkg/utils"
 	"isula.org/eggo/pkg/utils/infra"
+	"isula.org/eggo/pkg/utils/secrets"
 )
 
 const (
@@ -74,6 +76,8 @@ func ToEggoOpenPort(ports []*OpenPorts) []*api.OpenPorts {
 		res = append(res, &api.OpenPorts{
 			Port:     pc.Port,
 			Protocol: pc.Protocol,
+			Zone:     pc.Zone,
+			Sources:  pc.Sources,
 		})
 	}
 	return res
@@ -101,7 +105,92 @@ func savedDeployConfigPath(ClusterID string) string {
 	return filepath.Join(api.GetEggoClusterPath(), ClusterID, "deploy.yaml")
 }
 
+// loginSecret is password or passphrase of login in deploy config, name is its name in keystore
+type loginSecret struct {
+	value *string
+	name  string
+}
+
+func loginSecrets(cc *DeployConfig) []loginSecret {
+	var res []loginSecret
+	addLogin := func(password, passphrase *string, prefix string) {
+		res = append(res, loginSecret{value: password, name: cc.ClusterID + "/" + prefix + "password"},
+			loginSecret{value: passphrase, name: cc.ClusterID + "/" + prefix + "passphrase"})
+	}
+
+	addLogin(&cc.Password, &cc.PrivateKeyPassphrase, "")
+	if cc.Bastion != nil {
+		addLogin(&cc.Bastion.Password, &cc.Bastion.PrivateKeyPassphrase, "bastion-")
+	}
+	addLogin(&cc.LoadBalance.Password, &cc.LoadBalance.PrivateKeyPassphrase, "loadbalance-")
+	if cc.LoadBalance.Bastion != nil {
+		addLogin(&cc.LoadBalance.Bastion.Password, &cc.LoadBalance.Bastion.PrivateKeyPassphrase, "loadbalance-bastion-")
+	}
+	hosts := append([]*HostConfig{}, cc.Masters...)
+	hosts = append(hosts, cc.Workers...)
+	hosts = append(hosts, cc.Etcds...)
+	for _, h := range hosts {
+		name := h.Name
+		if name == "" {
+			name = h.Ip
+		}
+		addLogin(&h.Password, &h.PrivateKeyPassphrase, name+"-")
+		if h.Bastion != nil {
+			addLogin(&h.Bastion.Password, &h.Bastion.PrivateKeyPassphrase, name+"-bastion-")
+		}
+	}
+	return res
+}
+
+// hasPlainSecrets return whether deploy config contains passwords or passphrases written in plain text
+func hasPlainSecrets(cc *DeployConfig) bool {
+	for _, s := range loginSecrets(cc) {
+		if secrets.IsPlain(*s.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// storePlainSecrets move passwords and passphrases written in plain text to keystore, and replace them by
+// references, so that saved deploy config never contains secrets. If passphrase of keystore cannot be got,
+// such as running in CI without terminal, secrets are only kept in plain text with --allow-plain-secrets
+func storePlainSecrets(cc *DeployConfig) error {
+	if !hasPlainSecrets(cc) {
+		return nil
+	}
+	if !secrets.PassphraseAvailable() {
+		if !opts.allowPlainSecrets {
+			return fmt.Errorf("passphrase of keystore is required to save passwords of cluster %s: set it by %s, "+
+				"or set passwords by reference: env:NAME, file:PATH, or run with --allow-plain-secrets to save them in plain text",
+				cc.ClusterID, secrets.PassphraseEnv)
+		}
+		logrus.Warnf("passphrase of keystore is not set by %s, secrets of cluster %s are saved in plain text",
+			secrets.PassphraseEnv, cc.ClusterID)
+		return nil
+	}
+
+	for _, s := range loginSecrets(cc) {
+		if !secrets.IsPlain(*s.value) {
+			continue
+		}
+		if err := secrets.StoreKeystore(s.name, *s.value); err != nil {
+			return fmt.Errorf("store %s in keystore failed: %v, or set it by reference: env:NAME, file:PATH", s.name, err)
+		}
+		logrus.Infof("%s is stored in keystore", s.name)
+		*s.value = secrets.RefKeystore + ":" + s.name
+	}
+	return nil
+}
+
 func saveDeployConfig(cc *DeployConfig, filePath string) error {
+	// deploy config is saved in temporary home of dry run, which is removed after dry run
+	if !opts.dryRun {
+		if err := storePlainSecrets(cc); err != nil {
+			return err
+		}
+	}
+	setDeployConfigVersion(cc)
 	d, err := yaml.Marshal(cc)
 	if err != nil {
 		return fmt.Errorf("marshal template config failed: %v", err)
@@ -137,9 +226,13 @@ func loadDeployConfig(file string) (*DeployConfig, error) {
 		return nil, err
 	}
 
-	conf := &DeployConfig{}
-	if err := yaml.Unmarshal([]byte(yamlStr), conf); err != nil {
-		return nil, err
+	conf, version, err := parseDeployConfig(yamlStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", file, err)
+	}
+	if version != CurrentDeployConfigVersion {
+		logrus.Debugf("deploy config %s of version %s is converted to %s, run \"eggo config migrate\" to upgrade it",
+			file, version, CurrentDeployConfigVersion)
 	}
 
 	// default install etcds to masters if etcds not configed
@@ -200,8 +293,63 @@ func getDefaultPrivateKeyPath() string {
 	return filepath.Join(utils.GetSysHome(), ".ssh", "id_rsa")
 }
 
-func createCommonHostConfig(userHostconfig *HostConfig, defaultName string, username string,
-	password string, userPrivateKeyPath string) *api.HostConfig {
+func createBastionConfig(bastion *BastionConfig, clusterBastion *BastionConfig) *api.BastionConfig {
+	if bastion == nil {
+		bastion = clusterBastion
+	}
+	if bastion == nil || bastion.Ip == "" {
+		return nil
+	}
+	port := 22
+	if bastion.Port != 0 {
+		port = bastion.Port
+	}
+	return &api.BastionConfig{
+		Address:              bastion.Ip,
+		Port:                 port,
+		UserName:             bastion.Username,
+		Password:             bastion.Password,
+		PrivateKeyPath:       bastion.PrivateKeyPath,
+		PrivateKeyPassphrase: bastion.PrivateKeyPassphrase,
+		CertificatePath:      bastion.CertificatePath,
+		Fingerprint:          bastion.Fingerprint,
+	}
+}
+
+func clusterLoginConfig(conf *DeployConfig) LoginConfig {
+	return LoginConfig{
+		Username:             conf.Username,
+		Password:             conf.Password,
+		PrivateKeyPath:       conf.PrivateKeyPath,
+		PrivateKeyPassphrase: conf.PrivateKeyPassphrase,
+		CertificatePath:      conf.CertificatePath,
+	}
+}
+
+// mergeLoginConfig fill login of host which not set by login of cluster,
+// passphrase and certificate belong to private key, so they are not inherited if host set its own key
+func mergeLoginConfig(host LoginConfig, cluster LoginConfig) LoginConfig {
+	res := host
+	if res.Username == "" {
+		res.Username = cluster.Username
+	}
+	if res.Password == "" {
+		res.Password = cluster.Password
+	}
+	if res.PrivateKeyPath == "" {
+		res.PrivateKeyPath = cluster.PrivateKeyPath
+		if res.PrivateKeyPassphrase == "" {
+			res.PrivateKeyPassphrase = cluster.PrivateKeyPassphrase
+		}
+		if res.CertificatePath == "" {
+			res.CertificatePath = cluster.CertificatePath
+		}
+	}
+	return res
+}
+
+func createCommonHostConfig(userHostconfig *HostConfig, defaultName string, clusterLogin LoginConfig,
+	clusterBastion *BastionConfig) *api.HostConfig {
 	arch, name, port, privateKeyPath := "amd64", defaultName, 22, getDefaultPrivateKeyPath()
 	if userHostconfig.Arch != "" {
 		arch = userHostconfig.Arch
@@ -209,8 +357,9 @@ func createCommonHostConfig(userHostconfig *HostConfig, defaultName string, user
 	if userHostconfig.Name != "" {
 		name = userHostconfig.Name
 	}
-	if userPrivateKeyPath != "" {
-		privateKeyPath = userPrivateKeyPath
+	login := mergeLoginConfig(userHostconfig.LoginConfig, clusterLogin)
+	if login.PrivateKeyPath != "" {
+		privateKeyPath = login.PrivateKeyPath
 	}
 	// If private key path does not exist, ignore it
 	if _, err := os.Stat(privateKeyPath); err != nil {
@@ -221,13 +370,17 @@ func createCommonHostConfig(userHostconfig *HostConfig, defaultName string, user
 	}
 
 	hostconfig := &api.HostConfig{
-		Arch:           arch,
-		Name:           name,
-		Address:        userHostconfig.Ip,
-		Port:           port,
-		UserName:       username,
-		Password:       password,
-		PrivateKeyPath: privateKeyPath,
+		Arch:                 arch,
+		Name:                 name,
+		Address:              userHostconfig.Ip,
+		Port:                 port,
+		UserName:             login.Username,
+		Password:             login.Password,
+		PrivateKeyPath:       privateKeyPath,
+		PrivateKeyPassphrase: login.PrivateKeyPassphrase,
+		CertificatePath:      login.CertificatePath,
+		Fingerprint:          userHostconfig.Fingerprint,
+		Bastion:              createBastionConfig(userHostconfig.Bastion, clusterBastion),
 	}
 
 	return hostconfig
@@ -331,6 +484,33 @@ func fillOpenPort(ccfg *api.ClusterConfig, openports map[string][]*OpenPorts, dn
 	}
 }
 
+func fillNodeTuning(ccfg *api.ClusterConfig, tunings map[string]*NodeTuning) {
+	// key: master, worker, etcd, loadbalance
+	for t, tuning := range tunings {
+		role, ok := toTypeInt[t]
+		if !ok {
+			logrus.Warnf("invalid role %s", t)
+			continue
+		}
+		if tuning == nil {
+			continue
+		}
+
+		merged := api.MergeNodeTuning(ccfg.RoleInfra[role].Tuning, &api.NodeTuning{
+			Sysctl:              tuning.Sysctl,
+			KernelModules:       tuning.KernelModules,
+			SELinux:             tuning.SELinux,
+			Ulimits:             tuning.Ulimits,
+			TransparentHugepage: tuning.TransparentHugepage,
+			NTPServers:          tuning.NTPServers,
+		})
+		if tuning.DisableSwap != nil {
+			merged.DisableSwap = *tuning.DisableSwap
+		}
+		ccfg.RoleInfra[role].Tuning = merged
+	}
+}
+
 func defaultHostName(clusterID string, nodeType string, i int) string {
 	return fmt.Sprintf("%s-%s-%s", clusterID, nodeType, strconv.Itoa(i))
 }
@@ -348,10 +528,13 @@ func getAllHostConfigs(conf *DeployConfig) []*HostConfig {
 	allHostConfigs := append(conf.Masters, conf.Workers...)
 	allHostConfigs = append(allHostConfigs, conf.Etcds...)
 	allHostConfigs = append(allHostConfigs, &HostConfig{
-		Name: conf.LoadBalance.Name,
-		Ip:   conf.LoadBalance.Ip,
-		Port: conf.LoadBalance.Port,
-		Arch: conf.LoadBalance.Arch,
+		Name:        conf.LoadBalance.Name,
+		Ip:          conf.LoadBalance.Ip,
+		Port:        conf.LoadBalance.Port,
+		Arch:        conf.LoadBalance.Arch,
+		Fingerprint: conf.LoadBalance.Fingerprint,
+		Bastion:     conf.LoadBalance.Bastion,
+		LoginConfig: conf.LoadBalance.LoginConfig,
 	})
 
 	return allHostConfigs
@@ -364,6 +547,9 @@ func createHostConfig(host *HostConfig, joinHost *HostConfig, defaultName string
 		hostconfig.Name = host.Name
 		hostconfig.Arch = host.Arch
 		hostconfig.Port = host.Port
+		hostconfig.Fingerprint = host.Fingerprint
+		hostconfig.Bastion = host.Bastion
+		hostconfig.LoginConfig = host.LoginConfig
 	} else {
 		hostconfig.Name = defaultName
 		if joinHost.Name != "" {
@@ -377,6 +563,9 @@ func createHostConfig(host *HostConfig, joinHost *HostConfig, defaultName string
 		if joinHost.Port != 0 {
 			hostconfig.Port = joinHost.Port
 		}
+		hostconfig.Fingerprint = joinHost.Fingerprint
+		hostconfig.Bastion = joinHost.Bastion
+		hostconfig.LoginConfig = joinHost.LoginConfig
 	}
 	hostconfig.Ip = joinHost.Ip
 
@@ -390,7 +579,7 @@ func fillHostConfig(ccfg *api.ClusterConfig, conf *DeployConfig) {
 
 	for i, master := range conf.Masters {
 		hostconfig = createCommonHostConfig(master, conf.ClusterID+"-master-"+strconv.Itoa(i),
-			conf.Username, conf.Password, conf.PrivateKeyPath)
+			clusterLoginConfig(conf), conf.Bastion)
 		hostconfig.Type |= api.Master
 		idx, ok := cache[hostconfig.Address]
 		if ok {
@@ -405,7 +594,7 @@ func fillHostConfig(ccfg *api.ClusterConfig, conf *DeployConfig) {
 		idx, exist := cache[worker.Ip]
 		if !exist {
 			hostconfig = createCommonHostConfig(worker, conf.ClusterID+"-worker-"+strconv.Itoa(i),
-				conf.Username, conf.Password, conf.PrivateKeyPath)
+				clusterLoginConfig(conf), conf.Bastion)
 		} else {
 			hostconfig = nodes[idx]
 		}
@@ -422,7 +611,7 @@ func fillHostConfig(ccfg *api.ClusterConfig, conf *DeployConfig) {
 		idx, exist := cache[etcd.Ip]
 		if !exist {
 			hostconfig = createCommonHostConfig(etcd, conf.ClusterID+"-etcd-"+strconv.Itoa(i),
-				conf.Username, conf.Password, conf.PrivateKeyPath)
+				clusterLoginConfig(conf), conf.Bastion)
 		} else {
 			hostconfig = nodes[idx]
 		}
@@ -439,13 +628,16 @@ func fillHostConfig(ccfg *api.ClusterConfig, conf *DeployConfig) {
 		idx, exist := cache[conf.LoadBalance.Ip]
 		if !exist {
 			config := &HostConfig{
-				Name: conf.LoadBalance.Name,
-				Ip:   conf.LoadBalance.Ip,
-				Port: conf.LoadBalance.Port,
-				Arch: conf.LoadBalance.Arch,
+				Name:        conf.LoadBalance.Name,
+				Ip:          conf.LoadBalance.Ip,
+				Port:        conf.LoadBalance.Port,
+				Arch:        conf.LoadBalance.Arch,
+				Fingerprint: conf.LoadBalance.Fingerprint,
+				Bastion:     conf.LoadBalance.Bastion,
+				LoginConfig: conf.LoadBalance.LoginConfig,
 			}
-			hostconfig = createCommonHostConfig(config, conf.ClusterID+"-loadbalance", conf.Username,
-				conf.Password, conf.PrivateKeyPath)
+			hostconfig = createCommonHostConfig(config, conf.ClusterID+"-loadbalance", clusterLoginConfig(conf),
+				conf.Bastion)
 		} else {
 			hostconfig = nodes[idx]
 		}
@@ -563,11 +755,56 @@ func fillExtrArgs(ccfg *api.ClusterConfig, eargs []*ConfigExtraArgs) {
 	}
 }
 
+func fillRuntimeHandlers(ccfg *api.ClusterConfig, handlers []*RuntimeHandler) {
+	// nodes of handler can be set by ip, convert them to names of nodes
+	names := make(map[string]string)
+	for _, node := range ccfg.Nodes {
+		names[node.Address] = node.Name
+	}
+
+	for _, h := range handlers {
+		var nodes []string
+		for _, n := range h.Nodes {
+			if name, ok := names[n]; ok {
+				n = name
+			}
+			nodes = append(nodes, n)
+		}
+		ccfg.WorkerConfig.ContainerEngineConf.RuntimeHandlers = append(ccfg.WorkerConfig.ContainerEngineConf.RuntimeHandlers,
+			&api.RuntimeHandler{
+				Name:        h.Name,
+				RuntimeType: h.RuntimeType,
+				Path:        h.Path,
+				RuntimeArgs: h.RuntimeArgs,
+				ConfigPath:  h.ConfigPath,
+				Nodes:       nodes,
+				Packages:    ToEggoPackageConfig(h.Packages),
+			})
+	}
+}
+
+func fillRegistries(ccfg *api.ClusterConfig, registries []*RegistryConfig) {
+	for _, r := range registries {
+		ccfg.WorkerConfig.ContainerEngineConf.Registries = append(ccfg.WorkerConfig.ContainerEngineConf.Registries,
+			&api.RegistryConfig{
+				Host:       r.Host,
+				Mirrors:    r.Mirrors,
+				CAFile:     r.CAFile,
+				ClientCert: r.ClientCert,
+				ClientKey:  r.ClientKey,
+				SkipVerify: r.SkipVerify,
+			})
+	}
+}
+
 func toClusterdeploymentConfig(conf *DeployConfig, hooks []*api.ClusterHookConf) *api.ClusterConfig {
 	ccfg := getDefaultClusterdeploymentConfig()
 
 	setIfStrConfigNotEmpty(&ccfg.Name, conf.ClusterID)
 	fillHostConfig(ccfg, conf)
+	ccfg.HostKeyChecking = conf.HostKeyChecking
+	ccfg.Local = conf.Local
+	ccfg.Firewall = conf.Firewall
 	ccfg.Certificate.ExternalCA = conf.ExternalCA
 	setIfStrConfigNotEmpty(&ccfg.Certificate.ExternalCAPath, conf.ExternalCAPath)
 	setIfStrConfigNotEmpty(&ccfg.ServiceCluster.CIDR, conf.Service.CIDR)
@@ -596,12 +833,19 @@ func toClusterdeploymentConfig(conf *DeployConfig, hooks []*api.ClusterHookConf)
 	setIfStrConfigNotEmpty(&ccfg.WorkerConfig.KubeletConf.CniBinDir, conf.CniBinDir)
 	setIfStrConfigNotEmpty(&ccfg.WorkerConfig.ContainerEngineConf.Runtime, conf.Runtime)
 	setIfStrConfigNotEmpty(&ccfg.WorkerConfig.ContainerEngineConf.RuntimeEndpoint, conf.RuntimeEndpoint)
+	if rt := runtime.GetRuntime(conf.Runtime); rt != nil && ccfg.WorkerConfig.ContainerEngineConf.RuntimeEndpoint == "" {
+		ccfg.WorkerConfig.ContainerEngineConf.RuntimeEndpoint = rt.GetRuntimeEndpoint()
+	}
 	setStrArray(&ccfg.WorkerConfig.ContainerEngineConf.RegistryMirrors, conf.RegistryMirrors)
 	setStrArray(&ccfg.WorkerConfig.ContainerEngineConf.InsecureRegistries, conf.InsecureRegistries)
+	fillRuntimeHandlers(ccfg, conf.RuntimeHandlers)
+	fillRegistries(ccfg, conf.Registries)
+	ccfg.WorkerConfig.ContainerEngineConf.RegistryCredentials = conf.RegistryCredentials
 	fillLoadBalance(&ccfg.LoadBalancer, conf.LoadBalance)
 	fillAPIEndPoint(&ccfg.APIEndpoint, conf)
 	fillPackageConfig(ccfg, &conf.InstallConfig)
 	fillOpenPort(ccfg, conf.OpenPorts, conf.Service.DNS.CorednsType, conf.LoadBalance)
+	fillNodeTuning(ccfg, conf.NodeTuning)
 	ccfg.WorkerConfig.KubeletConf.EnableServer = conf.EnableKubeletServing
 
 	fillExtrArgs(ccfg, conf.ConfigExtraArgs)
@@ -936,6 +1180,7 @@ func createDeployConfigTemplate(file string) error {
 		},
 	}
 
+	setDeployConfigVersion(conf)
 	d, err := yaml.Marshal(conf)
 	if err != nil {
 		return fmt.Errorf("marshal template config failed: %v", err)
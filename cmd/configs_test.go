@@ -20,11 +20,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v1"
 
 	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/infra"
 )
 
 func TestCmdConfigs(t *testing.T) {
@@ -66,3 +68,142 @@ func TestCmdConfigs(t *testing.T) {
 		t.Fatalf("save deploy config to file failed: %v", err)
 	}
 }
+
+func TestBastionConfigs(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cmd-bastion-test-")
+	if err != nil {
+		t.Fatalf("create tempdir for cmd configs failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	f := filepath.Join(tempdir, "config.yaml")
+	if err = createDeployConfigTemplate(f); err != nil {
+		t.Fatalf("create deploy template config file failed: %v", err)
+	}
+	conf, err := loadDeployConfig(f)
+	if err != nil {
+		t.Fatalf("load deploy config file failed: %v", err)
+	}
+
+	conf.Bastion = &BastionConfig{Ip: "10.0.0.1", Username: "jump"}
+	conf.Masters[0].Bastion = &BastionConfig{Ip: "10.0.0.2", Port: 2222}
+	ccfg := toClusterdeploymentConfig(conf, nil)
+	for _, n := range ccfg.Nodes {
+		if n.Bastion == nil {
+			t.Fatalf("node %s without bastion", n.Name)
+		}
+		if n.Address == conf.Masters[0].Ip {
+			if n.Bastion.Address != "10.0.0.2" || n.Bastion.Port != 2222 || n.Bastion.UserName != "" {
+				t.Fatalf("bastion of host is not override: %+v", n.Bastion)
+			}
+			continue
+		}
+		if n.Bastion.Address != "10.0.0.1" || n.Bastion.Port != 22 || n.Bastion.UserName != "jump" {
+			t.Fatalf("invalid bastion of cluster: %+v", n.Bastion)
+		}
+	}
+
+	conf.Bastion = nil
+	conf.Masters[0].Bastion = nil
+	for _, n := range toClusterdeploymentConfig(conf, nil).Nodes {
+		if n.Bastion != nil {
+			t.Fatalf("node %s with unexpected bastion: %+v", n.Name, n.Bastion)
+		}
+	}
+}
+
+func TestNodeTuningConfigs(t *testing.T) {
+	conf := &DeployConfig{}
+	tuningYaml := `node-tuning:
+  worker:
+    sysctl:
+      net.ipv4.ip_forward: "0"
+    kernel-modules:
+    - nf_conntrack
+    - vxlan
+    disable-swap: false
+    ntp-servers:
+    - ntp.example.com
+  etcd:
+    transparent-hugepage: never
+`
+	if err := yaml.Unmarshal([]byte(tuningYaml), conf); err != nil {
+		t.Fatalf("unmarshal node tuning failed: %v", err)
+	}
+
+	ccfg := &api.ClusterConfig{RoleInfra: infra.RegisterInfra()}
+	fillNodeTuning(ccfg, conf.NodeTuning)
+	worker := ccfg.RoleInfra[api.Worker].Tuning
+	if worker.DisableSwap || worker.Sysctl["net.ipv4.ip_forward"] != "0" || worker.Sysctl["vm.overcommit_memory"] != "1" {
+		t.Fatalf("node tuning of worker is not merged with default: %+v", worker)
+	}
+	if worker.KernelModules[0] != "br_netfilter" || worker.KernelModules[len(worker.KernelModules)-1] != "vxlan" ||
+		strings.Count(strings.Join(worker.KernelModules, " "), "nf_conntrack") != 1 {
+		t.Fatalf("invalid kernel modules of worker: %v", worker.KernelModules)
+	}
+	if len(worker.NTPServers) != 1 || worker.NTPServers[0] != "ntp.example.com" {
+		t.Fatalf("invalid ntp servers of worker: %v", worker.NTPServers)
+	}
+	if etcd := ccfg.RoleInfra[api.ETCD].Tuning; etcd == nil || etcd.TransparentHugepage != "never" || etcd.DisableSwap {
+		t.Fatalf("invalid node tuning of etcd: %+v", etcd)
+	}
+	if master := ccfg.RoleInfra[api.Master].Tuning; master.DisableSwap || master.Sysctl["net.ipv4.ip_forward"] != "1" {
+		t.Fatalf("default node tuning of master is changed: %+v", master)
+	}
+}
+
+func TestLoginConfigs(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cmd-login-test-")
+	if err != nil {
+		t.Fatalf("create tempdir for cmd configs failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	f := filepath.Join(tempdir, "config.yaml")
+	if err = createDeployConfigTemplate(f); err != nil {
+		t.Fatalf("create deploy template config file failed: %v", err)
+	}
+	conf, err := loadDeployConfig(f)
+	if err != nil {
+		t.Fatalf("load deploy config file failed: %v", err)
+	}
+	clusterKey, hostKey := filepath.Join(tempdir, "id_rsa"), filepath.Join(tempdir, "admin_rsa")
+	for _, k := range []string{clusterKey, hostKey} {
+		if err = ioutil.WriteFile(k, []byte("key"), 0600); err != nil {
+			t.Fatalf("write key failed: %v", err)
+		}
+	}
+	conf.Username, conf.Password = "root", ""
+	conf.PrivateKeyPath = clusterKey
+	conf.PrivateKeyPassphrase = "env:EGGO_KEY_PASSPHRASE"
+	conf.CertificatePath = clusterKey + "-cert.pub"
+
+	var master HostConfig
+	hostYaml := fmt.Sprintf("name: k8s-master-0\nip: %s\nport: 22\nusername: admin\nprivate-key-path: %s\n",
+		conf.Masters[0].Ip, hostKey)
+	if err = yaml.Unmarshal([]byte(hostYaml), &master); err != nil {
+		t.Fatalf("unmarshal host failed: %v", err)
+	}
+	conf.Masters[0] = &master
+	conf.Workers[0].Password = "agent:"
+
+	for _, n := range toClusterdeploymentConfig(conf, nil).Nodes {
+		switch n.Address {
+		case master.Ip:
+			if n.UserName != "admin" || n.PrivateKeyPath != hostKey || n.PrivateKeyPassphrase != "" ||
+				n.CertificatePath != "" {
+				t.Fatalf("login of host is not override: %+v", n)
+			}
+		case conf.Workers[0].Ip:
+			if n.UserName != "root" || n.Password != "agent:" || n.PrivateKeyPath != clusterKey ||
+				n.CertificatePath != conf.CertificatePath {
+				t.Fatalf("invalid login of host with agent: %+v", n)
+			}
+		default:
+			if n.UserName != "root" || n.Password != "" || n.PrivateKeyPath != clusterKey ||
+				n.PrivateKeyPassphrase != conf.PrivateKeyPassphrase || n.CertificatePath != conf.CertificatePath {
+				t.Fatalf("login of cluster is not inherited: %+v", n)
+			}
+		}
+	}
+}
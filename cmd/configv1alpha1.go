@@ -0,0 +1,356 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: deploy config of v1alpha1, which must not be changed
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// types of v1alpha1 are copies of deploy config when versions of deploy config are introduced,
+// they are frozen to decode old files, new fields are only added to types of the current version
+
+type ConfigExtraArgsV1alpha1 struct {
+	Name      string            `yaml:"name"`
+	ExtraArgs map[string]string `yaml:"extra-args"`
+}
+
+type PackageSrcConfigV1alpha1 struct {
+	Type    string            `yaml:"type"`
+	DstPath string            `yaml:"dstpath"`
+	SrcPath map[string]string `yaml:"srcpath"`
+}
+
+type PackageConfigV1alpha1 struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Dst      string `yaml:"dst,omitempty"`
+	Schedule string `yaml:"schedule,omitempty"`
+	TimeOut  string `yaml:"timeout,omitempty"`
+}
+
+type InstallConfigV1alpha1 struct {
+	PackageSrc       *PackageSrcConfigV1alpha1           `yaml:"package-source"`
+	KubernetesMaster []*PackageConfigV1alpha1            `yaml:"kubernetes-master"`
+	KubernetesWorker []*PackageConfigV1alpha1            `yaml:"kubernetes-worker"`
+	Network          []*PackageConfigV1alpha1            `yaml:"network"`
+	ETCD             []*PackageConfigV1alpha1            `yaml:"etcd"`
+	LoadBalance      []*PackageConfigV1alpha1            `yaml:"loadbalance"`
+	Container        []*PackageConfigV1alpha1            `yaml:"container"`
+	Image            []*PackageConfigV1alpha1            `yaml:"image"`
+	Dns              []*PackageConfigV1alpha1            `yaml:"dns"`
+	Addition         map[string][]*PackageConfigV1alpha1 `yaml:"addition"`
+}
+
+type BastionConfigV1alpha1 struct {
+	Ip             string `yaml:"ip"`
+	Port           int    `yaml:"port"`
+	Username       string `yaml:"username"`
+	Password       string `yaml:"password"`
+	PrivateKeyPath string `yaml:"private-key-path"`
+	Fingerprint    string `yaml:"fingerprint,omitempty"`
+}
+
+type HostConfigV1alpha1 struct {
+	Name        string                 `yaml:"name"`
+	Ip          string                 `yaml:"ip"`
+	Port        int                    `yaml:"port"`
+	Arch        string                 `yaml:"arch"`
+	Fingerprint string                 `yaml:"fingerprint,omitempty"`
+	Bastion     *BastionConfigV1alpha1 `yaml:"bastion,omitempty"`
+}
+
+type LoadBalanceV1alpha1 struct {
+	Name        string                 `yaml:"name"`
+	Ip          string                 `yaml:"ip"`
+	Port        int                    `yaml:"port"`
+	Arch        string                 `yaml:"arch"`
+	BindPort    int                    `yaml:"bind-port"`
+	Fingerprint string                 `yaml:"fingerprint,omitempty"`
+	Bastion     *BastionConfigV1alpha1 `yaml:"bastion,omitempty"`
+}
+
+type DnsConfigV1alpha1 struct {
+	CorednsType  string `yaml:"corednstype"`
+	ImageVersion string `yaml:"imageversion"`
+	Replicas     int    `yaml:"replicas"`
+}
+
+// ServiceClusterConfigV1alpha1 has json tags, which are ignored by yaml, so keys are lower case of field names
+type ServiceClusterConfigV1alpha1 struct {
+	CIDR    string            `json:"cidr"`
+	DNSAddr string            `json:"dnsaddress"`
+	Gateway string            `json:"gateway"`
+	DNS     DnsConfigV1alpha1 `json:"dns"`
+}
+
+type NetworkConfigV1alpha1 struct {
+	PodCIDR    string            `yaml:"podcidr"`
+	Plugin     string            `yaml:"plugin"`
+	PluginArgs map[string]string `yaml:"pluginargs"`
+}
+
+type SansV1alpha1 struct {
+	DNSNames []string `yaml:"dnsnames"`
+	IPs      []string `yaml:"ips"`
+}
+
+type OpenPortsV1alpha1 struct {
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol"`
+}
+
+// DeployConfigV1alpha1Type is deploy config without apiVersion, apiserver-endpoint may be url,
+// such as https://192.168.0.1:6443, which is written by eggops
+type DeployConfigV1alpha1Type struct {
+	ClusterID            string                          `yaml:"cluster-id"`
+	Username             string                          `yaml:"username"`
+	Password             string                          `yaml:"password"`
+	PrivateKeyPath       string                          `yaml:"private-key-path"`
+	Bastion              *BastionConfigV1alpha1          `yaml:"bastion,omitempty"`
+	HostKeyChecking      string                          `yaml:"host-key-checking,omitempty"`
+	Local                bool                            `yaml:"local,omitempty"`
+	Masters              []*HostConfigV1alpha1           `yaml:"masters"`
+	Workers              []*HostConfigV1alpha1           `yaml:"workers"`
+	Etcds                []*HostConfigV1alpha1           `yaml:"etcds"`
+	LoadBalance          LoadBalanceV1alpha1             `yaml:"loadbalance"`
+	ExternalCA           bool                            `yaml:"external-ca"`
+	ExternalCAPath       string                          `yaml:"external-ca-path"`
+	Service              ServiceClusterConfigV1alpha1    `yaml:"service"`
+	NetWork              NetworkConfigV1alpha1           `yaml:"network"`
+	ApiServerEndpoint    string                          `yaml:"apiserver-endpoint"`
+	ApiServerCertSans    SansV1alpha1                    `yaml:"apiserver-cert-sans"`
+	ApiServerTimeout     string                          `yaml:"apiserver-timeout"`
+	EtcdExternal         bool                            `yaml:"etcd-external"`
+	EtcdToken            string                          `yaml:"etcd-token"`
+	DnsVip               string                          `yaml:"dns-vip"`
+	DnsDomain            string                          `yaml:"dns-domain"`
+	PauseImage           string                          `yaml:"pause-image"`
+	NetworkPlugin        string                          `yaml:"network-plugin"`
+	EnableKubeletServing bool                            `yaml:"enable-kubelet-serving"`
+	CniBinDir            string                          `yaml:"cni-bin-dir"`
+	Runtime              string                          `yaml:"runtime"`
+	RuntimeEndpoint      string                          `yaml:"runtime-endpoint"`
+	RegistryMirrors      []string                        `yaml:"registry-mirrors"`
+	InsecureRegistries   []string                        `yaml:"insecure-registries"`
+	ConfigExtraArgs      []*ConfigExtraArgsV1alpha1      `yaml:"config-extra-args"`
+	OpenPorts            map[string][]*OpenPortsV1alpha1 `yaml:"open-ports"`
+	InstallConfig        InstallConfigV1alpha1           `yaml:"install"`
+}
+
+func convertBastionV1alpha1(in *BastionConfigV1alpha1) *BastionConfig {
+	if in == nil {
+		return nil
+	}
+	return &BastionConfig{
+		Ip:             in.Ip,
+		Port:           in.Port,
+		Username:       in.Username,
+		Password:       in.Password,
+		PrivateKeyPath: in.PrivateKeyPath,
+		Fingerprint:    in.Fingerprint,
+	}
+}
+
+func convertHostsV1alpha1(in []*HostConfigV1alpha1) []*HostConfig {
+	if in == nil {
+		return nil
+	}
+	out := make([]*HostConfig, 0, len(in))
+	for _, h := range in {
+		if h == nil {
+			out = append(out, nil)
+			continue
+		}
+		out = append(out, &HostConfig{
+			Name:        h.Name,
+			Ip:          h.Ip,
+			Port:        h.Port,
+			Arch:        h.Arch,
+			Fingerprint: h.Fingerprint,
+			Bastion:     convertBastionV1alpha1(h.Bastion),
+		})
+	}
+	return out
+}
+
+func convertPackagesV1alpha1(in []*PackageConfigV1alpha1) []*PackageConfig {
+	if in == nil {
+		return nil
+	}
+	out := make([]*PackageConfig, 0, len(in))
+	for _, p := range in {
+		if p == nil {
+			out = append(out, nil)
+			continue
+		}
+		out = append(out, &PackageConfig{
+			Name:     p.Name,
+			Type:     p.Type,
+			Dst:      p.Dst,
+			Schedule: p.Schedule,
+			TimeOut:  p.TimeOut,
+		})
+	}
+	return out
+}
+
+func convertInstallV1alpha1(in *InstallConfigV1alpha1) InstallConfig {
+	out := InstallConfig{
+		KubernetesMaster: convertPackagesV1alpha1(in.KubernetesMaster),
+		KubernetesWorker: convertPackagesV1alpha1(in.KubernetesWorker),
+		Network:          convertPackagesV1alpha1(in.Network),
+		ETCD:             convertPackagesV1alpha1(in.ETCD),
+		LoadBalance:      convertPackagesV1alpha1(in.LoadBalance),
+		Container:        convertPackagesV1alpha1(in.Container),
+		Image:            convertPackagesV1alpha1(in.Image),
+		Dns:              convertPackagesV1alpha1(in.Dns),
+	}
+	if in.PackageSrc != nil {
+		out.PackageSrc = &PackageSrcConfig{
+			Type:    in.PackageSrc.Type,
+			DstPath: in.PackageSrc.DstPath,
+			SrcPath: in.PackageSrc.SrcPath,
+		}
+	}
+	if in.Addition != nil {
+		out.Addition = make(map[string][]*PackageConfig, len(in.Addition))
+		for k, v := range in.Addition {
+			out.Addition[k] = convertPackagesV1alpha1(v)
+		}
+	}
+	return out
+}
+
+func convertOpenPortsV1alpha1(in map[string][]*OpenPortsV1alpha1) map[string][]*OpenPorts {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string][]*OpenPorts, len(in))
+	for role, ports := range in {
+		var res []*OpenPorts
+		for _, p := range ports {
+			if p == nil {
+				continue
+			}
+			res = append(res, &OpenPorts{Port: p.Port, Protocol: p.Protocol})
+		}
+		out[role] = res
+	}
+	return out
+}
+
+func convertExtraArgsV1alpha1(in []*ConfigExtraArgsV1alpha1) []*ConfigExtraArgs {
+	if in == nil {
+		return nil
+	}
+	out := make([]*ConfigExtraArgs, 0, len(in))
+	for _, a := range in {
+		if a == nil {
+			continue
+		}
+		out = append(out, &ConfigExtraArgs{Name: a.Name, ExtraArgs: a.ExtraArgs})
+	}
+	return out
+}
+
+// convertAPIServerEndpointV1alpha1 convert url written by eggops to host:port
+func convertAPIServerEndpointV1alpha1(endpoint string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid api server endpoint: %s, err: %v", endpoint, err)
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "6443"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func convertV1alpha1ToV1beta1(in *DeployConfigV1alpha1Type) (*DeployConfig, error) {
+	endpoint, err := convertAPIServerEndpointV1alpha1(in.ApiServerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &DeployConfig{
+		APIVersion:      DeployConfigV1beta1,
+		Kind:            DeployConfigKind,
+		ClusterID:       in.ClusterID,
+		Username:        in.Username,
+		Password:        in.Password,
+		PrivateKeyPath:  in.PrivateKeyPath,
+		Bastion:         convertBastionV1alpha1(in.Bastion),
+		HostKeyChecking: in.HostKeyChecking,
+		Local:           in.Local,
+		Masters:         convertHostsV1alpha1(in.Masters),
+		Workers:         convertHostsV1alpha1(in.Workers),
+		Etcds:           convertHostsV1alpha1(in.Etcds),
+		LoadBalance: LoadBalance{
+			Name:        in.LoadBalance.Name,
+			Ip:          in.LoadBalance.Ip,
+			Port:        in.LoadBalance.Port,
+			Arch:        in.LoadBalance.Arch,
+			BindPort:    in.LoadBalance.BindPort,
+			Fingerprint: in.LoadBalance.Fingerprint,
+			Bastion:     convertBastionV1alpha1(in.LoadBalance.Bastion),
+		},
+		ExternalCA:     in.ExternalCA,
+		ExternalCAPath: in.ExternalCAPath,
+		Service: ServiceClusterConfig{
+			CIDR:    in.Service.CIDR,
+			DNSAddr: in.Service.DNSAddr,
+			Gateway: in.Service.Gateway,
+			DNS: DnsConfig{
+				CorednsType:  in.Service.DNS.CorednsType,
+				ImageVersion: in.Service.DNS.ImageVersion,
+				Replicas:     in.Service.DNS.Replicas,
+			},
+		},
+		NetWork: NetworkConfig{
+			PodCIDR:    in.NetWork.PodCIDR,
+			Plugin:     in.NetWork.Plugin,
+			PluginArgs: in.NetWork.PluginArgs,
+		},
+		ApiServerEndpoint: endpoint,
+		ApiServerCertSans: Sans{
+			DNSNames: in.ApiServerCertSans.DNSNames,
+			IPs:      in.ApiServerCertSans.IPs,
+		},
+		ApiServerTimeout:     in.ApiServerTimeout,
+		EtcdExternal:         in.EtcdExternal,
+		EtcdToken:            in.EtcdToken,
+		DnsVip:               in.DnsVip,
+		DnsDomain:            in.DnsDomain,
+		PauseImage:           in.PauseImage,
+		NetworkPlugin:        in.NetworkPlugin,
+		EnableKubeletServing: in.EnableKubeletServing,
+		CniBinDir:            in.CniBinDir,
+		Runtime:              in.Runtime,
+		RuntimeEndpoint:      in.RuntimeEndpoint,
+		RegistryMirrors:      in.RegistryMirrors,
+		InsecureRegistries:   in.InsecureRegistries,
+		ConfigExtraArgs:      convertExtraArgsV1alpha1(in.ConfigExtraArgs),
+		OpenPorts:            convertOpenPortsV1alpha1(in.OpenPorts),
+		InstallConfig:        convertInstallV1alpha1(&in.InstallConfig),
+	}
+	return out, nil
+}
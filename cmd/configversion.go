@@ -0,0 +1,136 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: versions of deploy config and conversions between them
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v1"
+)
+
+const (
+	DeployConfigGroup = "eggo.isula.org"
+	DeployConfigKind  = "DeployConfig"
+
+	// DeployConfigV1alpha1 is the deploy config without apiVersion, which is written by eggo
+	// before versions of deploy config are introduced
+	DeployConfigV1alpha1 = DeployConfigGroup + "/v1alpha1"
+	DeployConfigV1beta1  = DeployConfigGroup + "/v1beta1"
+
+	CurrentDeployConfigVersion = DeployConfigV1beta1
+)
+
+// TypeMeta is the header of deploy config
+type TypeMeta struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+}
+
+type deployConfigVersion struct {
+	version string
+	// decode config of this version
+	decode func(data []byte) (interface{}, error)
+	// convert config of this version to the next version
+	convert func(in interface{}) (interface{}, error)
+}
+
+// deployConfigVersions are ordered from the oldest to the current
+var deployConfigVersions = []deployConfigVersion{
+	{
+		version: DeployConfigV1alpha1,
+		decode: func(data []byte) (interface{}, error) {
+			conf := &DeployConfigV1alpha1Type{}
+			err := yaml.Unmarshal(data, conf)
+			return conf, err
+		},
+		convert: func(in interface{}) (interface{}, error) {
+			return convertV1alpha1ToV1beta1(in.(*DeployConfigV1alpha1Type))
+		},
+	},
+	{
+		version: DeployConfigV1beta1,
+		decode: func(data []byte) (interface{}, error) {
+			conf := &DeployConfig{}
+			err := yaml.Unmarshal(data, conf)
+			return conf, err
+		},
+	},
+}
+
+func supportedDeployConfigVersions() []string {
+	var versions []string
+	for _, v := range deployConfigVersions {
+		versions = append(versions, v.version)
+	}
+	return versions
+}
+
+// parseDeployConfig decode deploy config of any supported version and convert it to the current
+// version, return the version of data
+func parseDeployConfig(data []byte) (*DeployConfig, string, error) {
+	meta := &TypeMeta{}
+	if err := yaml.Unmarshal(data, meta); err != nil {
+		return nil, "", err
+	}
+	if meta.Kind != "" && meta.Kind != DeployConfigKind {
+		return nil, "", fmt.Errorf("invalid kind: %s, expect: %s", meta.Kind, DeployConfigKind)
+	}
+	version := meta.APIVersion
+	if version == "" {
+		version = DeployConfigV1alpha1
+	}
+
+	index := -1
+	for i, v := range deployConfigVersions {
+		if v.version == version {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		if strings.HasPrefix(version, DeployConfigGroup+"/") {
+			return nil, "", fmt.Errorf("deploy config version %s is newer than this eggo, supported: %s, please upgrade eggo",
+				version, strings.Join(supportedDeployConfigVersions(), ", "))
+		}
+		return nil, "", fmt.Errorf("unsupported deploy config version: %s, supported: %s", version,
+			strings.Join(supportedDeployConfigVersions(), ", "))
+	}
+
+	conf, err := deployConfigVersions[index].decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, v := range deployConfigVersions[index:] {
+		if v.convert == nil {
+			break
+		}
+		if conf, err = v.convert(conf); err != nil {
+			return nil, "", fmt.Errorf("convert deploy config from %s failed: %v", v.version, err)
+		}
+	}
+
+	current := conf.(*DeployConfig)
+	current.APIVersion = CurrentDeployConfigVersion
+	current.Kind = DeployConfigKind
+	return current, version, nil
+}
+
+// setDeployConfigVersion set header of current version before deploy config is written
+func setDeployConfigVersion(conf *DeployConfig) {
+	conf.APIVersion = CurrentDeployConfigVersion
+	conf.Kind = DeployConfigKind
+}
@@ -0,0 +1,253 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of deploy config versions
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+const legacyDeployConfig = `cluster-id: k8s-cluster
+username: root
+masters:
+- name: k8s-master-0
+  ip: 192.168.0.2
+  port: 22
+  arch: amd64
+apiserver-endpoint: https://192.168.0.2:6443
+`
+
+func TestParseDeployConfig(t *testing.T) {
+	conf, version, err := parseDeployConfig([]byte(legacyDeployConfig))
+	if err != nil {
+		t.Fatalf("parse legacy deploy config failed: %v", err)
+	}
+	if version != DeployConfigV1alpha1 {
+		t.Fatalf("expect version %s, get %s", DeployConfigV1alpha1, version)
+	}
+	if conf.APIVersion != CurrentDeployConfigVersion || conf.Kind != DeployConfigKind {
+		t.Fatalf("invalid header of converted config: %s, %s", conf.APIVersion, conf.Kind)
+	}
+	if conf.ApiServerEndpoint != "192.168.0.2:6443" || conf.ClusterID != "k8s-cluster" || len(conf.Masters) != 1 {
+		t.Fatalf("invalid converted config: %+v", conf)
+	}
+
+	current := "apiVersion: " + CurrentDeployConfigVersion + "\nkind: DeployConfig\ncluster-id: k8s-cluster\n"
+	if _, version, err = parseDeployConfig([]byte(current)); err != nil || version != CurrentDeployConfigVersion {
+		t.Fatalf("parse current deploy config failed: %s, %v", version, err)
+	}
+
+	future := "apiVersion: " + DeployConfigGroup + "/v9\ncluster-id: k8s-cluster\n"
+	if _, _, err = parseDeployConfig([]byte(future)); err == nil || !strings.Contains(err.Error(), "upgrade eggo") {
+		t.Fatalf("expect error of future version, get: %v", err)
+	}
+	if _, _, err = parseDeployConfig([]byte("apiVersion: v1\nkind: Pod\n")); err == nil {
+		t.Fatalf("expect error of invalid kind")
+	}
+}
+
+const fullLegacyDeployConfig = `cluster-id: k8s-cluster
+username: root
+password: secret
+bastion:
+  ip: 10.0.0.1
+  port: 2222
+  username: jump
+  fingerprint: SHA256:bastion
+masters:
+- name: k8s-master-0
+  ip: 192.168.0.2
+  port: 22
+  arch: amd64
+  fingerprint: SHA256:master
+loadbalance:
+  name: k8s-lb
+  ip: 192.168.0.9
+  port: 22
+  bind-port: 8443
+service:
+  cidr: 10.32.0.0/16
+  dnsaddr: 10.32.0.10
+  dns:
+    corednstype: pod
+    replicas: 2
+apiserver-endpoint: https://192.168.0.9
+config-extra-args:
+- name: kubelet
+  extra-args:
+    max-pods: "100"
+open-ports:
+  worker:
+  - port: 111
+    protocol: tcp
+install:
+  package-source:
+    type: tar.gz
+    srcpath:
+      x86_64: /root/pkg.tar.gz
+  etcd:
+  - name: etcd
+    type: bin
+    dst: /usr/bin
+  addition:
+    master:
+    - name: prejoin.sh
+      type: shell
+      schedule: prejoin
+      timeout: 30s
+`
+
+func TestConvertV1alpha1ToV1beta1(t *testing.T) {
+	conf, version, err := parseDeployConfig([]byte(fullLegacyDeployConfig))
+	if err != nil || version != DeployConfigV1alpha1 {
+		t.Fatalf("parse legacy deploy config failed: %s, %v", version, err)
+	}
+	if conf.ApiServerEndpoint != "192.168.0.9:6443" || conf.Password != "secret" {
+		t.Fatalf("invalid converted config: %+v", conf)
+	}
+	if conf.Bastion == nil || conf.Bastion.Port != 2222 || conf.Bastion.Fingerprint != "SHA256:bastion" {
+		t.Fatalf("invalid converted bastion: %+v", conf.Bastion)
+	}
+	if len(conf.Masters) != 1 || conf.Masters[0].Fingerprint != "SHA256:master" || conf.Masters[0].Bastion != nil {
+		t.Fatalf("invalid converted masters: %+v", conf.Masters)
+	}
+	if conf.LoadBalance.Name != "k8s-lb" || conf.LoadBalance.BindPort != 8443 {
+		t.Fatalf("invalid converted loadbalance: %+v", conf.LoadBalance)
+	}
+	if conf.Service.CIDR != "10.32.0.0/16" || conf.Service.DNSAddr != "10.32.0.10" ||
+		conf.Service.DNS.CorednsType != "pod" || conf.Service.DNS.Replicas != 2 {
+		t.Fatalf("invalid converted service: %+v", conf.Service)
+	}
+	if len(conf.ConfigExtraArgs) != 1 || conf.ConfigExtraArgs[0].ExtraArgs["max-pods"] != "100" {
+		t.Fatalf("invalid converted extra args: %+v", conf.ConfigExtraArgs)
+	}
+	ports := conf.OpenPorts["worker"]
+	if len(ports) != 1 || ports[0].Port != 111 || ports[0].Protocol != "tcp" {
+		t.Fatalf("invalid converted open ports: %+v", conf.OpenPorts)
+	}
+	install := conf.InstallConfig
+	if install.PackageSrc == nil || install.PackageSrc.SrcPath["x86_64"] != "/root/pkg.tar.gz" {
+		t.Fatalf("invalid converted package source: %+v", install.PackageSrc)
+	}
+	if len(install.ETCD) != 1 || install.ETCD[0].Dst != "/usr/bin" {
+		t.Fatalf("invalid converted etcd packages: %+v", install.ETCD)
+	}
+	additions := install.Addition["master"]
+	if len(additions) != 1 || additions[0].Schedule != "prejoin" || additions[0].TimeOut != "30s" {
+		t.Fatalf("invalid converted addition packages: %+v", install.Addition)
+	}
+}
+
+func TestMigrateDeployConfigFile(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cmd-migrate-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	f := filepath.Join(tempdir, "deploy.yaml")
+	if err = ioutil.WriteFile(f, []byte(legacyDeployConfig), 0600); err != nil {
+		t.Fatalf("write deploy config failed: %v", err)
+	}
+
+	version, backup, err := migrateDeployConfigFile(f)
+	if err != nil || version != DeployConfigV1alpha1 {
+		t.Fatalf("migrate deploy config failed: %s, %v", version, err)
+	}
+	info, err := os.Stat(f)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("mode of migrated file is changed: %v, %v", info, err)
+	}
+	// original file is kept with comments, and no temp file is left
+	if backup != f+".v1alpha1.bak" {
+		t.Fatalf("invalid backup of deploy config: %s", backup)
+	}
+	if data, rerr := ioutil.ReadFile(backup); rerr != nil || string(data) != legacyDeployConfig {
+		t.Fatalf("invalid backup of deploy config: %s, %v", string(data), rerr)
+	}
+	if info, err = os.Stat(backup); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("mode of backup is changed: %v, %v", info, err)
+	}
+	if _, err = os.Stat(f + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file of migration is left: %v", err)
+	}
+	conf, err := loadDeployConfig(f)
+	if err != nil {
+		t.Fatalf("load migrated deploy config failed: %v", err)
+	}
+	if conf.APIVersion != CurrentDeployConfigVersion || conf.ApiServerEndpoint != "192.168.0.2:6443" {
+		t.Fatalf("invalid migrated config: %+v", conf)
+	}
+
+	if version, backup, err = migrateDeployConfigFile(f); err != nil || version != CurrentDeployConfigVersion || backup != "" {
+		t.Fatalf("migrate current deploy config failed: %s, %s, %v", version, backup, err)
+	}
+}
+
+func TestMigrateSavedDeployConfigSecrets(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cmd-migrate-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	oldHome := api.EggoHomePath
+	api.EggoHomePath = tempdir
+	secrets.KeystorePath = filepath.Join(tempdir, "keystore.json")
+	secrets.SetPassphrase("test-passphrase")
+	defer func() {
+		api.EggoHomePath = oldHome
+		secrets.KeystorePath = ""
+		secrets.SetPassphrase("")
+	}()
+
+	saved := savedDeployConfigPath("k8s-cluster")
+	if err = os.MkdirAll(filepath.Dir(saved), 0700); err != nil {
+		t.Fatalf("create cluster dir failed: %v", err)
+	}
+	// legacy config and current config saved before keystore is supported
+	current := "apiVersion: " + CurrentDeployConfigVersion + "\nkind: DeployConfig\ncluster-id: k8s-cluster\n"
+	for _, content := range []string{legacyDeployConfig, current} {
+		if err = ioutil.WriteFile(saved, []byte(content+"password: plain-password\n"), 0600); err != nil {
+			t.Fatalf("write deploy config failed: %v", err)
+		}
+		if _, _, err = migrateDeployConfigFile(saved); err != nil {
+			t.Fatalf("migrate deploy config failed: %v", err)
+		}
+		data, err := ioutil.ReadFile(saved)
+		if err != nil || strings.Contains(string(data), "plain-password") ||
+			!strings.Contains(string(data), "keystore:k8s-cluster/password") {
+			t.Fatalf("plain password is not moved to keystore: %s, %v", data, err)
+		}
+	}
+
+	// deploy config of user is not saved by eggo, only upgrade version of it
+	user := filepath.Join(tempdir, "deploy.yaml")
+	if err = ioutil.WriteFile(user, []byte(legacyDeployConfig+"password: plain-password\n"), 0600); err != nil {
+		t.Fatalf("write deploy config failed: %v", err)
+	}
+	if _, _, err = migrateDeployConfigFile(user); err != nil {
+		t.Fatalf("migrate deploy config failed: %v", err)
+	}
+	if data, err := ioutil.ReadFile(user); err != nil || !strings.Contains(string(data), "plain-password") {
+		t.Fatalf("password of user deploy config should be kept: %s, %v", data, err)
+	}
+}
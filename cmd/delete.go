@@ -71,10 +71,16 @@ func getDeletedAndDiffConfigs(conf *DeployConfig, delNames []string) (*DeployCon
 	return &deletedConfig, clusterConfig.Nodes, nil
 }
 
-func deleteCluster(cmd *cobra.Command, args []string) error {
+func deleteCluster(cmd *cobra.Command, args []string) (err error) {
 	if opts.debug {
 		initLog()
 	}
+	if err = startOutput("delete"); err != nil {
+		return err
+	}
+	defer func() {
+		finishOutput(err)
+	}()
 
 	if len(args) == 0 {
 		return fmt.Errorf("delete command need at least one argument")
@@ -83,6 +89,15 @@ func deleteCluster(cmd *cobra.Command, args []string) error {
 	if opts.delClusterID == "" {
 		return fmt.Errorf("please specify cluster id")
 	}
+	setOutputResult(opts.delClusterID, nil)
+
+	if opts.dryRun {
+		dr, err := startDryRun("delete", opts.delClusterID, true)
+		if err != nil {
+			return err
+		}
+		defer dr.finish(opts.planDir)
+	}
 
 	conf, err := loadDeployConfig(savedDeployConfigPath(opts.delClusterID))
 	if err != nil {
@@ -108,7 +123,7 @@ func deleteCluster(cmd *cobra.Command, args []string) error {
 	}
 	defer func() {
 		if terr := holder.Remove(); terr != nil {
-			fmt.Printf("remove process place holder failed: %v", terr)
+			fmt.Fprintf(infoWriter(), "remove process place holder failed: %v\n", terr)
 		}
 	}()
 
@@ -122,7 +137,9 @@ func deleteCluster(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err = clusterdeployment.DeleteNodes(toClusterdeploymentConfig(conf, hooksConf), diffHostconfigs); err != nil {
+	ccfg := toClusterdeploymentConfig(conf, hooksConf)
+	ccfg.DryRun = opts.dryRun
+	if err = clusterdeployment.DeleteNodes(ccfg, diffHostconfigs); err != nil {
 		return err
 	}
 
@@ -17,12 +17,14 @@ package cmd
 
 import (
 	"fmt"
+	"os/user"
 
 	"github.com/spf13/cobra"
 
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/clusterdeployment"
 	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/nodemanager"
 )
 
 func removeFailedNodes(cstatus *api.ClusterStatus, conf *DeployConfig) {
@@ -59,11 +61,11 @@ func removeFailedNodes(cstatus *api.ClusterStatus, conf *DeployConfig) {
 	conf.Etcds = tmp
 
 	if err := saveDeployConfig(conf, savedDeployConfigPath(conf.ClusterID)); err != nil {
-		fmt.Printf("Warn: failed to save config!!!\n")
-		fmt.Printf("	you can call \"eggo delete --id %s [failed nodes id]\" to remove failed node from your cluster.\n", conf.ClusterID)
+		fmt.Fprintf(infoWriter(), "Warn: failed to save config!!!\n")
+		fmt.Fprintf(infoWriter(), "	you can call \"eggo delete --id %s [failed nodes id]\" to remove failed node from your cluster.\n", conf.ClusterID)
 		return
 	}
-	fmt.Printf("update config of cluster: %s", conf.ClusterID)
+	fmt.Fprintf(infoWriter(), "update config of cluster: %s\n", conf.ClusterID)
 }
 
 func deploy(conf *DeployConfig) error {
@@ -76,26 +78,37 @@ func deploy(conf *DeployConfig) error {
 		return fmt.Errorf("get cmd hooks config failed:%v", err)
 	}
 	ccfg := toClusterdeploymentConfig(conf, hooksConf)
+	ccfg.DryRun = opts.dryRun
+	ccfg.Resume = opts.deployResume
 
-	cstatus, err := clusterdeployment.CreateCluster(ccfg, opts.deployEnableRollback)
+	// never remove nodes deployed in last deploy when resume
+	rollback := opts.deployEnableRollback && !opts.deployResume
+	cstatus, err := clusterdeployment.CreateCluster(ccfg, rollback)
+	setOutputResult(conf.ClusterID, &cstatus)
 	if err != nil {
+		if !rollback && !ccfg.DryRun {
+			fmt.Fprintf(infoWriter(), "you can call \"eggo deploy --resume --id %s\" to continue deploy after fix problems\n", conf.ClusterID)
+		}
 		return err
 	}
 
 	// if disable rollback, just ignore error, and wait user to cleanup
-	if opts.deployEnableRollback {
+	if rollback {
 		removeFailedNodes(&cstatus, conf)
 	} else {
 		if cstatus.FailureCnt > 0 {
-			fmt.Printf("Warn: you can call \"eggo delete --id %s [failed nodes id]\" to remove failed node from your cluster.\n", conf.ClusterID)
+			fmt.Fprintf(infoWriter(), "Warn: you can call \"eggo delete --id %s [failed nodes id]\" to remove failed node from your cluster,\n", conf.ClusterID)
+			fmt.Fprintf(infoWriter(), "	or call \"eggo deploy --resume --id %s\" to retry failed nodes.\n", conf.ClusterID)
 		}
 	}
 
-	fmt.Print(cstatus.Show())
+	if output == nil {
+		fmt.Print(cstatus.Show())
+	}
 
-	if cstatus.Working {
-		fmt.Printf("To start using cluster: %s, you need following as a regular user:\n\n", ccfg.Name)
-		fmt.Printf("\texport KUBECONFIG=%s/admin.conf\n\n", api.GetClusterHomePath(ccfg.Name))
+	if cstatus.Working && !ccfg.DryRun {
+		fmt.Fprintf(infoWriter(), "To start using cluster: %s, you need following as a regular user:\n\n", ccfg.Name)
+		fmt.Fprintf(infoWriter(), "\texport KUBECONFIG=%s/admin.conf\n\n", api.GetClusterHomePath(ccfg.Name))
 	}
 
 	return err
@@ -109,16 +122,89 @@ func checkClusterExist(ClusterID string) error {
 	return nil
 }
 
-func deployCluster(cmd *cobra.Command, args []string) error {
+// checkResumeJournal check journal of last deploy, only failed deploy can be resumed
+func checkResumeJournal(ClusterID string) error {
+	j, err := nodemanager.LoadJournal(api.GetJournalPath(ClusterID, api.HookOpDeploy))
+	if err != nil {
+		return fmt.Errorf("no journal of deploy found for cluster %s: %v", ClusterID, err)
+	}
+	if j.Status == nodemanager.JournalSuccess {
+		return fmt.Errorf("deploy of cluster %s is finished, nothing to resume", ClusterID)
+	}
+	return nil
+}
+
+func resumeCluster() error {
+	if opts.deployClusterID == "" {
+		return fmt.Errorf("please specify cluster id to resume")
+	}
+	if opts.dryRun {
+		return fmt.Errorf("conflict option --resume and --dry-run")
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.deployClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+
+	if err = checkCmdHooksParameter(opts.clusterPrehook, opts.clusterPosthook); err != nil {
+		return err
+	}
+	if err = RunChecker(conf); err != nil {
+		return err
+	}
+
+	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
+	if err != nil {
+		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
+	}
+	defer func() {
+		if terr := holder.Remove(); terr != nil {
+			fmt.Fprintf(infoWriter(), "remove process place holder failed: %v\n", terr)
+		}
+	}()
+
+	if err = checkResumeJournal(conf.ClusterID); err != nil {
+		return err
+	}
+
+	return deploy(conf)
+}
+
+// setLocalDeployConfig deploy cluster on this machine, commands are run by current user
+func setLocalDeployConfig(conf *DeployConfig) error {
+	conf.Local = true
+	u, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("get current user failed: %v", err)
+	}
+	conf.Username = u.Username
+	return nil
+}
+
+func deployCluster(cmd *cobra.Command, args []string) (err error) {
 	if opts.debug {
 		initLog()
 	}
-	var err error
+	if err = startOutput("deploy"); err != nil {
+		return err
+	}
+	defer func() {
+		finishOutput(err)
+	}()
+	if opts.deployResume {
+		return resumeCluster()
+	}
 
 	conf, err := loadDeployConfig(opts.deployConfig)
 	if err != nil {
 		return fmt.Errorf("load deploy config file failed: %v", err)
 	}
+	if opts.deployLocal || conf.Local {
+		if err = setLocalDeployConfig(conf); err != nil {
+			return err
+		}
+	}
 
 	if err = checkCmdHooksParameter(opts.clusterPrehook, opts.clusterPosthook); err != nil {
 		return err
@@ -132,13 +218,28 @@ func deployCluster(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// nothing is run on nodes in dry run
+	if !opts.dryRun {
+		if err = runPreflight(conf, toClusterdeploymentConfig(conf, nil).Nodes); err != nil {
+			return err
+		}
+	}
+
+	if opts.dryRun {
+		dr, err := startDryRun("deploy", conf.ClusterID, false)
+		if err != nil {
+			return err
+		}
+		defer dr.finish(opts.planDir)
+	}
+
 	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
 	if err != nil {
 		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
 	}
 	defer func() {
 		if terr := holder.Remove(); terr != nil {
-			fmt.Printf("remove process place holder failed: %v", terr)
+			fmt.Fprintf(infoWriter(), "remove process place holder failed: %v\n", terr)
 		}
 	}()
 
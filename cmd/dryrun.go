@@ -0,0 +1,181 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: dry run of deploy, join and delete, write plan of nodes instead of executing
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils/runner"
+)
+
+const (
+	planIndent = "        "
+)
+
+type dryRunPlan struct {
+	Operation string             `json:"operation"`
+	Cluster   string             `json:"cluster"`
+	Nodes     []*runner.NodePlan `json:"nodes"`
+}
+
+// dryRun redirect eggo home to temporary directory, so that certificates and configs
+// generated in eggo never overwrite the real ones
+type dryRun struct {
+	operation string
+	clusterID string
+	home      string
+	origHome  string
+}
+
+func copyClusterHome(src, dst string, skip string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == skip {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, info.Mode())
+	})
+}
+
+// startDryRun redirect eggo home, and copy files of existed cluster if copyCluster is set
+func startDryRun(operation, clusterID string, copyCluster bool) (*dryRun, error) {
+	home, err := ioutil.TempDir("", "eggo-dryrun-")
+	if err != nil {
+		return nil, fmt.Errorf("create dry run directory failed: %v", err)
+	}
+
+	if copyCluster {
+		// snapshots of etcd are useless for dry run
+		err = copyClusterHome(api.GetClusterHomePath(clusterID), filepath.Join(home, clusterID), etcdBackupDir(clusterID))
+		if err != nil {
+			os.RemoveAll(home)
+			return nil, fmt.Errorf("copy cluster %s for dry run failed: %v", clusterID, err)
+		}
+	}
+
+	runner.ResetPlan()
+	dr := &dryRun{
+		operation: operation,
+		clusterID: clusterID,
+		home:      home,
+		origHome:  api.EggoHomePath,
+	}
+	api.EggoHomePath = home
+	return dr, nil
+}
+
+func (dr *dryRun) plan() *dryRunPlan {
+	nodes := runner.GetPlan()
+	for _, n := range nodes {
+		for _, s := range n.Steps {
+			// show path of real eggo home for files copied from eggo
+			if strings.HasPrefix(s.Src, dr.home) {
+				s.Src = filepath.Join(dr.origHome, strings.TrimPrefix(s.Src, dr.home))
+			}
+		}
+	}
+	return &dryRunPlan{Operation: dr.operation, Cluster: dr.clusterID, Nodes: nodes}
+}
+
+func writeIndent(w io.Writer, content string) {
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", planIndent, line)
+	}
+}
+
+func showDryRunPlan(w io.Writer, p *dryRunPlan) {
+	fmt.Fprintf(w, "# plan of %s cluster %s\n", p.Operation, p.Cluster)
+	fmt.Fprintf(w, "# dry run: nothing executed on nodes, outputs of commands are faked\n")
+	for _, n := range p.Nodes {
+		fmt.Fprintf(w, "\n== node %s (%s): %d steps ==\n", n.Name, n.Address, len(n.Steps))
+		for i, s := range n.Steps {
+			switch s.Type {
+			case runner.StepCopy:
+				fmt.Fprintf(w, "[%d] copy %s -> %s\n", i+1, s.Src, s.Dst)
+			case runner.StepShell:
+				fmt.Fprintf(w, "[%d] shell %s:\n", i+1, s.Command)
+				writeIndent(w, runner.ShortenFileWrites(s.Shell))
+			default:
+				fmt.Fprintf(w, "[%d] run: %s\n", i+1, runner.ShortenFileWrites(s.Command))
+			}
+			for _, f := range s.Files {
+				fmt.Fprintf(w, "    file %s:\n", f.Path)
+				writeIndent(w, f.Content)
+			}
+		}
+	}
+}
+
+func writeDryRunPlan(dir string, p *dryRunPlan) (string, error) {
+	if err := os.MkdirAll(dir, constants.EggoDirMode); err != nil {
+		return "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("eggo-plan-%s-%s", p.Operation, p.Cluster))
+
+	var sb strings.Builder
+	showDryRunPlan(&sb, p)
+	if err := ioutil.WriteFile(base+".txt", []byte(sb.String()), constants.DeployConfigFileMode); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".json", data, constants.DeployConfigFileMode); err != nil {
+		return "", err
+	}
+	return base, nil
+}
+
+// finish restore eggo home and write plan into dir
+func (dr *dryRun) finish(dir string) {
+	api.EggoHomePath = dr.origHome
+	defer os.RemoveAll(dr.home)
+
+	base, err := writeDryRunPlan(dir, dr.plan())
+	if err != nil {
+		fmt.Fprintf(infoWriter(), "write plan of dry run failed: %v\n", err)
+		return
+	}
+	fmt.Fprintf(infoWriter(), "dry run of %s cluster %s finished, plan is saved in %s.txt and %s.json\n",
+		dr.operation, dr.clusterID, base, base)
+}
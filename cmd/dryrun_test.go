@@ -0,0 +1,91 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: cmd dry run testcase
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+)
+
+func TestDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmd-dryrun-test-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	origHome := api.EggoHomePath
+	defer func() {
+		api.EggoHomePath = origHome
+	}()
+	api.EggoHomePath = filepath.Join(dir, "eggo")
+
+	pki := filepath.Join(api.GetCertificateStorePath("test"), "ca.crt")
+	backup := filepath.Join(etcdBackupDir("test"), "snapshot-1.db")
+	for _, f := range []string{pki, backup} {
+		if err = os.MkdirAll(filepath.Dir(f), 0700); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err = ioutil.WriteFile(f, []byte("data"), 0600); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+
+	dr, err := startDryRun("join", "test", true)
+	if err != nil {
+		t.Fatalf("start dry run failed: %v", err)
+	}
+	if api.EggoHomePath == filepath.Join(dir, "eggo") {
+		t.Fatalf("eggo home should be redirected")
+	}
+	copied := filepath.Join(api.GetCertificateStorePath("test"), "ca.crt")
+	if _, err = os.Stat(copied); err != nil {
+		t.Fatalf("certificates of cluster should be copied: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(etcdBackupDir("test"), "snapshot-1.db")); err == nil {
+		t.Fatalf("snapshots of etcd should not be copied")
+	}
+
+	r, _ := runner.NewRecordingRunner(&api.HostConfig{Name: "worker0", Address: "192.168.0.3"})
+	if err = r.Copy(copied, "/etc/kubernetes/pki/ca.crt"); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	home := dr.home
+	dr.finish(dir)
+
+	if api.EggoHomePath != filepath.Join(dir, "eggo") {
+		t.Fatalf("eggo home should be restored, get: %s", api.EggoHomePath)
+	}
+	if _, err = os.Stat(home); err == nil {
+		t.Fatalf("dry run directory should be removed")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "eggo-plan-join-test.json")); err != nil {
+		t.Fatalf("json plan not found: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "eggo-plan-join-test.txt"))
+	if err != nil {
+		t.Fatalf("read plan failed: %v", err)
+	}
+	if !strings.Contains(string(data), "[1] copy "+pki+" -> /etc/kubernetes/pki/ca.crt") {
+		t.Fatalf("unexpect plan: %s", string(data))
+	}
+}
@@ -18,14 +18,18 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/utils/nodemanager"
 )
 
 func showVersion() {
@@ -67,13 +71,27 @@ func preCheck() {
 	if flag {
 		sb.WriteString("Maybe cause to failure!!!\n")
 		sb.WriteString("Shutdown current operator!!!\n")
-		fmt.Println(sb.String())
+		fmt.Fprintln(infoWriter(), sb.String())
 
 		const preCheckSecond = 10
 		time.Sleep(time.Second * preCheckSecond)
 	}
 }
 
+// handleInterrupt cancel running tasks when eggo is interrupted,
+// and exit at once if it is interrupted again
+func handleInterrupt() {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		logrus.Warnf("receive signal: %v, cancel running tasks, interrupt again to exit at once", sig)
+		nodemanager.CancelTasks()
+		<-sigs
+		os.Exit(1)
+	}()
+}
+
 func NewEggoCmd() *cobra.Command {
 	eggoCmd := &cobra.Command{
 		Short:         "eggo is a tool built to provide standard multi-ways for creating Kubernetes clusters",
@@ -82,6 +100,7 @@ func NewEggoCmd() *cobra.Command {
 		SilenceErrors: true,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			preCheck()
+			handleInterrupt()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if opts.version {
@@ -99,11 +118,19 @@ func NewEggoCmd() *cobra.Command {
 	setupEggoCmdOpts(eggoCmd)
 
 	eggoCmd.AddCommand(NewDeployCmd())
+	eggoCmd.AddCommand(NewPreflightCmd())
 	eggoCmd.AddCommand(NewCleanupCmd())
 	eggoCmd.AddCommand(NewTemplateCmd())
 	eggoCmd.AddCommand(NewJoinCmd())
 	eggoCmd.AddCommand(NewDeleteCmd())
 	eggoCmd.AddCommand(NewListCmd())
+	eggoCmd.AddCommand(NewStatusCmd())
+	eggoCmd.AddCommand(NewUpgradeCmd())
+	eggoCmd.AddCommand(NewEtcdCmd())
+	eggoCmd.AddCommand(NewCertsCmd())
+	eggoCmd.AddCommand(NewHostsCmd())
+	eggoCmd.AddCommand(NewConfigCmd())
+	eggoCmd.AddCommand(NewKeystoreCmd())
 
 	return eggoCmd
 }
@@ -0,0 +1,152 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo etcd backup and restore command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment"
+	"isula.org/eggo/pkg/clusterdeployment/binary/etcdcluster"
+)
+
+func etcdBackupDir(ClusterID string) string {
+	return filepath.Join(api.GetEggoClusterPath(), ClusterID, "backups")
+}
+
+// restored members must not join the old cluster, so generate a new token
+func newEtcdToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "etcd-cluster-" + hex.EncodeToString(b), nil
+}
+
+func backupEtcd(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.etcdClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.etcdClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+
+	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
+	if err != nil {
+		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
+	}
+	defer func() {
+		if terr := holder.Remove(); terr != nil {
+			fmt.Printf("remove process place holder failed: %v", terr)
+		}
+	}()
+
+	snapshot, err := clusterdeployment.BackupEtcd(toClusterdeploymentConfig(conf, nil), etcdBackupDir(conf.ClusterID),
+		opts.etcdBackupRetain)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backup etcd of cluster %s success, snapshot: %s\n", conf.ClusterID, snapshot)
+	return nil
+}
+
+func restoreEtcd(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.etcdClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.etcdClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+
+	snapshot := opts.etcdSnapshot
+	if snapshot == "" {
+		if snapshot, err = etcdcluster.LatestSnapshot(etcdBackupDir(conf.ClusterID)); err != nil {
+			return fmt.Errorf("find snapshot of cluster %s failed: %v", conf.ClusterID, err)
+		}
+	}
+
+	token, err := newEtcdToken()
+	if err != nil {
+		return fmt.Errorf("generate etcd token failed: %v", err)
+	}
+
+	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
+	if err != nil {
+		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
+	}
+	defer func() {
+		if terr := holder.Remove(); terr != nil {
+			fmt.Printf("remove process place holder failed: %v", terr)
+		}
+	}()
+
+	conf.EtcdToken = token
+	if err = clusterdeployment.RestoreEtcd(toClusterdeploymentConfig(conf, nil), snapshot); err != nil {
+		return err
+	}
+
+	// save new token, later join of etcd member will use it
+	if err = saveDeployConfig(conf, savedDeployConfigPath(conf.ClusterID)); err != nil {
+		return fmt.Errorf("restore etcd success, but save deploy config failed: %v", err)
+	}
+
+	fmt.Printf("restore etcd of cluster %s from %s success\n", conf.ClusterID, snapshot)
+	return nil
+}
+
+func NewEtcdCmd() *cobra.Command {
+	etcdCmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "manage etcd cluster of kubernetes cluster",
+	}
+
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "save snapshot of etcd cluster into local backup directory",
+		RunE:  backupEtcd,
+	}
+	setupEtcdBackupCmdOpts(backupCmd)
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restore all etcd members from snapshot",
+		RunE:  restoreEtcd,
+	}
+	setupEtcdRestoreCmdOpts(restoreCmd)
+
+	etcdCmd.AddCommand(backupCmd)
+	etcdCmd.AddCommand(restoreCmd)
+
+	return etcdCmd
+}
@@ -0,0 +1,133 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo hosts command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+)
+
+type rescanResult struct {
+	node string
+	keys []*runner.ScannedKey
+	err  error
+}
+
+func selectRescanNodes(nodes []*api.HostConfig, args []string) ([]*api.HostConfig, error) {
+	if len(args) == 0 {
+		return nodes, nil
+	}
+	var selected []*api.HostConfig
+	for _, arg := range args {
+		found := false
+		for _, n := range nodes {
+			if n.Name == arg || n.Address == arg {
+				selected = append(selected, n)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("node %s is not in cluster", arg)
+		}
+	}
+	return selected, nil
+}
+
+func showRescanResults(w io.Writer, results []*rescanResult) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tADDRESS\tFINGERPRINT\tMESSAGE")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(tw, "%s\t\t\t%v\n", r.node, r.err)
+			continue
+		}
+		for _, k := range r.keys {
+			msg := ""
+			if k.Bastion {
+				msg = "bastion"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.node, k.Address, k.Fingerprint, msg)
+		}
+	}
+	tw.Flush()
+}
+
+func rescanHosts(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.hostsClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.hostsClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+	ccfg := toClusterdeploymentConfig(conf, nil)
+	nodes, err := selectRescanNodes(ccfg.Nodes, args)
+	if err != nil {
+		return err
+	}
+
+	checker, err := runner.NewHostKeyChecker(api.GetKnownHostsPath(ccfg.Name), ccfg.HostKeyChecking)
+	if err != nil {
+		return err
+	}
+
+	var results []*rescanResult
+	failed := 0
+	for _, n := range nodes {
+		keys, err := runner.RescanHostKeys(n, checker)
+		if err != nil {
+			failed++
+		}
+		results = append(results, &rescanResult{node: n.Name, keys: keys, err: err})
+	}
+	showRescanResults(os.Stdout, results)
+
+	if failed != 0 {
+		return fmt.Errorf("rescan host keys of %d nodes failed", failed)
+	}
+	return nil
+}
+
+func NewHostsCmd() *cobra.Command {
+	hostsCmd := &cobra.Command{
+		Use:   "hosts",
+		Short: "manage ssh host keys of nodes in cluster",
+	}
+
+	rescanCmd := &cobra.Command{
+		Use:   "rescan [NODE]...",
+		Short: "rescan ssh host keys of nodes and replace keys pinned in known_hosts",
+		RunE:  rescanHosts,
+	}
+	setupHostsRescanCmdOpts(rescanCmd)
+
+	hostsCmd.AddCommand(rescanCmd)
+
+	return hostsCmd
+}
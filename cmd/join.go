@@ -196,15 +196,20 @@ func dropFailedConfigs(conf *DeployConfig, failedConfigs []*api.HostConfig) {
 	conf.Etcds = etcds
 }
 
-func joinCluster(cmd *cobra.Command, args []string) error {
+func joinCluster(cmd *cobra.Command, args []string) (err error) {
 	if opts.debug {
 		initLog()
 	}
+	if err = startOutput("join"); err != nil {
+		return err
+	}
+	defer func() {
+		finishOutput(err)
+	}()
 
 	if len(args) != 0 {
 		opts.joinHost.Ip = args[0]
 	}
-	var err error
 
 	if err = checkCmdHooksParameter(opts.prehook, opts.posthook); err != nil {
 		return err
@@ -214,6 +219,14 @@ func joinCluster(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if opts.dryRun {
+		dr, err := startDryRun("join", joinConf.ClusterID, true)
+		if err != nil {
+			return err
+		}
+		defer dr.finish(opts.planDir)
+	}
+
 	conf, err := loadDeployConfig(savedDeployConfigPath(joinConf.ClusterID))
 	if err != nil {
 		return fmt.Errorf("load saved deploy config failed: %v", err)
@@ -243,17 +256,27 @@ func joinCluster(cmd *cobra.Command, args []string) error {
 	if err = RunChecker(mergedConf); err != nil {
 		return err
 	}
+	if !opts.dryRun {
+		if err = runPreflight(mergedConf, diffConfigs); err != nil {
+			return err
+		}
+	}
 
 	hooksConf, err := getClusterHookConf(api.HookOpJoin)
 	if err != nil {
 		return fmt.Errorf("get cmd hooks config failed:%v", err)
 	}
 
-	cstatus, err := clusterdeployment.JoinNodes(toClusterdeploymentConfig(conf, hooksConf), diffConfigs)
+	ccfg := toClusterdeploymentConfig(conf, hooksConf)
+	ccfg.DryRun = opts.dryRun
+	cstatus, err := clusterdeployment.JoinNodes(ccfg, diffConfigs)
+	setOutputResult(conf.ClusterID, &cstatus)
 	if err != nil {
 		failedConfigs := getFailedConfigs(diffConfigs, cstatus)
 		// rollback
-		if err1 := clusterdeployment.DeleteNodes(toClusterdeploymentConfig(mergedConf, nil), failedConfigs); err1 != nil {
+		mergedCcfg := toClusterdeploymentConfig(mergedConf, nil)
+		mergedCcfg.DryRun = opts.dryRun
+		if err1 := clusterdeployment.DeleteNodes(mergedCcfg, failedConfigs); err1 != nil {
 			logrus.Errorf("delete nodes failed when join failed: %v", err1)
 		}
 
@@ -264,7 +287,9 @@ func joinCluster(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Print(cstatus.Show())
+	if output == nil {
+		fmt.Print(cstatus.Show())
+	}
 
 	return nil
 }
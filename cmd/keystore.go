@@ -0,0 +1,120 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo keystore command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+// readSecret read secret from terminal without echo, or the first line of stdin
+func readSecret(in *os.File, prompt string) (string, error) {
+	if terminal.IsTerminal(int(in.Fd())) {
+		fmt.Fprint(os.Stderr, prompt)
+		s, err := terminal.ReadPassword(int(in.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(s), err
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func setKeystoreSecret(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("please specify name of secret")
+	}
+
+	secret, err := readSecret(os.Stdin, fmt.Sprintf("Enter secret of %s: ", args[0]))
+	if err != nil {
+		return fmt.Errorf("read secret failed: %v", err)
+	}
+	if secret == "" {
+		return fmt.Errorf("empty secret")
+	}
+	if err = secrets.StoreKeystore(args[0], secret); err != nil {
+		return err
+	}
+	fmt.Printf("secret is stored, reference it by %s:%s\n", secrets.RefKeystore, args[0])
+	return nil
+}
+
+func listKeystoreSecrets(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+	names, err := secrets.ListKeystore()
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		fmt.Println(n)
+	}
+	return nil
+}
+
+func removeKeystoreSecrets(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("please specify name of secret")
+	}
+	for _, n := range args {
+		if err := secrets.RemoveKeystore(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewKeystoreCmd() *cobra.Command {
+	keystoreCmd := &cobra.Command{
+		Use:   "keystore",
+		Short: "manage secrets in keystore encrypted by passphrase, passphrase is read from " + secrets.PassphraseEnv + " or prompt",
+	}
+
+	keystoreCmd.AddCommand(&cobra.Command{
+		Use:   "set NAME",
+		Short: "encrypt secret read from stdin and store it as NAME",
+		RunE:  setKeystoreSecret,
+	})
+	keystoreCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "list names of secrets in keystore",
+		RunE:  listKeystoreSecrets,
+	})
+	keystoreCmd.AddCommand(&cobra.Command{
+		Use:   "remove NAME...",
+		Short: "remove secrets, NAME also removes secrets of NAME/xxx, such as secrets of cluster",
+		RunE:  removeKeystoreSecrets,
+	})
+
+	return keystoreCmd
+}
@@ -0,0 +1,122 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of secrets in saved deploy config
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+func TestStorePlainSecrets(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "cmd-keystore-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	secrets.KeystorePath = filepath.Join(tempdir, "keystore.json")
+	secrets.SetPassphrase("test-passphrase")
+	defer func() {
+		secrets.KeystorePath = ""
+		secrets.SetPassphrase("")
+	}()
+
+	master := &HostConfig{Name: "master", Ip: "192.168.0.2", Bastion: &BastionConfig{Ip: "10.0.0.1", Password: "host-bastion"}}
+	conf := &DeployConfig{
+		ClusterID: "k8s-cluster",
+		Password:  "cluster-password",
+		Bastion:   &BastionConfig{Ip: "10.0.0.2", Password: "env:BASTION_PASSWORD"},
+		Masters:   []*HostConfig{master},
+		Etcds:     []*HostConfig{master},
+	}
+	if err = storePlainSecrets(conf); err != nil {
+		t.Fatalf("store plain secrets failed: %v", err)
+	}
+
+	if conf.Password != "keystore:k8s-cluster/password" || master.Bastion.Password != "keystore:k8s-cluster/master-bastion-password" {
+		t.Fatalf("plain secrets are not replaced: %s, %s", conf.Password, master.Bastion.Password)
+	}
+	if conf.Bastion.Password != "env:BASTION_PASSWORD" {
+		t.Fatalf("reference should be kept, get %s", conf.Bastion.Password)
+	}
+	if s, err := secrets.Resolve(conf.Password); err != nil || s != "cluster-password" {
+		t.Fatalf("resolve stored password failed: %s, %v", s, err)
+	}
+}
+
+func TestStorePlainSecretsWithoutPassphrase(t *testing.T) {
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("passphrase can be read from terminal")
+	}
+	env, exist := os.LookupEnv(secrets.PassphraseEnv)
+	os.Unsetenv(secrets.PassphraseEnv)
+	defer func() {
+		if exist {
+			os.Setenv(secrets.PassphraseEnv, env)
+		}
+	}()
+
+	// such as deploy in CI, secrets are not saved in plain text by default
+	conf := &DeployConfig{ClusterID: "k8s-cluster", Password: "cluster-password"}
+	err := storePlainSecrets(conf)
+	if err == nil || !strings.Contains(err.Error(), secrets.PassphraseEnv) || !strings.Contains(err.Error(), "env:NAME") {
+		t.Fatalf("store plain secrets without passphrase should fail: %v", err)
+	}
+
+	// saved deploy config with plain secrets is not migrated
+	tempdir, err := ioutil.TempDir("", "cmd-keystore-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	oldHome := api.EggoHomePath
+	api.EggoHomePath = tempdir
+	defer func() {
+		api.EggoHomePath = oldHome
+	}()
+	saved := savedDeployConfigPath("k8s-cluster")
+	if err = os.MkdirAll(filepath.Dir(saved), 0700); err != nil {
+		t.Fatalf("create cluster dir failed: %v", err)
+	}
+	if err = ioutil.WriteFile(saved, []byte(legacyDeployConfig+"password: plain-password\n"), 0600); err != nil {
+		t.Fatalf("write deploy config failed: %v", err)
+	}
+	if _, _, err = migrateDeployConfigFile(saved); err == nil {
+		t.Fatalf("migrate saved deploy config with plain secrets without passphrase should fail")
+	}
+	if data, rerr := ioutil.ReadFile(saved); rerr != nil || string(data) != legacyDeployConfig+"password: plain-password\n" {
+		t.Fatalf("saved deploy config should not be changed: %s, %v", data, rerr)
+	}
+
+	// unless it is allowed explicitly
+	opts.allowPlainSecrets = true
+	defer func() {
+		opts.allowPlainSecrets = false
+	}()
+	if err = storePlainSecrets(conf); err != nil {
+		t.Fatalf("store plain secrets with --allow-plain-secrets failed: %v", err)
+	}
+	if conf.Password != "cluster-password" {
+		t.Fatalf("plain secret should be kept, get %s", conf.Password)
+	}
+}
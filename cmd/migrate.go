@@ -0,0 +1,154 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo config migrate command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v1"
+
+	"isula.org/eggo/pkg/api"
+)
+
+// isSavedDeployConfig return whether file is deploy config saved by eggo for cluster
+func isSavedDeployConfig(file string) bool {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+	clusterID := filepath.Base(filepath.Dir(abs))
+	return abs == filepath.Clean(savedDeployConfigPath(clusterID))
+}
+
+// backupDeployConfigPath return path to keep deploy config file before migrated, such as deploy.yaml.v1alpha1.bak
+func backupDeployConfigPath(file, version string) string {
+	return fmt.Sprintf("%s.%s.bak", file, version[strings.LastIndex(version, "/")+1:])
+}
+
+// writeFileAtomic write to temp file in the same dir and rename it, so that file never be broken
+// by interrupt or full disk
+func writeFileAtomic(file string, data []byte, perm os.FileMode) error {
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// mode of existed temp file is not changed by WriteFile
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// migrateDeployConfigFile upgrade deploy config file to the current version in place,
+// and move plain secrets of saved deploy config to keystore like saveDeployConfig,
+// return the version of file before migrated, and the backup of it if file is changed
+func migrateDeployConfigFile(file string) (string, string, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", "", err
+	}
+	conf, version, err := parseDeployConfig(data)
+	if err != nil {
+		return "", "", err
+	}
+	saved := isSavedDeployConfig(file)
+	if version == CurrentDeployConfigVersion && !(saved && hasPlainSecrets(conf)) {
+		return version, "", nil
+	}
+
+	if saved {
+		if err = storePlainSecrets(conf); err != nil {
+			return "", "", err
+		}
+	}
+	d, err := yaml.Marshal(conf)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal deploy config failed: %v", err)
+	}
+	// comments and formats of file are lost after migrated, so keep the original one
+	backup := backupDeployConfigPath(file, version)
+	if err = writeFileAtomic(backup, data, info.Mode().Perm()); err != nil {
+		return "", "", fmt.Errorf("backup deploy config to %s failed: %v", backup, err)
+	}
+	if err = writeFileAtomic(file, d, info.Mode().Perm()); err != nil {
+		return "", "", fmt.Errorf("write deploy config failed: %v", err)
+	}
+	return version, backup, nil
+}
+
+func migrateConfig(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	files := args
+	if len(files) == 0 {
+		// saved deploy configs of all clusters
+		saved, err := filepath.Glob(filepath.Join(api.GetEggoClusterPath(), "*", "deploy.yaml"))
+		if err != nil {
+			return err
+		}
+		files = saved
+	}
+
+	failed := 0
+	for _, f := range files {
+		version, backup, err := migrateDeployConfigFile(f)
+		if err != nil {
+			failed++
+			fmt.Printf("%s: migrate failed: %v\n", f, err)
+		} else if backup == "" {
+			fmt.Printf("%s: already %s\n", f, CurrentDeployConfigVersion)
+		} else if version == CurrentDeployConfigVersion {
+			fmt.Printf("%s: plain secrets are moved to keystore, backup: %s\n", f, backup)
+		} else {
+			fmt.Printf("%s: migrated from %s to %s, backup: %s\n", f, version, CurrentDeployConfigVersion, backup)
+		}
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("migrate %d deploy config files failed", failed)
+	}
+	return nil
+}
+
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage deploy config files",
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate [FILE]...",
+		Short: "upgrade deploy config files to " + CurrentDeployConfigVersion + " in place, default saved configs of all clusters",
+		RunE:  migrateConfig,
+	}
+
+	configCmd.AddCommand(migrateCmd)
+
+	return configCmd
+}
@@ -26,29 +26,49 @@ import (
 )
 
 type eggoOptions struct {
-	name                 string
-	templateConfig       string
-	masters              []string
-	nodes                []string
-	etcds                []string
-	loadbalance          string
-	username             string
-	password             string
-	deployConfig         string
-	deployEnableRollback bool
-	cleanupConfig        string
-	cleanupClusterID     string
-	debug                bool
-	version              bool
-	joinType             string
-	joinClusterID        string
-	joinYaml             string
-	joinHost             HostConfig
-	delClusterID         string
-	clusterPrehook       string
-	clusterPosthook      string
-	prehook              string
-	posthook             string
+	name                  string
+	templateConfig        string
+	masters               []string
+	nodes                 []string
+	etcds                 []string
+	loadbalance           string
+	username              string
+	password              string
+	deployConfig          string
+	deployEnableRollback  bool
+	deployResume          bool
+	deployLocal           bool
+	deployClusterID       string
+	cleanupConfig         string
+	cleanupClusterID      string
+	debug                 bool
+	version               bool
+	joinType              string
+	joinClusterID         string
+	joinYaml              string
+	joinHost              HostConfig
+	delClusterID          string
+	clusterPrehook        string
+	clusterPosthook       string
+	prehook               string
+	posthook              string
+	statusClusterID       string
+	statusOutput          string
+	upgradeClusterID      string
+	upgradeYaml           string
+	upgradeBatchSize      int
+	etcdClusterID         string
+	etcdBackupRetain      int
+	etcdSnapshot          string
+	certsClusterID        string
+	certsOutput           string
+	hostsClusterID        string
+	dryRun                bool
+	planDir               string
+	output                string
+	preflightConfig       string
+	ignorePreflightErrors []string
+	allowPlainSecrets     bool
 }
 
 var opts eggoOptions
@@ -66,14 +86,45 @@ func init() {
 func setupEggoCmdOpts(eggoCmd *cobra.Command) {
 	flags := eggoCmd.Flags()
 	flags.BoolVarP(&opts.version, "version", "v", false, "Print version information and quit")
+	eggoCmd.PersistentFlags().BoolVarP(&opts.allowPlainSecrets, "allow-plain-secrets", "", false,
+		"save passwords in plain text in saved deploy config if passphrase of keystore is not available")
 }
 
 func setupDeployCmdOpts(deployCmd *cobra.Command) {
 	flags := deployCmd.Flags()
 	flags.StringVarP(&opts.deployConfig, "file", "f", defaultDeployConfigPath(), "location of cluster deploy config file, default $HOME/.eggo/deploy.yaml")
 	flags.BoolVarP(&opts.deployEnableRollback, "rollback", "", true, "rollback failed node to cleanup")
+	flags.BoolVarP(&opts.deployResume, "resume", "", false, "resume failed deploy of cluster, skip tasks finished in last deploy")
+	flags.StringVarP(&opts.deployClusterID, "id", "", "", "cluster id to resume")
+	flags.BoolVarP(&opts.deployLocal, "local", "", false, "deploy single node cluster on this machine without ssh")
 	flags.StringVarP(&opts.clusterPrehook, "cluster-prehook", "", "", "cluser prehooks when deploy cluser")
 	flags.StringVarP(&opts.clusterPosthook, "cluster-posthook", "", "", "cluster posthook when deploy cluster")
+	setupDryRunCmdOpts(deployCmd)
+	setupOutputCmdOpts(deployCmd)
+	setupIgnorePreflightCmdOpts(deployCmd)
+}
+
+func setupIgnorePreflightCmdOpts(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringSliceVarP(&opts.ignorePreflightErrors, "ignore-preflight-errors", "", nil,
+		"preflight checks whose errors are shown as warnings, such as Swap,Port-6443, 'all' ignore all errors")
+}
+
+func setupPreflightCmdOpts(preflightCmd *cobra.Command) {
+	flags := preflightCmd.Flags()
+	flags.StringVarP(&opts.preflightConfig, "file", "f", defaultDeployConfigPath(), "location of cluster deploy config file, default $HOME/.eggo/deploy.yaml")
+	setupIgnorePreflightCmdOpts(preflightCmd)
+}
+
+func setupOutputCmdOpts(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.output, "output", "o", outputText, "output format, support: text, json. json output is events and result, one json per line")
+}
+
+func setupDryRunCmdOpts(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.dryRun, "dry-run", "", false, "only record what will be done on nodes into plan, nothing is executed")
+	flags.StringVarP(&opts.planDir, "plan-dir", "", ".", "directory to save plan of dry run")
 }
 
 func setupCleanupCmdOpts(cleanupCmd *cobra.Command) {
@@ -82,6 +133,7 @@ func setupCleanupCmdOpts(cleanupCmd *cobra.Command) {
 	flags.StringVarP(&opts.cleanupClusterID, "id", "", "", "cluster id")
 	flags.StringVarP(&opts.clusterPrehook, "cluster-prehook", "", "", "cluser prehooks when clenaup cluser")
 	flags.StringVarP(&opts.clusterPosthook, "cluster-posthook", "", "", "cluster posthook when cleaup cluster")
+	setupOutputCmdOpts(cleanupCmd)
 }
 
 func setupJoinCmdOpts(joinCmd *cobra.Command) {
@@ -94,6 +146,9 @@ func setupJoinCmdOpts(joinCmd *cobra.Command) {
 	flags.StringVarP(&opts.joinYaml, "file", "f", "", "yaml file contain nodes information")
 	flags.StringVarP(&opts.prehook, "prehook", "", "", "prehook when join cluster")
 	flags.StringVarP(&opts.posthook, "posthook", "", "", "posthook when join cluster")
+	setupDryRunCmdOpts(joinCmd)
+	setupOutputCmdOpts(joinCmd)
+	setupIgnorePreflightCmdOpts(joinCmd)
 }
 
 func setupDeleteCmdOpts(deleteCmd *cobra.Command) {
@@ -101,13 +156,56 @@ func setupDeleteCmdOpts(deleteCmd *cobra.Command) {
 	flags.StringVarP(&opts.delClusterID, "id", "", "", "cluster id")
 	flags.StringVarP(&opts.prehook, "prehook", "", "", "prehook when delete cluster")
 	flags.StringVarP(&opts.posthook, "posthook", "", "", "posthook when delete cluster")
+	setupDryRunCmdOpts(deleteCmd)
+	setupOutputCmdOpts(deleteCmd)
+}
+
+func setupStatusCmdOpts(statusCmd *cobra.Command) {
+	flags := statusCmd.Flags()
+	flags.StringVarP(&opts.statusClusterID, "id", "", "", "cluster id")
+	flags.StringVarP(&opts.statusOutput, "output", "o", "table", "output format, support: table, json")
+}
+
+func setupUpgradeCmdOpts(upgradeCmd *cobra.Command) {
+	flags := upgradeCmd.Flags()
+	flags.StringVarP(&opts.upgradeClusterID, "id", "", "", "cluster id")
+	flags.StringVarP(&opts.upgradeYaml, "file", "f", "", "yaml file contain new packages and target version")
+	flags.IntVarP(&opts.upgradeBatchSize, "worker-batch-size", "", 1, "number of workers upgrade at the same time")
+}
+
+func setupEtcdBackupCmdOpts(backupCmd *cobra.Command) {
+	flags := backupCmd.Flags()
+	flags.StringVarP(&opts.etcdClusterID, "id", "", "", "cluster id")
+	flags.IntVarP(&opts.etcdBackupRetain, "retain", "", 5, "number of snapshots to keep, 0 means keep all")
+}
+
+func setupEtcdRestoreCmdOpts(restoreCmd *cobra.Command) {
+	flags := restoreCmd.Flags()
+	flags.StringVarP(&opts.etcdClusterID, "id", "", "", "cluster id")
+	flags.StringVarP(&opts.etcdSnapshot, "snapshot", "", "", "snapshot to restore, default is the latest backup")
+}
+
+func setupCertsCheckCmdOpts(checkCmd *cobra.Command) {
+	flags := checkCmd.Flags()
+	flags.StringVarP(&opts.certsClusterID, "id", "", "", "cluster id")
+	flags.StringVarP(&opts.certsOutput, "output", "o", "table", "output format, support: table, json")
+}
+
+func setupCertsRenewCmdOpts(renewCmd *cobra.Command) {
+	flags := renewCmd.Flags()
+	flags.StringVarP(&opts.certsClusterID, "id", "", "", "cluster id")
+}
+
+func setupHostsRescanCmdOpts(rescanCmd *cobra.Command) {
+	flags := rescanCmd.Flags()
+	flags.StringVarP(&opts.hostsClusterID, "id", "", "", "cluster id")
 }
 
 func setupTemplateCmdOpts(templateCmd *cobra.Command) {
 	flags := templateCmd.Flags()
 	flags.StringVarP(&opts.name, "name", "n", "k8s-cluster", "set cluster name")
 	flags.StringVarP(&opts.username, "user", "u", "root", "user to login all node")
-	flags.StringVarP(&opts.password, "password", "p", "123456", "password to login all node")
+	flags.StringVarP(&opts.password, "password", "p", "", "password to login all node, or reference of it: env:NAME, file:PATH, keystore:NAME, agent:[SOCKET]")
 	flags.StringArrayVarP(&opts.masters, "masters", "", []string{"192.168.0.2"}, "set master ips")
 	flags.StringArrayVarP(&opts.nodes, "workers", "", []string{"192.168.0.3", "192.168.0.4"}, "set worker ips")
 	flags.StringArrayVarP(&opts.etcds, "etcds", "", nil, "set etcd node ips")
@@ -0,0 +1,167 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: machine-readable output of deploy, join, delete and cleanup
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+const (
+	outputText = "text"
+
+	eventResult = "result"
+)
+
+// outputResult is the last line of json output
+type outputResult struct {
+	Time           time.Time          `json:"time"`
+	Type           string             `json:"type"`
+	Operation      string             `json:"operation"`
+	Cluster        string             `json:"cluster,omitempty"`
+	Success        bool               `json:"success"`
+	Error          string             `json:"error,omitempty"`
+	ElapsedSeconds float64            `json:"elapsedSeconds"`
+	Status         *api.ClusterStatus `json:"status"`
+}
+
+// jsonOutput write events of tasks and phases, and result of operation into w,
+// one json object per line
+type jsonOutput struct {
+	lock   sync.Mutex
+	w      io.Writer
+	start  time.Time
+	result outputResult
+	// status of nodes collected from events, used if operation has no cluster status
+	nodes map[string]bool
+}
+
+var output *jsonOutput
+
+// infoWriter return writer for messages to user, stdout only contains json lines in json output
+func infoWriter() io.Writer {
+	if opts.output == outputJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func newJSONOutput(w io.Writer, operation string) *jsonOutput {
+	return &jsonOutput{
+		w:      w,
+		start:  time.Now(),
+		result: outputResult{Type: eventResult, Operation: operation},
+		nodes:  make(map[string]bool),
+	}
+}
+
+func (o *jsonOutput) write(v interface{}) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	data, err := json.Marshal(v)
+	if err != nil {
+		logrus.Warnf("marshal output failed: %v", err)
+		return
+	}
+	if _, err = o.w.Write(append(data, '\n')); err != nil {
+		logrus.Warnf("write output failed: %v", err)
+	}
+}
+
+func (o *jsonOutput) handleEvent(e *nodemanager.Event) {
+	if e.Address != "" {
+		o.lock.Lock()
+		if _, ok := o.nodes[e.Address]; !ok {
+			o.nodes[e.Address] = true
+		}
+		if e.Type == nodemanager.EventTaskFailed && !e.Ignored {
+			o.nodes[e.Address] = false
+		}
+		o.lock.Unlock()
+	}
+	o.write(e)
+}
+
+func (o *jsonOutput) finish(err error) {
+	r := o.result
+	r.Time = time.Now()
+	r.ElapsedSeconds = r.Time.Sub(o.start).Seconds()
+	if r.Status == nil {
+		o.lock.Lock()
+		r.Status = &api.ClusterStatus{StatusOfNodes: o.nodes}
+		for _, success := range o.nodes {
+			if success {
+				r.Status.SuccessCnt++
+			} else {
+				r.Status.FailureCnt++
+			}
+		}
+		o.lock.Unlock()
+	}
+	r.Success = err == nil && r.Status.FailureCnt == 0
+	if err != nil {
+		r.Error = secrets.Scrub(err.Error())
+	}
+	o.write(&r)
+}
+
+// startOutput start json output of operation on stdout if it is required
+func startOutput(operation string) error {
+	switch opts.output {
+	case outputText:
+		return nil
+	case outputJSON:
+	default:
+		return fmt.Errorf("unsupported output format: %s", opts.output)
+	}
+
+	// logs never mix with json lines
+	logrus.SetOutput(os.Stderr)
+	output = newJSONOutput(os.Stdout, operation)
+	nodemanager.SetEventHandler(output.handleEvent)
+	return nil
+}
+
+// setOutputResult set cluster and its status into result of json output
+func setOutputResult(clusterID string, cstatus *api.ClusterStatus) {
+	if output == nil {
+		return
+	}
+	output.result.Cluster = clusterID
+	if cstatus != nil {
+		output.result.Status = cstatus
+	}
+}
+
+// finishOutput write result of operation as the last line of json output
+func finishOutput(err error) {
+	if output == nil {
+		return
+	}
+	nodemanager.SetEventHandler(nil)
+	output.finish(err)
+	output = nil
+}
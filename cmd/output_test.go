@@ -0,0 +1,86 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of machine-readable output
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/nodemanager"
+)
+
+func readJSONLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		line := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid json line: %s: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestJSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	o := newJSONOutput(&buf, "delete")
+	o.handleEvent(&nodemanager.Event{Type: nodemanager.EventTaskFinished, Node: "worker0", Address: "192.168.0.3"})
+	o.handleEvent(&nodemanager.Event{Type: nodemanager.EventTaskFailed, Node: "worker1", Address: "192.168.0.4",
+		Ignored: true})
+	o.handleEvent(&nodemanager.Event{Type: nodemanager.EventTaskFailed, Node: "worker2", Address: "192.168.0.5"})
+	o.finish(fmt.Errorf("delete worker2 failed"))
+
+	lines := readJSONLines(t, &buf)
+	if len(lines) != 4 {
+		t.Fatalf("expect 3 events and result, get: %v", lines)
+	}
+	result := lines[3]
+	if result["type"] != eventResult || result["operation"] != "delete" || result["success"] != false ||
+		result["error"] != "delete worker2 failed" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	status := result["status"].(map[string]interface{})
+	nodes := status["statusOfNodes"].(map[string]interface{})
+	if nodes["192.168.0.3"] != true || nodes["192.168.0.4"] != true || nodes["192.168.0.5"] != false {
+		t.Fatalf("unexpected status of nodes: %v", nodes)
+	}
+}
+
+func TestJSONOutputWithClusterStatus(t *testing.T) {
+	var buf bytes.Buffer
+	o := newJSONOutput(&buf, "deploy")
+	o.result.Status = &api.ClusterStatus{
+		Working:       true,
+		StatusOfNodes: map[string]bool{"192.168.0.2": true, "192.168.0.3": false},
+		SuccessCnt:    1,
+		FailureCnt:    1,
+	}
+	o.finish(nil)
+
+	lines := readJSONLines(t, &buf)
+	if len(lines) != 1 || lines[0]["success"] != false {
+		t.Fatalf("deploy with failed nodes should not success: %v", lines)
+	}
+	nodes := lines[0]["status"].(map[string]interface{})["statusOfNodes"].(map[string]interface{})
+	if len(nodes) != 2 || nodes["192.168.0.3"] != false {
+		t.Fatalf("status of nodes should come from cluster status: %v", nodes)
+	}
+}
@@ -0,0 +1,155 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo preflight command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment"
+	"isula.org/eggo/pkg/clusterdeployment/binary/preflight"
+	"isula.org/eggo/pkg/utils"
+)
+
+const ignoreAllPreflightErrors = "all"
+
+// getIgnoredChecks return lower case names of checks to ignore
+func getIgnoredChecks(names []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	for _, c := range append(preflight.Checks(), ignoreAllPreflightErrors) {
+		known[strings.ToLower(c)] = true
+	}
+
+	ignored := make(map[string]bool)
+	for _, n := range names {
+		name := strings.ToLower(strings.TrimSpace(n))
+		if name == "" {
+			continue
+		}
+		if !known[name] && !strings.HasPrefix(name, strings.ToLower(preflight.CheckPortPrefix)) {
+			return nil, fmt.Errorf("unknown preflight check: %s, support: %s, %sN, %s", n,
+				strings.Join(preflight.Checks(), ", "), preflight.CheckPortPrefix, ignoreAllPreflightErrors)
+		}
+		ignored[name] = true
+	}
+	return ignored, nil
+}
+
+func ignorePreflightErrors(errs []*api.PreflightError, ignored map[string]bool) {
+	for _, e := range errs {
+		e.Ignored = ignored[ignoreAllPreflightErrors] || ignored[strings.ToLower(e.Check)]
+	}
+}
+
+// showPreflightErrors show errors grouped by node, return nodes failed in preflight
+func showPreflightErrors(w io.Writer, errs []*api.PreflightError) []string {
+	var nodes []string
+	grouped := make(map[string][]*api.PreflightError)
+	for _, e := range errs {
+		if _, ok := grouped[e.Node]; !ok {
+			nodes = append(nodes, e.Node)
+		}
+		grouped[e.Node] = append(grouped[e.Node], e)
+	}
+	sort.Strings(nodes)
+
+	var failed []string
+	for _, n := range nodes {
+		fmt.Fprintf(w, "[preflight] node %s (%s):\n", grouped[n][0].Name, n)
+		nodeFailed := false
+		for _, e := range grouped[n] {
+			level := "ERROR"
+			if e.Ignored {
+				level = "WARNING"
+			} else {
+				nodeFailed = true
+			}
+			fmt.Fprintf(w, "\t[%s %s]: %s\n", level, e.Check, e.Message)
+		}
+		if nodeFailed {
+			failed = append(failed, n)
+		}
+	}
+	return failed
+}
+
+// runPreflight check nodes of cluster with conf, home of cluster created to pin host keys
+// is removed if cluster is not deployed
+func runPreflight(conf *DeployConfig, nodes []*api.HostConfig) error {
+	ignored, err := getIgnoredChecks(opts.ignorePreflightErrors)
+	if err != nil {
+		return err
+	}
+
+	home := api.GetClusterHomePath(conf.ClusterID)
+	existed, err := utils.CheckPathExist(home)
+	if err != nil {
+		return err
+	}
+	errs, err := clusterdeployment.Preflight(toClusterdeploymentConfig(conf, nil), nodes)
+	if !existed {
+		if rerr := os.RemoveAll(home); rerr != nil {
+			logrus.Warnf("remove home of cluster %s failed: %v", conf.ClusterID, rerr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("preflight checks failed: %v", err)
+	}
+
+	ignorePreflightErrors(errs, ignored)
+	if failed := showPreflightErrors(infoWriter(), errs); len(failed) != 0 {
+		return fmt.Errorf("preflight checks failed on nodes: %s, fix them or skip checks by --ignore-preflight-errors",
+			strings.Join(failed, ", "))
+	}
+	fmt.Fprintf(infoWriter(), "[preflight] all checks of %d nodes passed\n", len(nodes))
+	return nil
+}
+
+func preflightCluster(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	conf, err := loadDeployConfig(opts.preflightConfig)
+	if err != nil {
+		return fmt.Errorf("load deploy config file failed: %v", err)
+	}
+	if err = RunChecker(conf); err != nil {
+		return err
+	}
+
+	ccfg := toClusterdeploymentConfig(conf, nil)
+	return runPreflight(conf, ccfg.Nodes)
+}
+
+func NewPreflightCmd() *cobra.Command {
+	preflightCmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "check nodes of cluster before deploy",
+		RunE:  preflightCluster,
+	}
+
+	setupPreflightCmdOpts(preflightCmd)
+
+	return preflightCmd
+}
@@ -0,0 +1,70 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of preflight command
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestGetIgnoredChecks(t *testing.T) {
+	ignored, err := getIgnoredChecks([]string{"swap", "Port-6443", ""})
+	if err != nil {
+		t.Fatalf("get ignored checks failed: %v", err)
+	}
+	if !ignored["swap"] || !ignored["port-6443"] || len(ignored) != 2 {
+		t.Fatalf("invalid ignored checks: %v", ignored)
+	}
+
+	if _, err = getIgnoredChecks([]string{"unknown"}); err == nil {
+		t.Fatalf("expect error of unknown check")
+	}
+}
+
+func TestShowPreflightErrors(t *testing.T) {
+	errs := []*api.PreflightError{
+		{Node: "192.168.0.2", Name: "node2", Check: "Swap", Message: "swap is enabled"},
+		{Node: "192.168.0.1", Name: "node1", Check: "Port-6443", Message: "port 6443 is in use"},
+		{Node: "192.168.0.2", Name: "node2", Check: "Mem", Message: "memory is not enough"},
+	}
+	ignored, err := getIgnoredChecks([]string{"port-6443", "swap"})
+	if err != nil {
+		t.Fatalf("get ignored checks failed: %v", err)
+	}
+	ignorePreflightErrors(errs, ignored)
+
+	var buf bytes.Buffer
+	failed := showPreflightErrors(&buf, errs)
+	if len(failed) != 1 || failed[0] != "192.168.0.2" {
+		t.Fatalf("invalid failed nodes: %v", failed)
+	}
+	expect := "[preflight] node node1 (192.168.0.1):\n" +
+		"\t[WARNING Port-6443]: port 6443 is in use\n" +
+		"[preflight] node node2 (192.168.0.2):\n" +
+		"\t[WARNING Swap]: swap is enabled\n" +
+		"\t[ERROR Mem]: memory is not enough\n"
+	if buf.String() != expect {
+		t.Fatalf("invalid output:\n%s", buf.String())
+	}
+
+	ignored, _ = getIgnoredChecks([]string{"all"})
+	ignorePreflightErrors(errs, ignored)
+	if failed = showPreflightErrors(&buf, errs); len(failed) != 0 {
+		t.Fatalf("expect all errors ignored, get %v", failed)
+	}
+}
@@ -0,0 +1,129 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo status command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func healthString(healthy bool) string {
+	if healthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+func showStatusTable(w io.Writer, cstatus *api.ClusterStatus) {
+	fmt.Fprintf(w, "ControlPlane: %s\n", cstatus.ControlPlane)
+	fmt.Fprintf(w, "Message: %s\n", cstatus.Message)
+	fmt.Fprintf(w, "Nodes: %d healthy, %d unhealthy\n\n", cstatus.SuccessCnt, cstatus.FailureCnt)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tSTATUS\tMESSAGE")
+	for _, c := range cstatus.Components {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, healthString(c.Healthy), c.Message)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tADDRESS\tROLES\tSTATUS\tUNHEALTHY")
+	for _, n := range cstatus.NodesHealth {
+		var unhealthy []string
+		for _, c := range n.Components {
+			if !c.Healthy {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s(%s)", c.Name, c.Message))
+			}
+		}
+		if n.Message != "" {
+			unhealthy = append(unhealthy, n.Message)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", n.Name, n.Address, strings.Join(n.Roles, ","),
+			healthString(n.Healthy), strings.Join(unhealthy, ","))
+	}
+	tw.Flush()
+}
+
+func showStatus(w io.Writer, cstatus *api.ClusterStatus, output string) error {
+	switch output {
+	case outputTable:
+		showStatusTable(w, cstatus)
+	case outputJSON:
+		data, err := json.MarshalIndent(cstatus, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	default:
+		return fmt.Errorf("unsupported output format: %s", output)
+	}
+	return nil
+}
+
+func statusCluster(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.statusClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+	if opts.statusOutput != outputTable && opts.statusOutput != outputJSON {
+		return fmt.Errorf("unsupported output format: %s", opts.statusOutput)
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.statusClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+	if err = RunChecker(conf); err != nil {
+		return err
+	}
+
+	cstatus, err := clusterdeployment.GetClusterStatus(toClusterdeploymentConfig(conf, nil))
+	if err != nil {
+		return err
+	}
+
+	return showStatus(os.Stdout, cstatus, opts.statusOutput)
+}
+
+func NewStatusCmd() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "show health status of cluster",
+		RunE:  statusCluster,
+	}
+
+	setupStatusCmdOpts(statusCmd)
+
+	return statusCmd
+}
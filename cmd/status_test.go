@@ -0,0 +1,72 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: cmd status testcase
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestShowStatus(t *testing.T) {
+	cstatus := &api.ClusterStatus{
+		Message:       "cluster is working with unhealthy nodes",
+		ControlPlane:  "https://192.168.0.1:6443",
+		Working:       true,
+		StatusOfNodes: map[string]bool{"192.168.0.2": false},
+		FailureCnt:    1,
+		Components: []*api.ComponentStatus{
+			{Name: "kube-apiserver", Healthy: true},
+		},
+		NodesHealth: []*api.NodeHealth{
+			{
+				Name:    "master0",
+				Address: "192.168.0.2",
+				Roles:   []string{"master"},
+				Components: []*api.ComponentStatus{
+					{Name: "kube-scheduler", Healthy: false, Message: "inactive"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := showStatus(&buf, cstatus, outputTable); err != nil {
+		t.Fatalf("show table failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "kube-scheduler(inactive)") {
+		t.Fatalf("unhealthy component not found in table: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := showStatus(&buf, cstatus, outputJSON); err != nil {
+		t.Fatalf("show json failed: %v", err)
+	}
+	var got api.ClusterStatus
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if got.FailureCnt != 1 || len(got.NodesHealth) != 1 || got.NodesHealth[0].Healthy {
+		t.Fatalf("unexpect json output: %s", buf.String())
+	}
+
+	if err := showStatus(&buf, cstatus, "yaml"); err == nil {
+		t.Fatalf("expect error for unsupported output")
+	}
+}
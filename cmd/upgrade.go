@@ -0,0 +1,121 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo upgrade command implement
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v1"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment"
+)
+
+func loadUpgradeConfig(file string) (*UpgradeConfig, error) {
+	yamlStr, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &UpgradeConfig{}
+	if err := yaml.Unmarshal([]byte(yamlStr), conf); err != nil {
+		return nil, err
+	}
+	if conf.KubernetesVersion == "" {
+		return nil, fmt.Errorf("kubernetes-version is required")
+	}
+	if conf.InstallConfig.PackageSrc == nil {
+		return nil, fmt.Errorf("package-source of install is required")
+	}
+
+	return conf, nil
+}
+
+// replace install config of cluster with new packages
+func getUpgradedConfig(conf *DeployConfig, uconf *UpgradeConfig) *DeployConfig {
+	upgraded := *conf
+	upgraded.InstallConfig = uconf.InstallConfig
+	return &upgraded
+}
+
+func upgradeCluster(cmd *cobra.Command, args []string) error {
+	if opts.debug {
+		initLog()
+	}
+
+	if opts.upgradeClusterID == "" {
+		return fmt.Errorf("please specify cluster id")
+	}
+	if opts.upgradeYaml == "" {
+		return fmt.Errorf("please specify yaml file of new packages")
+	}
+
+	conf, err := loadDeployConfig(savedDeployConfigPath(opts.upgradeClusterID))
+	if err != nil {
+		return fmt.Errorf("load saved deploy config failed: %v", err)
+	}
+
+	uconf, err := loadUpgradeConfig(opts.upgradeYaml)
+	if err != nil {
+		return fmt.Errorf("load upgrade config file %v failed: %v", opts.upgradeYaml, err)
+	}
+
+	upgraded := getUpgradedConfig(conf, uconf)
+	if err = RunChecker(upgraded); err != nil {
+		return err
+	}
+
+	holder, err := NewProcessPlaceHolder(eggoPlaceHolderPath(conf.ClusterID))
+	if err != nil {
+		return fmt.Errorf("create process holder failed: %v, mayebe other eggo is running with cluster: %s", err, conf.ClusterID)
+	}
+	defer func() {
+		if terr := holder.Remove(); terr != nil {
+			fmt.Printf("remove process place holder failed: %v", terr)
+		}
+	}()
+
+	ccfg := toClusterdeploymentConfig(upgraded, nil)
+	ccfg.UpgradeConf = &api.UpgradeConfig{
+		KubernetesVersion: uconf.KubernetesVersion,
+		WorkerBatchSize:   opts.upgradeBatchSize,
+	}
+	if err = clusterdeployment.UpgradeCluster(ccfg); err != nil {
+		return err
+	}
+
+	// save new packages, later join will install them
+	if err = saveDeployConfig(upgraded, savedDeployConfigPath(conf.ClusterID)); err != nil {
+		return fmt.Errorf("upgrade cluster success, but save deploy config failed: %v", err)
+	}
+
+	fmt.Printf("upgrade cluster %s to %s success\n", conf.ClusterID, uconf.KubernetesVersion)
+	return nil
+}
+
+func NewUpgradeCmd() *cobra.Command {
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "rolling upgrade kubernetes cluster with new packages",
+		RunE:  upgradeCluster,
+	}
+
+	setupUpgradeCmdOpts(upgradeCmd)
+
+	return upgradeCmd
+}
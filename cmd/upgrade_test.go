@@ -0,0 +1,68 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: cmd upgrade testcase
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadUpgradeConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eggo-upgrade")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "new-packages.yaml")
+	data := `kubernetes-version: v1.21.1
+install:
+  package-source:
+    type: tar.gz
+    srcpath:
+      amd64: /root/packages/packages-x86.tar.gz
+  kubernetes-master:
+  - name: kubernetes-client,kubernetes-master
+    type: pkg
+`
+	if err = ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatalf("write upgrade config failed: %v", err)
+	}
+
+	uconf, err := loadUpgradeConfig(file)
+	if err != nil {
+		t.Fatalf("load upgrade config failed: %v", err)
+	}
+	if uconf.KubernetesVersion != "v1.21.1" || len(uconf.InstallConfig.KubernetesMaster) != 1 {
+		t.Fatalf("unexpect upgrade config: %+v", uconf)
+	}
+
+	conf := &DeployConfig{ClusterID: "test", Masters: []*HostConfig{{Name: "m0", Ip: "192.168.0.2"}}}
+	upgraded := getUpgradedConfig(conf, uconf)
+	if upgraded.InstallConfig.PackageSrc.SrcPath["amd64"] != "/root/packages/packages-x86.tar.gz" ||
+		upgraded.Masters[0].Ip != "192.168.0.2" || conf.InstallConfig.PackageSrc != nil {
+		t.Fatalf("unexpect upgraded config: %+v", upgraded)
+	}
+
+	if err = ioutil.WriteFile(file, []byte("install: {}\n"), 0600); err != nil {
+		t.Fatalf("write upgrade config failed: %v", err)
+	}
+	if _, err = loadUpgradeConfig(file); err == nil {
+		t.Fatalf("expect error without kubernetes-version")
+	}
+}
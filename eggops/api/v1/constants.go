@@ -17,6 +17,14 @@ const (
 	PrivateKeyVolumeFormat string = "/%s-privatekey"
 	PackageVolumeFormat    string = "/%s-package"
 
+	// optional keys of machine login secret, besides keys of kubernetes.io/ssh-auth and kubernetes.io/basic-auth
+	LoginSecretUsernameKey    string = "username"
+	LoginSecretPassphraseKey  string = "passphrase"
+	LoginSecretCertificateKey string = "ssh-certificate"
+	// key of machine login secret only used by one machine, <hostname>.<key>, such as node1.ssh-privatekey
+	LoginSecretMachineKeyFormat string = "%s.%s"
+	DefaultLoginUsername        string = "root"
+
 	DefaultPackageArmName   string = "packages-arm.tar.gz"
 	DefaultPackageX86Name   string = "packages-x86.tar.gz"
 	DefaultPackageRISCVName string = "packages-risc-v.tar.gz"
@@ -98,6 +98,14 @@ type OpenPorts struct {
 
 	// tcp/udp
 	Protocol string `json:"protocol"`
+
+	// zone of firewalld, table of nftables or chain of iptables
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// CIDRs allowed to access port, default all
+	// +optional
+	Sources []string `json:"sources,omitempty"`
 }
 type OpenPortsConfig struct {
 	// +optional
@@ -125,6 +133,10 @@ type InfrastructureSpec struct {
 	InstallConfig InstallConfig `json:"install,omitempty"`
 
 	OpenPorts OpenPortsConfig `json:"open-ports,omitempty"`
+
+	// firewall to open ports: auto, none, firewalld, nftables, iptables or ufw
+	// +optional
+	Firewall string `json:"firewall,omitempty"`
 }
 
 // InfrastructureStatus defines the observed state of Infrastructure
@@ -760,6 +760,11 @@ func (in *OpenPorts) DeepCopyInto(out *OpenPorts) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenPorts.
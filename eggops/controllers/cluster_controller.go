@@ -481,29 +481,7 @@ func (r *ClusterReconciler) prepareSecret(ctx context.Context, cluster *eggov1.C
 		return
 	}
 
-	if secret.Type == v1.SecretTypeSSHAuth {
-		if _, ok := secret.Data[v1.SSHAuthPrivateKey]; !ok {
-			err = fmt.Errorf("invalid secret")
-			r.Log.Error(err, "get secret for cluster", "name", cluster.Name)
-		}
-		return
-	}
-
-	if secret.Type != v1.SecretTypeBasicAuth {
-		err = fmt.Errorf("secret %s type invalid", secret.Name)
-		r.Log.Error(err, "get secret for cluster", "name", cluster.Name)
-		return
-	}
-
-	// secret.Type == v1.SecretTypeBasicAuth
-	if _, ok := secret.Data[v1.BasicAuthUsernameKey]; !ok {
-		err = fmt.Errorf("invalid secret")
-		r.Log.Error(err, "get secret for cluster", "name", cluster.Name)
-		return
-	}
-
-	if _, ok := secret.Data[v1.BasicAuthPasswordKey]; !ok {
-		err = fmt.Errorf("invalid secret")
+	if err = checkLoginSecret(&secret); err != nil {
 		r.Log.Error(err, "get secret for cluster", "name", cluster.Name)
 		return
 	}
@@ -516,6 +494,32 @@ func (r *ClusterReconciler) prepareSecret(ctx context.Context, cluster *eggov1.C
 	return
 }
 
+// checkLoginSecret check keys of machine login secret, ssh-auth secret must have private key,
+// basic-auth secret must have username and password, and certificate need a private key
+func checkLoginSecret(secret *v1.Secret) error {
+	has := func(key string) bool {
+		_, ok := secret.Data[key]
+		return ok
+	}
+
+	switch secret.Type {
+	case v1.SecretTypeSSHAuth:
+		if !has(v1.SSHAuthPrivateKey) {
+			return fmt.Errorf("invalid secret %s: no %s", secret.Name, v1.SSHAuthPrivateKey)
+		}
+	case v1.SecretTypeBasicAuth:
+		if !has(v1.BasicAuthUsernameKey) || !has(v1.BasicAuthPasswordKey) {
+			return fmt.Errorf("invalid secret %s: no %s or %s", secret.Name, v1.BasicAuthUsernameKey, v1.BasicAuthPasswordKey)
+		}
+		if has(eggov1.LoginSecretCertificateKey) {
+			return fmt.Errorf("invalid secret %s: %s without %s", secret.Name, eggov1.LoginSecretCertificateKey, v1.SSHAuthPrivateKey)
+		}
+	default:
+		return fmt.Errorf("secret %s type invalid", secret.Name)
+	}
+	return nil
+}
+
 func (r *ClusterReconciler) prepareInfrastructureRef(ctx context.Context, cluster *eggov1.Cluster) (err error) {
 	infrastructure := eggov1.Infrastructure{}
 	if cluster.Spec.Infrastructure.Namespace != "" && cluster.Spec.Infrastructure.Namespace != cluster.Namespace {
@@ -758,7 +762,8 @@ func fillEggoJobConfig(r *ClusterReconciler, ctx context.Context, cluster *eggov
 		}
 		return err
 	}
-	if secret.Type == v1.SecretTypeSSHAuth {
+	// password of basic auth is referenced as file too
+	if secret.Type == v1.SecretTypeSSHAuth || secret.Type == v1.SecretTypeBasicAuth {
 		addPrivateKeySecret(secret.Name, fmt.Sprintf(eggov1.PrivateKeyVolumeFormat, cluster.Name), job)
 	}
 
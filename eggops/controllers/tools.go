@@ -3,7 +3,6 @@ package controllers
 import (
 	"fmt"
 	"net"
-	"net/url"
 	"path/filepath"
 	"strconv"
 
@@ -20,21 +19,53 @@ func getEndpoint(conf eggov1.APIEndpointConfig) string {
 	if conf.BindPort != nil {
 		port = strconv.Itoa(int(*conf.BindPort))
 	}
-	turl := url.URL{
-		Scheme: "https",
-		Host:   net.JoinHostPort(conf.Advertise, port),
+	return net.JoinHostPort(conf.Advertise, port)
+}
+
+// loginConfig return login set by keys of secret with prefix, passwords and keys are read from
+// files of secret mounted in eggo job, never kept in configmap
+func loginConfig(secret *v1.Secret, dir string, prefix string) cmd.LoginConfig {
+	var login cmd.LoginConfig
+	has := func(key string) bool {
+		_, ok := secret.Data[prefix+key]
+		return ok
+	}
+	file := func(key string) string {
+		return filepath.Join(dir, prefix+key)
+	}
+
+	if has(eggov1.LoginSecretUsernameKey) {
+		login.Username = string(secret.Data[prefix+eggov1.LoginSecretUsernameKey])
+	}
+	if has(v1.BasicAuthPasswordKey) {
+		login.Password = "file:" + file(v1.BasicAuthPasswordKey)
 	}
-	return turl.String()
+	if has(v1.SSHAuthPrivateKey) {
+		login.PrivateKeyPath = file(v1.SSHAuthPrivateKey)
+	}
+	if has(eggov1.LoginSecretPassphraseKey) {
+		login.PrivateKeyPassphrase = "file:" + file(eggov1.LoginSecretPassphraseKey)
+	}
+	if has(eggov1.LoginSecretCertificateKey) {
+		login.CertificatePath = file(eggov1.LoginSecretCertificateKey)
+	}
+	return login
 }
 
-func toEggoHosts(machines []*eggov1.Machine) []*cmd.HostConfig {
+// machineLoginConfig return login of machine override cluster, set by keys <hostname>.<key> of secret
+func machineLoginConfig(secret *v1.Secret, dir string, hostname string) cmd.LoginConfig {
+	return loginConfig(secret, dir, fmt.Sprintf(eggov1.LoginSecretMachineKeyFormat, hostname, ""))
+}
+
+func toEggoHosts(machines []*eggov1.Machine, secret *v1.Secret, dir string) []*cmd.HostConfig {
 	var result []*cmd.HostConfig
 	for _, m := range machines {
 		result = append(result, &cmd.HostConfig{
-			Name: m.Spec.HostName,
-			Ip:   m.Spec.IP,
-			Port: int(*m.Spec.Port),
-			Arch: m.Spec.Arch,
+			Name:        m.Spec.HostName,
+			Ip:          m.Spec.IP,
+			Port:        int(*m.Spec.Port),
+			Arch:        m.Spec.Arch,
+			LoginConfig: machineLoginConfig(secret, dir, m.Spec.HostName),
 		})
 	}
 	return result
@@ -116,6 +147,8 @@ func fillOpenPorts(src []*eggov1.OpenPorts) []*cmd.OpenPorts {
 		copy = append(copy, &cmd.OpenPorts{
 			Port:     int(*op.Port),
 			Protocol: op.Protocol,
+			Zone:     op.Zone,
+			Sources:  op.Sources,
 		})
 	}
 
@@ -141,21 +174,26 @@ func fillOpenPortsConfig(openPorts eggov1.OpenPortsConfig) map[string][]*cmd.Ope
 }
 
 func ConvertClusterToEggoConfig(cluster *eggov1.Cluster, mb *eggov1.MachineBinding, secret *v1.Secret, infrastructure *eggov1.Infrastructure) ([]byte, error) {
-	conf := cmd.DeployConfig{}
+	conf := cmd.DeployConfig{
+		APIVersion: cmd.CurrentDeployConfigVersion,
+		Kind:       cmd.DeployConfigKind,
+	}
 	// set cluster config
 	conf.ClusterID = cluster.GetName()
 
-	if secret.Type == v1.SecretTypeSSHAuth {
-		conf.PrivateKeyPath = filepath.Join(fmt.Sprintf(eggov1.PrivateKeyVolumeFormat, cluster.Name), v1.SSHAuthPrivateKey)
-	} else {
-		conf.Username = string(secret.Data[v1.BasicAuthUsernameKey])
-		conf.Password = string(secret.Data[v1.BasicAuthPasswordKey])
+	secretDir := fmt.Sprintf(eggov1.PrivateKeyVolumeFormat, cluster.Name)
+	login := loginConfig(secret, secretDir, "")
+	if login.Username == "" {
+		login.Username = eggov1.DefaultLoginUsername
 	}
+	conf.Username, conf.Password, conf.PrivateKeyPath = login.Username, login.Password, login.PrivateKeyPath
+	conf.PrivateKeyPassphrase, conf.CertificatePath = login.PrivateKeyPassphrase, login.CertificatePath
 
 	packagePath := fmt.Sprintf(eggov1.PackageVolumeFormat, cluster.Name)
 	conf.InstallConfig = fillInstallConfig(infrastructure.Spec.InstallConfig, packagePath)
 
 	conf.OpenPorts = fillOpenPortsConfig(infrastructure.Spec.OpenPorts)
+	conf.Firewall = infrastructure.Spec.Firewall
 
 	conf.EnableKubeletServing = false
 	if cluster.Spec.EnableKubeletServing {
@@ -197,23 +235,24 @@ func ConvertClusterToEggoConfig(cluster *eggov1.Cluster, mb *eggov1.MachineBindi
 	conf.Workers = make([]*cmd.HostConfig, 0)
 	for _, set := range mb.Spec.MachineSets {
 		if set.MatchType(eggov1.UsageMaster) {
-			conf.Masters = toEggoHosts(set.Machines)
+			conf.Masters = toEggoHosts(set.Machines, secret, secretDir)
 			// set master machines as worker machines
 			conf.Workers = append(conf.Workers, conf.Masters...)
 		} else if set.MatchType(eggov1.UsageWorker) {
-			conf.Workers = append(conf.Workers, toEggoHosts(set.Machines)...)
+			conf.Workers = append(conf.Workers, toEggoHosts(set.Machines, secret, secretDir)...)
 		} else if set.MatchType(eggov1.UsageEtcd) {
-			conf.Etcds = toEggoHosts(set.Machines)
+			conf.Etcds = toEggoHosts(set.Machines, secret, secretDir)
 		} else if set.MatchType(eggov1.UsageLoadbalance) {
 			if len(set.Machines) != 1 {
 				continue
 			}
 			conf.LoadBalance = cmd.LoadBalance{
-				Name:     set.Machines[0].Spec.HostName,
-				Ip:       set.Machines[0].Spec.IP,
-				Port:     int(*set.Machines[0].Spec.Port),
-				Arch:     set.Machines[0].Spec.Arch,
-				BindPort: int(cluster.Spec.LoadbalanceBindPort),
+				Name:        set.Machines[0].Spec.HostName,
+				Ip:          set.Machines[0].Spec.IP,
+				Port:        int(*set.Machines[0].Spec.Port),
+				Arch:        set.Machines[0].Spec.Arch,
+				BindPort:    int(cluster.Spec.LoadbalanceBindPort),
+				LoginConfig: machineLoginConfig(secret, secretDir, set.Machines[0].Spec.HostName),
 			}
 		}
 	}
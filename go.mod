@@ -3,6 +3,7 @@ module isula.org/eggo
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/go-logr/logr v1.2.0
 	github.com/kubesphere/kubekey v1.1.0
 	github.com/lithammer/dedent v1.1.0
@@ -11,6 +12,8 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.8.1
 	github.com/spf13/cobra v1.1.1
+	github.com/tmc/scp v0.0.0-20170824174625-f7b48647feef
+	golang.org/x/crypto v0.0.0-20220214200702-86341886e292
 	gopkg.in/yaml.v1 v1.0.0-20140924161607-9f9df34309c0
 	k8s.io/api v0.24.0
 	k8s.io/apimachinery v0.24.0
@@ -19,12 +19,16 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sirupsen/logrus"
+
 	"isula.org/eggo/cmd"
+	"isula.org/eggo/pkg/utils/secrets"
 )
 
 func main() {
+	logrus.AddHook(&secrets.ScrubHook{})
 	if err := cmd.NewEggoCmd().Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, secrets.Scrub(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -60,6 +60,19 @@ func (ep APIEndpoint) GetURL() string {
 	return fmt.Sprintf("%s/%v", ep.AdvertiseAddress, ep.BindPort)
 }
 
+// OnNode return whether node has the handler
+func (h *RuntimeHandler) OnNode(name string) bool {
+	if len(h.Nodes) == 0 {
+		return true
+	}
+	for _, n := range h.Nodes {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 func GetClusterHomePath(cluster string) string {
 	return filepath.Join(EggoHomePath, cluster)
 }
@@ -68,6 +81,26 @@ func GetCertificateStorePath(cluster string) string {
 	return filepath.Join(EggoHomePath, cluster, "pki")
 }
 
+// GetJournalPath return path of journal which record tasks of operation on cluster
+func GetJournalPath(cluster string, op HookOperator) string {
+	return filepath.Join(EggoHomePath, cluster, "journal", string(op)+".json")
+}
+
+// GetReportDir return directory of timing reports of operations on cluster
+func GetReportDir(cluster string) string {
+	return filepath.Join(EggoHomePath, cluster, "reports")
+}
+
+// GetKnownHostsPath return path of ssh host keys pinned for nodes of cluster
+func GetKnownHostsPath(cluster string) string {
+	return filepath.Join(EggoHomePath, cluster, "known_hosts")
+}
+
+// GetKeystorePath return path of keystore which save secrets of all clusters
+func GetKeystorePath() string {
+	return filepath.Join(EggoHomePath, "keystore.json")
+}
+
 func GetEggoClusterPath() string {
 	return EggoHomePath
 }
@@ -149,6 +182,9 @@ func WithAPIServerExtrArgs(eargs map[string]string) ClusterConfigOption {
 
 func WithControllerManagerExtrArgs(eargs map[string]string) ClusterConfigOption {
 	return func(conf *ClusterConfig) *ClusterConfig {
+		if conf.ControlPlane.ManagerConf == nil {
+			conf.ControlPlane.ManagerConf = &ControlManager{}
+		}
 		conf.ControlPlane.ManagerConf.ExtraArgs = eargs
 		return conf
 	}
@@ -156,6 +192,9 @@ func WithControllerManagerExtrArgs(eargs map[string]string) ClusterConfigOption
 
 func WithSchedulerExtrArgs(eargs map[string]string) ClusterConfigOption {
 	return func(conf *ClusterConfig) *ClusterConfig {
+		if conf.ControlPlane.SchedulerConf == nil {
+			conf.ControlPlane.SchedulerConf = &Scheduler{}
+		}
 		conf.ControlPlane.SchedulerConf.ExtraArgs = eargs
 		return conf
 	}
@@ -170,6 +209,9 @@ func WithKubeletExtrArgs(eargs map[string]string) ClusterConfigOption {
 
 func WithKubeProxyExtrArgs(eargs map[string]string) ClusterConfigOption {
 	return func(conf *ClusterConfig) *ClusterConfig {
+		if conf.WorkerConfig.ProxyConf == nil {
+			conf.WorkerConfig.ProxyConf = &KubeProxy{}
+		}
 		conf.WorkerConfig.ProxyConf.ExtraArgs = eargs
 		return conf
 	}
@@ -221,3 +263,53 @@ func GetUserTempDir(user string) string {
 	}
 	return fmt.Sprintf(constants.DefaultUserCopyTempHomeFormat, user)
 }
+
+// MergeNodeTuning merges tunings of roles on one node, settings of latter one take precedence
+func MergeNodeTuning(tunings ...*NodeTuning) *NodeTuning {
+	var merged *NodeTuning
+	for _, t := range tunings {
+		if t == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &NodeTuning{}
+		}
+		for k, v := range t.Sysctl {
+			if merged.Sysctl == nil {
+				merged.Sysctl = make(map[string]string)
+			}
+			merged.Sysctl[k] = v
+		}
+		for k, v := range t.Ulimits {
+			if merged.Ulimits == nil {
+				merged.Ulimits = make(map[string]string)
+			}
+			merged.Ulimits[k] = v
+		}
+		for _, m := range t.KernelModules {
+			if !containsString(merged.KernelModules, m) {
+				merged.KernelModules = append(merged.KernelModules, m)
+			}
+		}
+		merged.DisableSwap = merged.DisableSwap || t.DisableSwap
+		if t.SELinux != "" {
+			merged.SELinux = t.SELinux
+		}
+		if t.TransparentHugepage != "" {
+			merged.TransparentHugepage = t.TransparentHugepage
+		}
+		if len(t.NTPServers) != 0 {
+			merged.NTPServers = append([]string{}, t.NTPServers...)
+		}
+	}
+	return merged
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -71,11 +71,29 @@ type HookRunConfig struct {
 type RoleInfra struct {
 	OpenPorts []*OpenPorts     `json:"open-ports"`
 	Softwares []*PackageConfig `json:"softwares"`
+	Tuning    *NodeTuning      `json:"tuning,omitempty"`
+}
+
+// NodeTuning is os settings of node, applied when setup infrastructure and reverted when cleanup
+type NodeTuning struct {
+	Sysctl        map[string]string `json:"sysctl,omitempty"`
+	KernelModules []string          `json:"kernel-modules,omitempty"`
+	DisableSwap   bool              `json:"disable-swap,omitempty"`
+	// enforcing, permissive or disabled, keep current mode if empty
+	SELinux string `json:"selinux,omitempty"`
+	// items of limits.conf, such as nofile, used as both soft and hard limit
+	Ulimits map[string]string `json:"ulimits,omitempty"`
+	// always, madvise or never, keep current setting if empty
+	TransparentHugepage string `json:"transparent-hugepage,omitempty"`
+	// servers of chrony, keep current configuration if empty
+	NTPServers []string `json:"ntp-servers,omitempty"`
 }
 
 type OpenPorts struct {
-	Port     int    `json:"port"`
-	Protocol string `json:"protocol"` // tcp/udp
+	Port     int      `json:"port"`
+	Protocol string   `json:"protocol"` // tcp/udp
+	Zone     string   `json:"zone,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
 }
 
 type PackageConfig struct {
@@ -92,6 +110,22 @@ type PackageSrcConfig struct {
 	SrcPath map[string]string `json:"srcpath"`  // key: arm/amd/risc-v...
 }
 
+// BastionConfig is jump host to connect nodes, certificate not set is same as node
+type BastionConfig struct {
+	Address        string `json:"address"`
+	Port           int    `json:"port"`
+	UserName       string `json:"username"`
+	Password       string `json:"password"`
+	PrivateKey     string `json:"private-key"`
+	PrivateKeyPath string `json:"private-key-path"`
+	// reference of passphrase to decrypt private key, prompt if not set
+	PrivateKeyPassphrase string `json:"private-key-passphrase,omitempty"`
+	// openssh user certificate of private key, signed by ssh ca
+	CertificatePath string `json:"certificate-path,omitempty"`
+	// fingerprint of host key, such as SHA256:xxx, verified if set
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
 type HostConfig struct {
 	Arch           string   `json:"arch"`
 	Name           string   `json:"name"`
@@ -102,6 +136,14 @@ type HostConfig struct {
 	Password       string   `json:"password"`
 	PrivateKey     string   `json:"private-key"`
 	PrivateKeyPath string   `json:"private-key-path"`
+	// reference of passphrase to decrypt private key, prompt if not set
+	PrivateKeyPassphrase string `json:"private-key-passphrase,omitempty"`
+	// openssh user certificate of private key, signed by ssh ca
+	CertificatePath string `json:"certificate-path,omitempty"`
+	// fingerprint of host key, such as SHA256:xxx, verified if set
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// connect to node through bastion if set
+	Bastion *BastionConfig `json:"bastion,omitempty"`
 
 	// 0x1 is master, 0x2 is worker, 0x4 is etcd
 	// 0x3 is master and worker
@@ -151,11 +193,39 @@ type KubeProxy struct {
 }
 
 type ContainerEngine struct {
-	Runtime            string            `json:"runtime"`
-	RuntimeEndpoint    string            `json:"runtime-endpoint"`
-	RegistryMirrors    []string          `json:"registry-mirrors"`
-	InsecureRegistries []string          `json:"insecure-registries"`
-	ExtraArgs          map[string]string `json:"extra-args"`
+	Runtime             string            `json:"runtime"`
+	RuntimeEndpoint     string            `json:"runtime-endpoint"`
+	RegistryMirrors     []string          `json:"registry-mirrors"`
+	InsecureRegistries  []string          `json:"insecure-registries"`
+	ExtraArgs           map[string]string `json:"extra-args"`
+	RuntimeHandlers     []*RuntimeHandler `json:"runtime-handlers,omitempty"`
+	Registries          []*RegistryConfig `json:"registries,omitempty"`
+	RegistryCredentials string            `json:"registry-credentials,omitempty"` // local file in format of docker config.json
+}
+
+// RegistryConfig is hosts config of registry, certificates are local files copied to nodes
+type RegistryConfig struct {
+	Host       string   `json:"host"`
+	Mirrors    []string `json:"mirrors,omitempty"`
+	CAFile     string   `json:"ca-file,omitempty"`
+	ClientCert string   `json:"client-cert,omitempty"`
+	ClientKey  string   `json:"client-key,omitempty"`
+	SkipVerify bool     `json:"skip-verify,omitempty"`
+}
+
+// RuntimeHandler is sandbox runtime registered in container engine, pods use it by RuntimeClass with same name
+type RuntimeHandler struct {
+	Name string `json:"name"`
+	// shim of runtime for containerd, such as io.containerd.kata.v2
+	RuntimeType string `json:"runtime-type,omitempty"`
+	// path and args of oci runtime for isulad
+	Path        string   `json:"path,omitempty"`
+	RuntimeArgs []string `json:"runtime-args,omitempty"`
+	// config of runtime for containerd
+	ConfigPath string `json:"config-path,omitempty"`
+	// names of workers with handler, all workers if empty
+	Nodes    []string         `json:"nodes,omitempty"`
+	Packages []*PackageConfig `json:"packages,omitempty"`
 }
 
 type APIEndpoint struct {
@@ -242,6 +312,13 @@ type ClusterHookConf struct {
 	HookFiles  []string
 }
 
+type UpgradeConfig struct {
+	// target version of kubernetes packages
+	KubernetesVersion string
+	// number of workers upgrade at the same time
+	WorkerBatchSize int
+}
+
 type ClusterConfig struct {
 	Name            string                  `json:"name"`
 	DeployDriver    string                  `json:"deploy-driver"` // default is binary
@@ -258,10 +335,28 @@ type ClusterConfig struct {
 	LoadBalancer    LoadBalancer            `json:"loadBalancer"`
 	WorkerConfig    WorkerConfig            `json:"workerconfig"`
 	RoleInfra       map[uint16]*RoleInfra   `json:"role-infra"`
+	// tofu, strict or off, default is tofu
+	HostKeyChecking string `json:"host-key-checking,omitempty"`
+	// run commands on machine running eggo without ssh, only single node is supported
+	Local bool `json:"local,omitempty"`
+	// firewall to open ports on nodes: auto, none, firewalld, nftables, iptables or ufw, default is auto
+	Firewall string `json:"firewall,omitempty"`
 
 	// do not encode hooks, just set before use it
 	HooksConf []*ClusterHookConf `json:"-"`
 
+	// do not encode upgrade config, just set before upgrade cluster
+	UpgradeConf *UpgradeConfig `json:"-"`
+
+	// do not encode dry run, only record what will be done on nodes if set
+	DryRun bool `json:"-"`
+
+	// do not encode resume, skip tasks finished in journal of last deploy if set
+	Resume bool `json:"-"`
+
+	// do not encode it, nodes failed to connect are skipped and reported if set, such as status and preflight
+	SkipUnreachable bool `json:"-"`
+
 	// TODO: add other configurations at here
 }
 
@@ -272,6 +367,43 @@ type ClusterStatus struct {
 	StatusOfNodes map[string]bool `json:"statusOfNodes"`
 	SuccessCnt    uint32          `json:"successCnt"`
 	FailureCnt    uint32          `json:"failureCnt"`
+
+	Components  []*ComponentStatus `json:"components,omitempty"`
+	NodesHealth []*NodeHealth      `json:"nodesHealth,omitempty"`
+}
+
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+type NodeHealth struct {
+	Name       string             `json:"name"`
+	Address    string             `json:"address"`
+	Roles      []string           `json:"roles"`
+	Healthy    bool               `json:"healthy"`
+	Message    string             `json:"message,omitempty"`
+	Components []*ComponentStatus `json:"components,omitempty"`
+}
+
+type CertExpiration struct {
+	// node address, empty means machine running eggo
+	Node     string    `json:"node,omitempty"`
+	Path     string    `json:"path"`
+	CA       bool      `json:"ca"`
+	NotAfter time.Time `json:"notAfter"`
+	Message  string    `json:"message,omitempty"`
+}
+
+type PreflightError struct {
+	// address of node
+	Node    string `json:"node"`
+	Name    string `json:"name"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	// failure of check is ignored by user
+	Ignored bool `json:"ignored,omitempty"`
 }
 
 type InfrastructureAPI interface {
@@ -286,11 +418,13 @@ type EtcdAPI interface {
 	EtcdClusterDestroy() error
 	EtcdNodeSetup(machine *HostConfig) error
 	EtcdNodeDestroy(machine *HostConfig) error
+	EtcdBackup(backupDir string, retain int) (string, error)
+	EtcdRestore(snapshot string) error
 }
 
 type ClusterManagerAPI interface {
 	// TODO: should add other dependence cluster configurations
-	PreCreateClusterHooks() error
+	PreCreateClusterHooks(nodes []*HostConfig) error
 	PostCreateClusterHooks(nodes []*HostConfig) error
 	PreDeleteClusterHooks()
 	PostDeleteClusterHooks()
@@ -305,6 +439,9 @@ type ClusterManagerAPI interface {
 	ClusterNodeCleanup(node *HostConfig, delType uint16) error
 	ClusterUpgrade() error
 	ClusterStatus() (*ClusterStatus, error)
+	ClusterCertsExpiration() ([]*CertExpiration, error)
+	ClusterCertsRenew(targets []string) error
+	ClusterPreflight(nodes []*HostConfig) ([]*PreflightError, error)
 	AddonsSetup() error
 	AddonsDestroy() error
 
@@ -1,6 +1,7 @@
 package addons
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -25,7 +26,7 @@ func (ct *SetupAddonsTask) Name() string {
 	return "AddonsTask"
 }
 
-func (ct *SetupAddonsTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *SetupAddonsTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	logrus.Info("do apply addons...")
 
 	yamlDep := dependency.NewDependencyYaml(ct.srcPath, ct.kubeconfig, ct.yaml)
@@ -62,11 +63,11 @@ func setupAddons(cluster *api.ClusterConfig) error {
 	yamlPath := filepath.Join(cluster.PackageSrc.GetPkgDstPath(), constants.DefaultFilePath)
 	kubeconfig := filepath.Join(cluster.GetConfigDir(), constants.KubeConfigFileNameAdmin)
 
-	t := task.NewTaskInstance(&SetupAddonsTask{
+	t := task.NewTaskRetryInstance(&SetupAddonsTask{
 		yaml:       yaml,
 		srcPath:    yamlPath,
 		kubeconfig: kubeconfig,
-	})
+	}, task.DefaultRetryPolicy())
 	var masters []string
 	for _, n := range cluster.Nodes {
 		if (n.Type & api.Master) != 0 {
@@ -98,7 +99,7 @@ func (ct *CleanupAddonsTask) Name() string {
 	return "CleanupAddonsTask"
 }
 
-func (ct *CleanupAddonsTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *CleanupAddonsTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	logrus.Info("do remove addons...")
 
 	yamlDep := dependency.NewDependencyYaml(ct.srcPath, ct.kubeconfig, ct.yaml)
@@ -22,13 +22,18 @@ import (
 	"isula.org/eggo/pkg/clusterdeployment/binary/addons"
 	"isula.org/eggo/pkg/clusterdeployment/binary/bootstrap"
 	"isula.org/eggo/pkg/clusterdeployment/binary/cleanupcluster"
+	"isula.org/eggo/pkg/clusterdeployment/binary/clustercerts"
+	"isula.org/eggo/pkg/clusterdeployment/binary/clusterstatus"
 	"isula.org/eggo/pkg/clusterdeployment/binary/commontools"
 	"isula.org/eggo/pkg/clusterdeployment/binary/controlplane"
 	"isula.org/eggo/pkg/clusterdeployment/binary/coredns"
 	"isula.org/eggo/pkg/clusterdeployment/binary/etcdcluster"
 	"isula.org/eggo/pkg/clusterdeployment/binary/infrastructure"
 	"isula.org/eggo/pkg/clusterdeployment/binary/loadbalance"
+	"isula.org/eggo/pkg/clusterdeployment/binary/preflight"
+	"isula.org/eggo/pkg/clusterdeployment/binary/upgradecluster"
 	"isula.org/eggo/pkg/clusterdeployment/manager"
+	"isula.org/eggo/pkg/clusterdeployment/runtime"
 	"isula.org/eggo/pkg/utils"
 	"isula.org/eggo/pkg/utils/dependency"
 	"isula.org/eggo/pkg/utils/kubectl"
@@ -54,6 +59,7 @@ func New(conf *api.ClusterConfig) (api.ClusterDeploymentAPI, error) {
 	bcd := &BinaryClusterDeployment{
 		config:      conf,
 		connections: make(map[string]runner.Runner),
+		unreachable: make(map[string]error),
 	}
 	// register and connect all nodes
 	if err := bcd.registerNodes(); err != nil {
@@ -68,6 +74,8 @@ type BinaryClusterDeployment struct {
 
 	connLock    sync.RWMutex
 	connections map[string]runner.Runner
+	// errors of nodes failed to connect, only if config.SkipUnreachable is set
+	unreachable map[string]error
 }
 
 func (b *BinaryClusterDeployment) exists(nodeID string) bool {
@@ -77,6 +85,21 @@ func (b *BinaryClusterDeployment) exists(nodeID string) bool {
 	return ok
 }
 
+func (bcp *BinaryClusterDeployment) newRunner(hcf *api.HostConfig) (runner.Runner, error) {
+	if bcp.config.DryRun {
+		return runner.NewRecordingRunner(hcf)
+	}
+	if bcp.config.Local {
+		return &runner.LocalRunner{}, nil
+	}
+	// verify host keys with known_hosts of cluster
+	checker, err := runner.NewHostKeyChecker(api.GetKnownHostsPath(bcp.config.Name), bcp.config.HostKeyChecking)
+	if err != nil {
+		return nil, err
+	}
+	return runner.NewVerifiedSSHRunner(hcf, checker)
+}
+
 func (bcp *BinaryClusterDeployment) registerNode(hcf *api.HostConfig) error {
 	bcp.connLock.Lock()
 	defer bcp.connLock.Unlock()
@@ -84,7 +107,7 @@ func (bcp *BinaryClusterDeployment) registerNode(hcf *api.HostConfig) error {
 		logrus.Debugf("node: %s is already registered", hcf.Address)
 		return nil
 	}
-	r, err := runner.NewSSHRunner(hcf)
+	r, err := bcp.newRunner(hcf)
 	if err != nil {
 		logrus.Errorf("connect node: %s failed: %v", hcf.Address, err)
 		return err
@@ -109,9 +132,15 @@ func (bcp *BinaryClusterDeployment) registerNodes() error {
 
 	for _, cfg := range bcp.config.Nodes {
 		err = bcp.registerNode(cfg)
-		if err != nil {
+		if err == nil {
+			continue
+		}
+		if !bcp.config.SkipUnreachable {
 			return err
 		}
+		logrus.Warnf("skip unreachable node %s: %v", cfg.Address, err)
+		bcp.unreachable[cfg.Address] = err
+		err = nil
 	}
 	return nil
 }
@@ -141,6 +170,10 @@ func taintAndLabelNode(clusterID string, name string) error {
 }
 
 func (bcp *BinaryClusterDeployment) taintAndLabelMasterNodes() error {
+	// nodes never register to apiserver in dry run
+	if bcp.config.DryRun {
+		return nil
+	}
 	for _, node := range bcp.config.Nodes {
 		if (node.Type&api.Master != 0) && (node.Type&api.Worker != 0) {
 			if err := taintAndLabelNode(bcp.config.Name, node.Name); err != nil {
@@ -152,6 +185,55 @@ func (bcp *BinaryClusterDeployment) taintAndLabelMasterNodes() error {
 	return nil
 }
 
+func labelRuntimeHandlerNode(clusterID string, engine *api.ContainerEngine, name string) error {
+	labels := runtime.NodeRuntimeHandlerLabels(engine, name)
+	if len(labels) == 0 {
+		return nil
+	}
+	if err := kubectl.WaitNodeRegister(name, clusterID); err != nil {
+		logrus.Errorf("wait node: %s joined failed: %v", name, err)
+		return err
+	}
+	return kubectl.NodeTaintAndLabel(clusterID, name, labels, nil)
+}
+
+func (bcp *BinaryClusterDeployment) setupRuntimeHandlers() error {
+	engine := bcp.config.WorkerConfig.ContainerEngineConf
+	// nodes never register to apiserver in dry run
+	if bcp.config.DryRun || engine == nil || len(engine.RuntimeHandlers) == 0 {
+		return nil
+	}
+	for _, node := range bcp.config.Nodes {
+		if node.Type&api.Worker == 0 {
+			continue
+		}
+		if err := labelRuntimeHandlerNode(bcp.config.Name, engine, node.Name); err != nil {
+			return err
+		}
+	}
+	for _, h := range engine.RuntimeHandlers {
+		selector := map[string]string{fmt.Sprintf(runtime.RuntimeHandlerLabelFormat, h.Name): "true"}
+		if err := kubectl.ApplyRuntimeClass(bcp.config.Name, h.Name, h.Name, selector); err != nil {
+			logrus.Errorf("apply runtimeclass %s failed: %v", h.Name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bcp *BinaryClusterDeployment) cleanupRuntimeHandlers() {
+	engine := bcp.config.WorkerConfig.ContainerEngineConf
+	if bcp.config.DryRun || engine == nil {
+		return
+	}
+	for _, h := range engine.RuntimeHandlers {
+		if err := kubectl.DeleteRuntimeClass(bcp.config.Name, h.Name); err != nil {
+			logrus.Errorf("delete runtimeclass %s failed: %v", h.Name, err)
+		}
+	}
+}
+
 func (bcp *BinaryClusterDeployment) prepareCoredns() error {
 	// Setup coredns at here, like need addons
 	if err := coredns.CorednsSetup(bcp.config); err != nil {
@@ -260,6 +342,27 @@ func (bcp *BinaryClusterDeployment) EtcdNodeDestroy(machine *api.HostConfig) err
 	return nil
 }
 
+func (bcp *BinaryClusterDeployment) EtcdBackup(backupDir string, retain int) (string, error) {
+	logrus.Info("do etcd backup...")
+	snapshot, err := etcdcluster.BackupEtcd(bcp.config, backupDir, retain)
+	if err != nil {
+		return "", fmt.Errorf("etcd backup failed: %v", err)
+	}
+
+	logrus.Infof("do etcd backup done, snapshot: %s", snapshot)
+	return snapshot, nil
+}
+
+func (bcp *BinaryClusterDeployment) EtcdRestore(snapshot string) error {
+	logrus.Info("do etcd restore...")
+	if err := etcdcluster.RestoreEtcd(bcp.config, snapshot); err != nil {
+		return fmt.Errorf("etcd restore from %s failed: %v", snapshot, err)
+	}
+
+	logrus.Info("do etcd restore done")
+	return nil
+}
+
 func (bcp *BinaryClusterDeployment) ClusterControlPlaneInit(master *api.HostConfig) error {
 	logrus.Info("do init control plane...")
 	if !bcp.exists(master.Address) {
@@ -317,13 +420,56 @@ func (bcp *BinaryClusterDeployment) ClusterNodeCleanup(node *api.HostConfig, del
 }
 
 func (bcp *BinaryClusterDeployment) ClusterUpgrade() error {
-	// TODO: add implement
+	logrus.Info("do upgrade cluster...")
+	if err := upgradecluster.UpgradeCluster(bcp.config); err != nil {
+		logrus.Errorf("upgrade cluster failed: %v", err)
+		return err
+	}
+	logrus.Info("upgrade cluster success")
 	return nil
 }
 
 func (bcp *BinaryClusterDeployment) ClusterStatus() (*api.ClusterStatus, error) {
-	// TODO: add implement
-	return nil, nil
+	logrus.Info("do check status of cluster...")
+	cstatus, err := clusterstatus.GetClusterStatus(bcp.config, bcp.unreachable)
+	if err != nil {
+		logrus.Errorf("check status of cluster failed: %v", err)
+		return nil, err
+	}
+	logrus.Info("check status of cluster success")
+	return cstatus, nil
+}
+
+func (bcp *BinaryClusterDeployment) ClusterCertsExpiration() ([]*api.CertExpiration, error) {
+	logrus.Info("do check expiration of certificates...")
+	ces, err := clustercerts.CheckCertsExpiration(bcp.config)
+	if err != nil {
+		logrus.Errorf("check expiration of certificates failed: %v", err)
+		return nil, err
+	}
+	logrus.Info("check expiration of certificates success")
+	return ces, nil
+}
+
+func (bcp *BinaryClusterDeployment) ClusterPreflight(nodes []*api.HostConfig) ([]*api.PreflightError, error) {
+	logrus.Info("do preflight checks of nodes...")
+	errs, err := preflight.Preflight(bcp.config, nodes, bcp.unreachable)
+	if err != nil {
+		logrus.Errorf("preflight checks of nodes failed: %v", err)
+		return nil, err
+	}
+	logrus.Infof("preflight checks of nodes finished, %d errors found", len(errs))
+	return errs, nil
+}
+
+func (bcp *BinaryClusterDeployment) ClusterCertsRenew(targets []string) error {
+	logrus.Info("do renew certificates...")
+	if err := clustercerts.RenewCerts(bcp.config, targets); err != nil {
+		logrus.Errorf("renew certificates failed: %v", err)
+		return err
+	}
+	logrus.Info("renew certificates success")
+	return nil
 }
 
 func (bcp *BinaryClusterDeployment) AddonsSetup() error {
@@ -347,12 +493,19 @@ func (bcp *BinaryClusterDeployment) AddonsSetup() error {
 		return err
 	}
 
+	err = bcp.setupRuntimeHandlers()
+	if err != nil {
+		logrus.Errorf("[addons] setup runtime handlers failed: %v", err)
+		return err
+	}
+
 	logrus.Info("[addons] apply addons success.")
 	return nil
 }
 
 func (bcp *BinaryClusterDeployment) AddonsDestroy() error {
 	logrus.Info("do destroy addons...")
+	bcp.cleanupRuntimeHandlers()
 	err := addons.CleanupAddons(bcp.config)
 	if err != nil {
 		logrus.Errorf("[addons] destroy addons failed: %v", err)
@@ -421,13 +574,13 @@ func (bcp *BinaryClusterDeployment) Finish() {
 	logrus.Info("do finish binary deployment success")
 }
 
-func (bcp *BinaryClusterDeployment) PreCreateClusterHooks() error {
+func (bcp *BinaryClusterDeployment) PreCreateClusterHooks(nodes []*api.HostConfig) error {
 	role := []uint16{api.LoadBalance, api.ETCD, api.Master, api.Worker}
-	if err := dependency.ExecuteCmdHooks(bcp.config, bcp.config.Nodes, api.HookOpDeploy, api.ClusterPrehookType); err != nil {
+	if err := dependency.ExecuteCmdHooks(bcp.config, nodes, api.HookOpDeploy, api.ClusterPrehookType); err != nil {
 		return err
 	}
 
-	if err := dependency.HookSchedule(bcp.config, bcp.config.Nodes, role, api.SchedulePreJoin); err != nil {
+	if err := dependency.HookSchedule(bcp.config, nodes, role, api.SchedulePreJoin); err != nil {
 		return err
 	}
 	return nil
@@ -559,11 +712,17 @@ func (bcp *BinaryClusterDeployment) PostNodeJoinHooks(node *api.HostConfig) erro
 	}
 
 	// check whether the node is worker and master
-	if utils.IsType(roles, (api.Master | api.Worker)) {
+	// nodes never register to apiserver in dry run
+	if !bcp.config.DryRun && utils.IsType(roles, api.Master|api.Worker) {
 		if err := taintAndLabelNode(bcp.config.Name, node.Name); err != nil {
 			return err
 		}
 	}
+	if !bcp.config.DryRun && utils.IsType(roles, api.Worker) {
+		if err := labelRuntimeHandlerNode(bcp.config.Name, bcp.config.WorkerConfig.ContainerEngineConf, node.Name); err != nil {
+			return err
+		}
+	}
 
 	// check node status
 	if err := checkK8sServices([]*api.HostConfig{node}); err != nil {
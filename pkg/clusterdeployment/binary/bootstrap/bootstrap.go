@@ -16,11 +16,13 @@
 package bootstrap
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -47,6 +49,8 @@ const (
 var (
 	KubeWorkerSoftwares = []string{"kubelet", "kube-proxy", "kubectl"}
 	tokenTask           *GetTokenTask
+	// workers join at the same time, but only get token once
+	tokenLock sync.Mutex
 )
 
 type GetTokenTask struct {
@@ -58,7 +62,7 @@ func (gt *GetTokenTask) Name() string {
 	return "GetTokenTask"
 }
 
-func (gt *GetTokenTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (gt *GetTokenTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	token, err := commontools.GetBootstrapToken(r, gt.tokenStr,
 		filepath.Join(gt.cluster.GetConfigDir(), constants.KubeConfigFileNameAdmin), gt.cluster.GetManifestDir())
 	if err != nil {
@@ -69,6 +73,8 @@ func (gt *GetTokenTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 }
 
 func getTokenString() string {
+	tokenLock.Lock()
+	defer tokenLock.Unlock()
 	if tokenTask == nil {
 		return ""
 	}
@@ -83,7 +89,7 @@ func (it *NewWorkerTask) Name() string {
 	return "NewWorkerTask"
 }
 
-func (it *NewWorkerTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (it *NewWorkerTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	logrus.Info("do join new worker...\n")
 
 	// check worker dependences
@@ -103,6 +109,7 @@ func (it *NewWorkerTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 		return err
 	}
 
+	defer nodemanager.StartTiming(hcg, nodemanager.TimingService)()
 	if err := commontools.SetupWorkerServices(r, it.ccfg, hcg); err != nil {
 		logrus.Errorf("run service failed: %v", err)
 		return err
@@ -314,6 +321,16 @@ func genProxyCert(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig)
 	return nil
 }
 
+// RenewKubeProxyKubeConfig re-issue certificate of kube-proxy and render kubeconfig again
+func RenewKubeProxyKubeConfig(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+	apiEndpoint, err := endpoint.GetAPIServerEndpoint(ccfg)
+	if err != nil {
+		logrus.Errorf("get api server endpoint failed: %v", err)
+		return err
+	}
+	return genProxyCertAndConfig(r, ccfg, hcf, apiEndpoint)
+}
+
 func genProxyConfig(r runner.Runner, ccfg *api.ClusterConfig, apiEndpoint string) error {
 	proxyConfig := `kind: KubeProxyConfiguration
 apiVersion: kubeproxy.config.k8s.io/v1alpha1
@@ -362,18 +379,33 @@ func JoinMaster(config *api.ClusterConfig, master *api.HostConfig) error {
 	return nil
 }
 
+func prepareToken(config *api.ClusterConfig, controlPlane *api.HostConfig) error {
+	tokenLock.Lock()
+	defer tokenLock.Unlock()
+	if tokenTask != nil {
+		return nil
+	}
+
+	gt := &GetTokenTask{
+		cluster: config,
+	}
+	// get token again if apiserver is not ready
+	t := task.NewTaskRetryInstance(gt, task.DefaultRetryPolicy())
+	// token is required by all workers, get it again when resume
+	task.SetAlwaysRunFlag(t)
+	if err := nodemanager.RunTaskOnNodes(t, []string{controlPlane.Address}); err != nil {
+		return err
+	}
+	if err := nodemanager.WaitNodesFinish([]string{controlPlane.Address}, time.Minute*2); err != nil {
+		return err
+	}
+	tokenTask = gt
+	return nil
+}
+
 func JoinWorker(config *api.ClusterConfig, controlPlane *api.HostConfig, worker *api.HostConfig) error {
-	if tokenTask == nil {
-		tokenTask = &GetTokenTask{
-			cluster: config,
-		}
-
-		if err := nodemanager.RunTaskOnNodes(task.NewTaskInstance(tokenTask), []string{controlPlane.Address}); err != nil {
-			return err
-		}
-		if err := nodemanager.WaitNodesFinish([]string{controlPlane.Address}, time.Minute*2); err != nil {
-			return err
-		}
+	if err := prepareToken(config, controlPlane); err != nil {
+		return err
 	}
 
 	joinWorkerTasks := []task.Task{
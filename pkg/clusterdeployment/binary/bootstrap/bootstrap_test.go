@@ -16,6 +16,7 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -44,6 +45,10 @@ func (m *MockRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (m *MockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
 func (m *MockRunner) Reconnect() error {
 	logrus.Infof("reconnect")
 	return nil
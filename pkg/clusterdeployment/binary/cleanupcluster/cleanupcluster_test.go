@@ -16,6 +16,7 @@
 package cleanupcluster
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -24,6 +25,7 @@ import (
 
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
 )
 
 const (
@@ -63,6 +65,10 @@ func (m *fakeRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (r *fakeRunner) WithContext(ctx context.Context) runner.Runner {
+	return r
+}
+
 func (r *fakeRunner) Reconnect() error {
 	// nothing to do
 	return nil
@@ -98,7 +104,7 @@ func TestRemoveWorkers(t *testing.T) {
 		ccfg:    conf,
 		delType: api.Master | api.Worker | api.ETCD,
 	}
-	if err := task.Run(&fakeRunner{}, nodes[0]); err != nil {
+	if err := task.Run(context.Background(), &fakeRunner{}, nodes[0]); err != nil {
 		t.Fatalf("task execute failed for cleanup workers")
 	}
 }
@@ -123,7 +129,7 @@ func TestRemoveEtcds(t *testing.T) {
 	}
 
 	task := &cleanupEtcdMemberTask{ccfg: conf}
-	if err := task.Run(&fakeRunner{}, nodes[0]); err != nil {
+	if err := task.Run(context.Background(), &fakeRunner{}, nodes[0]); err != nil {
 		t.Fatalf("task execute failed for cleanup etcds")
 	}
 }
@@ -153,12 +159,12 @@ func TestRemoveWorkerTask(t *testing.T) {
 	}
 
 	// test remove worker success
-	if err := task.Run(&fakeRunner{}, nodes[0]); err != nil {
+	if err := task.Run(context.Background(), &fakeRunner{}, nodes[0]); err != nil {
 		t.Fatalf("test success of remove worker failed")
 	}
 
 	// test remove worker failed
-	if err := task.Run(&fakeRunner{failIfContainCmd: "kubectl"}, nodes[0]); err == nil {
+	if err := task.Run(context.Background(), &fakeRunner{failIfContainCmd: "kubectl"}, nodes[0]); err == nil {
 		t.Fatalf("test failure of remove worker failed")
 	}
 }
@@ -16,6 +16,7 @@
 package cleanupcluster
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -58,7 +59,7 @@ func (c *CleanupTempDirTask) Name() string {
 	return "CleanupTempDirTask"
 }
 
-func (c *CleanupTempDirTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (c *CleanupTempDirTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
@@ -16,6 +16,7 @@
 package cleanupcluster
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -59,7 +60,7 @@ func getEtcdPathes(ccfg *api.ClusterConfig) []string {
 	}
 }
 
-func (t *cleanupEtcdMemberTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *cleanupEtcdMemberTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
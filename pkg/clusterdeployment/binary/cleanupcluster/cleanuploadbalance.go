@@ -16,6 +16,7 @@
 package cleanupcluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
@@ -42,7 +43,7 @@ func getLoadBalancePathes() []string {
 	return []string{"/etc/nginx", "/usr/lib/systemd/system/nginx.service"}
 }
 
-func (t *cleanupLoadBalanceTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *cleanupLoadBalanceTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	// stop service before remove dependences
 	if err := stopServices(r, LoadBalanceService); err != nil {
 		logrus.Errorf("stop loadbalance service failed: %v", err)
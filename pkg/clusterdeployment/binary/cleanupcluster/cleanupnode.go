@@ -16,6 +16,7 @@
 package cleanupcluster
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -157,7 +158,7 @@ func isAllNodeDeleted(nodeType uint16, delType uint16) bool {
 	return !utils.IsType(remain, api.Master) && !utils.IsType(remain, api.Worker)
 }
 
-func (t *cleanupNodeTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *cleanupNodeTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
@@ -168,9 +169,12 @@ func (t *cleanupNodeTask) Run(r runner.Runner, hostConfig *api.HostConfig) error
 			logrus.Errorf("get worker services failed")
 		}
 
+		// credentials are removed by container engine, so before it is stopped
+		runtime.RemoveRegistryAuths(r, t.ccfg.WorkerConfig.ContainerEngineConf)
 		if err := stopServices(r, services); err != nil {
 			logrus.Warnf("stop service failed: %v", err)
 		}
+		runtime.RemoveRuntimeHandlers(r, t.ccfg, hostConfig)
 		removePathes(r, getWorkerPathes(r, t.ccfg))
 	}
 
@@ -222,7 +226,7 @@ func runRemoveWorker(configDir string, r runner.Runner, worker string) error {
 	return nil
 }
 
-func (t *removeWorkerTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *removeWorkerTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if err := runRemoveWorker(t.ccfg.GetConfigDir(), r, t.workerName); err != nil {
 		return err
 	}
@@ -0,0 +1,181 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: check expiration of cluster certificates
+ ******************************************************************************/
+
+package clustercerts
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/certs"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	checkCertsTimeout = 2 * time.Minute
+)
+
+// readFunc read content of file, local or on node
+type readFunc func(path string) ([]byte, error)
+
+func certExpiration(node, path string, read readFunc) *api.CertExpiration {
+	ce := &api.CertExpiration{Node: node, Path: path}
+	data, err := read(path)
+	if err != nil {
+		ce.Message = err.Error()
+		return ce
+	}
+
+	if !strings.HasSuffix(path, ".conf") {
+		cert, err := certs.ParseCertificate(data)
+		if err != nil {
+			ce.Message = err.Error()
+			return ce
+		}
+		ce.CA, ce.NotAfter = cert.IsCA, cert.NotAfter
+		return ce
+	}
+
+	cert, certPath, err := certs.ParseKubeConfigCertificate(data)
+	if err != nil {
+		// kubeconfig without client certificate, such as bootstrap kubeconfig
+		return nil
+	}
+	if cert == nil {
+		// kubelet rotate its client certificate, and kubeconfig only reference it
+		data, err = read(certPath)
+		if err == nil {
+			cert, err = certs.ParseCertificate(data)
+		}
+		if err != nil {
+			ce.Message = fmt.Sprintf("read %s failed: %v", certPath, err)
+			return ce
+		}
+	}
+	ce.CA, ce.NotAfter = cert.IsCA, cert.NotAfter
+	return ce
+}
+
+type checkCertsTask struct {
+	ccfg *api.ClusterConfig
+
+	lock   sync.Mutex
+	result map[string][]*api.CertExpiration
+}
+
+func (t *checkCertsTask) Name() string {
+	return "checkCertsTask"
+}
+
+func (t *checkCertsTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	cmd := fmt.Sprintf("find %s -type f -name '*.crt' 2>/dev/null; find %s -maxdepth 1 -type f -name '*.conf' 2>/dev/null; true",
+		t.ccfg.GetCertDir(), t.ccfg.GetConfigDir())
+	output, err := r.RunCommand(utils.AddSudo(cmd))
+	if err != nil {
+		return fmt.Errorf("list certificates on %s failed: %v", hcf.Address, err)
+	}
+
+	read := func(path string) ([]byte, error) {
+		content, err := r.RunCommand(utils.AddSudo("cat " + path))
+		if err != nil {
+			return nil, err
+		}
+		return []byte(content), nil
+	}
+
+	var result []*api.CertExpiration
+	for _, path := range strings.Fields(output) {
+		if ce := certExpiration(hcf.Address, path, read); ce != nil {
+			result = append(result, ce)
+		}
+	}
+
+	t.lock.Lock()
+	t.result[hcf.Address] = result
+	t.lock.Unlock()
+	return nil
+}
+
+func checkLocalCerts(conf *api.ClusterConfig) []*api.CertExpiration {
+	var paths []string
+	err := filepath.Walk(api.GetCertificateStorePath(conf.Name), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".crt") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Warnf("walk certificates of eggo failed: %v", err)
+	}
+	paths = append(paths, filepath.Join(api.GetClusterHomePath(conf.Name), constants.KubeConfigFileNameAdmin))
+
+	var result []*api.CertExpiration
+	for _, path := range paths {
+		if ce := certExpiration("", path, ioutil.ReadFile); ce != nil {
+			result = append(result, ce)
+		}
+	}
+	return result
+}
+
+// CheckCertsExpiration return expiration of certificates and kubeconfigs in eggo and on all nodes
+func CheckCertsExpiration(conf *api.ClusterConfig) ([]*api.CertExpiration, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("empty cluster config")
+	}
+
+	result := checkLocalCerts(conf)
+
+	t := &checkCertsTask{
+		ccfg:   conf,
+		result: make(map[string][]*api.CertExpiration),
+	}
+	nodes := utils.GetAllIPs(conf.Nodes)
+	// ignore error, unreachable node will be reported in message
+	if err := nodemanager.RunTaskOnNodes(task.NewTaskIgnoreErrInstance(t), nodes); err != nil {
+		return nil, fmt.Errorf("run check certificates task failed: %v", err)
+	}
+	if err := nodemanager.WaitNodesFinish(nodes, checkCertsTimeout); err != nil {
+		logrus.Warnf("wait check certificates task failed: %v", err)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, n := range utils.RemoveDupString(nodes) {
+		ces, ok := t.result[n]
+		if !ok {
+			result = append(result, &api.CertExpiration{Node: n, Message: "check certificates failed"})
+			continue
+		}
+		result = append(result, ces...)
+	}
+	return result, nil
+}
@@ -0,0 +1,327 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: renew leaf certificates of cluster with existed ca
+ ******************************************************************************/
+
+package clustercerts
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/bootstrap"
+	"isula.org/eggo/pkg/clusterdeployment/binary/controlplane"
+	"isula.org/eggo/pkg/clusterdeployment/binary/etcdcluster"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/certs"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	TargetAll = "all"
+
+	apiserverEtcdClientName = "apiserver-etcd-client"
+
+	apiserverReadyRetry    = 30
+	apiserverRetryInterval = 2 * time.Second
+)
+
+type renewTarget struct {
+	// role of nodes which own the certificate
+	role uint16
+	// services need restart to load new certificate
+	services []string
+	renew    func(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error
+}
+
+func masterCert(name string) func(runner.Runner, *api.ClusterConfig, *api.HostConfig) error {
+	return func(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+		return controlplane.RenewCert(r, ccfg, hcf, name)
+	}
+}
+
+func masterKubeConfig(name string) func(runner.Runner, *api.ClusterConfig, *api.HostConfig) error {
+	return func(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+		return controlplane.RenewKubeConfig(r, ccfg, name)
+	}
+}
+
+func etcdCert(name string) func(runner.Runner, *api.ClusterConfig, *api.HostConfig) error {
+	return func(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+		return etcdcluster.RenewCert(r, ccfg, hcf, name)
+	}
+}
+
+// apiserver-etcd-client is issued in eggo, just copy it to master
+func copyApiserverEtcdClientCert(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+	for _, name := range []string{certs.GetCertName(apiserverEtcdClientName), certs.GetKeyName(apiserverEtcdClientName)} {
+		src := filepath.Join(api.GetCertificateStorePath(ccfg.Name), name)
+		if err := r.Copy(src, filepath.Join(ccfg.GetCertDir(), name)); err != nil {
+			return fmt.Errorf("copy %s to %s failed: %v", name, hcf.Address, err)
+		}
+	}
+	return nil
+}
+
+var renewTargets = map[string]*renewTarget{
+	controlplane.APIServerCertName: {
+		role:     api.Master,
+		services: []string{"kube-apiserver"},
+		renew:    masterCert(controlplane.APIServerCertName),
+	},
+	controlplane.APIServerKubeletName: {
+		role:     api.Master,
+		services: []string{"kube-apiserver"},
+		renew:    masterCert(controlplane.APIServerKubeletName),
+	},
+	controlplane.FrontProxyClientName: {
+		role:     api.Master,
+		services: []string{"kube-apiserver"},
+		renew:    masterCert(controlplane.FrontProxyClientName),
+	},
+	apiserverEtcdClientName: {
+		role:     api.Master,
+		services: []string{"kube-apiserver"},
+		renew:    copyApiserverEtcdClientCert,
+	},
+	constants.KubeConfigFileNameAdmin: {
+		role:  api.Master,
+		renew: masterKubeConfig(controlplane.AdminKubeConfigName),
+	},
+	constants.KubeConfigFileNameController: {
+		role:     api.Master,
+		services: []string{"kube-controller-manager"},
+		renew:    masterKubeConfig(controlplane.ControllerManagerKubeConfigName),
+	},
+	constants.KubeConfigFileNameScheduler: {
+		role:     api.Master,
+		services: []string{"kube-scheduler"},
+		renew:    masterKubeConfig(controlplane.SchedulerKubeConfigName),
+	},
+	"etcd-server": {
+		role:     api.ETCD,
+		services: []string{"etcd"},
+		renew:    etcdCert("server"),
+	},
+	"etcd-peer": {
+		role:     api.ETCD,
+		services: []string{"etcd"},
+		renew:    etcdCert("peer"),
+	},
+	"etcd-healthcheck-client": {
+		role:  api.ETCD,
+		renew: etcdCert("healthcheck-client"),
+	},
+	bootstrap.KubeConfigFileNameKubeProxy: {
+		role:     api.Worker,
+		services: []string{"kube-proxy"},
+		renew:    bootstrap.RenewKubeProxyKubeConfig,
+	},
+}
+
+// order of services to restart on one node
+var restartOrder = []string{"etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler", "kube-proxy"}
+
+// RenewTargets return all certificates support to renew
+func RenewTargets() []string {
+	var names []string
+	for name := range renewTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isEtcdTarget(name string) bool {
+	return renewTargets[name].role == api.ETCD
+}
+
+// expandTargets check targets and replace 'all' with all supported certificates
+func expandTargets(conf *api.ClusterConfig, targets []string) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no certificate to renew")
+	}
+
+	var result []string
+	for _, t := range targets {
+		if t == TargetAll {
+			for _, name := range RenewTargets() {
+				// etcd of external is not managed by eggo
+				if conf.EtcdCluster.External && isEtcdTarget(name) {
+					continue
+				}
+				result = append(result, name)
+			}
+			continue
+		}
+		if _, ok := renewTargets[t]; !ok {
+			return nil, fmt.Errorf("unsupported certificate: %s, support: %s, %s", t, TargetAll,
+				strings.Join(RenewTargets(), ", "))
+		}
+		if conf.EtcdCluster.External && isEtcdTarget(t) {
+			return nil, fmt.Errorf("certificate %s of external etcd is not managed by eggo", t)
+		}
+		result = append(result, t)
+	}
+	return utils.RemoveDupString(result), nil
+}
+
+type renewCertsTask struct {
+	ccfg    *api.ClusterConfig
+	targets []string
+}
+
+func (t *renewCertsTask) Name() string {
+	return "renewCertsTask"
+}
+
+func waitAPIServerReady(ctx context.Context, r runner.Runner, ccfg *api.ClusterConfig) error {
+	cmd := fmt.Sprintf("KUBECONFIG=%s kubectl --server=%s get --raw=/readyz",
+		filepath.Join(ccfg.GetConfigDir(), constants.KubeConfigFileNameAdmin), controlplane.LocalEndpoint)
+
+	var err error
+	var output string
+	for i := 0; i < apiserverReadyRetry; i++ {
+		if output, err = r.RunCommand(utils.AddSudo(cmd)); err == nil {
+			return nil
+		}
+		if serr := task.Sleep(ctx, apiserverRetryInterval); serr != nil {
+			return serr
+		}
+	}
+	return fmt.Errorf("wait kube-apiserver ready failed: %v\noutput: %v", err, output)
+}
+
+func (t *renewCertsTask) restartServices(ctx context.Context, r runner.Runner, hcf *api.HostConfig, services map[string]bool) error {
+	for _, s := range restartOrder {
+		if !services[s] {
+			continue
+		}
+		if output, err := r.RunCommand(utils.AddSudo("systemctl restart " + s)); err != nil {
+			return fmt.Errorf("restart %s on %s failed: %v\noutput: %v", s, hcf.Address, err, output)
+		}
+
+		switch s {
+		case "etcd":
+			if err := etcdcluster.WaitEtcdHealthy(ctx, r, t.ccfg, hcf); err != nil {
+				return err
+			}
+		case "kube-apiserver":
+			if err := waitAPIServerReady(ctx, r, t.ccfg); err != nil {
+				return err
+			}
+		}
+		logrus.Infof("restart %s on %s success", s, hcf.Address)
+	}
+	return nil
+}
+
+func (t *renewCertsTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	services := make(map[string]bool)
+	for _, name := range t.targets {
+		target := renewTargets[name]
+		if !utils.IsType(hcf.Type, target.role) {
+			continue
+		}
+		if err := target.renew(r, t.ccfg, hcf); err != nil {
+			return fmt.Errorf("renew %s on %s failed: %v", name, hcf.Address, err)
+		}
+		logrus.Infof("renew %s on %s success", name, hcf.Address)
+		for _, s := range target.services {
+			services[s] = true
+		}
+	}
+
+	return t.restartServices(ctx, r, hcf, services)
+}
+
+func hasTarget(targets []string, name string) bool {
+	for _, t := range targets {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func needRenew(hcf *api.HostConfig, targets []string) bool {
+	for _, name := range targets {
+		if utils.IsType(hcf.Type, renewTargets[name].role) {
+			return true
+		}
+	}
+	return false
+}
+
+// renew etcd members first, then masters and workers, so that cluster keep working
+func getRollingNodes(conf *api.ClusterConfig, targets []string) []*api.HostConfig {
+	var nodes []*api.HostConfig
+	added := make(map[string]bool)
+	for _, role := range []uint16{api.ETCD, api.Master, api.Worker} {
+		for _, n := range conf.Nodes {
+			if added[n.Address] || !utils.IsType(n.Type, role) || !needRenew(n, targets) {
+				continue
+			}
+			added[n.Address] = true
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// RenewCerts re-issue certificates with existed ca node by node, and restart services on node to use them
+func RenewCerts(conf *api.ClusterConfig, targets []string) error {
+	if conf == nil {
+		return fmt.Errorf("empty cluster config")
+	}
+
+	targets, err := expandTargets(conf, targets)
+	if err != nil {
+		return err
+	}
+
+	if hasTarget(targets, apiserverEtcdClientName) {
+		if err := etcdcluster.RenewApiserverEtcdClientCert(conf); err != nil {
+			return fmt.Errorf("renew %s failed: %v", apiserverEtcdClientName, err)
+		}
+	}
+
+	for _, n := range getRollingNodes(conf, targets) {
+		t := task.NewTaskInstance(&renewCertsTask{ccfg: conf, targets: targets})
+		if err := nodemanager.RunTaskOnNodes(t, []string{n.Address}); err != nil {
+			return fmt.Errorf("run renew certificates task on %s failed: %v", n.Address, err)
+		}
+		// stop at the first failed node, other nodes keep working with old certificates
+		if err := nodemanager.WaitNodesFinish([]string{n.Address}, time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+			return fmt.Errorf("renew certificates on %s failed: %v", n.Address, err)
+		}
+	}
+
+	if hasTarget(targets, constants.KubeConfigFileNameAdmin) {
+		if err := controlplane.RenewEggoAdminKubeConfig(conf); err != nil {
+			return fmt.Errorf("renew admin kubeconfig of eggo failed: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: renew certificates testcase
+ ******************************************************************************/
+
+package clustercerts
+
+import (
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestExpandTargets(t *testing.T) {
+	conf := &api.ClusterConfig{}
+	all, err := expandTargets(conf, []string{TargetAll, "apiserver"})
+	if err != nil {
+		t.Fatalf("expand all failed: %v", err)
+	}
+	if len(all) != len(renewTargets) {
+		t.Fatalf("expect %d targets, get %v", len(renewTargets), all)
+	}
+
+	if _, err = expandTargets(conf, []string{"unknown"}); err == nil {
+		t.Fatalf("expect error for unsupported certificate")
+	}
+
+	conf.EtcdCluster.External = true
+	if _, err = expandTargets(conf, []string{"etcd-server"}); err == nil {
+		t.Fatalf("expect error for certificate of external etcd")
+	}
+	all, err = expandTargets(conf, []string{TargetAll})
+	if err != nil {
+		t.Fatalf("expand all failed: %v", err)
+	}
+	for _, name := range all {
+		if isEtcdTarget(name) {
+			t.Fatalf("etcd certificate %s should be skipped for external etcd", name)
+		}
+	}
+}
+
+func TestGetRollingNodes(t *testing.T) {
+	conf := &api.ClusterConfig{
+		Nodes: []*api.HostConfig{
+			{Name: "worker0", Address: "192.168.0.4", Type: api.Worker},
+			{Name: "master0", Address: "192.168.0.2", Type: api.Master},
+			{Name: "etcd0", Address: "192.168.0.3", Type: api.ETCD | api.Master},
+		},
+	}
+
+	nodes := getRollingNodes(conf, []string{"etcd-server", "kube-proxy.conf"})
+	var got []string
+	for _, n := range nodes {
+		got = append(got, n.Name)
+	}
+	// master0 own no certificate to renew
+	if len(got) != 2 || got[0] != "etcd0" || got[1] != "worker0" {
+		t.Fatalf("unexpect rolling nodes: %v", got)
+	}
+
+	// etcd members always go first
+	nodes = getRollingNodes(conf, []string{"apiserver"})
+	if len(nodes) != 2 || nodes[0].Name != "etcd0" || nodes[1].Name != "master0" {
+		t.Fatalf("unexpect rolling nodes for apiserver")
+	}
+}
@@ -0,0 +1,287 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: probe health of cluster
+ ******************************************************************************/
+
+package clusterstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/cleanupcluster"
+	"isula.org/eggo/pkg/clusterdeployment/binary/coredns"
+	"isula.org/eggo/pkg/clusterdeployment/binary/etcdcluster"
+	"isula.org/eggo/pkg/clusterdeployment/runtime"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/kubectl"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	ComponentAPIServer = "kube-apiserver"
+	ComponentEtcd      = "etcd"
+	ComponentCoredns   = "coredns"
+	ComponentNodeReady = "node-ready"
+
+	serviceActive = "active"
+
+	checkServicesTimeout = time.Minute
+)
+
+type checkServicesTask struct {
+	services map[string][]string
+
+	lock   sync.Mutex
+	result map[string]map[string]string
+}
+
+func (t *checkServicesTask) Name() string {
+	return "checkServicesTask"
+}
+
+func (t *checkServicesTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	services := t.services[hcf.Address]
+	if len(services) == 0 {
+		return nil
+	}
+
+	// is-active print state of each unit by order, and exit with error if any unit is inactive
+	output, err := r.RunCommand(utils.AddSudo(fmt.Sprintf("systemctl is-active %s; true", strings.Join(services, " "))))
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]string, len(services))
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i, s := range services {
+		states[s] = "unknown"
+		if i < len(lines) {
+			states[s] = strings.TrimSpace(lines[i])
+		}
+	}
+
+	t.lock.Lock()
+	t.result[hcf.Address] = states
+	t.lock.Unlock()
+	return nil
+}
+
+func getNodeServices(conf *api.ClusterConfig, n *api.HostConfig) []string {
+	var services []string
+	if utils.IsType(n.Type, api.Master) {
+		services = append(services, cleanupcluster.MasterService...)
+		if coredns.IsTypeBinary(conf.ServiceCluster.DNS.CorednsType) {
+			services = append(services, ComponentCoredns)
+		}
+	}
+	if utils.IsType(n.Type, api.Worker) {
+		services = append(services, cleanupcluster.WorkerService...)
+		if rt := runtime.GetRuntime(conf.WorkerConfig.ContainerEngineConf.Runtime); rt != nil {
+			services = append(services, rt.GetRuntimeService())
+		}
+	}
+	if utils.IsType(n.Type, api.ETCD) && !conf.EtcdCluster.External {
+		services = append(services, ComponentEtcd)
+	}
+	if utils.IsType(n.Type, api.LoadBalance) {
+		services = append(services, "nginx")
+	}
+	return utils.RemoveDupString(services)
+}
+
+func checkNodesServices(conf *api.ClusterConfig, unreachable map[string]error) map[string]map[string]string {
+	t := &checkServicesTask{
+		services: make(map[string][]string),
+		result:   make(map[string]map[string]string),
+	}
+	var nodes []string
+	for _, n := range conf.Nodes {
+		if _, ok := unreachable[n.Address]; ok {
+			continue
+		}
+		t.services[n.Address] = getNodeServices(conf, n)
+		nodes = append(nodes, n.Address)
+	}
+
+	// ignore error, unreachable node will be reported as unknown
+	if err := nodemanager.RunTaskOnNodes(task.NewTaskIgnoreErrInstance(t), nodes); err != nil {
+		logrus.Warnf("run check services task failed: %v", err)
+		return t.result
+	}
+	if err := nodemanager.WaitNodesFinish(nodes, checkServicesTimeout); err != nil {
+		logrus.Warnf("wait check services task failed: %v", err)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.result
+}
+
+func newComponent(name string, err error) *api.ComponentStatus {
+	cs := &api.ComponentStatus{
+		Name:    name,
+		Healthy: err == nil,
+	}
+	if err != nil {
+		cs.Message = err.Error()
+	}
+	return cs
+}
+
+func checkCoredns(conf *api.ClusterConfig, services map[string]map[string]string) *api.ComponentStatus {
+	if coredns.IsTypePod(conf.ServiceCluster.DNS.CorednsType) {
+		return newComponent(ComponentCoredns, kubectl.CheckDeploymentReady(conf.Name, "kube-system", "coredns"))
+	}
+
+	// binary coredns is working if any master run it
+	for _, n := range conf.Nodes {
+		if !utils.IsType(n.Type, api.Master) {
+			continue
+		}
+		if services[n.Address][ComponentCoredns] == serviceActive {
+			return newComponent(ComponentCoredns, nil)
+		}
+	}
+	return newComponent(ComponentCoredns, fmt.Errorf("no active coredns service found on masters"))
+}
+
+// GetClusterStatus check status of cluster, nodes in unreachable are reported as unhealthy with the errors
+func GetClusterStatus(conf *api.ClusterConfig, unreachable map[string]error) (*api.ClusterStatus, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("empty cluster config")
+	}
+
+	cstatus := &api.ClusterStatus{
+		ControlPlane:  conf.APIEndpoint.GetURL(),
+		StatusOfNodes: make(map[string]bool),
+	}
+
+	services := checkNodesServices(conf, unreachable)
+
+	apiErr := kubectl.CheckAPIServerReady(conf.Name)
+	cstatus.Components = append(cstatus.Components, newComponent(ComponentAPIServer, apiErr))
+	cstatus.Working = apiErr == nil
+
+	var etcdHealth map[string]bool
+	if !conf.EtcdCluster.External {
+		var err error
+		etcdHealth, err = etcdcluster.CheckEtcdHealth(conf)
+		cstatus.Components = append(cstatus.Components, newComponent(ComponentEtcd, err))
+		if err == nil {
+			for addr, ok := range etcdHealth {
+				if !ok {
+					cstatus.Components[len(cstatus.Components)-1] = newComponent(ComponentEtcd,
+						fmt.Errorf("etcd member %s is unhealthy", addr))
+					break
+				}
+			}
+		}
+	}
+
+	var readyErr error
+	var nodesReady map[string]bool
+	if apiErr == nil {
+		nodesReady, readyErr = kubectl.GetNodesReady(conf.Name)
+		cstatus.Components = append(cstatus.Components, checkCoredns(conf, services))
+	} else {
+		readyErr = apiErr
+	}
+
+	for _, n := range conf.Nodes {
+		nh := &api.NodeHealth{
+			Name:    n.Name,
+			Address: n.Address,
+			Roles:   api.GetRoleString(n.Type),
+			Healthy: true,
+		}
+
+		states, ok := services[n.Address]
+		if !ok {
+			nh.Healthy = false
+			nh.Message = "check services failed"
+			if err, found := unreachable[n.Address]; found {
+				nh.Message = fmt.Sprintf("connect to node failed: %v", err)
+			}
+		}
+		for _, s := range getNodeServices(conf, n) {
+			state := "unknown"
+			if ok {
+				state = states[s]
+			}
+			c := &api.ComponentStatus{Name: s, Healthy: state == serviceActive, Message: state}
+			nh.Healthy = nh.Healthy && c.Healthy
+			nh.Components = append(nh.Components, c)
+		}
+
+		if utils.IsType(n.Type, api.ETCD) && !conf.EtcdCluster.External {
+			healthy, found := etcdHealth[n.Address]
+			c := &api.ComponentStatus{Name: "etcd-member", Healthy: found && healthy}
+			if !c.Healthy {
+				c.Message = "etcd member is unhealthy"
+			}
+			nh.Healthy = nh.Healthy && c.Healthy
+			nh.Components = append(nh.Components, c)
+		}
+
+		if utils.IsType(n.Type, api.Worker) {
+			c := &api.ComponentStatus{Name: ComponentNodeReady}
+			if readyErr != nil {
+				c.Message = readyErr.Error()
+			} else if ready, found := nodesReady[n.Name]; !found {
+				c.Message = "node is not registered"
+			} else {
+				c.Healthy = ready
+				if !ready {
+					c.Message = "node is not ready"
+				}
+			}
+			nh.Healthy = nh.Healthy && c.Healthy
+			nh.Components = append(nh.Components, c)
+		}
+
+		cstatus.NodesHealth = append(cstatus.NodesHealth, nh)
+		cstatus.StatusOfNodes[n.Address] = nh.Healthy
+		if nh.Healthy {
+			cstatus.SuccessCnt += 1
+		} else {
+			cstatus.FailureCnt += 1
+		}
+	}
+
+	componentsHealthy := true
+	for _, c := range cstatus.Components {
+		componentsHealthy = componentsHealthy && c.Healthy
+	}
+
+	switch {
+	case !cstatus.Working:
+		cstatus.Message = "cluster is not working"
+	case cstatus.FailureCnt > 0:
+		cstatus.Message = "cluster is working with unhealthy nodes"
+	case !componentsHealthy:
+		cstatus.Message = "cluster is working with unhealthy components"
+	default:
+		cstatus.Message = "cluster is healthy"
+	}
+
+	return cstatus, nil
+}
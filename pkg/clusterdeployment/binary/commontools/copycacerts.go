@@ -15,6 +15,7 @@
 package commontools
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -89,7 +90,7 @@ func getRequireCerts(hostType uint16) []string {
 	return ret
 }
 
-func (ct *CopyCaCertificatesTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *CopyCaCertificatesTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	hostType := hcf.Type | ct.JoinType
 
 	requireCerts := getRequireCerts(hostType)
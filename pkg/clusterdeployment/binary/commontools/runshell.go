@@ -15,6 +15,7 @@
 package commontools
 
 import (
+	"context"
 	"github.com/sirupsen/logrus"
 
 	"isula.org/eggo/pkg/api"
@@ -30,7 +31,7 @@ func (ct *RunShellTask) Name() string {
 	return "RunShellTask"
 }
 
-func (ct *RunShellTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *RunShellTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	out, err := r.RunShell(ct.Shell, ct.ShellName)
 	if err != nil {
 		return err
@@ -16,6 +16,7 @@
 package controlplane
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/base64"
@@ -140,7 +141,7 @@ func (ct *ControlPlaneTask) copyEncryConfig(r runner.Runner) error {
 	return err
 }
 
-func (ct *ControlPlaneTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *ControlPlaneTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	if hcf == nil {
 		return fmt.Errorf("empty cluster config")
 	}
@@ -365,37 +366,67 @@ func prepareCredentials(clusterName string, ccfg *api.ClusterConfig) error {
 	return createAdminKubeConfigForEggo(lcg, caPath, api.GetClusterHomePath(clusterName), ccfg)
 }
 
-func generateKubeConfigs(rootPath, certPath string, cg certs.CertGenerator, ccfg *api.ClusterConfig) (err error) {
-	// create temp certificates and keys for kubeconfigs
-	if err = generateAdminCertificate(certPath, cg); err != nil {
-		return
+func generateKubeConfig(rootPath, certPath string, cg certs.CertGenerator, ccfg *api.ClusterConfig, name string) error {
+	var err error
+	var filename, credName, apiEndpoint string
+	switch name {
+	case AdminKubeConfigName:
+		err = generateAdminCertificate(certPath, cg)
+		filename, credName = constants.KubeConfigFileNameAdmin, "default-admin"
+		if err == nil {
+			apiEndpoint, err = endpoint.GetAPIServerEndpoint(ccfg)
+		}
+	case ControllerManagerKubeConfigName:
+		err = generateControllerManagerCertificate(certPath, cg)
+		filename, credName, apiEndpoint = constants.KubeConfigFileNameController, "default-controller-manager", LocalEndpoint
+	case SchedulerKubeConfigName:
+		err = generateSchedulerCertificate(certPath, cg)
+		filename, credName, apiEndpoint = constants.KubeConfigFileNameScheduler, "default-scheduler", LocalEndpoint
+	default:
+		return fmt.Errorf("unsupported kubeconfig: %s", name)
 	}
-	apiEndpoint, err := endpoint.GetAPIServerEndpoint(ccfg)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = cg.CreateKubeConfig(rootPath, constants.KubeConfigFileNameAdmin, filepath.Join(certPath, "ca.crt"), ccfg.Name, "default-admin",
-		filepath.Join(certPath, "admin.crt"), filepath.Join(certPath, "admin.key"), apiEndpoint)
-	if err != nil {
-		return
-	}
+	return cg.CreateKubeConfig(rootPath, filename, filepath.Join(certPath, "ca.crt"), ccfg.Name, credName,
+		filepath.Join(certPath, certs.GetCertName(name)), filepath.Join(certPath, certs.GetKeyName(name)), apiEndpoint)
+}
 
-	if err = generateControllerManagerCertificate(certPath, cg); err != nil {
-		return
-	}
-	err = cg.CreateKubeConfig(rootPath, constants.KubeConfigFileNameController, filepath.Join(certPath, "ca.crt"), ccfg.Name, "default-controller-manager",
-		filepath.Join(certPath, "controller-manager.crt"), filepath.Join(certPath, "controller-manager.key"), LocalEndpoint)
-	if err != nil {
-		return
+func generateKubeConfigs(rootPath, certPath string, cg certs.CertGenerator, ccfg *api.ClusterConfig) error {
+	// create temp certificates and keys for kubeconfigs
+	for _, name := range []string{AdminKubeConfigName, ControllerManagerKubeConfigName, SchedulerKubeConfigName} {
+		if err := generateKubeConfig(rootPath, certPath, cg, ccfg, name); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if err = generateSchedulerCertificate(certPath, cg); err != nil {
-		return
+// RenewCert re-issue certificate of master by the ca on node
+func RenewCert(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig, name string) error {
+	certPath := ccfg.GetCertDir()
+	cg := certs.NewOpensshBinCertGenerator(r)
+	switch name {
+	case APIServerCertName:
+		return generateApiServerCertificate(certPath, cg, ccfg, hcf)
+	case APIServerKubeletName:
+		return generateApiServerKubeletCertificate(certPath, cg)
+	case FrontProxyClientName:
+		return generateFrontProxyClientCertificate(certPath, cg)
 	}
+	return fmt.Errorf("unsupported certificate: %s", name)
+}
+
+// RenewKubeConfig re-issue client certificate of kubeconfig and render it again
+func RenewKubeConfig(r runner.Runner, ccfg *api.ClusterConfig, name string) error {
+	return generateKubeConfig(ccfg.GetConfigDir(), ccfg.GetCertDir(), certs.NewOpensshBinCertGenerator(r), ccfg, name)
+}
 
-	return cg.CreateKubeConfig(rootPath, constants.KubeConfigFileNameScheduler, filepath.Join(certPath, "ca.crt"), ccfg.Name, "default-scheduler",
-		filepath.Join(certPath, "scheduler.crt"), filepath.Join(certPath, "scheduler.key"), LocalEndpoint)
+// RenewEggoAdminKubeConfig re-render admin kubeconfig used by eggo
+func RenewEggoAdminKubeConfig(ccfg *api.ClusterConfig) error {
+	return createAdminKubeConfigForEggo(certs.NewLocalCertGenerator(), api.GetCertificateStorePath(ccfg.Name),
+		api.GetClusterHomePath(ccfg.Name), ccfg)
 }
 
 func getRandSecret() (string, error) {
@@ -410,7 +441,17 @@ func getRandSecret() (string, error) {
 	return encoded, nil
 }
 
+// generateEncryption keep existed encryption config, because masters which have finished
+// deploying use it to encrypt secrets, and they must be same on all masters when resume
 func generateEncryption(savePath string) error {
+	fname := filepath.Join(savePath, constants.EncryptionConfigName)
+	if _, err := os.Stat(fname); err == nil {
+		logrus.Infof("use existed encryption config: %s", fname)
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
 	const encry = `kind: EncryptionConfig
 apiVersion: v1
 resources:
@@ -435,7 +476,6 @@ resources:
 		return err
 	}
 
-	fname := filepath.Join(savePath, constants.EncryptionConfigName)
 	return ioutil.WriteFile(fname, []byte(encryStr), constants.EncryptionConfigFileMode)
 }
 
@@ -466,6 +506,8 @@ func generateCertsAndKubeConfigs(r runner.Runner, ccfg *api.ClusterConfig, hcf *
 }
 
 func runKubernetesServices(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) error {
+	defer nodemanager.StartTiming(hcf, nodemanager.TimingService)()
+
 	// set up api-server service
 	if err := commontools.SetupMasterServices(r, ccfg, hcf); err != nil {
 		return err
@@ -712,7 +754,7 @@ exit 1
 	return nil
 }
 
-func (ct *PostControlPlaneTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *PostControlPlaneTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	// we should setup some resources for new cluster
 	// 0. wait cluster ready
 	if err := ct.waitClusterReady(r); err != nil {
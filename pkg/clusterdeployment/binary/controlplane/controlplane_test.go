@@ -15,13 +15,18 @@
 package controlplane
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/sirupsen/logrus"
 
 	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
 	"isula.org/eggo/pkg/utils"
 	"isula.org/eggo/pkg/utils/nodemanager"
 	"isula.org/eggo/pkg/utils/runner"
@@ -45,6 +50,10 @@ func (m *MockRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (m *MockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
 func (m *MockRunner) Reconnect() error {
 	logrus.Infof("reconnect")
 	return nil
@@ -125,3 +134,29 @@ func TestInit(t *testing.T) {
 	}
 	t.Logf("do control plane init success")
 }
+
+func TestGenerateEncryption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eggo-encryption-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := generateEncryption(dir); err != nil {
+		t.Fatalf("generate encryption failed: %v", err)
+	}
+	fname := filepath.Join(dir, constants.EncryptionConfigName)
+	first, err := ioutil.ReadFile(fname)
+	if err != nil || !strings.Contains(string(first), "aescbc") {
+		t.Fatalf("invalid encryption config: %s, %v", first, err)
+	}
+
+	// resume must not change key of encryption
+	if err := generateEncryption(dir); err != nil {
+		t.Fatalf("generate encryption again failed: %v", err)
+	}
+	second, err := ioutil.ReadFile(fname)
+	if err != nil || string(second) != string(first) {
+		t.Fatalf("encryption config is changed: %s", second)
+	}
+}
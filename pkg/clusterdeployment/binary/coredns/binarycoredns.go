@@ -15,6 +15,7 @@
 package coredns
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
@@ -128,7 +129,7 @@ func (cs *BinaryCorednsServerSetupTask) Name() string {
 	return "CorednsSetupTask"
 }
 
-func (cs *BinaryCorednsServerSetupTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (cs *BinaryCorednsServerSetupTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	if err := createCoreServerTemplate(cs.Cluster, r); err != nil {
 		return nil
 	}
@@ -251,7 +252,7 @@ func (ct *BinaryCorednsSetupTask) createServiceTemplate(r runner.Runner) error {
 	return nil
 }
 
-func (ct *BinaryCorednsSetupTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *BinaryCorednsSetupTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	if err := ct.createCoreConfigTemplate(r); err != nil {
 		return err
 	}
@@ -309,7 +310,7 @@ type BinaryCorednsCleanupTask struct {
 func (ct *BinaryCorednsCleanupTask) Name() string {
 	return "BinaryCorednsCleanupTask"
 }
-func (ct *BinaryCorednsCleanupTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *BinaryCorednsCleanupTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	cleanTmpl := `
 #!/bin/bash
 export KUBECONFIG={{ .KubeConfig }}
@@ -393,7 +394,7 @@ func (cs *BinaryCorednsServerJoinTask) Name() string {
 	return "BinaryCorednsServerJoinTask"
 }
 
-func (cs *BinaryCorednsServerJoinTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (cs *BinaryCorednsServerJoinTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	return createCoreEndpointTemplate(cs.Cluster, r, cs.NodeIPs)
 }
 
@@ -15,6 +15,7 @@
 package coredns
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
@@ -45,7 +46,7 @@ func (ct *PodCorednsSetupTask) Name() string {
 	return "PodCorednsSetupTask"
 }
 
-func (ct *PodCorednsSetupTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *PodCorednsSetupTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	datastore := make(map[string]interface{})
 	datastore["Replicas"] = defaultCorednsReplicas
 	datastore["ImageVersion"] = defaultCorednsImageVersion
@@ -90,7 +91,7 @@ func (ct *PodCorednsCleanupTask) Name() string {
 	return "PodCorednsCleanupTask"
 }
 
-func (ct *PodCorednsCleanupTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *PodCorednsCleanupTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	datastore := make(map[string]interface{})
 	datastore["Replicas"] = defaultCorednsReplicas
 	datastore["ImageVersion"] = defaultCorednsImageVersion
@@ -0,0 +1,388 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: backup and restore etcd cluster by snapshot
+ ******************************************************************************/
+
+package etcdcluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/commontools"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/kubectl"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	snapshotPrefix     = "snapshot-"
+	snapshotSuffix     = ".db"
+	checksumSuffix     = ".sha256"
+	snapshotTimeFormat = "20060102-150405"
+
+	apiserverReadyTimeout = 3 * time.Minute
+)
+
+var (
+	// stop control plane before restore etcd, and start them by order after restore
+	masterRestoreServices = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+	workerRestoreServices = []string{"kubelet", "kube-proxy"}
+)
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func remoteSHA256(r runner.Runner, path string) (string, error) {
+	output, err := r.RunCommand(utils.AddSudo(fmt.Sprintf("sha256sum %s", path)))
+	if err != nil {
+		return "", fmt.Errorf("get sha256 of %s failed: %v", path, err)
+	}
+	items := strings.Fields(output)
+	if len(items) == 0 {
+		return "", fmt.Errorf("invalid sha256sum output: %s", output)
+	}
+	return items[0], nil
+}
+
+// ListSnapshots return snapshots in backup dir, sorted from oldest to newest
+func ListSnapshots(backupDir string) ([]string, error) {
+	files, err := ioutil.ReadDir(backupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []string
+	for _, f := range files {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), snapshotPrefix) || !strings.HasSuffix(f.Name(), snapshotSuffix) {
+			continue
+		}
+		snapshots = append(snapshots, filepath.Join(backupDir, f.Name()))
+	}
+	// name of snapshot contains timestamp, so sort by name
+	sort.Strings(snapshots)
+	return snapshots, nil
+}
+
+// LatestSnapshot return the newest snapshot in backup dir
+func LatestSnapshot(backupDir string) (string, error) {
+	snapshots, err := ListSnapshots(backupDir)
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) == 0 {
+		return "", fmt.Errorf("no snapshot found in %s", backupDir)
+	}
+	return snapshots[len(snapshots)-1], nil
+}
+
+func pruneSnapshots(backupDir string, retain int) {
+	if retain <= 0 {
+		return
+	}
+	snapshots, err := ListSnapshots(backupDir)
+	if err != nil {
+		logrus.Warnf("list snapshots in %s failed: %v", backupDir, err)
+		return
+	}
+	for i := 0; i < len(snapshots)-retain; i++ {
+		logrus.Infof("remove expired snapshot: %s", snapshots[i])
+		if err := os.Remove(snapshots[i]); err != nil {
+			logrus.Warnf("remove snapshot %s failed: %v", snapshots[i], err)
+		}
+		if err := os.Remove(snapshots[i] + checksumSuffix); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("remove checksum of %s failed: %v", snapshots[i], err)
+		}
+	}
+}
+
+func writeChecksum(snapshot string, sum string) error {
+	// same format with sha256sum, so it can be checked by 'sha256sum -c'
+	content := fmt.Sprintf("%s  %s\n", sum, filepath.Base(snapshot))
+	return ioutil.WriteFile(snapshot+checksumSuffix, []byte(content), 0600)
+}
+
+// VerifySnapshot check snapshot with the checksum saved when backup
+func VerifySnapshot(snapshot string) error {
+	content, err := ioutil.ReadFile(snapshot + checksumSuffix)
+	if err != nil {
+		return fmt.Errorf("read checksum of %s failed: %v", snapshot, err)
+	}
+	items := strings.Fields(string(content))
+	if len(items) == 0 {
+		return fmt.Errorf("invalid checksum file of %s", snapshot)
+	}
+	sum, err := fileSHA256(snapshot)
+	if err != nil {
+		return err
+	}
+	if sum != items[0] {
+		return fmt.Errorf("checksum of %s mismatch, expect %s, got %s", snapshot, items[0], sum)
+	}
+	return nil
+}
+
+type etcdSnapshotTask struct {
+	ccfg     *api.ClusterConfig
+	snapshot string
+	checksum string
+}
+
+func (t *etcdSnapshotTask) Name() string {
+	return "etcdSnapshotTask"
+}
+
+func (t *etcdSnapshotTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
+	remote := filepath.Join("/tmp", filepath.Base(t.snapshot))
+	defer func() {
+		if _, err := r.RunCommand(utils.AddSudo("rm -f " + remote)); err != nil {
+			logrus.Warnf("remove snapshot %s on %s failed: %v", remote, hostConfig.Address, err)
+		}
+	}()
+
+	cmd := fmt.Sprintf("ETCDCTL_API=3 etcdctl %v snapshot save %v", getEtcdCertsOpts(t.ccfg.GetCertDir()), remote)
+	if output, err := r.RunCommand(utils.AddSudo(cmd)); err != nil {
+		return fmt.Errorf("save etcd snapshot on %s failed: %v\noutput: %v", hostConfig.Address, err, output)
+	}
+
+	sum, err := remoteSHA256(r, remote)
+	if err != nil {
+		return err
+	}
+	if err := runner.Fetch(r, remote, t.snapshot); err != nil {
+		return fmt.Errorf("fetch snapshot from %s failed: %v", hostConfig.Address, err)
+	}
+	localSum, err := fileSHA256(t.snapshot)
+	if err != nil {
+		return err
+	}
+	if localSum != sum {
+		return fmt.Errorf("checksum of fetched snapshot mismatch, expect %s, got %s", sum, localSum)
+	}
+
+	t.checksum = sum
+	return nil
+}
+
+// BackupEtcd save snapshot of etcd cluster into backupDir, and only keep the newest retain snapshots
+func BackupEtcd(conf *api.ClusterConfig, backupDir string, retain int) (string, error) {
+	if conf.EtcdCluster.External {
+		return "", fmt.Errorf("external etcd is not managed by eggo, cannot backup")
+	}
+
+	if err := os.MkdirAll(backupDir, constants.EggoDirMode); err != nil {
+		return "", fmt.Errorf("create backup dir %s failed: %v", backupDir, err)
+	}
+
+	firstEtcdNode := getFirstEtcd(conf.Nodes)
+	if firstEtcdNode == "" {
+		return "", fmt.Errorf("no etcd node found")
+	}
+	execNode := getEtcdLeader(conf, firstEtcdNode)
+	if execNode == "" {
+		execNode = firstEtcdNode
+	}
+
+	snapshot := filepath.Join(backupDir, snapshotPrefix+time.Now().Format(snapshotTimeFormat)+snapshotSuffix)
+	t := &etcdSnapshotTask{ccfg: conf, snapshot: snapshot}
+	if err := nodemanager.RunTaskOnNodes(task.NewTaskInstance(t), []string{execNode}); err != nil {
+		return "", fmt.Errorf("run task for save etcd snapshot failed: %v", err)
+	}
+	if err := nodemanager.WaitNodesFinish([]string{execNode}, time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+		os.Remove(snapshot)
+		return "", fmt.Errorf("wait save etcd snapshot task finish failed: %v", err)
+	}
+
+	if err := writeChecksum(snapshot, t.checksum); err != nil {
+		os.Remove(snapshot)
+		return "", fmt.Errorf("write checksum of %s failed: %v", snapshot, err)
+	}
+
+	pruneSnapshots(backupDir, retain)
+	return snapshot, nil
+}
+
+type etcdRestoreTask struct {
+	ccfg     *api.ClusterConfig
+	snapshot string
+	suffix   string
+}
+
+func (t *etcdRestoreTask) Name() string {
+	return "etcdRestoreTask"
+}
+
+func getRestoreInitialCluster(nodes []*api.HostConfig) string {
+	var peers []string
+	for _, node := range nodes {
+		peers = append(peers, node.Name+"=https://"+node.Address+":2380")
+	}
+	return strings.Join(peers, ",")
+}
+
+func (t *etcdRestoreTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
+	dataDir := t.ccfg.EtcdCluster.DataDir
+	if dataDir == "" {
+		dataDir = DefaultEtcdDataDir
+	}
+
+	remote := filepath.Join("/tmp", filepath.Base(t.snapshot))
+	if err := r.Copy(t.snapshot, remote); err != nil {
+		return fmt.Errorf("copy snapshot to %s failed: %v", hostConfig.Address, err)
+	}
+	defer func() {
+		if _, err := r.RunCommand(utils.AddSudo("rm -f " + remote)); err != nil {
+			logrus.Warnf("remove snapshot %s on %s failed: %v", remote, hostConfig.Address, err)
+		}
+	}()
+
+	// keep old data dir, user can recover it by hand if restore failed
+	cmd := fmt.Sprintf("systemctl stop etcd && if [ -d %s ]; then mv %s %s.%s; fi", dataDir, dataDir, dataDir, t.suffix)
+	if output, err := r.RunCommand(utils.AddSudo(cmd)); err != nil {
+		return fmt.Errorf("stop etcd on %s failed: %v\noutput: %v", hostConfig.Address, err, output)
+	}
+
+	cmd = fmt.Sprintf("ETCDCTL_API=3 etcdctl snapshot restore %s --name %s --initial-cluster %s --initial-cluster-token %s --initial-advertise-peer-urls https://%s:2380 --data-dir %s",
+		remote, hostConfig.Name, getRestoreInitialCluster(t.ccfg.EtcdCluster.Nodes), t.ccfg.EtcdCluster.Token,
+		hostConfig.Address, dataDir)
+	if output, err := r.RunCommand(utils.AddSudo(cmd)); err != nil {
+		return fmt.Errorf("restore etcd snapshot on %s failed: %v\noutput: %v", hostConfig.Address, err, output)
+	}
+
+	// config file must use the new token
+	return prepareEtcdConfigs(t.ccfg, r, hostConfig, "", EtcdConfFile, EtcdServiceFile)
+}
+
+func runServicesCommand(nodes []string, action string, services []string) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	shell := fmt.Sprintf("#!/bin/bash\nsystemctl daemon-reload\nsystemctl %s %s\n", action, strings.Join(services, " "))
+	t := task.NewTaskInstance(&commontools.RunShellTask{
+		ShellName: fmt.Sprintf("%s-%s", action, strings.Join(services, "-")),
+		Shell:     shell,
+	})
+	if err := nodemanager.RunTaskOnNodes(t, nodes); err != nil {
+		return fmt.Errorf("run task for %s %v failed: %v", action, services, err)
+	}
+	if err := nodemanager.WaitNodesFinish(nodes, time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+		return fmt.Errorf("wait %s %v finish failed: %v", action, services, err)
+	}
+	return nil
+}
+
+func waitAPIServerReady(cluster string) error {
+	var err error
+	deadline := time.Now().Add(apiserverReadyTimeout)
+	for time.Now().Before(deadline) {
+		if err = kubectl.CheckAPIServerReady(cluster); err == nil {
+			return nil
+		}
+		time.Sleep(3 * time.Second)
+	}
+	return fmt.Errorf("wait kube-apiserver ready failed: %v", err)
+}
+
+// RestoreEtcd restore all etcd members from snapshot, token of etcd cluster in conf must be a new one
+func RestoreEtcd(conf *api.ClusterConfig, snapshot string) error {
+	if conf.EtcdCluster.External {
+		return fmt.Errorf("external etcd is not managed by eggo, cannot restore")
+	}
+	if len(conf.EtcdCluster.Nodes) == 0 {
+		return fmt.Errorf("no etcd node found in config")
+	}
+	if err := VerifySnapshot(snapshot); err != nil {
+		return err
+	}
+
+	var masters, workers []string
+	for _, n := range conf.Nodes {
+		if utils.IsType(n.Type, api.Master) {
+			masters = append(masters, n.Address)
+		}
+		if utils.IsType(n.Type, api.Worker) {
+			workers = append(workers, n.Address)
+		}
+	}
+	etcds := utils.GetAllIPs(conf.EtcdCluster.Nodes)
+
+	logrus.Info("stop control plane services before restore etcd")
+	if err := runServicesCommand(masters, "stop", masterRestoreServices); err != nil {
+		return err
+	}
+
+	t := &etcdRestoreTask{
+		ccfg:     conf,
+		snapshot: snapshot,
+		suffix:   "bak-" + time.Now().Format(snapshotTimeFormat),
+	}
+	if err := nodemanager.RunTaskOnNodes(task.NewTaskInstance(t), etcds); err != nil {
+		return fmt.Errorf("run task for restore etcd failed: %v", err)
+	}
+	if err := nodemanager.WaitNodesFinish(etcds, time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+		return fmt.Errorf("wait restore etcd task finish failed: %v", err)
+	}
+
+	// all members must start at the same time, or the first one will block for quorum
+	logrus.Info("start etcd members")
+	if err := runServicesCommand(etcds, "start", []string{"etcd"}); err != nil {
+		return err
+	}
+	taskPostDeployEtcds := task.NewTaskInstance(&EtcdPostDeployEtcdsTask{ccfg: conf})
+	if err := nodemanager.RunTaskOnNodes(taskPostDeployEtcds, etcds); err != nil {
+		return fmt.Errorf("run task on nodes failed: %v", err)
+	}
+	if err := nodemanager.WaitNodesFinish(etcds, time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+		return fmt.Errorf("wait for etcds healthy failed: %v", err)
+	}
+
+	logrus.Info("start control plane services")
+	if err := runServicesCommand(masters, "start", masterRestoreServices[:1]); err != nil {
+		return err
+	}
+	if err := waitAPIServerReady(conf.Name); err != nil {
+		return err
+	}
+	if err := runServicesCommand(masters, "start", masterRestoreServices[1:]); err != nil {
+		return err
+	}
+
+	// refresh cached state of nodes
+	return runServicesCommand(workers, "restart", workerRestoreServices)
+}
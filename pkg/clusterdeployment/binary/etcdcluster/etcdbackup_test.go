@@ -0,0 +1,73 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: etcd backup testcase
+ ******************************************************************************/
+
+package etcdcluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotsRetainAndVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eggo-backup-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	names := []string{"snapshot-20260101-000000.db", "snapshot-20260102-000000.db", "snapshot-20260103-000000.db"}
+	for _, n := range names {
+		p := filepath.Join(dir, n)
+		if err := ioutil.WriteFile(p, []byte(n), 0600); err != nil {
+			t.Fatalf("write snapshot failed: %v", err)
+		}
+		sum, err := fileSHA256(p)
+		if err != nil {
+			t.Fatalf("get sha256 failed: %v", err)
+		}
+		if err := writeChecksum(p, sum); err != nil {
+			t.Fatalf("write checksum failed: %v", err)
+		}
+	}
+
+	pruneSnapshots(dir, 2)
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatalf("list snapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 || filepath.Base(snapshots[0]) != names[1] {
+		t.Fatalf("expect keep newest 2 snapshots, get: %v", snapshots)
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0]+checksumSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("checksum of pruned snapshot should be removed")
+	}
+
+	latest, err := LatestSnapshot(dir)
+	if err != nil || filepath.Base(latest) != names[2] {
+		t.Fatalf("expect latest snapshot %s, get %s: %v", names[2], latest, err)
+	}
+	if err := VerifySnapshot(latest); err != nil {
+		t.Fatalf("verify snapshot failed: %v", err)
+	}
+
+	if err := ioutil.WriteFile(latest, []byte("broken"), 0600); err != nil {
+		t.Fatalf("write snapshot failed: %v", err)
+	}
+	if err := VerifySnapshot(latest); err == nil {
+		t.Fatalf("expect verify failed for broken snapshot")
+	}
+}
@@ -122,3 +122,24 @@ func generateCaAndApiserverEtcdCerts(ccfg *api.ClusterConfig) error {
 
 	return nil
 }
+
+// RenewCert re-issue certificate of etcd member by the etcd ca on node
+func RenewCert(r runner.Runner, ccfg *api.ClusterConfig, hostConfig *api.HostConfig, name string) error {
+	etcdCertsPath := filepath.Join(ccfg.GetCertDir(), "etcd")
+	cg := certs.NewOpensshBinCertGenerator(r)
+	switch name {
+	case "server":
+		return genEtcdServerCerts(etcdCertsPath, hostConfig.Name, hostConfig.Address, cg, ccfg)
+	case "peer":
+		return genEtcdPeerCerts(etcdCertsPath, hostConfig.Name, hostConfig.Address, cg, ccfg)
+	case "healthcheck-client":
+		return genEtcdHealthcheckClientCerts(etcdCertsPath, hostConfig.Name, cg, ccfg)
+	}
+	return fmt.Errorf("unsupported etcd certificate: %s", name)
+}
+
+// RenewApiserverEtcdClientCert re-issue kube-apiserver-etcd-client certificate in eggo,
+// it should be copied to masters later
+func RenewApiserverEtcdClientCert(ccfg *api.ClusterConfig) error {
+	return genApiserverEtcdClientCerts(api.GetCertificateStorePath(ccfg.Name), certs.NewLocalCertGenerator(), ccfg)
+}
@@ -16,6 +16,7 @@
 package etcdcluster
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"path/filepath"
@@ -90,7 +91,7 @@ func copyCa(ccfg *api.ClusterConfig, r runner.Runner, hostConfig *api.HostConfig
 	return nil
 }
 
-func (t *EtcdDeployEtcdsTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *EtcdDeployEtcdsTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
@@ -116,6 +117,7 @@ func (t *EtcdDeployEtcdsTask) Run(r runner.Runner, hostConfig *api.HostConfig) e
 		return err
 	}
 
+	defer nodemanager.StartTiming(hostConfig, nodemanager.TimingService)()
 	shell, err := commontools.GetSystemdServiceShell("etcd", "", true)
 	if err != nil {
 		logrus.Errorf("get etcd systemd service shell failed: %v", err)
@@ -145,21 +147,28 @@ func healthcheck(r runner.Runner, etcdCertsDir string, ip string) error {
 	return nil
 }
 
-func (t *EtcdPostDeployEtcdsTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *EtcdPostDeployEtcdsTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
 
+	return WaitEtcdHealthy(ctx, r, t.ccfg, hostConfig)
+}
+
+// WaitEtcdHealthy wait etcd member on node become healthy
+func WaitEtcdHealthy(ctx context.Context, r runner.Runner, ccfg *api.ClusterConfig, hostConfig *api.HostConfig) error {
 	var err error
 	retry := 10
 	for retry != 0 {
-		if err = healthcheck(r, getDstEtcdCertsDir(t.ccfg), hostConfig.Address); err == nil {
+		if err = healthcheck(r, getDstEtcdCertsDir(ccfg), hostConfig.Address); err == nil {
 			return nil
 		}
 		retry--
 
 		const etcdRetrySecond = 3
-		time.Sleep(time.Second * etcdRetrySecond)
+		if serr := task.Sleep(ctx, time.Second*etcdRetrySecond); serr != nil {
+			return serr
+		}
 	}
 
 	return fmt.Errorf("etcd %v healthcheck failed: %v", hostConfig.Name, err)
@@ -16,6 +16,7 @@
 package etcdcluster
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -106,6 +107,10 @@ func (m *fakeRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (r *fakeRunner) WithContext(ctx context.Context) runner.Runner {
+	return r
+}
+
 func (r *fakeRunner) Reconnect() error {
 	// nothing to do
 	return nil
@@ -0,0 +1,99 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo etcdcluster health check implement
+ ******************************************************************************/
+
+package etcdcluster
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+type etcdHealthTask struct {
+	ccfg   *api.ClusterConfig
+	health map[string]bool
+}
+
+func (t *etcdHealthTask) Name() string {
+	return "etcdHealthTask"
+}
+
+// output:
+// https://192.168.0.1:2379 is healthy: successfully committed proposal: took = 2.1ms
+// https://192.168.0.2:2379 is unhealthy: failed to commit proposal: context deadline exceeded
+func parseEtcdEndpointHealth(output string) map[string]bool {
+	health := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		items := strings.Fields(line)
+		if len(items) < 3 || items[1] != "is" {
+			continue
+		}
+		u, err := url.Parse(items[0])
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		health[u.Hostname()] = strings.HasPrefix(items[2], "healthy")
+	}
+	return health
+}
+
+func (t *etcdHealthTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
+	// endpoint health exit with error if any member is unhealthy, so ignore exit code
+	cmd := fmt.Sprintf("ETCDCTL_API=3 etcdctl %v endpoint health --endpoints=%v 2>&1; true",
+		getEtcdCertsOpts(t.ccfg.GetCertDir()), api.GetEtcdServers(&t.ccfg.EtcdCluster))
+	output, err := r.RunCommand(utils.AddSudo(cmd))
+	if err != nil {
+		return fmt.Errorf("check etcd endpoint health failed: %v", err)
+	}
+	t.health = parseEtcdEndpointHealth(output)
+	return nil
+}
+
+// CheckEtcdHealth return health of etcd members, key is address of member
+func CheckEtcdHealth(conf *api.ClusterConfig) (map[string]bool, error) {
+	if conf.EtcdCluster.External {
+		return nil, fmt.Errorf("external etcd, ignore check health")
+	}
+
+	var lastErr error
+	for _, n := range conf.EtcdCluster.Nodes {
+		t := &etcdHealthTask{ccfg: conf}
+		// ignore error to keep node usable for other checks
+		if lastErr = nodemanager.RunTaskOnNodes(task.NewTaskIgnoreErrInstance(t), []string{n.Address}); lastErr != nil {
+			continue
+		}
+		if lastErr = nodemanager.WaitNodesFinish([]string{n.Address}, time.Minute); lastErr != nil {
+			continue
+		}
+		if t.health == nil {
+			lastErr = fmt.Errorf("check etcd health on %s failed", n.Address)
+			continue
+		}
+		return t.health, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no etcd node found")
+	}
+	return nil, lastErr
+}
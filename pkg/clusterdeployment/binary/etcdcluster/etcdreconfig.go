@@ -16,6 +16,7 @@
 package etcdcluster
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -65,7 +66,7 @@ func getInitalCluster(output string) (string, error) {
 	return "", fmt.Errorf("error found initial cluster from output: %v", output)
 }
 
-func (t *EtcdEtcdReconfigTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *EtcdEtcdReconfigTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	if hostConfig == nil {
 		return fmt.Errorf("empty host config")
 	}
@@ -87,7 +88,7 @@ func (t *EtcdEtcdReconfigTask) Run(r runner.Runner, hostConfig *api.HostConfig)
 		}
 	}
 	if t.reconfigType == "add" {
-		output, err := addEtcd(r, t.ccfg.GetCertDir(), t.reconfigHost.Name, t.reconfigHost.Address)
+		output, err := addEtcd(ctx, r, t.ccfg.GetCertDir(), t.reconfigHost.Name, t.reconfigHost.Address)
 		if err != nil {
 			return err
 		}
@@ -217,7 +218,7 @@ func removeEtcd(r runner.Runner, certDir string, id string) error {
 	return nil
 }
 
-func addEtcd(r runner.Runner, certDir string, name string, ip string) (string, error) {
+func addEtcd(ctx context.Context, r runner.Runner, certDir string, name string, ip string) (string, error) {
 	cmd := fmt.Sprintf("ETCDCTL_API=3 etcdctl %v member add %v --peer-urls=https://%v:2380",
 		getEtcdCertsOpts(certDir), name, ip)
 	logrus.Debugf("add etcd command: %v", cmd)
@@ -232,13 +233,15 @@ func addEtcd(r runner.Runner, certDir string, name string, ip string) (string, e
 		retry--
 
 		const etcdRetrySecond = 3
-		time.Sleep(time.Second * etcdRetrySecond)
+		if serr := task.Sleep(ctx, time.Second*etcdRetrySecond); serr != nil {
+			return "", serr
+		}
 	}
 	logrus.Errorf("add etcd %v failed: %v\noutput: %v", name, err, output)
 	return "", err
 }
 
-func (t *removeEtcdsTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *removeEtcdsTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	etcds := getEtcdMembers(t.ccfg.GetCertDir(), r)
 	for _, member := range etcds {
 		// do not delete self
@@ -300,7 +303,7 @@ func getNodeIpByName(nodes []*api.HostConfig, name string) string {
 	return ""
 }
 
-func (t *getEtcdLeaderTask) Run(r runner.Runner, hostConfig *api.HostConfig) error {
+func (t *getEtcdLeaderTask) Run(ctx context.Context, r runner.Runner, hostConfig *api.HostConfig) error {
 	etcds := getEtcdMembers(t.ccfg.GetCertDir(), r)
 	for _, member := range etcds {
 		if member.leader {
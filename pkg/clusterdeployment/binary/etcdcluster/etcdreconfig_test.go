@@ -32,3 +32,13 @@ func TestExecRemoveMemberTask(t *testing.T) {
 		t.Fatalf("test exec remove member task failed")
 	}
 }
+
+func TestParseEtcdEndpointHealth(t *testing.T) {
+	output := `https://192.168.0.1:2379 is healthy: successfully committed proposal: took = 2.1ms
+https://192.168.0.2:2379 is unhealthy: failed to commit proposal: context deadline exceeded
+Error: unhealthy cluster`
+	health := parseEtcdEndpointHealth(output)
+	if len(health) != 2 || !health["192.168.0.1"] || health["192.168.0.2"] {
+		t.Fatalf("parse etcd endpoint health failed: %v", health)
+	}
+}
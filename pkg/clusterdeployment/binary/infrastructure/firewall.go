@@ -17,6 +17,9 @@ package infrastructure
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -27,78 +30,610 @@ import (
 	"isula.org/eggo/pkg/utils/runner"
 )
 
-func getPorts(openPorts []*api.OpenPorts) []string {
-	ports := []string{}
+const (
+	// FirewallAuto use the firewall running on node, ports are not opened if none is running
+	FirewallAuto = "auto"
+	// FirewallNone never open ports
+	FirewallNone = "none"
+
+	// comment of rules added by eggo, only these rules are removed
+	firewallComment      = "eggo"
+	defaultFirewalldZone = "public"
+	defaultNftablesTable = "filter"
+	defaultIptablesChain = "INPUT"
+)
+
+// firewall open ports on node, and remove exactly the rules added by itself
+type firewall interface {
+	Name() string
+	// Active check whether the firewall is running on node
+	Active(r runner.Runner) bool
+	OpenPorts(r runner.Runner, rules []*firewallRule) error
+	ClosePorts(r runner.Runner, rules []*firewallRule) error
+}
+
+var (
+	// order to detect firewall, nftables and iptables are used by firewalld and ufw, so detect them at last
+	firewalls = []firewall{&firewalld{}, &ufw{}, &nftables{}, &iptables{}}
+)
+
+// getFirewall return firewall backend by name
+func getFirewall(name string) firewall {
+	for _, f := range firewalls {
+		if f.Name() == strings.ToLower(name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// IsValidFirewall check whether name is auto, none or name of firewall backend
+func IsValidFirewall(name string) bool {
+	switch strings.ToLower(name) {
+	case "", FirewallAuto, FirewallNone:
+		return true
+	}
+	return getFirewall(name) != nil
+}
+
+// detectFirewall return the firewall used on node, nil means ports are not opened
+func detectFirewall(r runner.Runner, name string) (firewall, error) {
+	switch strings.ToLower(name) {
+	case FirewallNone:
+		return nil, nil
+	case "", FirewallAuto:
+		for _, f := range firewalls {
+			if f.Active(r) {
+				return f, nil
+			}
+		}
+		logrus.Warnf("no firewall is running, just ignore")
+		return nil, nil
+	}
+
+	f := getFirewall(name)
+	if f == nil {
+		return nil, fmt.Errorf("unsupport firewall: %s", name)
+	}
+	if !f.Active(r) {
+		return nil, fmt.Errorf("firewall %s is not running", name)
+	}
+	return f, nil
+}
+
+// firewallRule is port opened to source, source is empty if the port is opened to all
+type firewallRule struct {
+	Port     int
+	Protocol string
+	Zone     string
+	Source   string
+}
+
+func (fr *firewallRule) port() string {
+	return strconv.Itoa(fr.Port) + "/" + fr.Protocol
+}
+
+func (fr *firewallRule) isIPv6() bool {
+	ip, _, err := net.ParseCIDR(fr.Source)
+	if err != nil {
+		ip = net.ParseIP(fr.Source)
+	}
+	return ip != nil && ip.To4() == nil
+}
+
+func (fr *firewallRule) zone(def string) string {
+	if fr.Zone == "" {
+		return def
+	}
+	return fr.Zone
+}
+
+// getRules split open ports to rules by sources, duplicate rules are removed
+func getRules(openPorts []*api.OpenPorts) []*firewallRule {
+	var rules []*firewallRule
+	exist := make(map[firewallRule]bool)
+	add := func(rule firewallRule) {
+		if exist[rule] {
+			return
+		}
+		exist[rule] = true
+		rules = append(rules, &rule)
+	}
 
 	for _, p := range openPorts {
-		ports = append(ports, strconv.Itoa(p.Port)+"/"+p.Protocol)
+		if len(p.Sources) == 0 {
+			add(firewallRule{Port: p.Port, Protocol: p.Protocol, Zone: p.Zone})
+			continue
+		}
+		for _, s := range p.Sources {
+			add(firewallRule{Port: p.Port, Protocol: p.Protocol, Zone: p.Zone, Source: s})
+		}
+	}
+
+	return rules
+}
+
+func runFirewallShell(r runner.Runner, lines []string, name string) error {
+	shell := "#!/bin/bash\n" + strings.Join(lines, "\n") + "\nexit 0\n"
+	_, err := r.RunShell(shell, name)
+	return err
+}
+
+// firewalld: ports are in services named by eggo, zones add the services or rich rules of them
+type firewalld struct {
+}
+
+func (f *firewalld) Name() string {
+	return "firewalld"
+}
+
+func (f *firewalld) Active(r runner.Runner) bool {
+	_, err := r.RunCommand(utils.AddSudo("systemctl status firewalld | grep running"))
+	return err == nil
+}
+
+func firewalldService(rule *firewallRule) string {
+	return fmt.Sprintf("%s-%d-%s", firewallComment, rule.Port, rule.Protocol)
+}
+
+func firewalldZoneArgs(rule *firewallRule, op string) string {
+	zone := rule.zone(defaultFirewalldZone)
+	if rule.Source == "" {
+		return fmt.Sprintf("--permanent --zone=%s --%s-service=%s", zone, op, firewalldService(rule))
 	}
+	family := "ipv4"
+	if rule.isIPv6() {
+		family = "ipv6"
+	}
+	return fmt.Sprintf("--permanent --zone=%s --%s-rich-rule='rule family=%s source address=%s service name=%s accept'",
+		zone, op, family, rule.Source, firewalldService(rule))
+}
+
+func (f *firewalld) openPortsShell(rules []*firewallRule) []string {
+	lines := []string{"set -e"}
+	services := make(map[string]bool)
+	for _, rule := range rules {
+		s := firewalldService(rule)
+		if services[s] {
+			continue
+		}
+		services[s] = true
+		lines = append(lines, fmt.Sprintf("if ! firewall-cmd --permanent --info-service=%s >/dev/null 2>&1; then", s),
+			fmt.Sprintf("\tfirewall-cmd --permanent --new-service=%s", s),
+			fmt.Sprintf("\tfirewall-cmd --permanent --service=%s --add-port=%s", s, rule.port()),
+			"fi")
+	}
+	for _, rule := range rules {
+		lines = append(lines, "firewall-cmd "+firewalldZoneArgs(rule, "add"))
+	}
+	return append(lines, "firewall-cmd --reload")
+}
+
+func (f *firewalld) closePortsShell(rules []*firewallRule) []string {
+	var lines []string
+	services := make(map[string]bool)
+	for _, rule := range rules {
+		lines = append(lines, "firewall-cmd "+firewalldZoneArgs(rule, "remove"))
+		services[firewalldService(rule)] = true
+	}
+	var names []string
+	for s := range services {
+		names = append(names, s)
+	}
+	sort.Strings(names)
+	for _, s := range names {
+		lines = append(lines, fmt.Sprintf("firewall-cmd --permanent --delete-service=%s", s))
+	}
+	return append(lines, "firewall-cmd --reload")
+}
 
-	return ports
+func (f *firewalld) OpenPorts(r runner.Runner, rules []*firewallRule) error {
+	return runFirewallShell(r, f.openPortsShell(rules), "firewalldOpenPorts")
 }
 
-func exposePorts(r runner.Runner, ports []string) error {
-	if _, err := r.RunCommand(utils.AddSudo("systemctl status firewalld | grep running")); err != nil {
-		logrus.Warnf("firewall is disable: %v, just ignore", err)
+func (f *firewalld) ClosePorts(r runner.Runner, rules []*firewallRule) error {
+	return runFirewallShell(r, f.closePortsShell(rules), "firewalldClosePorts")
+}
+
+// ufw: rules are commented by eggo, and deleted by numbers of them
+type ufw struct {
+}
+
+func (f *ufw) Name() string {
+	return "ufw"
+}
+
+func (f *ufw) Active(r runner.Runner) bool {
+	_, err := r.RunCommand(utils.AddSudo("ufw status | grep 'Status: active'"))
+	return err == nil
+}
+
+func (f *ufw) OpenPorts(r runner.Runner, rules []*firewallRule) error {
+	lines := []string{"set -e"}
+	for _, rule := range rules {
+		source := rule.Source
+		if source == "" {
+			source = "any"
+		}
+		lines = append(lines, fmt.Sprintf("ufw allow proto %s from %s to any port %d comment '%s'",
+			rule.Protocol, source, rule.Port, firewallComment))
+	}
+	return runFirewallShell(r, lines, "ufwOpenPorts")
+}
+
+// ufwRuleNumbers return numbers of rules added by eggo in output of 'ufw status numbered', from large to small
+func ufwRuleNumbers(status string, rules []*firewallRule) []int {
+	var numbers []int
+	for _, line := range strings.Split(status, "\n") {
+		line = strings.TrimSpace(line)
+		end := strings.Index(line, "]")
+		if !strings.HasPrefix(line, "[") || end < 0 || !strings.HasSuffix(line, "# "+firewallComment) {
+			continue
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(line[1:end]))
+		if err != nil {
+			continue
+		}
+		// such as: 6443/tcp (v6)  ALLOW IN  Anywhere (v6)  # eggo
+		var fields []string
+		for _, f := range strings.Fields(strings.Split(line[end+1:], "#")[0]) {
+			if f != "(v6)" {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		to, from := fields[0], fields[len(fields)-1]
+		for _, rule := range rules {
+			source := rule.Source
+			if source == "" {
+				source = "Anywhere"
+			}
+			if to == rule.port() && from == source {
+				numbers = append(numbers, num)
+				break
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(numbers)))
+	return numbers
+}
+
+func (f *ufw) ClosePorts(r runner.Runner, rules []*firewallRule) error {
+	status, err := r.RunCommand(utils.AddSudo("ufw status numbered"))
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, num := range ufwRuleNumbers(status, rules) {
+		lines = append(lines, fmt.Sprintf("ufw --force delete %d", num))
+	}
+	if len(lines) == 0 {
 		return nil
 	}
+	return runFirewallShell(r, lines, "ufwClosePorts")
+}
+
+// nftables: rules are commented by eggo and inserted into input chain of inet table, deleted by handles of them
+type nftables struct {
+}
+
+func (f *nftables) Name() string {
+	return "nftables"
+}
+
+func (f *nftables) Active(r runner.Runner) bool {
+	_, err := r.RunCommand(utils.AddSudo(fmt.Sprintf("nft list chain inet %s input", defaultNftablesTable)))
+	return err == nil
+}
+
+// nftablesRule return rule same as output of 'nft list'
+func nftablesRule(rule *firewallRule) string {
+	var sb strings.Builder
+	if rule.Source != "" {
+		if rule.isIPv6() {
+			sb.WriteString(fmt.Sprintf("ip6 saddr %s ", rule.Source))
+		} else {
+			sb.WriteString(fmt.Sprintf("ip saddr %s ", rule.Source))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%s dport %d accept comment \"%s\"", rule.Protocol, rule.Port, firewallComment))
+	return sb.String()
+}
 
+// nftablesHandles return handles of rules in output of 'nft -a list chain'
+func nftablesHandles(chain string) map[string][]string {
+	handles := make(map[string][]string)
+	for _, line := range strings.Split(chain, "\n") {
+		parts := strings.Split(line, " # handle ")
+		if len(parts) != 2 {
+			continue
+		}
+		rule := strings.TrimSpace(parts[0])
+		handles[rule] = append(handles[rule], strings.TrimSpace(parts[1]))
+	}
+	return handles
+}
+
+// persistLinesShell add lines to the first existed file of confs, lines are added before the first line
+// matched by pattern of awk, or appended if pattern is empty
+func persistLinesShell(confs []string, lines []string, pattern string) string {
 	var sb strings.Builder
-	sb.WriteString("sudo -E /bin/sh -c \"")
+	sb.WriteString(fmt.Sprintf("for conf in %s; do\n", strings.Join(confs, " ")))
+	sb.WriteString("\tif [ ! -f $conf ]; then\n\t\tcontinue\n\tfi\n")
+	for _, l := range lines {
+		if pattern == "" {
+			sb.WriteString(fmt.Sprintf("\tgrep -qxF -- '%s' $conf || echo '%s' >> $conf\n", l, l))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\tif ! grep -qxF -- '%s' $conf; then\n", l))
+		sb.WriteString(fmt.Sprintf("\t\tawk -v line='%s' '%s && !done { print line; done = 1 } { print }' $conf > $conf.eggo\n", l, pattern))
+		// keep mode and owner of conf
+		sb.WriteString("\t\tcat $conf.eggo > $conf && rm -f $conf.eggo\n")
+		sb.WriteString("\tfi\n")
+	}
+	sb.WriteString("\tbreak\ndone")
+	return sb.String()
+}
 
-	rPorts := utils.RemoveDupString(ports)
-	for _, p := range rPorts {
-		sb.WriteString(fmt.Sprintf("firewall-cmd --zone=public --add-port=%s && ", p))
+// removeLinesShell remove lines from confs, other lines are not changed
+func removeLinesShell(confs []string, lines []string) string {
+	var args []string
+	for _, l := range lines {
+		args = append(args, fmt.Sprintf("-e '%s'", l))
 	}
+	return fmt.Sprintf(`for conf in %s; do
+	if [ -f $conf ]; then
+		grep -vxF %s $conf > $conf.eggo || true
+		cat $conf.eggo > $conf && rm -f $conf.eggo
+	fi
+done`, strings.Join(confs, " "), strings.Join(args, " "))
+}
+
+const (
+	// rules of nftables only live in memory, so rules added by eggo are saved to a drop-in file,
+	// which is included by config of nftables service
+	nftablesDropIn = "/etc/nftables.d/eggo.nft"
+)
 
-	sb.WriteString("firewall-cmd --runtime-to-permanent \"")
-	if _, err := r.RunCommand(sb.String()); err != nil {
+var (
+	nftablesConfs   = []string{"/etc/sysconfig/nftables.conf", "/etc/nftables.conf"}
+	nftablesInclude = fmt.Sprintf("include \"%s\"", nftablesDropIn)
+)
+
+// nftablesDropInLines return lines of rule in drop-in file, table and chain are declared in case they are removed
+func nftablesDropInLines(rule *firewallRule) []string {
+	table := rule.zone(defaultNftablesTable)
+	return []string{
+		fmt.Sprintf("add table inet %s", table),
+		fmt.Sprintf("add chain inet %s input", table),
+		fmt.Sprintf("insert rule inet %s input %s", table, nftablesRule(rule)),
+	}
+}
+
+func nftablesSaveShell(rules []*firewallRule) []string {
+	var lines []string
+	for _, rule := range rules {
+		lines = append(lines, nftablesDropInLines(rule)...)
+	}
+	return []string{
+		fmt.Sprintf("mkdir -p %s && touch %s", filepath.Dir(nftablesDropIn), nftablesDropIn),
+		persistLinesShell([]string{nftablesDropIn}, lines, ""),
+		persistLinesShell(nftablesConfs, []string{nftablesInclude}, ""),
+	}
+}
+
+// nftablesRemoveShell remove rules from drop-in file, and remove the file if no rule remain
+func nftablesRemoveShell(rules []*firewallRule) []string {
+	var lines []string
+	for _, rule := range rules {
+		// table and chain may be used by other rules
+		lines = append(lines, nftablesDropInLines(rule)[2])
+	}
+	return []string{
+		removeLinesShell([]string{nftablesDropIn}, lines),
+		fmt.Sprintf("if [ -f %s ] && ! grep -q '^insert rule ' %s; then", nftablesDropIn, nftablesDropIn),
+		"\trm -f " + nftablesDropIn,
+		removeLinesShell(nftablesConfs, []string{nftablesInclude}),
+		"fi",
+	}
+}
+
+func (f *nftables) listChains(r runner.Runner, rules []*firewallRule) (map[string]map[string][]string, error) {
+	chains := make(map[string]map[string][]string)
+	for _, rule := range rules {
+		table := rule.zone(defaultNftablesTable)
+		if _, ok := chains[table]; ok {
+			continue
+		}
+		output, err := r.RunCommand(utils.AddSudo(fmt.Sprintf("nft -a list chain inet %s input", table)))
+		if err != nil {
+			return nil, fmt.Errorf("list input chain of table %s failed: %v", table, err)
+		}
+		chains[table] = nftablesHandles(output)
+	}
+	return chains, nil
+}
+
+func (f *nftables) OpenPorts(r runner.Runner, rules []*firewallRule) error {
+	chains, err := f.listChains(r, rules)
+	if err != nil {
 		return err
 	}
+	lines := []string{"set -e"}
+	for _, rule := range rules {
+		table := rule.zone(defaultNftablesTable)
+		if len(chains[table][nftablesRule(rule)]) != 0 {
+			continue
+		}
+		// insert rules before the rules dropping packets
+		lines = append(lines, fmt.Sprintf("nft insert rule inet %s input %s", table, strings.Replace(nftablesRule(rule), "\"", "\\\"", -1)))
+	}
+	lines = append(lines, nftablesSaveShell(rules)...)
+	return runFirewallShell(r, lines, "nftablesOpenPorts")
+}
 
-	return nil
+func (f *nftables) ClosePorts(r runner.Runner, rules []*firewallRule) error {
+	chains, err := f.listChains(r, rules)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, rule := range rules {
+		table := rule.zone(defaultNftablesTable)
+		for _, h := range chains[table][nftablesRule(rule)] {
+			lines = append(lines, fmt.Sprintf("nft delete rule inet %s input handle %s", table, h))
+		}
+	}
+	// rules in drop-in file are removed even if they are not loaded
+	lines = append(lines, nftablesRemoveShell(rules)...)
+	return runFirewallShell(r, lines, "nftablesClosePorts")
 }
 
-func shieldPorts(r runner.Runner, ports []string) {
-	if _, err := r.RunCommand(utils.AddSudo("systemctl status firewalld | grep running")); err != nil {
-		logrus.Warnf("firewall is disable: %v, just ignore", err)
-		return
+// iptables: rules are commented by eggo, and deleted by the same spec
+type iptables struct {
+}
+
+func (f *iptables) Name() string {
+	return "iptables"
+}
+
+func (f *iptables) Active(r runner.Runner) bool {
+	// rules of INPUT chain may be added by kube-proxy or docker, so only check services loading saved rules
+	_, err := r.RunCommand(utils.AddSudo("systemctl is-active iptables || systemctl is-active netfilter-persistent"))
+	return err == nil
+}
+
+// iptablesCommands return commands of iptables and ip6tables with args of rule
+func iptablesCommands(rule *firewallRule, op string) []string {
+	spec := fmt.Sprintf("%s %s -p %s --dport %d", op, rule.zone(defaultIptablesChain), rule.Protocol, rule.Port)
+	if rule.Source != "" {
+		spec += " -s " + rule.Source
 	}
+	spec += fmt.Sprintf(" -m comment --comment %s -j ACCEPT", firewallComment)
 
-	var sb strings.Builder
-	sb.WriteString("sudo -E /bin/sh -c \"")
+	if rule.Source != "" && rule.isIPv6() {
+		return []string{"ip6tables " + spec}
+	}
+	if rule.Source != "" {
+		return []string{"iptables " + spec}
+	}
+	return []string{"iptables " + spec, "ip6tables " + spec}
+}
 
-	rPorts := utils.RemoveDupString(ports)
-	for _, p := range rPorts {
-		sb.WriteString(fmt.Sprintf("firewall-cmd --zone=public --remove-port=%s ; ", p))
+var (
+	// rules of iptables only live in memory, so rules added by eggo are saved to rules loaded by
+	// iptables service or netfilter-persistent, other saved rules are not changed
+	iptablesConfs  = []string{"/etc/sysconfig/iptables", "/etc/iptables/rules.v4"}
+	ip6tablesConfs = []string{"/etc/sysconfig/ip6tables", "/etc/iptables/rules.v6"}
+)
+
+// rules are saved before other rules of filter table, same as inserted
+const iptablesSavePattern = `/^\*/ { table = $0 } table == "*filter" && (/^-A / || /^COMMIT/)`
+
+// iptablesSaveLines return lines of rules in format of iptables-save, split by iptables and ip6tables
+func iptablesSaveLines(rules []*firewallRule) ([]string, []string) {
+	var v4, v6 []string
+	for _, rule := range rules {
+		for _, cmd := range iptablesCommands(rule, "-A") {
+			if strings.HasPrefix(cmd, "ip6tables ") {
+				v6 = append(v6, strings.TrimPrefix(cmd, "ip6tables "))
+				continue
+			}
+			v4 = append(v4, strings.TrimPrefix(cmd, "iptables "))
+		}
 	}
+	return v4, v6
+}
 
-	sb.WriteString("firewall-cmd --runtime-to-permanent \"")
-	if _, err := r.RunCommand(sb.String()); err != nil {
-		logrus.Errorf("shield port failed: %v", err)
+func iptablesSaveShell(rules []*firewallRule) []string {
+	var lines []string
+	v4, v6 := iptablesSaveLines(rules)
+	if len(v4) != 0 {
+		lines = append(lines, persistLinesShell(iptablesConfs, v4, iptablesSavePattern))
 	}
+	if len(v6) != 0 {
+		lines = append(lines, persistLinesShell(ip6tablesConfs, v6, iptablesSavePattern))
+	}
+	return lines
+}
+
+func iptablesRemoveShell(rules []*firewallRule) []string {
+	var lines []string
+	v4, v6 := iptablesSaveLines(rules)
+	if len(v4) != 0 {
+		lines = append(lines, removeLinesShell(iptablesConfs, v4))
+	}
+	if len(v6) != 0 {
+		lines = append(lines, removeLinesShell(ip6tablesConfs, v6))
+	}
+	return lines
+}
+
+func (f *iptables) OpenPorts(r runner.Runner, rules []*firewallRule) error {
+	lines := []string{"set -e"}
+	for _, rule := range rules {
+		checks := iptablesCommands(rule, "-C")
+		for i, insert := range iptablesCommands(rule, "-I") {
+			if strings.HasPrefix(insert, "ip6tables") && len(checks) > 1 {
+				// ipv6 may be disabled, open port to ipv6 only if ip6tables is available
+				lines = append(lines, fmt.Sprintf("if which ip6tables >/dev/null 2>&1; then %s 2>/dev/null || %s; fi", checks[i], insert))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s 2>/dev/null || %s", checks[i], insert))
+		}
+	}
+	lines = append(lines, iptablesSaveShell(rules)...)
+	return runFirewallShell(r, lines, "iptablesOpenPorts")
+}
+
+func (f *iptables) ClosePorts(r runner.Runner, rules []*firewallRule) error {
+	var lines []string
+	for _, rule := range rules {
+		for _, del := range iptablesCommands(rule, "-D") {
+			lines = append(lines, del+" 2>/dev/null")
+		}
+	}
+	lines = append(lines, iptablesRemoveShell(rules)...)
+	return runFirewallShell(r, lines, "iptablesClosePorts")
 }
 
-func addFirewallPort(r runner.Runner, openPorts []*api.OpenPorts) error {
-	ports := getPorts(openPorts)
-	if len(ports) == 0 {
+func addFirewallPort(r runner.Runner, name string, openPorts []*api.OpenPorts) error {
+	rules := getRules(openPorts)
+	if len(rules) == 0 {
 		logrus.Warnf("empty open ports")
 		return nil
 	}
 
-	if err := exposePorts(r, ports); err != nil {
+	f, err := detectFirewall(r, name)
+	if err != nil || f == nil {
 		return err
 	}
+	if err := f.OpenPorts(r, rules); err != nil {
+		return fmt.Errorf("open ports by %s failed: %v", f.Name(), err)
+	}
+	logrus.Infof("open ports by %s success", f.Name())
 
 	return nil
 }
 
-func removeFirewallPort(r runner.Runner, openPorts []*api.OpenPorts) {
-	ports := getPorts(openPorts)
-	if len(ports) == 0 {
+func removeFirewallPort(r runner.Runner, name string, openPorts []*api.OpenPorts) {
+	rules := getRules(openPorts)
+	if len(rules) == 0 {
 		logrus.Warnf("empty open ports")
 		return
 	}
 
-	shieldPorts(r, ports)
+	f, err := detectFirewall(r, name)
+	if err != nil {
+		logrus.Errorf("shield port failed: %v", err)
+		return
+	}
+	if f == nil {
+		return
+	}
+	if err := f.ClosePorts(r, rules); err != nil {
+		logrus.Errorf("shield port by %s failed: %v", f.Name(), err)
+	}
 }
@@ -0,0 +1,273 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo firewall testcase
+ ******************************************************************************/
+
+package infrastructure
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestGetRules(t *testing.T) {
+	rules := getRules([]*api.OpenPorts{
+		{Port: 6443, Protocol: "tcp"},
+		{Port: 6443, Protocol: "tcp"},
+		{Port: 2379, Protocol: "tcp", Zone: "internal", Sources: []string{"10.0.0.0/8", "fd00::/64"}},
+	})
+	if len(rules) != 3 {
+		t.Fatalf("expect 3 rules, get %d", len(rules))
+	}
+	if rules[1].Source != "10.0.0.0/8" || rules[1].isIPv6() || !rules[2].isIPv6() || rules[2].zone("public") != "internal" {
+		t.Fatalf("invalid rules with sources: %v %v", rules[1], rules[2])
+	}
+	if rules[0].zone("public") != "public" || rules[0].port() != "6443/tcp" {
+		t.Fatalf("invalid rule: %v", rules[0])
+	}
+}
+
+func TestFirewalldShell(t *testing.T) {
+	f := &firewalld{}
+	rules := []*firewallRule{
+		{Port: 6443, Protocol: "tcp"},
+		{Port: 6443, Protocol: "tcp", Zone: "internal", Source: "10.0.0.0/8"},
+	}
+	open := strings.Join(f.openPortsShell(rules), "\n")
+	for _, expect := range []string{
+		"firewall-cmd --permanent --new-service=eggo-6443-tcp",
+		"firewall-cmd --permanent --service=eggo-6443-tcp --add-port=6443/tcp",
+		"firewall-cmd --permanent --zone=public --add-service=eggo-6443-tcp",
+		"firewall-cmd --permanent --zone=internal --add-rich-rule='rule family=ipv4 source address=10.0.0.0/8 service name=eggo-6443-tcp accept'",
+		"firewall-cmd --reload",
+	} {
+		if !strings.Contains(open, expect) {
+			t.Fatalf("expect %s in shell: %s", expect, open)
+		}
+	}
+	if strings.Count(open, "--new-service") != 1 {
+		t.Fatalf("service is created more than once: %s", open)
+	}
+
+	closed := strings.Join(f.closePortsShell(rules), "\n")
+	for _, expect := range []string{
+		"firewall-cmd --permanent --zone=public --remove-service=eggo-6443-tcp",
+		"--remove-rich-rule='rule family=ipv4 source address=10.0.0.0/8 service name=eggo-6443-tcp accept'",
+		"firewall-cmd --permanent --delete-service=eggo-6443-tcp",
+	} {
+		if !strings.Contains(closed, expect) {
+			t.Fatalf("expect %s in shell: %s", expect, closed)
+		}
+	}
+	if strings.Contains(closed, "--remove-port") {
+		t.Fatalf("ports not added by eggo may be removed: %s", closed)
+	}
+}
+
+func TestUfwRuleNumbers(t *testing.T) {
+	status := `Status: active
+
+     To                         Action      From
+     --                         ------      ----
+[ 1] 22/tcp                     ALLOW IN    Anywhere
+[ 2] 6443/tcp                   ALLOW IN    Anywhere
+[ 3] 6443/tcp                   ALLOW IN    Anywhere                   # eggo
+[ 4] 2379/tcp                   ALLOW IN    10.0.0.0/8                 # eggo
+[ 5] 2379/tcp                   ALLOW IN    192.168.0.0/16             # eggo
+[ 6] 6443/tcp (v6)              ALLOW IN    Anywhere (v6)              # eggo
+`
+	rules := []*firewallRule{
+		{Port: 6443, Protocol: "tcp"},
+		{Port: 2379, Protocol: "tcp", Source: "10.0.0.0/8"},
+	}
+	numbers := ufwRuleNumbers(status, rules)
+	if !reflect.DeepEqual(numbers, []int{6, 4, 3}) {
+		t.Fatalf("expect rules 6, 4, 3, get: %v", numbers)
+	}
+}
+
+func TestNftablesHandles(t *testing.T) {
+	chain := `table inet filter {
+	chain input { # handle 1
+		type filter hook input priority filter; policy drop;
+		tcp dport 22 accept # handle 4
+		tcp dport 6443 accept comment "eggo" # handle 7
+		ip saddr 10.0.0.0/8 tcp dport 2379 accept comment "eggo" # handle 8
+		ip6 saddr fd00::/64 tcp dport 2379 accept comment "eggo" # handle 9
+	}
+}`
+	handles := nftablesHandles(chain)
+	for rule, expect := range map[*firewallRule]string{
+		{Port: 6443, Protocol: "tcp"}:                       "7",
+		{Port: 2379, Protocol: "tcp", Source: "10.0.0.0/8"}: "8",
+		{Port: 2379, Protocol: "tcp", Source: "fd00::/64"}:  "9",
+	} {
+		h := handles[nftablesRule(rule)]
+		if len(h) != 1 || h[0] != expect {
+			t.Fatalf("expect handle %s of rule %s, get: %v", expect, nftablesRule(rule), h)
+		}
+	}
+	if len(handles[nftablesRule(&firewallRule{Port: 22, Protocol: "tcp"})]) != 0 {
+		t.Fatalf("rule not added by eggo is matched")
+	}
+}
+
+func TestIptablesCommands(t *testing.T) {
+	cmds := iptablesCommands(&firewallRule{Port: 6443, Protocol: "tcp"}, "-I")
+	expect := []string{
+		"iptables -I INPUT -p tcp --dport 6443 -m comment --comment eggo -j ACCEPT",
+		"ip6tables -I INPUT -p tcp --dport 6443 -m comment --comment eggo -j ACCEPT",
+	}
+	if !reflect.DeepEqual(cmds, expect) {
+		t.Fatalf("expect %v, get: %v", expect, cmds)
+	}
+	cmds = iptablesCommands(&firewallRule{Port: 2379, Protocol: "tcp", Zone: "EGGO", Source: "fd00::/64"}, "-D")
+	expect = []string{"ip6tables -D EGGO -p tcp --dport 2379 -s fd00::/64 -m comment --comment eggo -j ACCEPT"}
+	if !reflect.DeepEqual(cmds, expect) {
+		t.Fatalf("expect %v, get: %v", expect, cmds)
+	}
+}
+
+func runTestShell(t *testing.T, lines []string) {
+	shell := "#!/bin/bash\n" + strings.Join(lines, "\n") + "\nexit 0\n"
+	if output, err := exec.Command("/bin/bash", "-c", shell).CombinedOutput(); err != nil {
+		t.Fatalf("run shell failed: %v, output: %s\nshell:\n%s", err, string(output), shell)
+	}
+}
+
+func TestIptablesPersistRules(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-firewall-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	saved := `# saved by admin
+*nat
+:PREROUTING ACCEPT [0:0]
+-A PREROUTING -p tcp --dport 80 -j REDIRECT --to-ports 8080
+COMMIT
+*filter
+:INPUT DROP [0:0]
+-A INPUT -p tcp --dport 22 -j ACCEPT
+COMMIT
+`
+	conf := filepath.Join(tempdir, "iptables")
+	if err = ioutil.WriteFile(conf, []byte(saved), 0600); err != nil {
+		t.Fatalf("write saved rules failed: %v", err)
+	}
+	rules := []*firewallRule{{Port: 6443, Protocol: "tcp"}, {Port: 2379, Protocol: "tcp", Source: "10.0.0.0/8"}}
+	v4, v6 := iptablesSaveLines(rules)
+	if len(v4) != 2 || len(v6) != 1 {
+		t.Fatalf("invalid saved lines: %v, %v", v4, v6)
+	}
+	confs := []string{filepath.Join(tempdir, "not-exist"), conf}
+
+	// save twice, rules are only added once
+	runTestShell(t, []string{persistLinesShell(confs, v4, iptablesSavePattern)})
+	runTestShell(t, []string{persistLinesShell(confs, v4, iptablesSavePattern)})
+	data, err := ioutil.ReadFile(conf)
+	if err != nil {
+		t.Fatalf("read saved rules failed: %v", err)
+	}
+	// each rule is inserted before the first rule, same as iptables -I
+	expect := strings.Replace(saved, ":INPUT DROP [0:0]\n", ":INPUT DROP [0:0]\n"+v4[1]+"\n"+v4[0]+"\n", 1)
+	if string(data) != expect {
+		t.Fatalf("expect saved rules:\n%s\nget:\n%s", expect, string(data))
+	}
+	if info, err := os.Stat(conf); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("mode of saved rules is changed: %v, %v", info, err)
+	}
+
+	// only rules added by eggo are removed
+	runTestShell(t, []string{removeLinesShell(confs, v4)})
+	if data, err = ioutil.ReadFile(conf); err != nil || string(data) != saved {
+		t.Fatalf("expect saved rules:\n%s\nget:\n%s, %v", saved, string(data), err)
+	}
+}
+
+func TestNftablesPersistShell(t *testing.T) {
+	rules := []*firewallRule{{Port: 6443, Protocol: "tcp"}, {Port: 2379, Protocol: "tcp", Zone: "eggo"}}
+	save := strings.Join(nftablesSaveShell(rules), "\n")
+	for _, expect := range []string{
+		"'add table inet filter'",
+		"'add chain inet eggo input'",
+		"'insert rule inet filter input tcp dport 6443 accept comment \"eggo\"'",
+		"'include \"/etc/nftables.d/eggo.nft\"' $conf",
+	} {
+		if !strings.Contains(save, expect) {
+			t.Fatalf("expect %s in shell: %s", expect, save)
+		}
+	}
+	if strings.Contains(save, "nft list ruleset") || strings.Contains(save, "flush ruleset") {
+		t.Fatalf("rules not added by eggo may be saved: %s", save)
+	}
+
+	remove := strings.Join(nftablesRemoveShell(rules), "\n")
+	if strings.Contains(remove, "'add table inet filter'") ||
+		!strings.Contains(remove, "-e 'insert rule inet eggo input tcp dport 2379 accept comment \"eggo\"'") ||
+		!strings.Contains(remove, "rm -f /etc/nftables.d/eggo.nft") {
+		t.Fatalf("invalid shell to remove rules: %s", remove)
+	}
+}
+
+type firewallRunner struct {
+	MockRunner
+	active string
+}
+
+func (m *firewallRunner) RunCommand(cmd string) (string, error) {
+	if strings.Contains(cmd, m.active) {
+		return "", nil
+	}
+	return "", fmt.Errorf("not running")
+}
+
+func TestDetectFirewall(t *testing.T) {
+	r := &firewallRunner{active: "ufw status"}
+	if f, err := detectFirewall(r, ""); err != nil || f == nil || f.Name() != "ufw" {
+		t.Fatalf("expect ufw detected, get: %v, %v", f, err)
+	}
+	if f, err := detectFirewall(r, FirewallNone); err != nil || f != nil {
+		t.Fatalf("expect no firewall, get: %v, %v", f, err)
+	}
+	if _, err := detectFirewall(r, "firewalld"); err == nil {
+		t.Fatalf("expect error when firewalld is not running")
+	}
+
+	// rules of INPUT chain added by kube-proxy or docker are ignored
+	r.active = "iptables -S INPUT"
+	if f, err := detectFirewall(r, FirewallAuto); err != nil || f != nil {
+		t.Fatalf("expect no firewall detected, get: %v, %v", f, err)
+	}
+	r.active = "systemctl is-active iptables"
+	if f, err := detectFirewall(r, FirewallAuto); err != nil || f == nil || f.Name() != "iptables" {
+		t.Fatalf("expect iptables detected, get: %v, %v", f, err)
+	}
+
+	r.active = "not exist"
+	if f, err := detectFirewall(r, FirewallAuto); err != nil || f != nil {
+		t.Fatalf("expect no firewall detected, get: %v, %v", f, err)
+	}
+	if !IsValidFirewall("nftables") || !IsValidFirewall("") || IsValidFirewall("pf") {
+		t.Fatalf("invalid firewall names")
+	}
+}
@@ -16,8 +16,8 @@
 package infrastructure
 
 import (
+	"context"
 	"crypto/md5"
-	"encoding/base64"
 	"fmt"
 	"io"
 	"os"
@@ -46,30 +46,32 @@ var (
 type SetupInfraTask struct {
 	packageSrc *api.PackageSrcConfig
 	roleInfra  *api.RoleInfra
+	firewall   string
+	tuning     *api.NodeTuning
 }
 
 func (it *SetupInfraTask) Name() string {
 	return "SetupInfraTask"
 }
 
-func (it *SetupInfraTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (it *SetupInfraTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	if err := check(r, hcg, it.packageSrc); err != nil {
 		logrus.Errorf("check failed: %v", err)
 		return err
 	}
 
-	if err := setNetBridge(r); err != nil {
-		logrus.Errorf("set net bridge nf call iptables failed: %v", err)
+	if err := setupTuning(r, it.tuning); err != nil {
+		logrus.Errorf("setup node tuning failed: %v", err)
 		return err
 	}
 
-	if err := copyPackage(r, hcg, it.packageSrc); err != nil {
-		logrus.Errorf("prepare package failed: %v", err)
+	if err := it.installPackages(r, hcg); err != nil {
 		return err
 	}
 
-	if err := dependency.InstallBaseDependency(r, it.roleInfra, hcg, it.packageSrc.GetPkgDstPath()); err != nil {
-		logrus.Errorf("install dependency failed: %v", err)
+	// chrony may be installed by packages
+	if err := setupNTP(r, it.tuning); err != nil {
+		logrus.Errorf("setup ntp servers failed: %v", err)
 		return err
 	}
 
@@ -78,7 +80,7 @@ func (it *SetupInfraTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 		return err
 	}
 
-	if err := addFirewallPort(r, it.roleInfra.OpenPorts); err != nil {
+	if err := addFirewallPort(r, it.firewall, it.roleInfra.OpenPorts); err != nil {
 		logrus.Errorf("add firewall port failed: %v", err)
 		return err
 	}
@@ -86,6 +88,23 @@ func (it *SetupInfraTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 	return nil
 }
 
+// installPackages copy packages to node and install them
+func (it *SetupInfraTask) installPackages(r runner.Runner, hcg *api.HostConfig) error {
+	defer nodemanager.StartTiming(hcg, nodemanager.TimingInstall)()
+
+	if err := copyPackage(r, hcg, it.packageSrc); err != nil {
+		logrus.Errorf("prepare package failed: %v", err)
+		return err
+	}
+
+	if err := dependency.InstallBaseDependency(r, it.roleInfra, hcg, it.packageSrc.GetPkgDstPath()); err != nil {
+		logrus.Errorf("install dependency failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
 func check(r runner.Runner, hcg *api.HostConfig, packageSrc *api.PackageSrcConfig) error {
 	if hcg == nil {
 		return fmt.Errorf("empty host config")
@@ -108,53 +127,6 @@ func check(r runner.Runner, hcg *api.HostConfig, packageSrc *api.PackageSrcConfi
 	return nil
 }
 
-func setNetBridge(r runner.Runner) error {
-	const netBridgeNfCallIptablesConf = `net.bridge.bridge-nf-call-ip6tables = 1
-net.bridge.bridge-nf-call-iptables = 1
-net.ipv4.ip_forward = 1
-vm.swappiness=0
-`
-	base64Str := base64.StdEncoding.EncodeToString([]byte(netBridgeNfCallIptablesConf))
-
-	confTmpl := `
-#!/bin/bash
-echo {{ .Config }} | base64 -d > /etc/sysctl.d/k8s.conf
-if [ $? -ne 0 ]; then
-	echo "set sysctl file failed" 1>&2
-	exit 1
-fi
-
-modprobe br_netfilter
-if [ $? -ne 0 ]; then
-	echo "modprobe br_netfilter failed" 1>&2
-	exit 1
-fi
-
-sysctl -p /etc/sysctl.d/k8s.conf
-if [ $? -ne 0 ]; then
-	echo "sysctl -p /etc/sysctl.d/k8s.conf failed" 1>&2
-	exit 1
-fi
-
-exit 0
-`
-
-	datastore := make(map[string]interface{})
-	datastore["Config"] = base64Str
-
-	cmdStr, err := template.TemplateRender(confTmpl, datastore)
-	if err != nil {
-		return err
-	}
-
-	_, err = r.RunShell(cmdStr, "k8s.conf")
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func getPackageSrcPath(arch string, pcfg *api.PackageSrcConfig) string {
 	return pcfg.SrcPath[strings.ToLower(arch)]
 }
@@ -301,7 +273,10 @@ func NodeInfrastructureSetup(config *api.ClusterConfig, nodeID string, role uint
 		&SetupInfraTask{
 			packageSrc: &config.PackageSrc,
 			roleInfra:  roleInfra,
+			firewall:   config.Firewall,
+			tuning:     getNodeTuning(config, nodeID, role),
 		})
+	task.SetTimeout(itask, dependency.InstallTimeout(roleInfra.Softwares))
 
 	if err := nodemanager.RunTaskOnNodes(itask, []string{nodeID}); err != nil {
 		return fmt.Errorf("setup infrastructure Task failed: %v", err)
@@ -310,17 +285,81 @@ func NodeInfrastructureSetup(config *api.ClusterConfig, nodeID string, role uint
 	return nil
 }
 
+type UpgradeInfraTask struct {
+	packageSrc *api.PackageSrcConfig
+	roleInfra  *api.RoleInfra
+}
+
+func (it *UpgradeInfraTask) Name() string {
+	return "UpgradeInfraTask"
+}
+
+func (it *UpgradeInfraTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
+	if err := check(r, hcg, it.packageSrc); err != nil {
+		logrus.Errorf("check failed: %v", err)
+		return err
+	}
+
+	if err := copyPackage(r, hcg, it.packageSrc); err != nil {
+		logrus.Errorf("prepare package failed: %v", err)
+		return err
+	}
+
+	if err := dependency.UpgradeBaseDependency(r, it.roleInfra, hcg, it.packageSrc.GetPkgDstPath()); err != nil {
+		logrus.Errorf("upgrade dependency failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// NodeInfrastructureUpgrade copy new packages to node, and overwrite softwares of roles with them
+func NodeInfrastructureUpgrade(config *api.ClusterConfig, nodeID string, roles uint16) error {
+	if config == nil {
+		return fmt.Errorf("empty cluster config")
+	}
+
+	var infras api.RoleInfra
+	for _, r := range []uint16{api.ETCD, api.Master, api.Worker} {
+		if !utils.IsType(roles, r) {
+			continue
+		}
+		roleInfra := config.RoleInfra[r]
+		if roleInfra == nil {
+			return fmt.Errorf("do not register %d roleinfra", r)
+		}
+		for _, software := range roleInfra.Softwares {
+			deleteSoftwareIfExist(&infras, software)
+		}
+		infras.Softwares = append(infras.Softwares, roleInfra.Softwares...)
+	}
+
+	itask := task.NewTaskInstance(
+		&UpgradeInfraTask{
+			packageSrc: &config.PackageSrc,
+			roleInfra:  &infras,
+		})
+	task.SetTimeout(itask, dependency.InstallTimeout(infras.Softwares))
+
+	if err := nodemanager.RunTaskOnNodes(itask, []string{nodeID}); err != nil {
+		return fmt.Errorf("upgrade infrastructure Task failed: %v", err)
+	}
+
+	return nil
+}
+
 type DestroyInfraTask struct {
 	packageSrc   *api.PackageSrcConfig
 	roleInfra    *api.RoleInfra
 	k8sConfigDir string
+	firewall     string
 }
 
 func (it *DestroyInfraTask) Name() string {
 	return "DestroyInfraTask"
 }
 
-func (it *DestroyInfraTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (it *DestroyInfraTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	if hcg == nil {
 		return fmt.Errorf("empty host config")
 	}
@@ -331,7 +370,14 @@ func (it *DestroyInfraTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 		logrus.Errorf("remove host name ip failed: %v", err)
 	}
 
-	removeFirewallPort(r, it.roleInfra.OpenPorts)
+	removeFirewallPort(r, it.firewall, it.roleInfra.OpenPorts)
+
+	// tuning is shared by all roles of node, only revert it when no role remain
+	if it.roleInfra.Tuning != nil {
+		if err := cleanupTuning(r); err != nil {
+			logrus.Errorf("cleanup node tuning failed: %v", err)
+		}
+	}
 
 	cleanupcluster.PostCleanup(r)
 
@@ -367,6 +413,7 @@ func deleteSoftwareIfExist(infras *api.RoleInfra, delSoftware *api.PackageConfig
 
 func getRoleInfra(ccfg *api.ClusterConfig, ip string, delRoles uint16) *api.RoleInfra {
 	var infras api.RoleInfra
+	var tunings []*api.NodeTuning
 	for _, r := range []uint16{api.Worker, api.Master, api.LoadBalance, api.ETCD} {
 		if utils.IsType(delRoles, r) {
 			roleInfra := ccfg.RoleInfra[r]
@@ -376,6 +423,7 @@ func getRoleInfra(ccfg *api.ClusterConfig, ip string, delRoles uint16) *api.Role
 			}
 			infras.OpenPorts = append(infras.OpenPorts, roleInfra.OpenPorts...)
 			infras.Softwares = append(infras.Softwares, roleInfra.Softwares...)
+			tunings = append(tunings, roleInfra.Tuning)
 		}
 	}
 
@@ -389,6 +437,7 @@ func getRoleInfra(ccfg *api.ClusterConfig, ip string, delRoles uint16) *api.Role
 	remainRoles := allRoles &^ delRoles
 	// if not found, it means no role remain, so delete all
 	if remainRoles == 0 {
+		infras.Tuning = api.MergeNodeTuning(tunings...)
 		return &infras
 	}
 
@@ -425,6 +474,7 @@ func NodeInfrastructureDestroy(config *api.ClusterConfig, hostconfig *api.HostCo
 			packageSrc:   &config.PackageSrc,
 			roleInfra:    roleInfra,
 			k8sConfigDir: config.GetConfigDir(),
+			firewall:     config.Firewall,
 		})
 
 	if err := nodemanager.RunTaskOnNodes(itask, []string{hostconfig.Address}); err != nil {
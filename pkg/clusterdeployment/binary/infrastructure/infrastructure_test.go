@@ -16,6 +16,7 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -24,6 +25,7 @@ import (
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/utils/dependency"
 	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
 )
 
 type MockRunner struct {
@@ -50,6 +52,10 @@ func (m *MockRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (m *MockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
 func (m *MockRunner) Reconnect() error {
 	logrus.Infof("reconnect")
 	return nil
@@ -0,0 +1,375 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: os tuning of node
+ ******************************************************************************/
+
+package infrastructure
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/template"
+)
+
+const (
+	// backup of settings changed by eggo, used to revert them when cleanup
+	tuningBackupDir   = "/var/lib/eggo/tuning"
+	tuningSysctlFile  = "/etc/sysctl.d/90-eggo.conf"
+	tuningModulesFile = "/etc/modules-load.d/eggo.conf"
+	tuningLimitsFile  = "/etc/security/limits.d/90-eggo.conf"
+	tuningSystemdFile = "/etc/systemd/system.conf.d/90-eggo.conf"
+	tuningTHPService  = "/etc/systemd/system/eggo-thp.service"
+	// sysctl file of old version of eggo, settings in it are moved into tuning of roles
+	tuningLegacySysctlFile = "/etc/sysctl.d/k8s.conf"
+	// prefix of lines commented by eggo in fstab and chrony.conf
+	tuningMarker = "#eggo#"
+)
+
+// getNodeTuning merges tunings of all roles of node, because setup runs once for every role
+// and the latter one will overwrite configurations of the former one
+func getNodeTuning(config *api.ClusterConfig, nodeID string, role uint16) *api.NodeTuning {
+	roles := role
+	for _, node := range config.Nodes {
+		if node != nil && node.Address == nodeID {
+			roles |= node.Type
+			break
+		}
+	}
+
+	var tunings []*api.NodeTuning
+	for _, r := range []uint16{api.LoadBalance, api.ETCD, api.Master, api.Worker} {
+		if !utils.IsType(roles, r) || config.RoleInfra[r] == nil {
+			continue
+		}
+		tunings = append(tunings, config.RoleInfra[r].Tuning)
+	}
+
+	return api.MergeNodeTuning(tunings...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func tuningSysctlConfig(tuning *api.NodeTuning) string {
+	var sb strings.Builder
+	for _, k := range sortedKeys(tuning.Sysctl) {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", k, tuning.Sysctl[k]))
+	}
+	return sb.String()
+}
+
+func tuningLimitsConfig(tuning *api.NodeTuning) string {
+	var sb strings.Builder
+	for _, k := range sortedKeys(tuning.Ulimits) {
+		sb.WriteString(fmt.Sprintf("* soft %s %s\n", k, tuning.Ulimits[k]))
+		sb.WriteString(fmt.Sprintf("* hard %s %s\n", k, tuning.Ulimits[k]))
+	}
+	return sb.String()
+}
+
+// tuningSystemdConfig sets limits of services started by systemd, which ignore limits.conf
+func tuningSystemdConfig(tuning *api.NodeTuning) string {
+	var sb strings.Builder
+	sb.WriteString("[Manager]\n")
+	for _, k := range sortedKeys(tuning.Ulimits) {
+		v := tuning.Ulimits[k]
+		if v == "unlimited" {
+			v = "infinity"
+		}
+		sb.WriteString(fmt.Sprintf("DefaultLimit%s=%s\n", strings.ToUpper(k), v))
+	}
+	return sb.String()
+}
+
+func tuningTHPServiceConfig(tuning *api.NodeTuning) string {
+	return fmt.Sprintf(`[Unit]
+Description=Set transparent hugepage by eggo
+After=local-fs.target
+
+[Service]
+Type=oneshot
+ExecStart=/bin/sh -c "echo %s > /sys/kernel/mm/transparent_hugepage/enabled"
+
+[Install]
+WantedBy=multi-user.target
+`, tuning.TransparentHugepage)
+}
+
+func setupTuningShell(tuning *api.NodeTuning) (string, error) {
+	const shell = `
+#!/bin/bash
+set -e
+mkdir -p {{ .BackupDir }}
+{{- if .Modules }}
+
+echo {{ .ModulesConfig }} | base64 -d > {{ .ModulesFile }}
+for m in {{ .Modules }}; do
+	modprobe $m || echo "load kernel module $m failed" 1>&2
+done
+{{- end }}
+{{- if .Sysctl }}
+
+for k in {{ .Sysctl }}; do
+	if ! grep -q "^$k = " {{ .BackupDir }}/sysctl.conf 2>/dev/null; then
+		v=$(sysctl -n $k 2>/dev/null) && echo "$k = $v" >> {{ .BackupDir }}/sysctl.conf
+	fi
+done
+echo {{ .SysctlConfig }} | base64 -d > {{ .SysctlFile }}
+sysctl -p {{ .SysctlFile }}
+{{- end }}
+{{- if .DisableSwap }}
+
+sed -i -E 's@^([^#].*[[:space:]]swap[[:space:]].*)$@{{ .Marker }}\1@' /etc/fstab
+swapoff -a
+{{- end }}
+{{- if .SELinux }}
+
+if [ -f /etc/selinux/config ]; then
+	if [ ! -f {{ .BackupDir }}/selinux ]; then
+		grep "^SELINUX=" /etc/selinux/config > {{ .BackupDir }}/selinux || true
+	fi
+	sed -i "s/^SELINUX=.*/SELINUX={{ .SELinux }}/" /etc/selinux/config
+	if [ "$(getenforce)" == "Disabled" ]; then
+		{{- if eq .SELinux "disabled" }}
+		true
+		{{- else }}
+		echo "reboot is required to set selinux {{ .SELinux }}" 1>&2
+		{{- end }}
+	else
+		setenforce {{ if eq .SELinux "enforcing" }}1{{ else }}0{{ end }}
+	fi
+fi
+{{- end }}
+{{- if .LimitsConfig }}
+
+echo {{ .LimitsConfig }} | base64 -d > {{ .LimitsFile }}
+mkdir -p $(dirname {{ .SystemdFile }})
+echo {{ .SystemdConfig }} | base64 -d > {{ .SystemdFile }}
+systemctl daemon-reexec
+{{- end }}
+{{- if .THP }}
+
+thp=/sys/kernel/mm/transparent_hugepage/enabled
+if [ -f $thp ]; then
+	if [ ! -f {{ .BackupDir }}/thp ]; then
+		sed -E 's/.*\[(.*)\].*/\1/' $thp > {{ .BackupDir }}/thp
+	fi
+	echo {{ .THP }} > $thp
+	echo {{ .THPConfig }} | base64 -d > {{ .THPService }}
+	systemctl daemon-reload
+	systemctl enable $(basename {{ .THPService }})
+fi
+{{- end }}
+
+exit 0
+`
+
+	datastore := map[string]interface{}{
+		"BackupDir":   tuningBackupDir,
+		"Marker":      tuningMarker,
+		"DisableSwap": tuning.DisableSwap,
+		"SELinux":     tuning.SELinux,
+		"THP":         tuning.TransparentHugepage,
+	}
+	if len(tuning.KernelModules) != 0 {
+		datastore["Modules"] = strings.Join(tuning.KernelModules, " ")
+		datastore["ModulesFile"] = tuningModulesFile
+		datastore["ModulesConfig"] = base64.StdEncoding.EncodeToString(
+			[]byte(strings.Join(tuning.KernelModules, "\n") + "\n"))
+	}
+	if len(tuning.Sysctl) != 0 {
+		datastore["Sysctl"] = strings.Join(sortedKeys(tuning.Sysctl), " ")
+		datastore["SysctlFile"] = tuningSysctlFile
+		datastore["SysctlConfig"] = base64.StdEncoding.EncodeToString([]byte(tuningSysctlConfig(tuning)))
+	}
+	if len(tuning.Ulimits) != 0 {
+		datastore["LimitsFile"] = tuningLimitsFile
+		datastore["LimitsConfig"] = base64.StdEncoding.EncodeToString([]byte(tuningLimitsConfig(tuning)))
+		datastore["SystemdFile"] = tuningSystemdFile
+		datastore["SystemdConfig"] = base64.StdEncoding.EncodeToString([]byte(tuningSystemdConfig(tuning)))
+	}
+	if tuning.TransparentHugepage != "" {
+		datastore["THPService"] = tuningTHPService
+		datastore["THPConfig"] = base64.StdEncoding.EncodeToString([]byte(tuningTHPServiceConfig(tuning)))
+	}
+
+	return template.TemplateRender(shell, datastore)
+}
+
+// setupNTPShell configures chrony, which is separated from other tuning because chrony may be
+// installed by packages of roles
+func setupNTPShell(tuning *api.NodeTuning) (string, error) {
+	const shell = `
+#!/bin/bash
+set -e
+conf=/etc/chrony.conf
+if [ -f /etc/chrony/chrony.conf ]; then
+	conf=/etc/chrony/chrony.conf
+fi
+if [ ! -f $conf ]; then
+	echo "chrony is not installed" 1>&2
+	exit 1
+fi
+sed -i '/^# BEGIN eggo/,/^# END eggo/d' $conf
+sed -i -E 's@^((server|pool)[[:space:]].*)$@{{ .Marker }}\1@' $conf
+echo "# BEGIN eggo" >> $conf
+for s in {{ .NTPServers }}; do
+	echo "server $s iburst" >> $conf
+done
+echo "# END eggo" >> $conf
+if systemctl cat chronyd > /dev/null 2>&1; then
+	systemctl enable chronyd && systemctl restart chronyd
+else
+	systemctl enable chrony && systemctl restart chrony
+fi
+
+exit 0
+`
+
+	datastore := map[string]interface{}{
+		"Marker":     tuningMarker,
+		"NTPServers": strings.Join(tuning.NTPServers, " "),
+	}
+
+	return template.TemplateRender(shell, datastore)
+}
+
+// revert does not depend on tuning config, so configurations applied by old version can be reverted too
+func cleanupTuningShell() (string, error) {
+	const shell = `
+#!/bin/bash
+# written by old version of eggo
+rm -f {{ .LegacySysctlFile }}
+
+if [ ! -d {{ .BackupDir }} ]; then
+	exit 0
+fi
+
+rm -f {{ .ModulesFile }}
+
+rm -f {{ .SysctlFile }}
+if [ -f {{ .BackupDir }}/sysctl.conf ]; then
+	sysctl -p {{ .BackupDir }}/sysctl.conf
+fi
+
+if grep -q "^{{ .Marker }}" /etc/fstab; then
+	sed -i 's@^{{ .Marker }}@@' /etc/fstab
+	swapon -a
+fi
+
+if [ -s {{ .BackupDir }}/selinux ] && [ -f /etc/selinux/config ]; then
+	old=$(cat {{ .BackupDir }}/selinux)
+	sed -i "s/^SELINUX=.*/$old/" /etc/selinux/config
+	if [ "$old" == "SELINUX=enforcing" ] && [ "$(getenforce)" == "Permissive" ]; then
+		setenforce 1
+	fi
+fi
+
+if [ -f {{ .LimitsFile }} ] || [ -f {{ .SystemdFile }} ]; then
+	rm -f {{ .LimitsFile }} {{ .SystemdFile }}
+	systemctl daemon-reexec
+fi
+
+if [ -f {{ .THPService }} ]; then
+	systemctl disable $(basename {{ .THPService }})
+	rm -f {{ .THPService }}
+	systemctl daemon-reload
+fi
+if [ -f {{ .BackupDir }}/thp ]; then
+	cat {{ .BackupDir }}/thp > /sys/kernel/mm/transparent_hugepage/enabled
+fi
+
+for conf in /etc/chrony.conf /etc/chrony/chrony.conf; do
+	if [ -f $conf ] && grep -q "^# BEGIN eggo" $conf; then
+		sed -i '/^# BEGIN eggo/,/^# END eggo/d' $conf
+		sed -i 's@^{{ .Marker }}@@' $conf
+		systemctl restart chronyd || systemctl restart chrony
+	fi
+done
+
+rm -rf {{ .BackupDir }}
+exit 0
+`
+
+	datastore := map[string]interface{}{
+		"BackupDir":        tuningBackupDir,
+		"Marker":           tuningMarker,
+		"ModulesFile":      tuningModulesFile,
+		"SysctlFile":       tuningSysctlFile,
+		"LimitsFile":       tuningLimitsFile,
+		"LegacySysctlFile": tuningLegacySysctlFile,
+		"SystemdFile":      tuningSystemdFile,
+		"THPService":       tuningTHPService,
+	}
+
+	return template.TemplateRender(shell, datastore)
+}
+
+func setupTuning(r runner.Runner, tuning *api.NodeTuning) error {
+	if tuning == nil {
+		return nil
+	}
+
+	shell, err := setupTuningShell(tuning)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.RunShell(shell, "setupTuning"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func setupNTP(r runner.Runner, tuning *api.NodeTuning) error {
+	if tuning == nil || len(tuning.NTPServers) == 0 {
+		return nil
+	}
+
+	shell, err := setupNTPShell(tuning)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.RunShell(shell, "setupNTP"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func cleanupTuning(r runner.Runner) error {
+	shell, err := cleanupTuningShell()
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.RunShell(shell, "cleanupTuning"); err != nil {
+		return err
+	}
+
+	return nil
+}
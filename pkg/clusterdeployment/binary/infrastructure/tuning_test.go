@@ -0,0 +1,174 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: node tuning testcase
+ ******************************************************************************/
+
+package infrastructure
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/infra"
+)
+
+func TestGetNodeTuning(t *testing.T) {
+	ccfg := &api.ClusterConfig{
+		Nodes: []*api.HostConfig{
+			{Address: "192.168.0.2", Type: api.Master | api.Worker},
+			{Address: "192.168.0.3", Type: api.ETCD},
+		},
+		RoleInfra: infra.RegisterInfra(),
+	}
+	ccfg.RoleInfra[api.Master].Tuning.Sysctl["net.ipv4.ip_forward"] = "0"
+	ccfg.RoleInfra[api.Master].Tuning.SELinux = "permissive"
+
+	tuning := getNodeTuning(ccfg, "192.168.0.2", api.Master)
+	if tuning == nil || !tuning.DisableSwap || tuning.SELinux != "permissive" {
+		t.Fatalf("tunings of master and worker are not merged: %v", tuning)
+	}
+	// worker is merged after master
+	if tuning.Sysctl["net.ipv4.ip_forward"] != "1" || tuning.Sysctl["vm.overcommit_memory"] != "1" {
+		t.Fatalf("invalid sysctl: %v", tuning.Sysctl)
+	}
+	if !reflect.DeepEqual(tuning.KernelModules, ccfg.RoleInfra[api.Worker].Tuning.KernelModules) {
+		t.Fatalf("invalid kernel modules: %v", tuning.KernelModules)
+	}
+
+	tuning = getNodeTuning(ccfg, "192.168.0.3", api.ETCD)
+	if tuning == nil || tuning.DisableSwap || tuning.Sysctl["net.ipv4.ip_forward"] != "1" ||
+		!reflect.DeepEqual(tuning.KernelModules, []string{"br_netfilter"}) {
+		t.Fatalf("expect base tuning for etcd, get %v", tuning)
+	}
+}
+
+func TestSetupTuningShell(t *testing.T) {
+	tuning := &api.NodeTuning{
+		Sysctl:              map[string]string{"vm.swappiness": "0", "net.ipv4.ip_forward": "1"},
+		KernelModules:       []string{"br_netfilter", "overlay"},
+		DisableSwap:         true,
+		SELinux:             "permissive",
+		Ulimits:             map[string]string{"nofile": "1048576", "memlock": "unlimited"},
+		TransparentHugepage: "never",
+		NTPServers:          []string{"ntp1.example.com", "192.168.0.1"},
+	}
+	shell, err := setupTuningShell(tuning)
+	if err != nil {
+		t.Fatalf("render setup tuning shell failed: %v", err)
+	}
+	for _, expect := range []string{
+		"for m in br_netfilter overlay; do",
+		"for k in net.ipv4.ip_forward vm.swappiness; do",
+		"sysctl -p " + tuningSysctlFile,
+		"s@^([^#].*[[:space:]]swap[[:space:]].*)$@#eggo#\\1@",
+		"SELINUX=permissive",
+		"setenforce 0",
+		"echo never > $thp",
+	} {
+		if !strings.Contains(shell, expect) {
+			t.Fatalf("expect %s in shell: %s", expect, shell)
+		}
+	}
+	// chrony may be installed with packages, so it is configured after packages installed
+	if strings.Contains(shell, "chrony") {
+		t.Fatalf("unexpect chrony in setup tuning shell: %s", shell)
+	}
+	ntp, err := setupNTPShell(tuning)
+	if err != nil {
+		t.Fatalf("render setup ntp shell failed: %v", err)
+	}
+	if !strings.Contains(ntp, "for s in ntp1.example.com 192.168.0.1; do") {
+		t.Fatalf("invalid ntp shell: %s", ntp)
+	}
+	sysctl := base64.StdEncoding.EncodeToString([]byte("net.ipv4.ip_forward = 1\nvm.swappiness = 0\n"))
+	if !strings.Contains(shell, sysctl) {
+		t.Fatalf("invalid sysctl config in shell: %s", shell)
+	}
+
+	if tuningSystemdConfig(tuning) != "[Manager]\nDefaultLimitMEMLOCK=infinity\nDefaultLimitNOFILE=1048576\n" {
+		t.Fatalf("invalid systemd config: %s", tuningSystemdConfig(tuning))
+	}
+	if tuningLimitsConfig(tuning) != "* soft memlock unlimited\n* hard memlock unlimited\n"+
+		"* soft nofile 1048576\n* hard nofile 1048576\n" {
+		t.Fatalf("invalid limits config: %s", tuningLimitsConfig(tuning))
+	}
+
+	shell, err = setupTuningShell(&api.NodeTuning{DisableSwap: true})
+	if err != nil {
+		t.Fatalf("render setup tuning shell failed: %v", err)
+	}
+	for _, unexpect := range []string{"modprobe", "sysctl", "SELINUX", "transparent_hugepage", "chrony"} {
+		if strings.Contains(shell, unexpect) {
+			t.Fatalf("unexpect %s in shell: %s", unexpect, shell)
+		}
+	}
+}
+
+func TestGetRoleInfraTuning(t *testing.T) {
+	ccfg := &api.ClusterConfig{
+		Nodes: []*api.HostConfig{
+			{Address: "192.168.0.2", Type: api.Master | api.Worker},
+		},
+		RoleInfra: infra.RegisterInfra(),
+	}
+
+	if roleInfra := getRoleInfra(ccfg, "192.168.0.2", api.Worker); roleInfra.Tuning != nil {
+		t.Fatalf("tuning should be kept when master remains")
+	}
+	if roleInfra := getRoleInfra(ccfg, "192.168.0.2", api.Master|api.Worker); roleInfra.Tuning == nil {
+		t.Fatalf("tuning should be reverted when no role remains")
+	}
+}
+
+type shellRecordRunner struct {
+	MockRunner
+	shells []string
+}
+
+func (m *shellRecordRunner) RunShell(shell string, name string) (string, error) {
+	m.shells = append(m.shells, name)
+	return "", nil
+}
+
+func TestSetupInfraTaskTuningOrder(t *testing.T) {
+	r := &shellRecordRunner{}
+	it := &SetupInfraTask{
+		packageSrc: &api.PackageSrcConfig{},
+		roleInfra:  &api.RoleInfra{},
+		tuning:     &api.NodeTuning{Sysctl: map[string]string{"vm.swappiness": "0"}, NTPServers: []string{"192.168.0.1"}},
+	}
+	if err := it.Run(context.Background(), r, &api.HostConfig{Name: "node0", Address: "192.168.0.2"}); err != nil {
+		t.Fatalf("run setup infrastructure task failed: %v", err)
+	}
+
+	// sysctl must be saved by setupTuning before any other change of it
+	expect := []string{"setupTuning", "setupNTP", "addHostNameIP"}
+	var got []string
+	for _, name := range r.shells {
+		for _, e := range expect {
+			if name == e {
+				got = append(got, name)
+			}
+		}
+		if name == "k8s.conf" {
+			t.Fatalf("sysctl is set out of tuning")
+		}
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect shells %v, get %v", expect, r.shells)
+	}
+}
@@ -16,6 +16,7 @@
 package loadbalance
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"strings"
@@ -48,7 +49,7 @@ func (it *LoadBalanceTask) Name() string {
 	return "LoadBalanceTask"
 }
 
-func (it *LoadBalanceTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (it *LoadBalanceTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	logrus.Info("prepare loadbalancer...\n")
 
 	// check loadbalancer dependences
@@ -69,6 +70,7 @@ func (it *LoadBalanceTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 	}
 
 	// prepare and start nginx service
+	defer nodemanager.StartTiming(hcg, nodemanager.TimingService)()
 	if err := commontools.SetupLoadBalanceServices(r, path); err != nil {
 		logrus.Errorf("run service failed: %v", err)
 		return err
@@ -197,7 +199,7 @@ func (it *UpdateLoadBalanceTask) Name() string {
 	return "LoadBalanceTask"
 }
 
-func (it *UpdateLoadBalanceTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (it *UpdateLoadBalanceTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	logrus.Info("update loadbalancer...\n")
 
 	// remove nginx config
@@ -230,10 +232,20 @@ func UpdateLoadBalancer(config *api.ClusterConfig, lb *api.HostConfig) error {
 		return fmt.Errorf("no master host found, can not update loadbalance")
 	}
 
+	return UpdateLoadBalancerUpstream(config, lb, masterIPs)
+}
+
+// UpdateLoadBalancerUpstream set masters as upstream of loadbalance,
+// masters not in the list will not receive any request from loadbalance.
+func UpdateLoadBalancerUpstream(config *api.ClusterConfig, lb *api.HostConfig, masters []string) error {
+	if len(masters) == 0 {
+		return fmt.Errorf("empty upstream, can not update loadbalance")
+	}
+
 	taskUpdateLoadBalancer := task.NewTaskInstance(
 		&UpdateLoadBalanceTask{
 			lbConfig: &config.LoadBalancer,
-			masters:  masterIPs,
+			masters:  masters,
 		},
 	)
 
@@ -15,6 +15,7 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -41,7 +42,7 @@ func (ct *ApplyNetworkTask) Name() string {
 	return "ApplyNetworkTask"
 }
 
-func (ct *ApplyNetworkTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *ApplyNetworkTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	return applyNetwork(r, ct.Cluster)
 }
 
@@ -68,7 +69,8 @@ func SetupNetwork(cluster *api.ClusterConfig) error {
 	if cluster == nil {
 		return fmt.Errorf("invalid cluster config")
 	}
-	t := task.NewTaskInstance(&ApplyNetworkTask{Cluster: cluster})
+	// apply again if apiserver is not ready
+	t := task.NewTaskRetryInstance(&ApplyNetworkTask{Cluster: cluster}, task.DefaultRetryPolicy())
 	var masters []string
 	for _, n := range cluster.Nodes {
 		if (n.Type & api.Master) != 0 {
@@ -96,7 +98,7 @@ func (ct *CleanupNetworkTask) Name() string {
 	return "CleanupNetworkTask"
 }
 
-func (ct *CleanupNetworkTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (ct *CleanupNetworkTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	return deleteNetwork(r, ct.Cluster)
 }
 
@@ -0,0 +1,399 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: preflight checks of nodes before deploy cluster or join nodes
+ ******************************************************************************/
+
+package preflight
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/secrets"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+// names of checks, which can be ignored by --ignore-preflight-errors
+const (
+	CheckConnection = "Connection"
+	CheckOS         = "OS"
+	CheckKernel     = "KernelVersion"
+	CheckMemory     = "Mem"
+	CheckDisk       = "DiskSpace"
+	CheckSwap       = "Swap"
+	CheckBridge     = "BridgeNetfilter"
+	CheckClock      = "ClockSkew"
+	CheckHostname   = "DuplicateHostname"
+	CheckMAC        = "DuplicateMAC"
+	CheckUUID       = "DuplicateProductUUID"
+	CheckSudo       = "Sudo"
+	// port check is named with port, such as Port-6443
+	CheckPortPrefix = "Port-"
+
+	preflightTimeout = 2 * time.Minute
+
+	minMasterMemoryMB = 1700
+	minNodeMemoryMB   = 1024
+	minDiskGB         = 10
+	maxClockSkew      = 10 * time.Second
+
+	apiServerPort  = 6443
+	etcdClientPort = 2379
+	etcdPeerPort   = 2380
+	kubeletPort    = 10250
+)
+
+var (
+	supportedOS      = []string{"openeuler", "centos", "rhel", "ubuntu"}
+	minKernelVersion = [2]int{3, 10}
+)
+
+// Checks return names of checks, except port checks
+func Checks() []string {
+	return []string{CheckConnection, CheckOS, CheckKernel, CheckMemory, CheckDisk, CheckSwap, CheckBridge,
+		CheckClock, CheckHostname, CheckMAC, CheckUUID, CheckSudo}
+}
+
+// factsScript print facts of node, one "key=value" per line
+const factsScript = `echo "os=$(. /etc/os-release 2>/dev/null; echo $ID $VERSION_ID)"
+echo "kernel=$(uname -r)"
+echo "memory=$(awk '/^MemTotal:/ {print $2}' /proc/meminfo)"
+echo "disk=$(df -Pk /var/lib 2>/dev/null | awk 'NR==2 {print $4}')"
+echo "swaps=$(awk 'NR>1' /proc/swaps 2>/dev/null | wc -l)"
+echo "listen=$(awk 'FNR>1 && $4=="0A" {split($2,a,":"); print a[2]}' /proc/net/tcp /proc/net/tcp6 2>/dev/null | tr '\n' ' ')"
+echo "bridge=$( (lsmod 2>/dev/null | grep -q '^br_netfilter' || [ -d /proc/sys/net/bridge ] || modinfo br_netfilter >/dev/null 2>&1) && echo yes || echo no)"
+echo "time=$(date +%s)"
+echo "hostname=$(hostname)"
+echo "macs=$(for i in /sys/class/net/*; do [ -e $i/device ] && cat $i/address; done 2>/dev/null | tr '\n' ' ')"
+echo "uuid=$(sudo -n cat /sys/class/dmi/id/product_uuid 2>/dev/null || cat /sys/class/dmi/id/product_uuid 2>/dev/null)"
+echo "uid=$(id -u)"
+sudo=no
+if sudo -n true 2>/dev/null; then
+	sudo=yes
+elif [ "$EGGO_SUDO_PASSWORD" = "yes" ] && sudo true >/dev/null 2>&1; then
+	# password prompt of sudo is answered by eggo
+	sudo=yes
+fi
+# prompt of sudo maybe not end with newline
+echo
+echo "sudo=$sudo"
+`
+
+// factsCommand return command to run facts script, sudo with password is checked only if
+// password is configured for node, otherwise sudo waits for password which is never answered
+func factsCommand(hcf *api.HostConfig) string {
+	script := base64.StdEncoding.EncodeToString([]byte(factsScript))
+	env := ""
+	if _, agent := secrets.AgentSocket(hcf.Password); hcf.Password != "" && !agent {
+		env = "EGGO_SUDO_PASSWORD=yes "
+	}
+	return fmt.Sprintf("echo %s | base64 -d | %s/bin/sh", script, env)
+}
+
+type nodeFacts struct {
+	os        string
+	kernel    string
+	memoryKB  int64
+	diskKB    int64
+	swaps     int
+	listen    map[int]bool
+	bridge    bool
+	clockSkew time.Duration
+	hostname  string
+	macs      []string
+	uuid      string
+	root      bool
+	sudo      bool
+}
+
+// parseFacts parse output of facts script, rtt is time to run script,
+// and mid is the local time when script is run
+func parseFacts(output string, mid time.Time, rtt time.Duration) (*nodeFacts, error) {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if kv := strings.SplitN(strings.TrimSpace(line), "=", 2); len(kv) == 2 {
+			values[kv[0]] = strings.TrimSpace(kv[1])
+		}
+	}
+	if values["kernel"] == "" {
+		return nil, fmt.Errorf("invalid facts of node: %s", output)
+	}
+
+	f := &nodeFacts{
+		os:       values["os"],
+		kernel:   values["kernel"],
+		listen:   make(map[int]bool),
+		bridge:   values["bridge"] == "yes",
+		hostname: values["hostname"],
+		macs:     strings.Fields(values["macs"]),
+		uuid:     strings.ToLower(values["uuid"]),
+		root:     values["uid"] == "0",
+		sudo:     values["sudo"] == "yes",
+	}
+	f.memoryKB, _ = strconv.ParseInt(values["memory"], 10, 64)
+	f.diskKB, _ = strconv.ParseInt(values["disk"], 10, 64)
+	f.swaps, _ = strconv.Atoi(values["swaps"])
+	for _, p := range strings.Fields(values["listen"]) {
+		if port, err := strconv.ParseInt(p, 16, 32); err == nil {
+			f.listen[int(port)] = true
+		}
+	}
+	if sec, err := strconv.ParseInt(values["time"], 10, 64); err == nil {
+		skew := time.Unix(sec, 0).Sub(mid)
+		if skew < 0 {
+			skew = -skew
+		}
+		// remote time is truncated to second, and is taken somewhere in rtt
+		skew -= rtt/2 + time.Second
+		if skew > 0 {
+			f.clockSkew = skew
+		}
+	}
+	return f, nil
+}
+
+type preflightTask struct {
+	lock  sync.Mutex
+	facts map[string]*nodeFacts
+	errs  map[string]error
+}
+
+func (t *preflightTask) Name() string {
+	return "preflightTask"
+}
+
+func (t *preflightTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	start := time.Now()
+	output, err := r.RunCommand(factsCommand(hcf))
+	rtt := time.Since(start)
+	var f *nodeFacts
+	if err == nil {
+		f, err = parseFacts(output, start.Add(rtt/2), rtt)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err != nil {
+		t.errs[hcf.Address] = err
+		return err
+	}
+	t.facts[hcf.Address] = f
+	return nil
+}
+
+func kernelVersion(release string) (int, int, error) {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid kernel release: %s", release)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid kernel release: %s", release)
+	}
+	// minor maybe followed by other characters, such as 10-60.oe2203
+	digits := strings.FieldsFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' })
+	if len(digits) == 0 || !strings.HasPrefix(parts[1], digits[0]) {
+		return 0, 0, fmt.Errorf("invalid kernel release: %s", release)
+	}
+	minor, err := strconv.Atoi(digits[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid kernel release: %s", release)
+	}
+	return major, minor, nil
+}
+
+func isSupportedOS(id string) bool {
+	for _, s := range supportedOS {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredPorts return ports must be free on node
+func requiredPorts(conf *api.ClusterConfig, hcf *api.HostConfig) []int {
+	var ports []int
+	if utils.IsType(hcf.Type, api.Master) {
+		ports = append(ports, apiServerPort)
+	}
+	if utils.IsType(hcf.Type, api.ETCD) {
+		ports = append(ports, etcdClientPort, etcdPeerPort)
+	}
+	if utils.IsType(hcf.Type, api.Worker) {
+		ports = append(ports, kubeletPort)
+	}
+	if utils.IsType(hcf.Type, api.LoadBalance) {
+		if port, err := strconv.Atoi(conf.LoadBalancer.Port); err == nil && port != apiServerPort {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func newError(hcf *api.HostConfig, check string, format string, args ...interface{}) *api.PreflightError {
+	return &api.PreflightError{Node: hcf.Address, Name: hcf.Name, Check: check, Message: fmt.Sprintf(format, args...)}
+}
+
+// checkNode return errors of checks which only depend on facts of node itself
+func checkNode(conf *api.ClusterConfig, hcf *api.HostConfig, f *nodeFacts) []*api.PreflightError {
+	var errs []*api.PreflightError
+	kubeNode := utils.IsType(hcf.Type, api.Master) || utils.IsType(hcf.Type, api.Worker)
+
+	osID := strings.ToLower(strings.Fields(f.os + " unknown")[0])
+	if !isSupportedOS(osID) {
+		errs = append(errs, newError(hcf, CheckOS, "unsupported os: %q, support: %s", f.os, strings.Join(supportedOS, ", ")))
+	}
+	if major, minor, err := kernelVersion(f.kernel); err != nil {
+		errs = append(errs, newError(hcf, CheckKernel, "%v", err))
+	} else if major < minKernelVersion[0] || (major == minKernelVersion[0] && minor < minKernelVersion[1]) {
+		errs = append(errs, newError(hcf, CheckKernel, "kernel %s is older than %d.%d", f.kernel,
+			minKernelVersion[0], minKernelVersion[1]))
+	}
+
+	minMemory := int64(minNodeMemoryMB)
+	if utils.IsType(hcf.Type, api.Master) {
+		minMemory = minMasterMemoryMB
+	}
+	if f.memoryKB/1024 < minMemory {
+		errs = append(errs, newError(hcf, CheckMemory, "memory %dMB is less than %dMB", f.memoryKB/1024, minMemory))
+	}
+	if (kubeNode || utils.IsType(hcf.Type, api.ETCD)) && f.diskKB/1024/1024 < minDiskGB {
+		errs = append(errs, newError(hcf, CheckDisk, "free space of /var/lib %dGB is less than %dGB",
+			f.diskKB/1024/1024, minDiskGB))
+	}
+
+	if utils.IsType(hcf.Type, api.Worker) && f.swaps > 0 {
+		errs = append(errs, newError(hcf, CheckSwap, "swap is enabled, kubelet does not run with swap, disable it by swapoff -a"))
+	}
+	if kubeNode && !f.bridge {
+		errs = append(errs, newError(hcf, CheckBridge, "kernel module br_netfilter is not found"))
+	}
+	for _, p := range requiredPorts(conf, hcf) {
+		if f.listen[p] {
+			errs = append(errs, newError(hcf, fmt.Sprintf("%s%d", CheckPortPrefix, p), "port %d is in use", p))
+		}
+	}
+	if f.clockSkew > maxClockSkew {
+		errs = append(errs, newError(hcf, CheckClock, "clock differs from this machine by %s, more than %s",
+			f.clockSkew.Round(time.Second), maxClockSkew))
+	}
+	// commands are run without sudo only if local cluster is deployed by root
+	if !f.sudo && !(conf.Local && f.root) {
+		errs = append(errs, newError(hcf, CheckSudo, "user %s cannot run sudo without password or with configured password",
+			hcf.UserName))
+	}
+	return errs
+}
+
+// checkDuplicates return errors of nodes to check, whose hostname, mac or product_uuid is the same as other nodes
+func checkDuplicates(nodes []*api.HostConfig, all map[string]*api.HostConfig, facts map[string]*nodeFacts) []*api.PreflightError {
+	owners := map[string]map[string][]string{CheckHostname: {}, CheckMAC: {}, CheckUUID: {}}
+	for addr, f := range facts {
+		owners[CheckHostname][f.hostname] = append(owners[CheckHostname][f.hostname], addr)
+		for _, mac := range utils.RemoveDupString(f.macs) {
+			owners[CheckMAC][mac] = append(owners[CheckMAC][mac], addr)
+		}
+		owners[CheckUUID][f.uuid] = append(owners[CheckUUID][f.uuid], addr)
+	}
+
+	var errs []*api.PreflightError
+	for _, hcf := range nodes {
+		f, ok := facts[hcf.Address]
+		if !ok {
+			continue
+		}
+		values := map[string][]string{CheckHostname: {f.hostname}, CheckMAC: f.macs, CheckUUID: {f.uuid}}
+		for _, check := range []string{CheckHostname, CheckMAC, CheckUUID} {
+			for _, v := range utils.RemoveDupString(values[check]) {
+				if v == "" {
+					continue
+				}
+				var others []string
+				for _, addr := range owners[check][v] {
+					if addr != hcf.Address {
+						others = append(others, all[addr].Name)
+					}
+				}
+				if len(others) != 0 {
+					errs = append(errs, newError(hcf, check, "%s is the same as nodes: %s", v, strings.Join(others, ", ")))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// Preflight check nodes before they are deployed, facts are collected on all nodes of cluster,
+// so that nodes can be compared with deployed ones, nodes in unreachable are reported as connection errors
+func Preflight(conf *api.ClusterConfig, nodes []*api.HostConfig, unreachable map[string]error) ([]*api.PreflightError, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("empty cluster config")
+	}
+
+	t := &preflightTask{
+		facts: make(map[string]*nodeFacts),
+		errs:  make(map[string]error),
+	}
+	all := make(map[string]*api.HostConfig)
+	for _, n := range conf.Nodes {
+		all[n.Address] = n
+	}
+	for _, n := range nodes {
+		all[n.Address] = n
+	}
+	var addrs []string
+	for addr := range all {
+		if err, ok := unreachable[addr]; ok {
+			t.errs[addr] = err
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	// ignore error, facts of failed node are reported as connection error
+	if err := nodemanager.RunTaskOnNodes(task.NewTaskIgnoreErrInstance(t), addrs); err != nil {
+		return nil, fmt.Errorf("run preflight task failed: %v", err)
+	}
+	if err := nodemanager.WaitNodesFinish(addrs, preflightTimeout); err != nil {
+		logrus.Warnf("wait preflight task failed: %v", err)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var errs []*api.PreflightError
+	for _, hcf := range nodes {
+		f, ok := t.facts[hcf.Address]
+		if !ok {
+			reason := t.errs[hcf.Address]
+			if reason == nil {
+				reason = fmt.Errorf("collect facts of node timeout")
+			}
+			errs = append(errs, newError(hcf, CheckConnection, "%v", reason))
+			continue
+		}
+		errs = append(errs, checkNode(conf, hcf, f)...)
+	}
+	errs = append(errs, checkDuplicates(nodes, all, t.facts)...)
+	return errs, nil
+}
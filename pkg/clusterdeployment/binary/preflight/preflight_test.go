@@ -0,0 +1,186 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of preflight checks
+ ******************************************************************************/
+
+package preflight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+)
+
+const testFacts = `os=openEuler 21.03
+kernel=5.10.0-4.17.0.28.oe1.x86_64
+memory=4019804
+disk=41152812
+swaps=0
+listen=0016 1A0B
+bridge=yes
+time=%d
+hostname=node1
+macs=52:54:00:aa:bb:cc 52:54:00:aa:bb:cc
+uuid=ABCD-1234
+uid=1000
+sudo=yes
+`
+
+func testNodeFacts(t *testing.T, output string) *nodeFacts {
+	f, err := parseFacts(strings.Replace(output, "%d", "1600000000", 1), time.Unix(1600000000, 0), time.Second)
+	if err != nil {
+		t.Fatalf("parse facts failed: %v", err)
+	}
+	return f
+}
+
+func checkNames(errs []*api.PreflightError) string {
+	var names []string
+	for _, e := range errs {
+		names = append(names, e.Check)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestParseFacts(t *testing.T) {
+	f := testNodeFacts(t, testFacts)
+	if f.memoryKB != 4019804 || f.swaps != 0 || !f.bridge || !f.sudo || f.root {
+		t.Fatalf("invalid facts: %+v", f)
+	}
+	if !f.listen[22] || !f.listen[6667] || len(f.listen) != 2 {
+		t.Fatalf("invalid listen ports: %v", f.listen)
+	}
+	if f.uuid != "abcd-1234" || f.clockSkew != 0 {
+		t.Fatalf("invalid uuid or clock skew: %+v", f)
+	}
+
+	f, err := parseFacts(strings.Replace(testFacts, "%d", "1600000030", 1), time.Unix(1600000000, 0), 2*time.Second)
+	if err != nil {
+		t.Fatalf("parse facts failed: %v", err)
+	}
+	if f.clockSkew != 28*time.Second {
+		t.Fatalf("expect clock skew 28s, get %s", f.clockSkew)
+	}
+
+	if _, err := parseFacts("permission denied", time.Now(), 0); err == nil {
+		t.Fatalf("expect error of invalid facts")
+	}
+}
+
+func TestKernelVersion(t *testing.T) {
+	major, minor, err := kernelVersion("4.19.90-2003.4.0.0036.oe1.x86_64")
+	if err != nil || major != 4 || minor != 19 {
+		t.Fatalf("invalid kernel version: %d.%d, %v", major, minor, err)
+	}
+	if _, _, err = kernelVersion(""); err == nil {
+		t.Fatalf("expect error of empty kernel version")
+	}
+}
+
+func TestCheckNode(t *testing.T) {
+	conf := &api.ClusterConfig{LoadBalancer: api.LoadBalancer{Port: "6443"}}
+	worker := &api.HostConfig{Name: "node1", Address: "192.168.0.2", UserName: "eggo", Type: api.Worker}
+	master := &api.HostConfig{Name: "master", Address: "192.168.0.1", UserName: "eggo", Type: api.Master}
+
+	if errs := checkNode(conf, worker, testNodeFacts(t, testFacts)); len(errs) != 0 {
+		t.Fatalf("expect no errors, get %s", checkNames(errs))
+	}
+
+	output := strings.NewReplacer("swaps=0", "swaps=1", "listen=0016", "listen=0016 280A", "sudo=yes", "sudo=no",
+		"os=openEuler", "os=arch").Replace(testFacts)
+	f := testNodeFacts(t, output)
+	if names := checkNames(checkNode(conf, worker, f)); names != "OS,Swap,Port-10250,Sudo" {
+		t.Fatalf("invalid errors of worker: %s", names)
+	}
+	// swap is only checked on worker, and 1.7GB memory is required by master
+	f.memoryKB = 1024 * 1024
+	if names := checkNames(checkNode(conf, master, f)); names != "OS,Mem,Sudo" {
+		t.Fatalf("invalid errors of master: %s", names)
+	}
+
+	// root does not need sudo in local cluster
+	f = testNodeFacts(t, strings.NewReplacer("sudo=yes", "sudo=no", "uid=1000", "uid=0").Replace(testFacts))
+	conf.Local = true
+	if errs := checkNode(conf, worker, f); len(errs) != 0 {
+		t.Fatalf("expect no errors of local root, get %s", checkNames(errs))
+	}
+}
+
+func TestSudoWithPassword(t *testing.T) {
+	// prompt of sudo is printed to terminal before facts of sudo
+	output := strings.Replace(testFacts, "sudo=yes", "[sudo] password for eggo: \nsudo=yes", 1)
+	if f := testNodeFacts(t, output); !f.sudo {
+		t.Fatalf("sudo with password is not parsed: %+v", f)
+	}
+
+	if cmd := factsCommand(&api.HostConfig{Password: "env:EGGO_PASSWORD"}); !strings.Contains(cmd, "EGGO_SUDO_PASSWORD=yes /bin/sh") {
+		t.Fatalf("sudo with password is not checked: %s", cmd)
+	}
+	for _, password := range []string{"", "agent:"} {
+		if cmd := factsCommand(&api.HostConfig{Password: password}); strings.Contains(cmd, "EGGO_SUDO_PASSWORD") {
+			t.Fatalf("sudo with password is checked without password: %s", cmd)
+		}
+	}
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	n1 := &api.HostConfig{Name: "node1", Address: "192.168.0.1"}
+	n2 := &api.HostConfig{Name: "node2", Address: "192.168.0.2"}
+	n3 := &api.HostConfig{Name: "node3", Address: "192.168.0.3"}
+	all := map[string]*api.HostConfig{n1.Address: n1, n2.Address: n2, n3.Address: n3}
+	facts := map[string]*nodeFacts{
+		n1.Address: {hostname: "node1", macs: []string{"aa"}, uuid: "u1"},
+		n2.Address: {hostname: "node1", macs: []string{"bb"}, uuid: "u2"},
+		n3.Address: {hostname: "node3", macs: []string{"bb", "bb"}, uuid: "u2"},
+	}
+
+	// only nodes to check are reported
+	errs := checkDuplicates([]*api.HostConfig{n3}, all, facts)
+	if names := checkNames(errs); names != "DuplicateMAC,DuplicateProductUUID" {
+		t.Fatalf("invalid errors: %s", names)
+	}
+	if errs[0].Message != "bb is the same as nodes: node2" {
+		t.Fatalf("invalid message: %s", errs[0].Message)
+	}
+
+	errs = checkDuplicates([]*api.HostConfig{n1}, all, facts)
+	if names := checkNames(errs); names != "DuplicateHostname" {
+		t.Fatalf("invalid errors: %s", names)
+	}
+}
+
+func TestPreflightUnreachable(t *testing.T) {
+	n1 := &api.HostConfig{Name: "node1", Address: "192.168.0.1"}
+	n2 := &api.HostConfig{Name: "node2", Address: "192.168.0.2"}
+	conf := &api.ClusterConfig{Nodes: []*api.HostConfig{n1, n2}}
+	unreachable := map[string]error{
+		n1.Address: fmt.Errorf("dial tcp 192.168.0.1:22: connection refused"),
+		n2.Address: fmt.Errorf("ssh: unable to authenticate"),
+	}
+
+	// nodes failed to connect are reported as connection errors of them
+	errs, err := Preflight(conf, []*api.HostConfig{n1, n2}, unreachable)
+	if err != nil {
+		t.Fatalf("preflight with unreachable nodes failed: %v", err)
+	}
+	if names := checkNames(errs); names != "Connection,Connection" {
+		t.Fatalf("invalid errors: %s", names)
+	}
+	if errs[0].Node != n1.Address || !strings.Contains(errs[0].Message, "connection refused") ||
+		errs[1].Node != n2.Address || !strings.Contains(errs[1].Message, "unable to authenticate") {
+		t.Fatalf("invalid connection errors: %+v, %+v", errs[0], errs[1])
+	}
+}
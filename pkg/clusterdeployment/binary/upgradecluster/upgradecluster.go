@@ -0,0 +1,456 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo rolling upgrade cluster binary implement
+ ******************************************************************************/
+
+package upgradecluster
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/cleanupcluster"
+	"isula.org/eggo/pkg/clusterdeployment/binary/commontools"
+	"isula.org/eggo/pkg/clusterdeployment/binary/controlplane"
+	"isula.org/eggo/pkg/clusterdeployment/binary/coredns"
+	"isula.org/eggo/pkg/clusterdeployment/binary/etcdcluster"
+	"isula.org/eggo/pkg/clusterdeployment/binary/infrastructure"
+	"isula.org/eggo/pkg/clusterdeployment/binary/loadbalance"
+	"isula.org/eggo/pkg/clusterdeployment/runtime"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils"
+	"isula.org/eggo/pkg/utils/kubectl"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	// kubelet may be up to two minor versions older than kube-apiserver
+	maxKubeletSkew = 2
+
+	drainTimeout     = "240s"
+	waitReadyTimeout = 5 * time.Minute
+)
+
+func parseVersion(v string) (*version.Version, error) {
+	ver, err := version.ParseGeneric(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %v", v, err)
+	}
+	return ver, nil
+}
+
+// checkVersionSkew follow the version skew policy of kubernetes:
+// control plane can only be upgraded one minor version at a time, downgrade is not supported,
+// and kubelets must not be too older than the new control plane.
+func checkVersionSkew(target string, current string, kubelets map[string]string) error {
+	tv, err := parseVersion(target)
+	if err != nil {
+		return err
+	}
+	cv, err := parseVersion(current)
+	if err != nil {
+		return err
+	}
+
+	if tv.Major() != cv.Major() {
+		return fmt.Errorf("upgrade across major version from %s to %s is not supported", current, target)
+	}
+	if tv.LessThan(cv) {
+		return fmt.Errorf("downgrade from %s to %s is not supported", current, target)
+	}
+	if tv.Minor() > cv.Minor()+1 {
+		return fmt.Errorf("control plane can only be upgraded one minor version at a time, from %s to %s is not allowed",
+			current, target)
+	}
+
+	allUpgraded := !cv.LessThan(tv)
+	for name, k := range kubelets {
+		kv, err := parseVersion(k)
+		if err != nil {
+			return fmt.Errorf("kubelet of node %s: %v", name, err)
+		}
+		if tv.LessThan(kv) {
+			return fmt.Errorf("kubelet of node %s is %s, newer than target version %s", name, k, target)
+		}
+		if kv.Major() != tv.Major() || kv.Minor()+maxKubeletSkew < tv.Minor() {
+			return fmt.Errorf("kubelet of node %s is %s, too old for target version %s", name, k, target)
+		}
+		if kv.LessThan(tv) {
+			allUpgraded = false
+		}
+	}
+	if allUpgraded {
+		return fmt.Errorf("cluster is already at version %s", target)
+	}
+
+	return nil
+}
+
+func checkClusterVersion(conf *api.ClusterConfig) error {
+	current, kubelets, err := kubectl.GetClusterVersions(conf.Name)
+	if err != nil {
+		return fmt.Errorf("get versions of cluster failed: %v", err)
+	}
+	logrus.Infof("[upgrade] current version: %s, target version: %s", current, conf.UpgradeConf.KubernetesVersion)
+
+	return checkVersionSkew(conf.UpgradeConf.KubernetesVersion, current, kubelets)
+}
+
+type kubectlTask struct {
+	ccfg *api.ClusterConfig
+	args string
+}
+
+func (t *kubectlTask) Name() string {
+	return "kubectlTask"
+}
+
+func (t *kubectlTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	cmd := fmt.Sprintf("KUBECONFIG=%s kubectl %s",
+		filepath.Join(t.ccfg.GetConfigDir(), constants.KubeConfigFileNameAdmin), t.args)
+	if output, err := r.RunCommand(utils.AddSudo(cmd)); err != nil {
+		logrus.Errorf("run kubectl %s failed: %v\noutput: %s", t.args, err, output)
+		return err
+	}
+	return nil
+}
+
+// run kubectl on master which is not upgrading
+func runKubectl(conf *api.ClusterConfig, exclude string, args string) error {
+	var node string
+	for _, n := range conf.Nodes {
+		if !utils.IsType(n.Type, api.Master) {
+			continue
+		}
+		if node == "" || node == exclude {
+			node = n.Address
+		}
+	}
+	if node == "" {
+		return fmt.Errorf("no master found to run kubectl")
+	}
+
+	t := task.NewTaskInstance(&kubectlTask{ccfg: conf, args: args})
+	if err := nodemanager.RunTaskOnNodes(t, []string{node}); err != nil {
+		return err
+	}
+	return nodemanager.WaitNodesFinish([]string{node}, time.Minute*constants.DefaultTaskWaitMinutes)
+}
+
+func runShellOnNodes(name string, shell string, nodes []string) error {
+	t := task.NewTaskInstance(
+		&commontools.RunShellTask{
+			ShellName: name,
+			Shell:     shell,
+		},
+	)
+	if err := nodemanager.RunTaskOnNodes(t, nodes); err != nil {
+		return err
+	}
+	return nodemanager.WaitNodesFinish(nodes, time.Minute*constants.DefaultTaskWaitMinutes)
+}
+
+func stopServices(nodes []string, services []string) error {
+	shell := fmt.Sprintf("#!/bin/bash\nsystemctl stop %s\n", strings.Join(services, " "))
+	return runShellOnNodes("stopServices", shell, nodes)
+}
+
+func startServices(nodes []string, services []string) error {
+	shell := fmt.Sprintf("#!/bin/bash\nsystemctl daemon-reload && systemctl restart %s\n", strings.Join(services, " "))
+	return runShellOnNodes("startServices", shell, nodes)
+}
+
+func upgradePackages(conf *api.ClusterConfig, nodes []string, roles uint16) error {
+	for _, n := range nodes {
+		if err := infrastructure.NodeInfrastructureUpgrade(conf, n, roles); err != nil {
+			return err
+		}
+	}
+	return nodemanager.WaitNodesFinish(nodes, time.Minute*constants.DefaultTaskWaitMinutes)
+}
+
+func getWorkerServices(conf *api.ClusterConfig) ([]string, error) {
+	services := append([]string{}, cleanupcluster.WorkerService...)
+	r := runtime.GetRuntime(conf.WorkerConfig.ContainerEngineConf.Runtime)
+	if r == nil {
+		return nil, fmt.Errorf("invalid container engine %s", conf.WorkerConfig.ContainerEngineConf.Runtime)
+	}
+	return append(services, r.GetRuntimeService()), nil
+}
+
+func getMasterServices(conf *api.ClusterConfig) []string {
+	services := append([]string{}, cleanupcluster.MasterService...)
+	if coredns.IsTypeBinary(conf.ServiceCluster.DNS.CorednsType) {
+		services = append(services, "coredns")
+	}
+	return services
+}
+
+func waitEtcdMemberHealthy(conf *api.ClusterConfig, addr string) error {
+	finish := time.After(waitReadyTimeout)
+	for {
+		health, err := etcdcluster.CheckEtcdHealth(conf)
+		if err == nil && health[addr] {
+			return nil
+		}
+		select {
+		case <-finish:
+			return fmt.Errorf("etcd member %s is not healthy after upgrade", addr)
+		case <-time.After(time.Second * 2):
+		}
+	}
+}
+
+func upgradeEtcds(conf *api.ClusterConfig) error {
+	if conf.EtcdCluster.External {
+		logrus.Info("[upgrade] external etcd, skip upgrade etcd")
+		return nil
+	}
+
+	for _, n := range conf.EtcdCluster.Nodes {
+		logrus.Infof("[upgrade] upgrade etcd on %s", n.Address)
+		nodes := []string{n.Address}
+		if err := stopServices(nodes, []string{"etcd"}); err != nil {
+			return err
+		}
+		if err := upgradePackages(conf, nodes, api.ETCD); err != nil {
+			return err
+		}
+		if err := startServices(nodes, []string{"etcd"}); err != nil {
+			return err
+		}
+		if err := waitEtcdMemberHealthy(conf, n.Address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func waitAPIServerReady(conf *api.ClusterConfig, node string) error {
+	shell := fmt.Sprintf(`#!/bin/bash
+for i in $(seq 60); do
+	kubectl --kubeconfig=%s --server=%s get --raw /readyz
+	if [ $? -eq 0 ]; then
+		exit 0
+	fi
+	sleep 2
+done
+exit 1
+`, filepath.Join(conf.GetConfigDir(), constants.KubeConfigFileNameAdmin), controlplane.LocalEndpoint)
+	return runShellOnNodes("waitAPIServer", shell, []string{node})
+}
+
+func getLoadBalance(conf *api.ClusterConfig) *api.HostConfig {
+	for _, n := range conf.Nodes {
+		if utils.IsType(n.Type, api.LoadBalance) {
+			return n
+		}
+	}
+	return nil
+}
+
+func drainNodes(conf *api.ClusterConfig, nodes []*api.HostConfig) error {
+	for _, n := range nodes {
+		if err := runKubectl(conf, n.Address, "cordon "+n.Name); err != nil {
+			return fmt.Errorf("cordon node %s failed: %v", n.Name, err)
+		}
+		args := fmt.Sprintf("drain %s --ignore-daemonsets --delete-emptydir-data --force --timeout=%s", n.Name, drainTimeout)
+		if err := runKubectl(conf, n.Address, args); err != nil {
+			return fmt.Errorf("drain node %s failed: %v", n.Name, err)
+		}
+	}
+	return nil
+}
+
+func uncordonNodes(conf *api.ClusterConfig, nodes []*api.HostConfig) error {
+	for _, n := range nodes {
+		if err := kubectl.WaitNodeReady(conf.Name, n.Name, waitReadyTimeout); err != nil {
+			return err
+		}
+		if err := runKubectl(conf, n.Address, "uncordon "+n.Name); err != nil {
+			return fmt.Errorf("uncordon node %s failed: %v", n.Name, err)
+		}
+	}
+	return nil
+}
+
+func upgradeMaster(conf *api.ClusterConfig, master *api.HostConfig, lb *api.HostConfig) error {
+	logrus.Infof("[upgrade] upgrade master %s", master.Name)
+
+	// remove master from upstream of loadbalance during upgrade
+	if lb != nil {
+		var upstream []string
+		for _, m := range utils.GetMasterIPList(conf) {
+			if m != master.Address {
+				upstream = append(upstream, m)
+			}
+		}
+		if len(upstream) > 0 {
+			if err := loadbalance.UpdateLoadBalancerUpstream(conf, lb, upstream); err != nil {
+				return fmt.Errorf("drain master %s from loadbalance failed: %v", master.Name, err)
+			}
+		}
+	}
+
+	roles := uint16(api.Master)
+	services := getMasterServices(conf)
+	isWorker := utils.IsType(master.Type, api.Worker)
+	if isWorker {
+		if err := drainNodes(conf, []*api.HostConfig{master}); err != nil {
+			return err
+		}
+		ws, err := getWorkerServices(conf)
+		if err != nil {
+			return err
+		}
+		roles |= api.Worker
+		services = append(services, ws...)
+	}
+
+	nodes := []string{master.Address}
+	if err := stopServices(nodes, services); err != nil {
+		return err
+	}
+	if err := upgradePackages(conf, nodes, roles); err != nil {
+		return err
+	}
+	if err := startServices(nodes, services); err != nil {
+		return err
+	}
+	if err := waitAPIServerReady(conf, master.Address); err != nil {
+		return err
+	}
+
+	if isWorker {
+		if err := uncordonNodes(conf, []*api.HostConfig{master}); err != nil {
+			return err
+		}
+	}
+
+	if lb != nil {
+		if err := loadbalance.UpdateLoadBalancer(conf, lb); err != nil {
+			return fmt.Errorf("add master %s back to loadbalance failed: %v", master.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func upgradeMasters(conf *api.ClusterConfig) error {
+	lb := getLoadBalance(conf)
+	for _, n := range conf.Nodes {
+		if !utils.IsType(n.Type, api.Master) {
+			continue
+		}
+		if err := upgradeMaster(conf, n, lb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upgradeWorkerBatch(conf *api.ClusterConfig, workers []*api.HostConfig) error {
+	var names []string
+	for _, w := range workers {
+		names = append(names, w.Name)
+	}
+	logrus.Infof("[upgrade] upgrade workers %v", names)
+
+	services, err := getWorkerServices(conf)
+	if err != nil {
+		return err
+	}
+
+	if err := drainNodes(conf, workers); err != nil {
+		return err
+	}
+
+	nodes := utils.GetAllIPs(workers)
+	if err := stopServices(nodes, services); err != nil {
+		return err
+	}
+	if err := upgradePackages(conf, nodes, api.Worker); err != nil {
+		return err
+	}
+	if err := startServices(nodes, services); err != nil {
+		return err
+	}
+
+	return uncordonNodes(conf, workers)
+}
+
+func upgradeWorkers(conf *api.ClusterConfig) error {
+	batch := conf.UpgradeConf.WorkerBatchSize
+	if batch <= 0 {
+		batch = 1
+	}
+
+	var workers []*api.HostConfig
+	for _, n := range conf.Nodes {
+		// workers on master are upgraded with master
+		if utils.IsType(n.Type, api.Worker) && !utils.IsType(n.Type, api.Master) {
+			workers = append(workers, n)
+		}
+	}
+
+	for i := 0; i < len(workers); i += batch {
+		end := i + batch
+		if end > len(workers) {
+			end = len(workers)
+		}
+		if err := upgradeWorkerBatch(conf, workers[i:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpgradeCluster upgrade etcds first, then masters one by one, and workers in batches at last.
+func UpgradeCluster(conf *api.ClusterConfig) error {
+	if conf == nil || conf.UpgradeConf == nil || conf.UpgradeConf.KubernetesVersion == "" {
+		return fmt.Errorf("target version of upgrade is required")
+	}
+
+	// check before anything is touched
+	if err := checkClusterVersion(conf); err != nil {
+		return err
+	}
+
+	if err := upgradeEtcds(conf); err != nil {
+		return fmt.Errorf("upgrade etcd failed: %v", err)
+	}
+	logrus.Info("[upgrade] upgrade etcd success")
+
+	if err := upgradeMasters(conf); err != nil {
+		return fmt.Errorf("upgrade masters failed: %v", err)
+	}
+	logrus.Info("[upgrade] upgrade masters success")
+
+	if err := upgradeWorkers(conf); err != nil {
+		return fmt.Errorf("upgrade workers failed: %v", err)
+	}
+	logrus.Info("[upgrade] upgrade workers success")
+
+	return nil
+}
@@ -0,0 +1,48 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: eggo upgrade cluster testcase
+ ******************************************************************************/
+
+package upgradecluster
+
+import (
+	"testing"
+)
+
+func TestCheckVersionSkew(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		current  string
+		kubelets map[string]string
+		wantErr  bool
+	}{
+		{"patch", "v1.20.3", "v1.20.2", map[string]string{"w1": "v1.20.2"}, false},
+		{"minor", "v1.21.1", "v1.20.2", map[string]string{"w1": "v1.20.2", "w2": "v1.19.5"}, false},
+		{"skip minor", "v1.22.0", "v1.20.2", map[string]string{"w1": "v1.20.2"}, true},
+		{"downgrade", "v1.20.1", "v1.20.2", map[string]string{"w1": "v1.20.2"}, true},
+		{"major", "v2.0.0", "v1.20.2", nil, true},
+		{"old kubelet", "v1.21.1", "v1.20.2", map[string]string{"w1": "v1.18.2"}, true},
+		{"newer kubelet", "v1.21.1", "v1.21.0", map[string]string{"w1": "v1.21.2"}, true},
+		{"resume", "v1.21.1", "v1.21.1", map[string]string{"w1": "v1.20.2"}, false},
+		{"already", "v1.21.1", "v1.21.1", map[string]string{"w1": "v1.21.1"}, true},
+		{"invalid", "latest", "v1.21.1", nil, true},
+	}
+
+	for _, c := range cases {
+		err := checkVersionSkew(c.target, c.current, c.kubelets)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("case %s: expect error %v, get %v", c.name, c.wantErr, err)
+		}
+	}
+}
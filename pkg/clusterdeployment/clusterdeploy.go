@@ -18,6 +18,7 @@ package clusterdeployment
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"isula.org/eggo/pkg/utils"
 	"isula.org/eggo/pkg/utils/certs"
 	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/secrets"
 )
 
 func splitNodes(nodes []*api.HostConfig) (*api.HostConfig, []*api.HostConfig, []*api.HostConfig, []string) {
@@ -61,7 +63,7 @@ func splitNodes(nodes []*api.HostConfig) (*api.HostConfig, []*api.HostConfig, []
 }
 
 func approveServingCsr(cc *api.ClusterConfig, nodes []*api.HostConfig) {
-	if cc.WorkerConfig.KubeletConf == nil || !cc.WorkerConfig.KubeletConf.EnableServer {
+	if cc.DryRun || cc.WorkerConfig.KubeletConf == nil || !cc.WorkerConfig.KubeletConf.EnableServer {
 		return
 	}
 
@@ -82,47 +84,68 @@ func approveServingCsr(cc *api.ClusterConfig, nodes []*api.HostConfig) {
 	}
 }
 
-func doJoinNodeOfCluster(handler api.ClusterDeploymentAPI, cc *api.ClusterConfig, masters, workers []*api.HostConfig) ([]string, []*api.HostConfig, []*api.HostConfig) {
-	var joinedNodeIDs []string
-	var joinedNodes, failedNodes []*api.HostConfig
-	for _, node := range workers {
-		if err := handler.ClusterNodeJoin(node); err != nil {
-			failedNodes = append(failedNodes, node)
-			continue
+const (
+	stepMachineInfraSetup       = "MachineInfraSetup"
+	stepPreCreateClusterHooks   = "PreCreateClusterHooks"
+	stepEtcdClusterSetup        = "EtcdClusterSetup"
+	stepLoadBalancerSetup       = "LoadBalancerSetup"
+	stepClusterControlPlaneInit = "ClusterControlPlaneInit"
+	stepClusterNodeJoin         = "ClusterNodeJoin"
+	stepAddonsSetup             = "AddonsSetup"
+	stepPostCreateClusterHooks  = "PostCreateClusterHooks"
+	stepPreNodeJoinHooks        = "PreNodeJoinHooks"
+	stepEtcdNodeSetup           = "EtcdNodeSetup"
+	stepPostNodeJoinHooks       = "PostNodeJoinHooks"
+)
+
+func hasNode(nodes []string, others []string) bool {
+	for _, n := range nodes {
+		for _, o := range others {
+			if n == o {
+				return true
+			}
 		}
-		joinedNodeIDs = append(joinedNodeIDs, node.Address)
 	}
-	for _, node := range masters {
-		if err := handler.ClusterNodeJoin(node); err != nil {
-			failedNodes = append(failedNodes, node)
-			continue
-		}
-		joinedNodeIDs = append(joinedNodeIDs, node.Address)
+	return false
+}
+
+// healthyNodes return nodes without failed task
+func healthyNodes(nodes []*api.HostConfig) []*api.HostConfig {
+	var ids []string
+	for _, n := range nodes {
+		ids = append(ids, n.Address)
 	}
-	// wait all nodes ready
-	if err := nodemanager.WaitNodesFinishWithProgress(joinedNodeIDs,
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
-		tFailedNodes, successNodes := nodemanager.CheckNodesStatus(joinedNodeIDs)
-		// update joined and failed nodes
-		failedNodes = append(failedNodes, tFailedNodes...)
-		joinedNodeIDs = successNodes
-		// allow all join nodes failed
-		logrus.Warnf("wait some node to complete join failed: %v", err)
-	}
-	flags := make(map[string]bool)
-	for _, jid := range joinedNodeIDs {
-		flags[jid] = true
-	}
-	for _, node := range workers {
-		for _, jid := range joinedNodeIDs {
-			if jid == node.Address {
-				joinedNodes = append(joinedNodes, node)
-				break
-			}
+	_, success := nodemanager.CheckNodesStatus(ids)
+
+	var healthy []*api.HostConfig
+	for _, n := range nodes {
+		if hasNode([]string{n.Address}, success) {
+			healthy = append(healthy, n)
 		}
 	}
+	return healthy
+}
 
-	return joinedNodeIDs, joinedNodes, failedNodes
+// failedSteps return nodes of steps not success, and error if any of these steps
+// is not run on node or run on critical nodes
+func failedSteps(results []*nodemanager.StepResult, critical []string) (map[string]bool, error) {
+	failed := make(map[string]bool)
+	var errmsg []string
+	for _, r := range results {
+		if r.Status == nodemanager.StepSuccess {
+			continue
+		}
+		for _, n := range r.Step.Nodes {
+			failed[n] = true
+		}
+		if len(r.Step.Nodes) == 0 || hasNode(r.Step.Nodes, critical) {
+			errmsg = append(errmsg, fmt.Sprintf("step %s %s: %v", r.Step, r.Status, r.Err))
+		}
+	}
+	if len(errmsg) != 0 {
+		return failed, fmt.Errorf("%s", strings.Join(errmsg, "\n"))
+	}
+	return failed, nil
 }
 
 func doCreateCluster(handler api.ClusterDeploymentAPI, cc *api.ClusterConfig, cstatus *api.ClusterStatus) ([]*api.HostConfig, error) {
@@ -137,76 +160,130 @@ func doCreateCluster(handler api.ClusterDeploymentAPI, cc *api.ClusterConfig, cs
 	}
 	cstatus.ControlPlane = controlPlaneNode.Address
 	masters = masters[1:]
+	cp := []string{controlPlaneNode.Address}
+	// cluster is failed if any step failed on these nodes
+	critical := append([]string{controlPlaneNode.Address}, etcdNodes...)
 
-	// Step1: setup infrastructure for all nodes in the cluster
-	for _, n := range cc.Nodes {
-		if err = handler.MachineInfraSetup(n); err != nil {
-			return nil, err
-		}
-	}
-
-	// Step2: run precreate cluster hooks
-	if err = handler.PreCreateClusterHooks(); err != nil {
-		return nil, err
-	}
+	s := nodemanager.NewScheduler(constants.DefaultStepConcurrency)
 
-	// Step3: setup etcd cluster
-	// wait infrastructure task success on nodes of etcd cluster
-	if err = nodemanager.WaitNodesFinishWithProgress(etcdNodes,
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
-		return nil, err
-	}
-	if err = handler.EtcdClusterSetup(); err != nil {
-		return nil, err
-	}
-
-	// Step4: setup loadbalance for cluster
-	if err = handler.LoadBalancerSetup(loadbalancer); err != nil {
-		return nil, err
-	}
-
-	// Step5: setup control plane for cluster
-	if err = handler.ClusterControlPlaneInit(controlPlaneNode); err != nil {
-		return nil, err
-	}
-	// wait controlplane setup task success
-	if err = nodemanager.WaitNodesFinish([]string{controlPlaneNode.Address},
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
-		return nil, err
-	}
+	// Step1: setup infrastructure and run precreate cluster hooks for all nodes in the cluster
+	for _, n := range cc.Nodes {
+		node := n
+		nodeID := []string{node.Address}
+		s.AddStep(&nodemanager.Step{
+			Name:  stepMachineInfraSetup,
+			Nodes: nodeID,
+			Run:   func() error { return handler.MachineInfraSetup(node) },
+		})
+		s.AddStep(&nodemanager.Step{
+			Name:     stepPreCreateClusterHooks,
+			Nodes:    nodeID,
+			Requires: []nodemanager.Prerequisite{{Name: stepMachineInfraSetup, Nodes: nodeID}},
+			Run:      func() error { return handler.PreCreateClusterHooks([]*api.HostConfig{node}) },
+		})
+	}
+
+	// Step2: setup etcd cluster once etcd nodes are ready, other nodes are still in setup
+	etcdStep := &nodemanager.Step{
+		Name:  stepEtcdClusterSetup,
+		Nodes: etcdNodes,
+		Run:   handler.EtcdClusterSetup,
+	}
+	if len(etcdNodes) != 0 {
+		etcdStep.Requires = []nodemanager.Prerequisite{{Name: stepPreCreateClusterHooks, Nodes: etcdNodes}}
+	}
+	s.AddStep(etcdStep)
+
+	// Step3: setup loadbalance for cluster
+	cpRequires := []nodemanager.Prerequisite{
+		{Name: stepPreCreateClusterHooks, Nodes: cp},
+		{Name: stepEtcdClusterSetup},
+	}
+	if loadbalancer != nil {
+		lbID := []string{loadbalancer.Address}
+		critical = append(critical, loadbalancer.Address)
+		s.AddStep(&nodemanager.Step{
+			Name:     stepLoadBalancerSetup,
+			Nodes:    lbID,
+			Requires: []nodemanager.Prerequisite{{Name: stepPreCreateClusterHooks, Nodes: lbID}},
+			Run:      func() error { return handler.LoadBalancerSetup(loadbalancer) },
+		})
+		cpRequires = append(cpRequires, nodemanager.Prerequisite{Name: stepLoadBalancerSetup})
+	}
+
+	// Step4: setup control plane for cluster
+	s.AddStep(&nodemanager.Step{
+		Name:     stepClusterControlPlaneInit,
+		Nodes:    cp,
+		Requires: cpRequires,
+		Run:      func() error { return handler.ClusterControlPlaneInit(controlPlaneNode) },
+	})
 	if utils.IsType(controlPlaneNode.Type, api.Worker) {
-		controlPlaneNode.Type = utils.ClearType(controlPlaneNode.Type, api.Master)
-		if err = handler.ClusterNodeJoin(controlPlaneNode); err != nil {
+		worker, err := controlPlaneNode.DeepCopy()
+		if err != nil {
 			return nil, err
 		}
-	}
-
-	// Step6: setup left nodes for cluster
-	joinedNodeIDs, joinedNodes, failedNodes := doJoinNodeOfCluster(handler, cc, masters, workers)
-	if len(joinedNodeIDs) == 0 {
-		logrus.Warnln("all join nodes failed")
-	}
-
-	// Step7: setup addons for cluster
-	if err = handler.AddonsSetup(); err != nil {
-		return nil, err
-	}
-
-	// Step8: approve kubelet serving csr
-	approveServingCsr(cc, append(joinedNodes, controlPlaneNode))
-
-	// Step9: run postcreate cluster hooks
-	if err = handler.PostCreateClusterHooks(cc.Nodes); err != nil {
+		worker.Type = utils.ClearType(worker.Type, api.Master)
+		s.AddStep(&nodemanager.Step{
+			Name:     stepClusterNodeJoin,
+			Nodes:    cp,
+			Requires: []nodemanager.Prerequisite{{Name: stepClusterControlPlaneInit}},
+			Run:      func() error { return handler.ClusterNodeJoin(worker) },
+		})
+	}
+
+	// Step5: join left nodes to cluster
+	for _, n := range append(workers, masters...) {
+		node := n
+		nodeID := []string{node.Address}
+		s.AddStep(&nodemanager.Step{
+			Name:  stepClusterNodeJoin,
+			Nodes: nodeID,
+			Requires: []nodemanager.Prerequisite{
+				{Name: stepPreCreateClusterHooks, Nodes: nodeID},
+				{Name: stepClusterControlPlaneInit},
+			},
+			Run: func() error { return handler.ClusterNodeJoin(node) },
+		})
+	}
+
+	// Step6: setup addons for cluster after join finished, allow join nodes failed
+	s.AddStep(&nodemanager.Step{
+		Name: stepAddonsSetup,
+		Requires: []nodemanager.Prerequisite{
+			{Name: stepClusterControlPlaneInit},
+			{Name: stepClusterNodeJoin, AllowFailure: true},
+		},
+		Run: handler.AddonsSetup,
+	})
+
+	// Step7: approve kubelet serving csr and run postcreate cluster hooks on healthy nodes
+	s.AddStep(&nodemanager.Step{
+		Name:     stepPostCreateClusterHooks,
+		Requires: []nodemanager.Prerequisite{{Name: stepAddonsSetup}},
+		Run: func() error {
+			nodes := healthyNodes(cc.Nodes)
+			approveServingCsr(cc, nodes)
+			return handler.PostCreateClusterHooks(nodes)
+		},
+	})
+
+	results, err := s.Run()
+	if err != nil {
 		return nil, err
 	}
-
-	if err = nodemanager.WaitNodesFinishWithProgress(append(joinedNodeIDs, controlPlaneNode.Address),
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
+	failed, err := failedSteps(results, critical)
+	if err != nil {
 		return nil, err
 	}
 
-	for _, sid := range joinedNodeIDs {
-		cstatus.StatusOfNodes[sid] = true
+	var failedNodes []*api.HostConfig
+	for _, n := range cc.Nodes {
+		if failed[n.Address] {
+			failedNodes = append(failedNodes, n)
+			continue
+		}
+		cstatus.StatusOfNodes[n.Address] = true
 		cstatus.SuccessCnt += 1
 	}
 	cstatus.Working = true
@@ -241,6 +318,34 @@ func rollbackFailedNoeds(handler api.ClusterDeploymentAPI, nodes []*api.HostConf
 	}
 }
 
+// startRecord record tasks of operation into journal and timing report
+func startRecord(cc *api.ClusterConfig, op api.HookOperator, resume bool) error {
+	if err := nodemanager.StartJournal(api.GetJournalPath(cc.Name, op), string(op), resume); err != nil {
+		return err
+	}
+	nodemanager.StartReport(cc.Name, string(op))
+	return nil
+}
+
+// finishRecord save journal and timing report with result of operation,
+// report is not saved if home of cluster is removed
+func finishRecord(cc *api.ClusterConfig, err error) {
+	nodemanager.FinishJournal(err)
+
+	dir := api.GetReportDir(cc.Name)
+	if exist, cerr := utils.CheckPathExist(api.GetClusterHomePath(cc.Name)); cerr != nil || !exist {
+		dir = ""
+	}
+	base, rerr := nodemanager.FinishReport(dir, err)
+	if rerr != nil {
+		logrus.Warnf("[cluster] write timing report failed: %v", rerr)
+		return
+	}
+	if base != "" {
+		logrus.Infof("[cluster] timing report is saved in %s.json and %s.html", base, base)
+	}
+}
+
 func CreateCluster(cc *api.ClusterConfig, deployEnableRollback bool) (api.ClusterStatus, error) {
 	cstatus := api.ClusterStatus{
 		StatusOfNodes: make(map[string]bool),
@@ -266,21 +371,31 @@ func CreateCluster(cc *api.ClusterConfig, deployEnableRollback bool) (api.Cluste
 		return cstatus, err
 	}
 
+	// record tasks on nodes, so that failed deploy can be resumed
+	if err = startRecord(cc, api.HookOpDeploy, cc.Resume); err != nil {
+		return cstatus, err
+	}
+
 	failedNodes, err := doCreateCluster(handler, cc, &cstatus)
 	if err != nil {
+		finishRecord(cc, err)
+		cstatus.Message = err.Error()
+		if !deployEnableRollback {
+			logrus.Warnf("keep cluster: %s, resume it by 'eggo deploy --resume --id %s'", cc.Name, cc.Name)
+			return cstatus, err
+		}
+
 		doRemoveCluster(handler, cc)
 		if terr := os.RemoveAll(api.GetClusterHomePath(cc.Name)); terr != nil {
 			logrus.Warnf("[cluster] cleanup eggo config directory failed: %v", terr)
 		}
+		if terr := secrets.RemoveKeystore(cc.Name); terr != nil {
+			logrus.Warnf("[cluster] remove secrets of cluster in keystore failed: %v", terr)
+		}
 
 		logrus.Warnf("rollbacked cluster: %s", cc.Name)
-		cstatus.Message = err.Error()
 		return cstatus, err
 	}
-	// rollback failed nodes
-	if deployEnableRollback {
-		rollbackFailedNoeds(handler, failedNodes)
-	}
 	// update status of cluster
 	if failedNodes != nil {
 		var failureIDs []string
@@ -289,56 +404,74 @@ func CreateCluster(cc *api.ClusterConfig, deployEnableRollback bool) (api.Cluste
 			cstatus.StatusOfNodes[fid.Address] = false
 			cstatus.FailureCnt += 1
 		}
+		finishRecord(cc, fmt.Errorf("failed nodes: %v", failureIDs))
+		// rollback failed nodes
+		if deployEnableRollback {
+			rollbackFailedNoeds(handler, failedNodes)
+		}
 		logrus.Warnf("[cluster] failed nodes: %v", failureIDs)
 		cstatus.Message = "partial success of create cluster"
 		return cstatus, nil
 	}
 
+	finishRecord(cc, nil)
 	cstatus.Message = "create cluster success"
 	return cstatus, nil
 }
 
-func doJoinNode(handler api.ClusterDeploymentAPI, cc *api.ClusterConfig, hostconfig *api.HostConfig) error {
-	if err := handler.MachineInfraSetup(hostconfig); err != nil {
-		return err
-	}
-
-	// Pre node join Hooks
-	if err := handler.PreNodeJoinHooks(hostconfig); err != nil {
-		return err
-	}
-
-	// wait infrastructure task success on node
-	if err := nodemanager.WaitNodesFinish([]string{hostconfig.Address},
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
-		return err
-	}
-
-	// join etcd to cluster
+// addJoinNodeSteps add steps to join node, prevEtcd is the etcd node joined before this node
+func addJoinNodeSteps(s *nodemanager.Scheduler, handler api.ClusterDeploymentAPI, hostconfig *api.HostConfig, prevEtcd string) {
+	nodeID := []string{hostconfig.Address}
+	s.AddStep(&nodemanager.Step{
+		Name:  stepMachineInfraSetup,
+		Nodes: nodeID,
+		Run:   func() error { return handler.MachineInfraSetup(hostconfig) },
+	})
+	s.AddStep(&nodemanager.Step{
+		Name:     stepPreNodeJoinHooks,
+		Nodes:    nodeID,
+		Requires: []nodemanager.Prerequisite{{Name: stepMachineInfraSetup, Nodes: nodeID}},
+		Run:      func() error { return handler.PreNodeJoinHooks(hostconfig) },
+	})
+
+	joinRequires := []nodemanager.Prerequisite{{Name: stepPreNodeJoinHooks, Nodes: nodeID}}
 	if utils.IsType(hostconfig.Type, api.ETCD) {
-		if err := handler.EtcdNodeSetup(hostconfig); err != nil {
-			logrus.Errorf("add etcd %s failed: %v", hostconfig.Name, err)
-			return err
+		// add etcd members one by one
+		etcdRequires := []nodemanager.Prerequisite{{Name: stepPreNodeJoinHooks, Nodes: nodeID}}
+		if prevEtcd != "" {
+			etcdRequires = append(etcdRequires, nodemanager.Prerequisite{Name: stepEtcdNodeSetup,
+				Nodes: []string{prevEtcd}, AllowFailure: true})
 		}
-	}
-
-	// join node to cluster
-	if err := handler.ClusterNodeJoin(hostconfig); err != nil {
-		return err
-	}
-
-	// Post node join Hooks
-	if err := handler.PostNodeJoinHooks(hostconfig); err != nil {
-		return err
-	}
-
-	// wait node ready
-	if err := nodemanager.WaitNodesFinishWithProgress([]string{hostconfig.Address},
-		time.Minute*constants.DefaultTaskWaitMinutes); err != nil {
-		return err
-	}
-
-	return nil
+		s.AddStep(&nodemanager.Step{
+			Name:     stepEtcdNodeSetup,
+			Nodes:    nodeID,
+			Requires: etcdRequires,
+			Run: func() error {
+				if err := handler.EtcdNodeSetup(hostconfig); err != nil {
+					logrus.Errorf("add etcd %s failed: %v", hostconfig.Name, err)
+					return err
+				}
+				return nil
+			},
+		})
+		joinRequires = append(joinRequires, nodemanager.Prerequisite{Name: stepEtcdNodeSetup, Nodes: nodeID})
+	} else {
+		// join nodes without etcd after etcd members added
+		joinRequires = append(joinRequires, nodemanager.Prerequisite{Name: stepEtcdNodeSetup, AllowFailure: true})
+	}
+
+	s.AddStep(&nodemanager.Step{
+		Name:     stepClusterNodeJoin,
+		Nodes:    nodeID,
+		Requires: joinRequires,
+		Run:      func() error { return handler.ClusterNodeJoin(hostconfig) },
+	})
+	s.AddStep(&nodemanager.Step{
+		Name:     stepPostNodeJoinHooks,
+		Nodes:    nodeID,
+		Requires: []nodemanager.Prerequisite{{Name: stepClusterNodeJoin, Nodes: nodeID}},
+		Run:      func() error { return handler.PostNodeJoinHooks(hostconfig) },
+	})
 }
 
 func JoinNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) (api.ClusterStatus, error) {
@@ -362,64 +495,50 @@ func JoinNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) (api.Cluste
 	}
 	defer handler.Finish()
 
-	var withEtcd []*api.HostConfig
-	var withoutEtcd []*api.HostConfig
+	if err = startRecord(cc, api.HookOpJoin, false); err != nil {
+		return cstatus, err
+	}
+
+	// join nodes with etcd first, members of etcd are added one by one
+	s := nodemanager.NewScheduler(constants.DefaultStepConcurrency)
+	prevEtcd := ""
 	for _, h := range hostconfigs {
 		if utils.IsType(h.Type, api.ETCD) {
-			withEtcd = append(withEtcd, h)
-		} else {
-			withoutEtcd = append(withoutEtcd, h)
+			addJoinNodeSteps(s, handler, h, prevEtcd)
+			prevEtcd = h.Address
+		}
+	}
+	for _, h := range hostconfigs {
+		if !utils.IsType(h.Type, api.ETCD) {
+			addJoinNodeSteps(s, handler, h, "")
 		}
 	}
+	results, err := s.Run()
+	if err != nil {
+		finishRecord(cc, err)
+		return cstatus, err
+	}
+	failed, _ := failedSteps(results, nil)
 
-	var joinedNodeIDs []string
 	var joinedNodes []*api.HostConfig
 	var failedNodes []*api.HostConfig
-
-	// join nodes with etcd
-	for _, h := range withEtcd {
-		if err := doJoinNode(handler, cc, h); err != nil {
+	for _, h := range hostconfigs {
+		if failed[h.Address] {
 			failedNodes = append(failedNodes, h)
-			logrus.Errorf("join node with etcd failed: %v", err)
+			logrus.Infof("[cluster] join '%s' to cluster '%s' failed", h.Address, cc.Name)
 			continue
 		}
-		joinedNodeIDs = append(joinedNodeIDs, h.Address)
 		joinedNodes = append(joinedNodes, h)
-		logrus.Infof("[cluster] join '%s' with etcd to cluster successed", cc.Name)
-	}
-
-	// join nodes without etcd
-	var lock sync.Mutex
-	var wg sync.WaitGroup
-	wg.Add(len(withoutEtcd))
-	for _, h := range withoutEtcd {
-		go func(hostconfig *api.HostConfig) {
-			defer wg.Done()
-			if err := doJoinNode(handler, cc, hostconfig); err != nil {
-				lock.Lock()
-				failedNodes = append(failedNodes, hostconfig)
-				lock.Unlock()
-				logrus.Infof("[cluster] join '%s' to cluster failed: %v", cc.Name, err)
-				return
-			}
-			lock.Lock()
-			joinedNodeIDs = append(joinedNodeIDs, hostconfig.Address)
-			joinedNodes = append(joinedNodes, hostconfig)
-			lock.Unlock()
-			logrus.Infof("[cluster] join '%s' to cluster successed", cc.Name)
-		}(h)
-	}
-	wg.Wait()
-
-	for _, sid := range joinedNodeIDs {
-		cstatus.StatusOfNodes[sid] = true
+		cstatus.StatusOfNodes[h.Address] = true
 		cstatus.SuccessCnt += 1
+		logrus.Infof("[cluster] join '%s' to cluster '%s' successed", h.Address, cc.Name)
 	}
 
 	// approve kubelet serving csr
 	approveServingCsr(cc, joinedNodes)
 
 	if len(failedNodes) == 0 {
+		finishRecord(cc, nil)
 		cstatus.Message = "join nodes to cluster success"
 		return cstatus, nil
 	}
@@ -427,10 +546,6 @@ func JoinNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) (api.Cluste
 	var failureIDs []string
 	for _, fid := range failedNodes {
 		failureIDs = append(failureIDs, fid.Address)
-		success, ok := cstatus.StatusOfNodes[fid.Address]
-		if ok && success {
-			cstatus.SuccessCnt -= 1
-		}
 		cstatus.StatusOfNodes[fid.Address] = false
 		cstatus.FailureCnt += 1
 	}
@@ -441,7 +556,9 @@ func JoinNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) (api.Cluste
 	} else {
 		cstatus.Message = "failed to join nodes to cluster"
 	}
-	return cstatus, fmt.Errorf("some nodes failed to join to cluster")
+	err = fmt.Errorf("some nodes failed to join to cluster")
+	finishRecord(cc, err)
+	return cstatus, err
 }
 
 func doDeleteNode(handler api.ClusterDeploymentAPI, cc *api.ClusterConfig, h *api.HostConfig) error {
@@ -505,6 +622,13 @@ func DeleteNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) error {
 	}
 	defer handler.Finish()
 
+	if err = startRecord(cc, api.HookOpDelete, false); err != nil {
+		return err
+	}
+	defer func() {
+		finishRecord(cc, err)
+	}()
+
 	var nodes []*api.HostConfig
 	var etcds []*api.HostConfig
 	for _, h := range hostconfigs {
@@ -521,7 +645,10 @@ func DeleteNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) error {
 	for _, h := range nodes {
 		go func(hostconfig *api.HostConfig) {
 			defer wg.Done()
-			if terr := doDeleteNode(handler, cc, hostconfig); terr != nil {
+			phaseDone := nodemanager.StartPhase("DeleteNode", []string{hostconfig.Address})
+			terr := doDeleteNode(handler, cc, hostconfig)
+			phaseDone(terr)
+			if terr != nil {
 				logrus.Errorf("[cluster] delete '%s' from cluster failed", hostconfig.Name)
 				return
 			}
@@ -532,7 +659,10 @@ func DeleteNodes(cc *api.ClusterConfig, hostconfigs []*api.HostConfig) error {
 
 	// delete node with etcds
 	for _, h := range etcds {
-		if err = doDeleteNode(handler, cc, h); err != nil {
+		phaseDone := nodemanager.StartPhase("DeleteNode", []string{h.Address})
+		err = doDeleteNode(handler, cc, h)
+		phaseDone(err)
+		if err != nil {
 			logrus.Errorf("[cluster] delete '%s' with etcd from cluster failed", h.Name)
 			return err
 		}
@@ -635,8 +765,13 @@ func RemoveCluster(cc *api.ClusterConfig) error {
 	defer handler.Finish()
 
 	// cleanup cluster
+	phaseDone := nodemanager.StartPhase("CleanupCluster", utils.GetAllIPs(cc.Nodes))
 	doRemoveCluster(handler, cc)
+	phaseDone(nil)
 
+	if err := secrets.RemoveKeystore(cc.Name); err != nil {
+		logrus.Warnf("[cluster] remove secrets of cluster in keystore failed: %v", err)
+	}
 	// cleanup eggo config directory
 	if err := os.RemoveAll(api.GetClusterHomePath(cc.Name)); err != nil {
 		logrus.Warnf("[cluster] cleanup eggo config directory failed: %v", err)
@@ -645,3 +780,164 @@ func RemoveCluster(cc *api.ClusterConfig) error {
 	logrus.Infof("[cluster] remove cluster '%s' successed", cc.Name)
 	return nil
 }
+
+func GetClusterStatus(cc *api.ClusterConfig) (*api.ClusterStatus, error) {
+	if cc == nil {
+		return nil, fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	// unreachable nodes are reported instead of failing the whole command
+	tolerant := *cc
+	tolerant.SkipUnreachable = true
+	handler, err := creator(&tolerant)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	defer handler.Finish()
+
+	return handler.ClusterStatus()
+}
+
+func UpgradeCluster(cc *api.ClusterConfig) error {
+	if cc == nil {
+		return fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	handler, err := creator(cc)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	defer handler.Finish()
+
+	if err = handler.ClusterUpgrade(); err != nil {
+		logrus.Errorf("[cluster] upgrade cluster '%s' failed: %v", cc.Name, err)
+		return err
+	}
+	logrus.Infof("[cluster] upgrade cluster '%s' successed", cc.Name)
+	return nil
+}
+
+func BackupEtcd(cc *api.ClusterConfig, backupDir string, retain int) (string, error) {
+	if cc == nil {
+		return "", fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return "", err
+	}
+	handler, err := creator(cc)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return "", err
+	}
+	defer handler.Finish()
+
+	snapshot, err := handler.EtcdBackup(backupDir, retain)
+	if err != nil {
+		logrus.Errorf("[cluster] backup etcd of cluster '%s' failed: %v", cc.Name, err)
+		return "", err
+	}
+	logrus.Infof("[cluster] backup etcd of cluster '%s' successed", cc.Name)
+	return snapshot, nil
+}
+
+func RestoreEtcd(cc *api.ClusterConfig, snapshot string) error {
+	if cc == nil {
+		return fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	handler, err := creator(cc)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	defer handler.Finish()
+
+	if err = handler.EtcdRestore(snapshot); err != nil {
+		logrus.Errorf("[cluster] restore etcd of cluster '%s' failed: %v", cc.Name, err)
+		return err
+	}
+	logrus.Infof("[cluster] restore etcd of cluster '%s' successed", cc.Name)
+	return nil
+}
+
+func CheckCertsExpiration(cc *api.ClusterConfig) ([]*api.CertExpiration, error) {
+	if cc == nil {
+		return nil, fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	handler, err := creator(cc)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	defer handler.Finish()
+
+	return handler.ClusterCertsExpiration()
+}
+
+func RenewCerts(cc *api.ClusterConfig, targets []string) error {
+	if cc == nil {
+		return fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	handler, err := creator(cc)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return err
+	}
+	defer handler.Finish()
+
+	if err = handler.ClusterCertsRenew(targets); err != nil {
+		logrus.Errorf("[cluster] renew certificates of cluster '%s' failed: %v", cc.Name, err)
+		return err
+	}
+	logrus.Infof("[cluster] renew certificates of cluster '%s' successed", cc.Name)
+	return nil
+}
+
+// Preflight check nodes before deploy cluster or join them to cluster
+func Preflight(cc *api.ClusterConfig, nodes []*api.HostConfig) ([]*api.PreflightError, error) {
+	if cc == nil {
+		return nil, fmt.Errorf("cluster config is required")
+	}
+	creator, err := manager.GetClusterDeploymentDriver(cc.DeployDriver)
+	if err != nil {
+		logrus.Errorf("[cluster] get cluster deployment driver: %s failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	// unreachable nodes are reported instead of failing the whole command
+	tolerant := *cc
+	tolerant.SkipUnreachable = true
+	handler, err := creator(&tolerant)
+	if err != nil {
+		logrus.Errorf("[cluster] create cluster deployment instance with driver: %s, failed: %v", cc.DeployDriver, err)
+		return nil, err
+	}
+	defer handler.Finish()
+
+	return handler.ClusterPreflight(nodes)
+}
@@ -16,10 +16,16 @@
 package clusterdeployment
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/clusterdeployment/manager"
+	"isula.org/eggo/pkg/utils/nodemanager"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
 )
 
 func TestRegisterControlPlaneDriver(t *testing.T) {
@@ -46,3 +52,164 @@ func TestGetControlPlaneDriver(t *testing.T) {
 		t.Fatal("expect err is not nil")
 	}
 }
+
+type mockRunner struct {
+}
+
+func (m *mockRunner) Copy(src, dst string) error {
+	return nil
+}
+
+func (m *mockRunner) RunCommand(cmd string) (string, error) {
+	return "", nil
+}
+
+func (m *mockRunner) RunShell(shell string, name string) (string, error) {
+	return "", nil
+}
+
+func (m *mockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
+func (m *mockRunner) Reconnect() error {
+	return nil
+}
+
+func (m *mockRunner) Close() {
+}
+
+type failTask struct {
+}
+
+func (f *failTask) Name() string {
+	return "failTask"
+}
+
+func (f *failTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	return fmt.Errorf("task failed")
+}
+
+// mockHandler record steps, and setup infrastructure failed on failInfra nodes
+type mockHandler struct {
+	api.ClusterDeploymentAPI
+	failInfra map[string]bool
+
+	lock  sync.Mutex
+	steps []string
+}
+
+func (m *mockHandler) record(step string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.steps = append(m.steps, step)
+}
+
+func (m *mockHandler) MachineInfraSetup(hcf *api.HostConfig) error {
+	if err := nodemanager.RegisterNode(hcf, &mockRunner{}); err != nil {
+		return err
+	}
+	if m.failInfra[hcf.Address] {
+		return nodemanager.RunTaskOnNodes(task.NewTaskInstance(&failTask{}), []string{hcf.Address})
+	}
+	m.record("infra-" + hcf.Address)
+	return nil
+}
+
+func (m *mockHandler) PreCreateClusterHooks(nodes []*api.HostConfig) error {
+	return nil
+}
+
+func (m *mockHandler) EtcdClusterSetup() error {
+	m.record("etcd")
+	return nil
+}
+
+func (m *mockHandler) LoadBalancerSetup(lb *api.HostConfig) error {
+	m.record("loadbalance")
+	return nil
+}
+
+func (m *mockHandler) ClusterControlPlaneInit(master *api.HostConfig) error {
+	m.record("controlplane")
+	return nil
+}
+
+func (m *mockHandler) ClusterNodeJoin(node *api.HostConfig) error {
+	m.record("join-" + node.Address)
+	return nil
+}
+
+func (m *mockHandler) AddonsSetup() error {
+	m.record("addons")
+	return nil
+}
+
+func (m *mockHandler) PostCreateClusterHooks(nodes []*api.HostConfig) error {
+	return nil
+}
+
+func (m *mockHandler) index(step string) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i, s := range m.steps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestDoCreateCluster(t *testing.T) {
+	cc := &api.ClusterConfig{
+		Nodes: []*api.HostConfig{
+			{Name: "master", Address: "192.168.0.2", Type: api.Master | api.Worker | api.ETCD},
+			{Name: "worker1", Address: "192.168.0.3", Type: api.Worker},
+			{Name: "worker2", Address: "192.168.0.4", Type: api.Worker},
+			{Name: "lb", Address: "192.168.0.5", Type: api.LoadBalance},
+		},
+	}
+
+	// failure of worker only fail itself
+	handler := &mockHandler{failInfra: map[string]bool{"192.168.0.4": true}}
+	cstatus := &api.ClusterStatus{StatusOfNodes: make(map[string]bool)}
+	failed, err := doCreateCluster(handler, cc, cstatus)
+	nodemanager.UnRegisterAllNodes()
+	if err != nil {
+		t.Fatalf("create cluster failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Address != "192.168.0.4" || cstatus.SuccessCnt != 3 || !cstatus.Working {
+		t.Fatalf("invalid result: %v, %+v", failed, cstatus)
+	}
+	if handler.index("join-192.168.0.4") != -1 {
+		t.Fatalf("failed worker should not join")
+	}
+	for _, order := range [][]string{
+		{"infra-192.168.0.2", "etcd"},
+		{"etcd", "controlplane"},
+		{"loadbalance", "controlplane"},
+		{"controlplane", "join-192.168.0.2"},
+		{"controlplane", "join-192.168.0.3"},
+		{"join-192.168.0.3", "addons"},
+	} {
+		first, second := handler.index(order[0]), handler.index(order[1])
+		if first == -1 || second == -1 || first > second {
+			t.Fatalf("expect %s before %s: %v", order[0], order[1], handler.steps)
+		}
+	}
+
+	// failure of etcd fail the cluster
+	handler = &mockHandler{failInfra: map[string]bool{"192.168.0.2": true}}
+	cstatus = &api.ClusterStatus{StatusOfNodes: make(map[string]bool)}
+	_, err = doCreateCluster(handler, cc, cstatus)
+	nodemanager.UnRegisterAllNodes()
+	if err == nil {
+		t.Fatalf("create cluster with failed etcd should fail")
+	}
+	if handler.index("etcd") != -1 || handler.index("join-192.168.0.3") != -1 {
+		t.Fatalf("steps depend on etcd should be skipped: %v", handler.steps)
+	}
+	if handler.index("infra-192.168.0.3") == -1 || handler.index("loadbalance") == -1 {
+		t.Fatalf("steps independent of etcd should run: %v", handler.steps)
+	}
+}
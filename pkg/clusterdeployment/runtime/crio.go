@@ -0,0 +1,275 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: cri-o container runtime
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/clusterdeployment/binary/commontools"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/template"
+)
+
+const (
+	crioConfigPath     = "/etc/crio/crio.conf.d/10-eggo.conf"
+	crioRegistriesPath = "/etc/containers/registries.conf.d/10-eggo.conf"
+	// cri-o use cgroup manager same as cgroup driver of kubelet
+	kubeletCgroupDriverArg = "--cgroup-driver"
+	defaultCgroupDriver    = "cgroupfs"
+)
+
+type crioRuntime struct {
+}
+
+func (cr *crioRuntime) GetRuntimeSoftwares() []string {
+	return []string{"podman", "crio"}
+}
+
+// GetRuntimeClient return podman, cri-o has no client to load images,
+// podman share images with cri-o in containers storage
+func (cr *crioRuntime) GetRuntimeClient() string {
+	return "podman"
+}
+
+func (cr *crioRuntime) GetRuntimeLoadImageCommand() string {
+	return "podman load -i"
+}
+
+func (cr *crioRuntime) GetRuntimeService() string {
+	return "crio"
+}
+
+func (cr *crioRuntime) GetRuntimeEndpoint() string {
+	return "unix:///var/run/crio/crio.sock"
+}
+
+func (cr *crioRuntime) PrepareRuntimeService(r runner.Runner, workerConfig *api.WorkerConfig) error {
+	crioConf, err := crioConfig(workerConfig)
+	if err != nil {
+		return err
+	}
+	registriesConf, err := crioRegistriesConfig(workerConfig.ContainerEngineConf)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p /etc/crio/crio.conf.d /etc/containers/registries.conf.d && echo %s | base64 -d > %s && echo %s | base64 -d > %s\"",
+		base64.StdEncoding.EncodeToString([]byte(crioConf)), crioConfigPath,
+		base64.StdEncoding.EncodeToString([]byte(registriesConf)), crioRegistriesPath))
+	if _, err = r.RunCommand(sb.String()); err != nil {
+		logrus.Errorf("write cri-o config failed: %v", err)
+		return err
+	}
+
+	service := `[Unit]
+Description=Container Runtime Interface for OCI (CRI-O)
+Documentation=https://github.com/cri-o/cri-o
+Wants=network-online.target
+Before=kubelet.service
+After=network-online.target
+
+[Service]
+Type=notify
+EnvironmentFile=-/etc/sysconfig/crio
+Environment=GOTRACEBACK=crash
+ExecStart=/usr/bin/crio \
+        $CRIO_CONFIG_OPTIONS \
+        $CRIO_RUNTIME_OPTIONS \
+        $CRIO_STORAGE_OPTIONS \
+        $CRIO_NETWORK_OPTIONS \
+        $CRIO_METRICS_OPTIONS
+ExecReload=/bin/kill -s HUP $MAINPID
+TasksMax=infinity
+LimitNOFILE=1048576
+LimitNPROC=1048576
+LimitCORE=infinity
+OOMScoreAdjust=-999
+TimeoutStartSec=0
+Restart=on-abnormal
+
+[Install]
+WantedBy=multi-user.target
+`
+
+	serviceBase64 := base64.StdEncoding.EncodeToString([]byte(service))
+	shell, err := commontools.GetSystemdServiceShell("crio", serviceBase64, true)
+	if err != nil {
+		logrus.Errorf("get cri-o systemd service file failed: %v", err)
+		return err
+	}
+
+	_, err = r.RunShell(shell, "crioService")
+	if err != nil {
+		logrus.Errorf("create cri-o service failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (cr *crioRuntime) GetRemovedPath() []string {
+	return []string{
+		"/usr/lib/systemd/system/crio.service",
+		crioConfigPath,
+		crioRegistriesPath,
+	}
+}
+
+type crioTable struct {
+	Name    string
+	Options []string
+}
+
+// crioConfig return drop-in config of cri-o, extra args of container engine are options of config,
+// key is table and option, such as crio.runtime.default_runtime, value is in toml, such as "\"crun\""
+func crioConfig(workerConfig *api.WorkerConfig) (string, error) {
+	crioConfig := `
+{{- range $i, $t := .tables }}
+[{{ $t.Name }}]
+{{- range $j, $o := $t.Options }}
+{{ $o }}
+{{- end }}
+{{ end }}
+`
+
+	pauseImage, cniBinDir, cniConfDir := "k8s.gcr.io/pause:3.2", "/opt/cni/bin", "/etc/cni/net.d"
+	cgroupDriver := defaultCgroupDriver
+	if workerConfig.KubeletConf != nil {
+		if workerConfig.KubeletConf.PauseImage != "" {
+			pauseImage = workerConfig.KubeletConf.PauseImage
+		}
+		if workerConfig.KubeletConf.CniBinDir != "" {
+			cniBinDir = workerConfig.KubeletConf.CniBinDir
+		}
+		if workerConfig.KubeletConf.CniConfDir != "" {
+			cniConfDir = workerConfig.KubeletConf.CniConfDir
+		}
+		if d, ok := workerConfig.KubeletConf.ExtraArgs[kubeletCgroupDriverArg]; ok && d != "" {
+			cgroupDriver = d
+		}
+	}
+	// conmon must be in cgroup of pod with cgroupfs, and in slice with systemd
+	conmonCgroup := "pod"
+	if cgroupDriver == "systemd" {
+		conmonCgroup = "system.slice"
+	}
+
+	options := map[string]map[string]string{
+		"crio.runtime": {
+			"cgroup_manager": fmt.Sprintf("%q", cgroupDriver),
+			"conmon_cgroup":  fmt.Sprintf("%q", conmonCgroup),
+		},
+		"crio.image": {
+			"pause_image": fmt.Sprintf("%q", pauseImage),
+		},
+		"crio.network": {
+			"network_dir": fmt.Sprintf("%q", cniConfDir),
+			"plugin_dirs": fmt.Sprintf("[%q]", cniBinDir),
+		},
+	}
+	if workerConfig.ContainerEngineConf != nil {
+		for k, v := range workerConfig.ContainerEngineConf.ExtraArgs {
+			table, option := "crio", k
+			if i := strings.LastIndex(k, "."); i != -1 {
+				table, option = k[:i], k[i+1:]
+			}
+			if option == "" || !strings.HasPrefix(table, "crio") {
+				return "", fmt.Errorf("invalid option of cri-o: %s, such as crio.runtime.default_runtime", k)
+			}
+			if _, ok := options[table]; !ok {
+				options[table] = make(map[string]string)
+			}
+			options[table][option] = v
+		}
+	}
+
+	var tables []crioTable
+	for name, opts := range options {
+		t := crioTable{Name: name}
+		for k, v := range opts {
+			t.Options = append(t.Options, fmt.Sprintf("%s = %s", k, v))
+		}
+		sort.Strings(t.Options)
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	datastore := map[string]interface{}{}
+	datastore["tables"] = tables
+	return template.TemplateRender(crioConfig, datastore)
+}
+
+// crioRegistriesConfig return drop-in of containers registries.conf, registry mirrors are mirrors of docker.io
+func crioRegistriesConfig(engine *api.ContainerEngine) (string, error) {
+	registriesConfig := `
+{{- $alen := len .mirrors }}
+{{- if ne $alen 0 }}
+[[registry]]
+prefix = "docker.io"
+location = "docker.io"
+{{- if index .insecureSet "docker.io" }}
+insecure = true
+{{- end }}
+{{- range $i, $v := .mirrors }}
+[[registry.mirror]]
+location = "{{ $v }}"
+{{- if index $.insecureSet $v }}
+insecure = true
+{{- end }}
+{{- end }}
+{{ end }}
+{{- range $i, $v := .insecure }}
+[[registry]]
+location = "{{ $v }}"
+insecure = true
+{{ end }}
+`
+
+	var mirrors, insecure []string
+	insecureSet := make(map[string]bool)
+	if engine != nil {
+		for _, i := range engine.InsecureRegistries {
+			deflash := strings.TrimPrefix(strings.TrimPrefix(i, "http://"), "https://")
+			insecureSet[deflash] = true
+		}
+		for _, m := range engine.RegistryMirrors {
+			deflash := strings.TrimPrefix(strings.TrimPrefix(m, "http://"), "https://")
+			if deflash == "docker.io" {
+				continue
+			}
+			mirrors = append(mirrors, deflash)
+		}
+	}
+	for i := range insecureSet {
+		if i == "docker.io" && len(mirrors) != 0 {
+			continue
+		}
+		insecure = append(insecure, i)
+	}
+	sort.Strings(insecure)
+
+	datastore := map[string]interface{}{}
+	datastore["mirrors"] = mirrors
+	datastore["insecure"] = insecure
+	datastore["insecureSet"] = insecureSet
+	return template.TemplateRender(registriesConfig, datastore)
+}
@@ -0,0 +1,93 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: cri-o container runtime testcase
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestCrioConfig(t *testing.T) {
+	wc := &api.WorkerConfig{
+		KubeletConf: &api.Kubelet{
+			PauseImage: "k8s.gcr.io/pause:3.5",
+			ExtraArgs:  map[string]string{"--cgroup-driver": "systemd"},
+		},
+		ContainerEngineConf: &api.ContainerEngine{
+			ExtraArgs: map[string]string{
+				"crio.runtime.default_runtime": `"crun"`,
+				"crio.image.pause_image":       `"example.com/pause:3.5"`,
+			},
+		},
+	}
+	conf, err := crioConfig(wc)
+	if err != nil {
+		t.Fatalf("render cri-o config failed: %v", err)
+	}
+	expects := []string{
+		"[crio.image]\npause_image = \"example.com/pause:3.5\"\n",
+		"[crio.network]\nnetwork_dir = \"/etc/cni/net.d\"\nplugin_dirs = [\"/opt/cni/bin\"]\n",
+		"[crio.runtime]\ncgroup_manager = \"systemd\"\nconmon_cgroup = \"system.slice\"\ndefault_runtime = \"crun\"\n",
+	}
+	for _, e := range expects {
+		if !strings.Contains(conf, e) {
+			t.Fatalf("expect %q in cri-o config:\n%s", e, conf)
+		}
+	}
+
+	wc.ContainerEngineConf.ExtraArgs = map[string]string{"runtime.": "true"}
+	if _, err = crioConfig(wc); err == nil {
+		t.Fatalf("invalid option of cri-o should fail")
+	}
+}
+
+func TestCrioRegistriesConfig(t *testing.T) {
+	conf, err := crioRegistriesConfig(&api.ContainerEngine{
+		RegistryMirrors:    []string{"https://mirror.example.com", "docker.io"},
+		InsecureRegistries: []string{"http://mirror.example.com", "registry.local:5000"},
+	})
+	if err != nil {
+		t.Fatalf("render registries config failed: %v", err)
+	}
+	expects := []string{
+		"[[registry]]\nprefix = \"docker.io\"\nlocation = \"docker.io\"\n[[registry.mirror]]\nlocation = \"mirror.example.com\"\ninsecure = true\n",
+		"[[registry]]\nlocation = \"registry.local:5000\"\ninsecure = true\n",
+	}
+	for _, e := range expects {
+		if !strings.Contains(conf, e) {
+			t.Fatalf("expect %q in registries config:\n%s", e, conf)
+		}
+	}
+
+	conf, err = crioRegistriesConfig(&api.ContainerEngine{})
+	if err != nil || strings.TrimSpace(conf) != "" {
+		t.Fatalf("expect empty registries config: %q, %v", conf, err)
+	}
+}
+
+func TestGetRuntimeEndpoint(t *testing.T) {
+	if GetRuntime("CRI-O").GetRuntimeEndpoint() != "unix:///var/run/crio/crio.sock" {
+		t.Fatalf("invalid endpoint of cri-o")
+	}
+	if name := GetRuntimeByEndpoint("unix:///var/run/isulad.sock"); name != "isulad" {
+		t.Fatalf("expect isulad, get: %s", name)
+	}
+	if name := GetRuntimeByEndpoint("unix:///var/run/unknown.sock"); name != "" {
+		t.Fatalf("expect no runtime, get: %s", name)
+	}
+}
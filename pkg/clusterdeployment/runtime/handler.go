@@ -0,0 +1,207 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: runtime handlers of container engine, such as kata and stratovirt
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/dependency"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/template"
+)
+
+const (
+	isuladDaemonConfig   = "/etc/isulad/daemon.json"
+	containerdConfigPath = "/etc/containerd/config.toml"
+	// RuntimeHandlerLabelFormat is label of nodes with runtime handler, RuntimeClass select nodes by it
+	RuntimeHandlerLabelFormat = "runtime-handler.eggo.isula.org/%s"
+)
+
+// HandlerRuntime is container engine which support runtime handlers
+type HandlerRuntime interface {
+	// RegisterRuntimeHandlers add handlers to config of engine, engine is restarted after it
+	RegisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error
+	// UnregisterRuntimeHandlers remove handlers from config of engine
+	UnregisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error
+}
+
+// NodeRuntimeHandlers return runtime handlers on node
+func NodeRuntimeHandlers(engine *api.ContainerEngine, name string) []*api.RuntimeHandler {
+	var handlers []*api.RuntimeHandler
+	if engine == nil {
+		return handlers
+	}
+	for _, h := range engine.RuntimeHandlers {
+		if h.OnNode(name) {
+			handlers = append(handlers, h)
+		}
+	}
+	return handlers
+}
+
+// NodeRuntimeHandlerLabels return labels of node for runtime handlers on it
+func NodeRuntimeHandlerLabels(engine *api.ContainerEngine, name string) map[string]string {
+	labels := make(map[string]string)
+	for _, h := range NodeRuntimeHandlers(engine, name) {
+		labels[fmt.Sprintf(RuntimeHandlerLabelFormat, h.Name)] = "true"
+	}
+	return labels
+}
+
+func handlerPackages(handlers []*api.RuntimeHandler) *api.RoleInfra {
+	infra := &api.RoleInfra{}
+	for _, h := range handlers {
+		infra.Softwares = append(infra.Softwares, h.Packages...)
+	}
+	return infra
+}
+
+// prepareRuntimeHandlers install packages of handlers on node, and register handlers to container engine
+func prepareRuntimeHandlers(r runner.Runner, rt Runtime, handlers []*api.RuntimeHandler,
+	packageSrc *api.PackageSrcConfig, hcg *api.HostConfig) error {
+	if len(handlers) == 0 {
+		return nil
+	}
+	hr, ok := rt.(HandlerRuntime)
+	if !ok {
+		return fmt.Errorf("container engine %s not support runtime handlers", rt.GetRuntimeService())
+	}
+
+	if err := dependency.InstallBaseDependency(r, handlerPackages(handlers), hcg, packageSrc.GetPkgDstPath()); err != nil {
+		logrus.Errorf("install packages of runtime handlers failed: %v", err)
+		return err
+	}
+	if err := hr.RegisterRuntimeHandlers(r, handlers); err != nil {
+		logrus.Errorf("register runtime handlers failed: %v", err)
+		return err
+	}
+	if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"systemctl restart %s\"", rt.GetRuntimeService())); err != nil {
+		logrus.Errorf("restart %s failed: %v", rt.GetRuntimeService(), err)
+		return err
+	}
+	return nil
+}
+
+// RemoveRuntimeHandlers unregister runtime handlers on node from container engine, and remove their packages
+func RemoveRuntimeHandlers(r runner.Runner, ccfg *api.ClusterConfig, hcf *api.HostConfig) {
+	handlers := NodeRuntimeHandlers(ccfg.WorkerConfig.ContainerEngineConf, hcf.Name)
+	if len(handlers) == 0 {
+		return
+	}
+	if hr, ok := GetRuntime(ccfg.WorkerConfig.ContainerEngineConf.Runtime).(HandlerRuntime); ok {
+		if err := hr.UnregisterRuntimeHandlers(r, handlers); err != nil {
+			logrus.Warnf("unregister runtime handlers failed: %v", err)
+		}
+	}
+	dependency.RemoveBaseDependency(r, handlerPackages(handlers), hcf, ccfg.PackageSrc.GetPkgDstPath())
+}
+
+// mergeIsuladRuntimes add or remove runtimes of handlers in daemon.json of isulad, other configs are kept
+func mergeIsuladRuntimes(content string, handlers []*api.RuntimeHandler, remove bool) (string, error) {
+	config := make(map[string]interface{})
+	if strings.TrimSpace(content) != "" {
+		if err := json.Unmarshal([]byte(content), &config); err != nil {
+			return "", fmt.Errorf("invalid %s: %v", isuladDaemonConfig, err)
+		}
+	}
+	runtimes, ok := config["runtimes"].(map[string]interface{})
+	if !ok {
+		runtimes = make(map[string]interface{})
+	}
+	for _, h := range handlers {
+		if remove {
+			delete(runtimes, h.Name)
+			continue
+		}
+		args := h.RuntimeArgs
+		if args == nil {
+			args = []string{}
+		}
+		runtimes[h.Name] = map[string]interface{}{
+			"path":         h.Path,
+			"runtime-args": args,
+		}
+	}
+	config["runtimes"] = runtimes
+
+	data, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func updateIsuladRuntimes(r runner.Runner, handlers []*api.RuntimeHandler, remove bool) error {
+	content, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"cat %s 2>/dev/null || true\"", isuladDaemonConfig))
+	if err != nil {
+		return fmt.Errorf("read %s failed: %v", isuladDaemonConfig, err)
+	}
+	config, err := mergeIsuladRuntimes(content, handlers, remove)
+	if err != nil {
+		return err
+	}
+	_, err = r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p /etc/isulad && echo %s | base64 -d > %s\"",
+		base64.StdEncoding.EncodeToString([]byte(config)), isuladDaemonConfig))
+	return err
+}
+
+func (ir *isuladRuntime) RegisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error {
+	return updateIsuladRuntimes(r, handlers, false)
+}
+
+func (ir *isuladRuntime) UnregisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error {
+	return updateIsuladRuntimes(r, handlers, true)
+}
+
+// containerdHandlersConfig return runtimes of cri plugin in containerd config, same version as config of eggo
+func containerdHandlersConfig(handlers []*api.RuntimeHandler) (string, error) {
+	handlersConfig := `
+{{- range $i, $h := .handlers }}
+[plugins.cri.containerd.runtimes.{{ $h.Name }}]
+  runtime_type = "{{ $h.RuntimeType }}"
+{{- if $h.ConfigPath }}
+[plugins.cri.containerd.runtimes.{{ $h.Name }}.options]
+  ConfigPath = "{{ $h.ConfigPath }}"
+{{- end }}
+{{- end }}
+`
+
+	datastore := map[string]interface{}{}
+	datastore["handlers"] = handlers
+	return template.TemplateRender(handlersConfig, datastore)
+}
+
+func (cr *containerdRuntime) RegisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error {
+	config, err := containerdHandlersConfig(handlers)
+	if err != nil {
+		return err
+	}
+	// config is created by PrepareRuntimeService before, so append handlers to it
+	_, err = r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"echo %s | base64 -d >> %s\"",
+		base64.StdEncoding.EncodeToString([]byte(config)), containerdConfigPath))
+	return err
+}
+
+func (cr *containerdRuntime) UnregisterRuntimeHandlers(r runner.Runner, handlers []*api.RuntimeHandler) error {
+	// config of containerd is created by eggo, and removed with handlers in it
+	return nil
+}
@@ -0,0 +1,112 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: runtime handlers testcase
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestMergeIsuladRuntimes(t *testing.T) {
+	handlers := []*api.RuntimeHandler{
+		{Name: "kata", Path: "/usr/bin/kata-runtime", RuntimeArgs: []string{"--debug"}},
+		{Name: "stratovirt", Path: "/usr/bin/kata-runtime"},
+	}
+	old := `{"log-level": "ERROR", "runtimes": {"lcr": {"path": "/usr/bin/lcr"}}}`
+	content, err := mergeIsuladRuntimes(old, handlers, false)
+	if err != nil {
+		t.Fatalf("merge runtimes failed: %v", err)
+	}
+
+	config := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		t.Fatalf("invalid merged config: %v", err)
+	}
+	if config["log-level"] != "ERROR" {
+		t.Fatalf("other configs of isulad are lost: %s", content)
+	}
+	runtimes := config["runtimes"].(map[string]interface{})
+	if len(runtimes) != 3 {
+		t.Fatalf("expect 3 runtimes, get: %s", content)
+	}
+	kata := runtimes["kata"].(map[string]interface{})
+	if kata["path"] != "/usr/bin/kata-runtime" || len(kata["runtime-args"].([]interface{})) != 1 {
+		t.Fatalf("invalid kata runtime: %v", kata)
+	}
+
+	content, err = mergeIsuladRuntimes(content, handlers, true)
+	if err != nil {
+		t.Fatalf("remove runtimes failed: %v", err)
+	}
+	if strings.Contains(content, "kata") || !strings.Contains(content, "lcr") {
+		t.Fatalf("remove runtimes failed: %s", content)
+	}
+
+	if _, err = mergeIsuladRuntimes("", handlers, false); err != nil {
+		t.Fatalf("merge runtimes without daemon.json failed: %v", err)
+	}
+	if _, err = mergeIsuladRuntimes("{invalid", handlers, false); err == nil {
+		t.Fatalf("merge runtimes with invalid daemon.json success")
+	}
+}
+
+func TestContainerdHandlersConfig(t *testing.T) {
+	handlers := []*api.RuntimeHandler{
+		{Name: "kata", RuntimeType: "io.containerd.kata.v2", ConfigPath: "/etc/kata-containers/configuration.toml"},
+		{Name: "runsc", RuntimeType: "io.containerd.runsc.v1"},
+	}
+	conf, err := containerdHandlersConfig(handlers)
+	if err != nil {
+		t.Fatalf("render containerd runtimes failed: %v", err)
+	}
+	for _, expect := range []string{
+		"[plugins.cri.containerd.runtimes.kata]",
+		`runtime_type = "io.containerd.kata.v2"`,
+		"[plugins.cri.containerd.runtimes.kata.options]",
+		`ConfigPath = "/etc/kata-containers/configuration.toml"`,
+		"[plugins.cri.containerd.runtimes.runsc]",
+	} {
+		if !strings.Contains(conf, expect) {
+			t.Fatalf("expect %s in config: %s", expect, conf)
+		}
+	}
+	if strings.Contains(conf, "runsc.options") {
+		t.Fatalf("unexpect options of runsc: %s", conf)
+	}
+}
+
+func TestNodeRuntimeHandlerLabels(t *testing.T) {
+	engine := &api.ContainerEngine{
+		RuntimeHandlers: []*api.RuntimeHandler{
+			{Name: "kata", Nodes: []string{"worker0"}},
+			{Name: "runsc"},
+		},
+	}
+	labels := NodeRuntimeHandlerLabels(engine, "worker0")
+	if len(labels) != 2 || labels["runtime-handler.eggo.isula.org/kata"] != "true" {
+		t.Fatalf("invalid labels of worker0: %v", labels)
+	}
+	labels = NodeRuntimeHandlerLabels(engine, "worker1")
+	if len(labels) != 1 || labels["runtime-handler.eggo.isula.org/runsc"] != "true" {
+		t.Fatalf("invalid labels of worker1: %v", labels)
+	}
+	if len(NodeRuntimeHandlers(nil, "worker0")) != 0 {
+		t.Fatalf("expect no handlers without container engine")
+	}
+}
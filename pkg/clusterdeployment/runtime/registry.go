@@ -0,0 +1,447 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: hosts config and credentials of registries for container engines
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/secrets"
+	"isula.org/eggo/pkg/utils/template"
+)
+
+const (
+	containerdCertsDir = "/etc/containerd/certs.d"
+	dockerAuthConfig   = "/root/.docker/config.json"
+	// kubelet read credentials from it, and send them to container engine when pull images
+	kubeletAuthConfig = "/var/lib/kubelet/config.json"
+
+	dockerHub         = "docker.io"
+	dockerHubServer   = "https://registry-1.docker.io"
+	dockerHubAuthKey  = "https://index.docker.io/v1/"
+	registryCAFile    = "ca.crt"
+	registryCertFile  = "client.cert"
+	registryKeyFile   = "client.key"
+	httpsSchemePrefix = "https://"
+)
+
+// RegistryAuthRuntime is container engine which support credentials of registries
+type RegistryAuthRuntime interface {
+	LoginRegistries(r runner.Runner, auths map[string]*RegistryAuth) error
+	LogoutRegistries(r runner.Runner, auths map[string]*RegistryAuth) error
+}
+
+// RegistryAuth is credential of registry
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
+func (a *RegistryAuth) encode() string {
+	return base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+}
+
+type dockerAuthEntry struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type dockerAuthConfigFile struct {
+	Auths map[string]*dockerAuthEntry `json:"auths"`
+}
+
+// NormalizeRegistryHost return host of registry, scheme and path are removed, and docker hub is docker.io
+func NormalizeRegistryHost(registry string) (string, error) {
+	s := registry
+	if !strings.Contains(s, "://") {
+		s = httpsSchemePrefix + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || u.User != nil {
+		return "", fmt.Errorf("invalid registry: %s", registry)
+	}
+	switch u.Host {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHub, nil
+	}
+	return u.Host, nil
+}
+
+// LoadRegistryCredentials load credentials of registries from local file in format of docker config.json,
+// passwords are registered to be scrubbed from logs
+func LoadRegistryCredentials(path string) (map[string]*RegistryAuth, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read registry credentials failed: %v", err)
+	}
+	var file dockerAuthConfigFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid registry credentials %s: %v", path, err)
+	}
+
+	auths := make(map[string]*RegistryAuth)
+	for registry, entry := range file.Auths {
+		host, err := NormalizeRegistryHost(registry)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, fmt.Errorf("empty credential of registry: %s", registry)
+		}
+		auth := &RegistryAuth{Username: entry.Username, Password: entry.Password}
+		if entry.Auth != "" {
+			secrets.Register(entry.Auth)
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of registry %s: %v", registry, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid auth of registry %s: expect username:password", registry)
+			}
+			auth.Username, auth.Password = parts[0], parts[1]
+		}
+		if auth.Username == "" || auth.Password == "" {
+			return nil, fmt.Errorf("empty username or password of registry: %s", registry)
+		}
+		secrets.Register(auth.Password)
+		secrets.Register(auth.encode())
+		auths[host] = auth
+	}
+
+	return auths, nil
+}
+
+func sortedRegistries(auths map[string]*RegistryAuth) []string {
+	var hosts []string
+	for h := range auths {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func loginRegistries(r runner.Runner, rt Runtime, engine *api.ContainerEngine) error {
+	auths, err := LoadRegistryCredentials(engine.RegistryCredentials)
+	if err != nil || len(auths) == 0 {
+		return err
+	}
+	ar, ok := rt.(RegistryAuthRuntime)
+	if !ok {
+		return fmt.Errorf("container engine %s not support registry credentials", rt.GetRuntimeService())
+	}
+	return ar.LoginRegistries(r, auths)
+}
+
+// RemoveRegistryAuths remove credentials of registries from container engine, engine must be running
+func RemoveRegistryAuths(r runner.Runner, engine *api.ContainerEngine) {
+	if engine == nil || engine.RegistryCredentials == "" {
+		return
+	}
+	auths, err := LoadRegistryCredentials(engine.RegistryCredentials)
+	if err != nil {
+		logrus.Warnf("load registry credentials failed: %v", err)
+		return
+	}
+	if ar, ok := GetRuntime(engine.Runtime).(RegistryAuthRuntime); ok {
+		if err := ar.LogoutRegistries(r, auths); err != nil {
+			logrus.Warnf("remove registry credentials failed: %v", err)
+		}
+	}
+}
+
+func (ir *isuladRuntime) LoginRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	// isulad encrypts credentials itself, so login by isula
+	for _, host := range sortedRegistries(auths) {
+		auth := auths[host]
+		password := base64.StdEncoding.EncodeToString([]byte(auth.Password))
+		secrets.Register(password)
+		username := base64.StdEncoding.EncodeToString([]byte(auth.Username))
+		cmd := fmt.Sprintf("sudo -E /bin/sh -c \"echo %s | base64 -d | isula login -u \\\"\\$(echo %s | base64 -d)\\\" --password-stdin %s\"",
+			password, username, host)
+		if _, err := r.RunCommand(cmd); err != nil {
+			return fmt.Errorf("login registry %s failed: %v", host, err)
+		}
+	}
+	return nil
+}
+
+func (ir *isuladRuntime) LogoutRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	for _, host := range sortedRegistries(auths) {
+		if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"isula logout %s\"", host)); err != nil {
+			return fmt.Errorf("logout registry %s failed: %v", host, err)
+		}
+	}
+	return nil
+}
+
+func dockerAuthKey(host string) string {
+	if host == dockerHub {
+		return dockerHubAuthKey
+	}
+	return host
+}
+
+// mergeDockerAuths add or remove credentials in config.json of docker, other configs are kept
+func mergeDockerAuths(content string, auths map[string]*RegistryAuth, remove bool) (string, error) {
+	config := make(map[string]interface{})
+	if strings.TrimSpace(content) != "" {
+		if err := json.Unmarshal([]byte(content), &config); err != nil {
+			return "", fmt.Errorf("invalid %s: %v", dockerAuthConfig, err)
+		}
+	}
+	entries, ok := config["auths"].(map[string]interface{})
+	if !ok {
+		entries = make(map[string]interface{})
+	}
+	for host, auth := range auths {
+		if remove {
+			delete(entries, dockerAuthKey(host))
+			continue
+		}
+		entries[dockerAuthKey(host)] = map[string]interface{}{"auth": auth.encode()}
+	}
+	config["auths"] = entries
+
+	data, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func writeAuthConfig(r runner.Runner, content string, path string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	secrets.Register(encoded)
+	_, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p %s && echo %s | base64 -d > %s && chmod 600 %s\"",
+		filepath.Dir(path), encoded, path, path))
+	return err
+}
+
+func updateDockerAuths(r runner.Runner, auths map[string]*RegistryAuth, remove bool) error {
+	content, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"cat %s 2>/dev/null || true\"", dockerAuthConfig))
+	if err != nil {
+		return fmt.Errorf("read %s failed: %v", dockerAuthConfig, err)
+	}
+	config, err := mergeDockerAuths(content, auths, remove)
+	if err != nil {
+		return err
+	}
+	return writeAuthConfig(r, config, dockerAuthConfig)
+}
+
+func (dr *dockerRuntime) LoginRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	if err := updateDockerAuths(r, auths, false); err != nil {
+		return err
+	}
+	// docker daemon never read config.json, kubelet send credentials to it
+	config, err := mergeDockerAuths("", auths, false)
+	if err != nil {
+		return err
+	}
+	return writeAuthConfig(r, config, kubeletAuthConfig)
+}
+
+func (dr *dockerRuntime) LogoutRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	// config.json of kubelet is removed with /var/lib/kubelet
+	return updateDockerAuths(r, auths, true)
+}
+
+func (cr *containerdRuntime) LoginRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	// credentials are written in config.toml by PrepareRuntimeService
+	return nil
+}
+
+func (cr *containerdRuntime) LogoutRegistries(r runner.Runner, auths map[string]*RegistryAuth) error {
+	// config of containerd is created by eggo, and removed with credentials in it
+	return nil
+}
+
+// containerdAuths return base64 of username:password of registries, keys are hosts in config of cri plugin
+func containerdAuths(auths map[string]*RegistryAuth) map[string]string {
+	res := make(map[string]string)
+	for host, auth := range auths {
+		if host == dockerHub {
+			host = "registry-1.docker.io"
+		}
+		res[host] = auth.encode()
+	}
+	return res
+}
+
+type containerdRegistry struct {
+	Host       string
+	Server     string
+	Mirrors    []string
+	CAFile     string
+	ClientCert string
+	ClientKey  string
+	SkipVerify bool
+}
+
+func registryURL(s string) string {
+	if strings.Contains(s, "://") {
+		return s
+	}
+	return httpsSchemePrefix + s
+}
+
+// containerdRegistries merge registries with registry-mirrors and insecure-registries,
+// mirrors of containerd cannot be set with hosts config, so all of them are in hosts.toml
+func containerdRegistries(engine *api.ContainerEngine) []*containerdRegistry {
+	if engine == nil || len(engine.Registries) == 0 {
+		return nil
+	}
+
+	var res []*containerdRegistry
+	index := make(map[string]*containerdRegistry)
+	get := func(host string) *containerdRegistry {
+		if reg, ok := index[host]; ok {
+			return reg
+		}
+		reg := &containerdRegistry{Host: host, Server: registryURL(host)}
+		if host == dockerHub {
+			reg.Server = dockerHubServer
+		}
+		index[host] = reg
+		res = append(res, reg)
+		return reg
+	}
+
+	for _, r := range engine.Registries {
+		reg := get(r.Host)
+		for _, m := range r.Mirrors {
+			reg.Mirrors = append(reg.Mirrors, registryURL(m))
+		}
+		reg.CAFile, reg.ClientCert, reg.ClientKey = r.CAFile, r.ClientCert, r.ClientKey
+		reg.SkipVerify = reg.SkipVerify || r.SkipVerify
+	}
+	for _, m := range engine.RegistryMirrors {
+		if host, err := NormalizeRegistryHost(m); err == nil && host != dockerHub {
+			reg := get(dockerHub)
+			reg.Mirrors = append(reg.Mirrors, registryURL(m))
+		}
+	}
+	for _, i := range engine.InsecureRegistries {
+		if host, err := NormalizeRegistryHost(i); err == nil {
+			get(host).SkipVerify = true
+		}
+	}
+
+	return res
+}
+
+func containerdHostsConfig(reg *containerdRegistry) (string, error) {
+	hostsConfig := `
+server = "{{ .server }}"
+{{- if .ca }}
+ca = "{{ .ca }}"
+{{- end }}
+{{- if .cert }}
+client = [["{{ .cert }}", "{{ .key }}"]]
+{{- end }}
+{{- if .skipVerify }}
+skip_verify = true
+{{- end }}
+{{- range $i, $m := .mirrors }}
+
+[host."{{ $m }}"]
+  capabilities = ["pull", "resolve"]
+{{- if $.ca }}
+  ca = "{{ $.ca }}"
+{{- end }}
+{{- if $.cert }}
+  client = [["{{ $.cert }}", "{{ $.key }}"]]
+{{- end }}
+{{- if $.skipVerify }}
+  skip_verify = true
+{{- end }}
+{{- end }}
+`
+
+	dir := filepath.Join(containerdCertsDir, reg.Host)
+	datastore := map[string]interface{}{}
+	datastore["server"] = reg.Server
+	datastore["mirrors"] = reg.Mirrors
+	datastore["skipVerify"] = reg.SkipVerify
+	datastore["ca"] = ""
+	datastore["cert"] = ""
+	datastore["key"] = ""
+	if reg.CAFile != "" {
+		datastore["ca"] = filepath.Join(dir, registryCAFile)
+	}
+	if reg.ClientCert != "" {
+		datastore["cert"] = filepath.Join(dir, registryCertFile)
+		datastore["key"] = filepath.Join(dir, registryKeyFile)
+	}
+	return template.TemplateRender(hostsConfig, datastore)
+}
+
+// prepareContainerdHosts create hosts.toml and copy certificates of registries to certs.d of containerd
+func prepareContainerdHosts(r runner.Runner, registries []*containerdRegistry) error {
+	if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"rm -rf %s && mkdir -p %s\"",
+		containerdCertsDir, containerdCertsDir)); err != nil {
+		return err
+	}
+
+	for _, reg := range registries {
+		config, err := containerdHostsConfig(reg)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(containerdCertsDir, reg.Host)
+		if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p %s && echo %s | base64 -d > %s\"",
+			dir, base64.StdEncoding.EncodeToString([]byte(config)), filepath.Join(dir, "hosts.toml"))); err != nil {
+			return err
+		}
+
+		files := [][]string{
+			{reg.CAFile, registryCAFile},
+			{reg.ClientCert, registryCertFile},
+			{reg.ClientKey, registryKeyFile},
+		}
+		for _, f := range files {
+			if f[0] == "" {
+				continue
+			}
+			if err := r.Copy(f[0], filepath.Join(dir, f[1])); err != nil {
+				logrus.Errorf("copy %s of registry %s failed: %v", f[0], reg.Host, err)
+				return err
+			}
+		}
+		if reg.ClientKey != "" {
+			if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"chmod 600 %s\"", filepath.Join(dir, registryKeyFile))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
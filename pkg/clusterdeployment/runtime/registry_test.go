@@ -0,0 +1,316 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: hosts config and credentials of registries testcase
+ ******************************************************************************/
+
+package runtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+func writeCredentials(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "eggo-registry-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "auth.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write credentials failed: %v", err)
+	}
+	return path
+}
+
+func TestLoadRegistryCredentials(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("robot$ci:pass:word1"))
+	path := writeCredentials(t, `{"auths": {
+		"https://index.docker.io/v1/": {"auth": "`+auth+`"},
+		"example.com:5000": {"username": "admin", "password": "secretpass2"}
+	}}`)
+
+	auths, err := LoadRegistryCredentials(path)
+	if err != nil {
+		t.Fatalf("load credentials failed: %v", err)
+	}
+	if len(auths) != 2 {
+		t.Fatalf("expect 2 credentials, get: %v", auths)
+	}
+	if a := auths["docker.io"]; a == nil || a.Username != "robot$ci" || a.Password != "pass:word1" {
+		t.Fatalf("invalid credential of docker hub: %v", a)
+	}
+	if a := auths["example.com:5000"]; a == nil || a.Username != "admin" || a.Password != "secretpass2" {
+		t.Fatalf("invalid credential of example.com:5000: %v", a)
+	}
+	if s := secrets.Scrub("password is secretpass2"); strings.Contains(s, "secretpass2") {
+		t.Fatalf("password is not scrubbed: %s", s)
+	}
+
+	if auths, err = LoadRegistryCredentials(""); err != nil || auths != nil {
+		t.Fatalf("load empty credentials failed: %v", err)
+	}
+	for _, invalid := range []string{
+		`{"auths": {"example.com": {"username": "admin"}}}`,
+		`{"auths": {"example.com": {"auth": "invalid"}}}`,
+		`{"auths": {"": {"username": "admin", "password": "pass"}}}`,
+		`invalid`,
+	} {
+		if _, err = LoadRegistryCredentials(writeCredentials(t, invalid)); err == nil {
+			t.Fatalf("load invalid credentials %s success", invalid)
+		}
+	}
+}
+
+func TestMergeDockerAuths(t *testing.T) {
+	auths := map[string]*RegistryAuth{
+		"docker.io":   {Username: "user", Password: "pass"},
+		"example.com": {Username: "admin", Password: "admin"},
+	}
+	old := `{"auths": {"other.com": {"auth": "b3RoZXI6b3RoZXI="}}, "credsStore": "pass"}`
+	content, err := mergeDockerAuths(old, auths, false)
+	if err != nil {
+		t.Fatalf("merge auths failed: %v", err)
+	}
+	var config dockerAuthConfigFile
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		t.Fatalf("invalid merged config: %v", err)
+	}
+	if len(config.Auths) != 3 || config.Auths[dockerHubAuthKey].Auth != auths["docker.io"].encode() {
+		t.Fatalf("invalid merged auths: %s", content)
+	}
+	if !strings.Contains(content, "credsStore") {
+		t.Fatalf("other configs of docker are lost: %s", content)
+	}
+
+	content, err = mergeDockerAuths(content, auths, true)
+	if err != nil {
+		t.Fatalf("remove auths failed: %v", err)
+	}
+	if strings.Contains(content, "example.com") || !strings.Contains(content, "other.com") {
+		t.Fatalf("remove auths failed: %s", content)
+	}
+}
+
+func TestContainerdRegistries(t *testing.T) {
+	engine := &api.ContainerEngine{
+		RegistryMirrors:    []string{"https://mirror.example.com"},
+		InsecureRegistries: []string{"http://insecure.example.com"},
+	}
+	if regs := containerdRegistries(engine); regs != nil {
+		t.Fatalf("expect no hosts config without registries: %v", regs)
+	}
+
+	engine.Registries = []*api.RegistryConfig{
+		{
+			Host:       "example.com:5000",
+			Mirrors:    []string{"mirror.example.com:5000"},
+			CAFile:     "/tmp/ca.crt",
+			ClientCert: "/tmp/client.cert",
+			ClientKey:  "/tmp/client.key",
+		},
+	}
+	regs := containerdRegistries(engine)
+	if len(regs) != 3 {
+		t.Fatalf("expect 3 registries, get: %d", len(regs))
+	}
+	if regs[1].Host != "docker.io" || regs[1].Server != dockerHubServer || regs[1].Mirrors[0] != "https://mirror.example.com" {
+		t.Fatalf("invalid docker hub: %v", regs[1])
+	}
+	if regs[2].Host != "insecure.example.com" || !regs[2].SkipVerify {
+		t.Fatalf("invalid insecure registry: %v", regs[2])
+	}
+
+	conf, err := containerdHostsConfig(regs[0])
+	if err != nil {
+		t.Fatalf("render hosts.toml failed: %v", err)
+	}
+	for _, expect := range []string{
+		`server = "https://example.com:5000"`,
+		`ca = "/etc/containerd/certs.d/example.com:5000/ca.crt"`,
+		`client = [["/etc/containerd/certs.d/example.com:5000/client.cert", "/etc/containerd/certs.d/example.com:5000/client.key"]]`,
+		`[host."https://mirror.example.com:5000"]`,
+		`capabilities = ["pull", "resolve"]`,
+	} {
+		if !strings.Contains(conf, expect) {
+			t.Fatalf("expect %s in hosts.toml: %s", expect, conf)
+		}
+	}
+	if strings.Contains(conf, "skip_verify") {
+		t.Fatalf("unexpect skip_verify in hosts.toml: %s", conf)
+	}
+}
+
+// containerdCRIConfig is part of config of cri plugin of containerd used by eggo
+type containerdCRIConfig struct {
+	SandboxImage string `toml:"sandbox_image"`
+	Containerd   struct {
+		Runtimes map[string]struct {
+			RuntimeType string `toml:"runtime_type"`
+		} `toml:"runtimes"`
+	} `toml:"containerd"`
+	Registry struct {
+		ConfigPath string `toml:"config_path"`
+		Mirrors    map[string]struct {
+			Endpoint []string `toml:"endpoint"`
+		} `toml:"mirrors"`
+		Configs map[string]struct {
+			Auth *struct {
+				Auth string `toml:"auth"`
+			} `toml:"auth"`
+			TLS *struct {
+				InsecureSkipVerify bool `toml:"insecure_skip_verify"`
+			} `toml:"tls"`
+		} `toml:"configs"`
+	} `toml:"registry"`
+}
+
+// decodeContainerdConfig decodes config.toml like containerd: config without version is version 1,
+// and config of cri plugin is read from plugins.cri only
+func decodeContainerdConfig(t *testing.T, conf string) *containerdCRIConfig {
+	var srvConfig struct {
+		Version int                       `toml:"version"`
+		Plugins map[string]toml.Primitive `toml:"plugins"`
+	}
+	md, err := toml.Decode(conf, &srvConfig)
+	if err != nil {
+		t.Fatalf("decode containerd config failed: %v\n%s", err, conf)
+	}
+	if srvConfig.Version > 1 {
+		t.Fatalf("expect containerd config of version 1: %s", conf)
+	}
+	for id := range srvConfig.Plugins {
+		if id != "cri" {
+			t.Fatalf("config of plugin %s is ignored by containerd: %s", id, conf)
+		}
+	}
+	var cri containerdCRIConfig
+	if err := md.PrimitiveDecode(srvConfig.Plugins["cri"], &cri); err != nil {
+		t.Fatalf("decode cri config failed: %v", err)
+	}
+	return &cri
+}
+
+func TestContainerdConfigContent(t *testing.T) {
+	wc := &api.WorkerConfig{
+		KubeletConf: &api.Kubelet{PauseImage: "k8s.gcr.io/pause:3.5"},
+		ContainerEngineConf: &api.ContainerEngine{
+			RegistryMirrors:    []string{"https://mirror.example.com"},
+			InsecureRegistries: []string{"http://insecure.example.com"},
+		},
+	}
+	auths := containerdAuths(map[string]*RegistryAuth{"docker.io": {Username: "user", Password: "pass"}})
+
+	conf, err := containerdConfigContent(wc, auths, true)
+	if err != nil {
+		t.Fatalf("render containerd config failed: %v", err)
+	}
+	handlers, err := containerdHandlersConfig([]*api.RuntimeHandler{{Name: "kata", RuntimeType: "io.containerd.kata.v2"}})
+	if err != nil {
+		t.Fatalf("render containerd runtimes failed: %v", err)
+	}
+	cri := decodeContainerdConfig(t, conf+handlers)
+	if cri.SandboxImage != "k8s.gcr.io/pause:3.5" {
+		t.Fatalf("invalid sandbox image: %s", cri.SandboxImage)
+	}
+	if cri.Registry.ConfigPath != containerdCertsDir || len(cri.Registry.Mirrors) != 0 {
+		t.Fatalf("mirrors must be in hosts.toml: %+v", cri.Registry)
+	}
+	auth := cri.Registry.Configs["registry-1.docker.io"].Auth
+	if auth == nil || auth.Auth != base64.StdEncoding.EncodeToString([]byte("user:pass")) {
+		t.Fatalf("expect auth of docker hub: %s", conf)
+	}
+	if cri.Containerd.Runtimes["kata"].RuntimeType != "io.containerd.kata.v2" {
+		t.Fatalf("expect runtime handler kata: %+v", cri.Containerd.Runtimes)
+	}
+
+	conf, err = containerdConfigContent(wc, nil, false)
+	if err != nil {
+		t.Fatalf("render containerd config failed: %v", err)
+	}
+	cri = decodeContainerdConfig(t, conf)
+	if cri.Registry.ConfigPath != "" {
+		t.Fatalf("unexpect config_path: %s", conf)
+	}
+	if m := cri.Registry.Mirrors["mirror.example.com"]; len(m.Endpoint) != 1 || m.Endpoint[0] != "https://mirror.example.com" {
+		t.Fatalf("expect mirrors in config.toml: %s", conf)
+	}
+	if tls := cri.Registry.Configs["insecure.example.com"].TLS; tls == nil || !tls.InsecureSkipVerify {
+		t.Fatalf("expect insecure registry in config.toml: %s", conf)
+	}
+}
+
+// commandRecorder record commands run on node
+type commandRecorder struct {
+	commands []string
+}
+
+func (m *commandRecorder) Copy(src, dst string) error {
+	return nil
+}
+
+func (m *commandRecorder) RunCommand(cmd string) (string, error) {
+	m.commands = append(m.commands, cmd)
+	return "", nil
+}
+
+func (m *commandRecorder) RunShell(shell string, name string) (string, error) {
+	return "", nil
+}
+
+func (m *commandRecorder) Reconnect() error {
+	return nil
+}
+
+func (m *commandRecorder) Close() {
+}
+
+func (m *commandRecorder) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
+func TestPrepareContainerdConfigMode(t *testing.T) {
+	wc := &api.WorkerConfig{
+		KubeletConf:         &api.Kubelet{PauseImage: "k8s.gcr.io/pause:3.5"},
+		ContainerEngineConf: &api.ContainerEngine{},
+	}
+	r := &commandRecorder{}
+	if err := prepareContainerdConfig(r, wc); err != nil {
+		t.Fatalf("prepare containerd config failed: %v", err)
+	}
+	if len(r.commands) != 1 || strings.Contains(r.commands[0], "chmod 600") {
+		t.Fatalf("config without credentials should keep default mode: %v", r.commands)
+	}
+
+	// config with credentials is only readable by root
+	wc.ContainerEngineConf.RegistryCredentials = writeCredentials(t, `{"auths": {"example.com": {"username": "admin", "password": "pass"}}}`)
+	r = &commandRecorder{}
+	if err := prepareContainerdConfig(r, wc); err != nil {
+		t.Fatalf("prepare containerd config with credentials failed: %v", err)
+	}
+	if len(r.commands) != 1 || !strings.Contains(r.commands[0], "chmod 600 "+containerdConfigPath) {
+		t.Fatalf("config with credentials should be only readable by root: %v", r.commands)
+	}
+}
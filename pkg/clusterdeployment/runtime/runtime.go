@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/clusterdeployment/binary/commontools"
 	"isula.org/eggo/pkg/utils/dependency"
+	"isula.org/eggo/pkg/utils/nodemanager"
 	"isula.org/eggo/pkg/utils/runner"
 	"isula.org/eggo/pkg/utils/template"
 )
@@ -19,6 +21,8 @@ var (
 		"isulad":     &isuladRuntime{},
 		"docker":     &dockerRuntime{},
 		"containerd": &containerdRuntime{},
+		"crio":       &crioRuntime{},
+		"cri-o":      &crioRuntime{},
 	}
 )
 
@@ -27,6 +31,8 @@ type Runtime interface {
 	GetRuntimeClient() string
 	GetRuntimeLoadImageCommand() string
 	GetRuntimeService() string
+	// GetRuntimeEndpoint return default cri endpoint of runtime, used by kubelet if endpoint is not set
+	GetRuntimeEndpoint() string
 	PrepareRuntimeService(r runner.Runner, workerConfig *api.WorkerConfig) error
 
 	GetRemovedPath() []string
@@ -51,6 +57,10 @@ func (ir *isuladRuntime) GetRuntimeService() string {
 	return "isulad"
 }
 
+func (ir *isuladRuntime) GetRuntimeEndpoint() string {
+	return "unix:///var/run/isulad.sock"
+}
+
 func (ir *isuladRuntime) PrepareRuntimeService(r runner.Runner, workerConfig *api.WorkerConfig) error {
 	service := `[Unit]
 Description=iSulad Application Container Engine
@@ -164,6 +174,10 @@ func (dr *dockerRuntime) GetRuntimeService() string {
 	return "docker"
 }
 
+func (dr *dockerRuntime) GetRuntimeEndpoint() string {
+	return "unix:///var/run/docker.sock"
+}
+
 func (dr *dockerRuntime) PrepareRuntimeService(r runner.Runner, workerConfig *api.WorkerConfig) error {
 	service := `[Unit]
 Description=Docker Application Container Engine
@@ -259,6 +273,10 @@ func (cr *containerdRuntime) GetRuntimeService() string {
 	return "containerd"
 }
 
+func (cr *containerdRuntime) GetRuntimeEndpoint() string {
+	return "unix:///run/containerd/containerd.sock"
+}
+
 func (cr *containerdRuntime) PrepareRuntimeService(r runner.Runner, workerConfig *api.WorkerConfig) error {
 	if err := prepareContainerdConfig(r, workerConfig); err != nil {
 		return err
@@ -310,31 +328,78 @@ WantedBy=multi-user.target
 func (cr *containerdRuntime) GetRemovedPath() []string {
 	return []string{
 		"/usr/lib/systemd/system/containerd.service",
-		"/etc/containerd/config.toml",
+		containerdConfigPath,
+		containerdCertsDir,
 	}
 }
 
 func prepareContainerdConfig(r runner.Runner, workerConfig *api.WorkerConfig) error {
+	auths, err := LoadRegistryCredentials(workerConfig.ContainerEngineConf.RegistryCredentials)
+	if err != nil {
+		return err
+	}
+	registries := containerdRegistries(workerConfig.ContainerEngineConf)
+	containerdConf, err := containerdConfigContent(workerConfig, containerdAuths(auths), len(registries) != 0)
+	if err != nil {
+		return err
+	}
+
+	if len(auths) != 0 {
+		// credentials of registries are in config, only readable by root
+		err = writeAuthConfig(r, containerdConf, containerdConfigPath)
+	} else {
+		containerdBase64 := base64.StdEncoding.EncodeToString([]byte(containerdConf))
+		_, err = r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p /etc/containerd && echo %s | base64 -d > %s\"",
+			containerdBase64, containerdConfigPath))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(registries) != 0 {
+		if err := prepareContainerdHosts(r, registries); err != nil {
+			logrus.Errorf("prepare hosts of registries failed: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// containerdConfigContent return config.toml of containerd, registries are configured in
+// hosts.toml under certs.d if withHosts, and auths are base64 of credentials of registries.
+// config.toml is version 1 without version field, which only decodes short plugin id cri
+// and ignores tables of full plugin uri silently, so all tables must use plugins.cri
+func containerdConfigContent(workerConfig *api.WorkerConfig, auths map[string]string, withHosts bool) (string, error) {
 	containerdConfig := `
 [plugins.cri]
   sandbox_image = "{{ .pauseImage }}"
+{{- if .certsDir }}
+[plugins.cri.registry]
+  config_path = "{{ .certsDir }}"
+{{- else }}
 {{- $alen := len .registryAggregate }}
 {{- if ne $alen 0 }}
-[plugins."io.containerd.grpc.v1.cri".registry]
-  [plugins."io.containerd.grpc.v1.cri".registry.mirrors]
+[plugins.cri.registry]
+  [plugins.cri.registry.mirrors]
 {{- range $i, $v := .registryAggregate }}
-    [plugins."io.containerd.grpc.v1.cri".registry.mirrors."{{ $v }}"]
+    [plugins.cri.registry.mirrors."{{ $v }}"]
       endpoint = ["https://{{ $v }}"]
 {{- end }}
 {{- end }}
 {{- $alen := len .insecure }}
 {{- if ne $alen 0 }}
-  [plugins."io.containerd.grpc.v1.cri".registry.configs]
+  [plugins.cri.registry.configs]
 {{- range $i, $v := .insecure }}
-    [plugins."io.containerd.grpc.v1.cri".registry.configs."{{ $v }}".tls]
+    [plugins.cri.registry.configs."{{ $v }}".tls]
       insecure_skip_verify = true
 {{- end }}
 {{- end }}
+{{- end }}
+{{- range $h, $a := .auths }}
+[plugins.cri.registry.configs."{{ $h }}".auth]
+  auth = "{{ $a }}"
+{{- end }}
 {{- range $i, $v := .addition }}
 {{ .addition }}
 {{- end }}
@@ -373,21 +438,12 @@ func prepareContainerdConfig(r runner.Runner, workerConfig *api.WorkerConfig) er
 	datastore["registryAggregate"] = registryAggregate
 	datastore["insecure"] = insecureTmp
 	datastore["addition"] = addition
-	containerdConf, err := template.TemplateRender(containerdConfig, datastore)
-	if err != nil {
-		return err
+	datastore["auths"] = auths
+	datastore["certsDir"] = ""
+	if withHosts {
+		datastore["certsDir"] = containerdCertsDir
 	}
-
-	var sb strings.Builder
-	containerdBase64 := base64.StdEncoding.EncodeToString([]byte(containerdConf))
-	sb.WriteString(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p /etc/containerd && echo %s | base64 -d > %s\"",
-		containerdBase64, "/etc/containerd/config.toml"))
-	_, err = r.RunCommand(sb.String())
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return template.TemplateRender(containerdConfig, datastore)
 }
 
 type DeployRuntimeTask struct {
@@ -409,7 +465,7 @@ func (ct *DeployRuntimeTask) Name() string {
 	return "DeployRuntimeTask"
 }
 
-func (ct *DeployRuntimeTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (ct *DeployRuntimeTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	logrus.Info("do deploy container engine...\n")
 
 	ct.runtime = GetRuntime(ct.workerConfig.ContainerEngineConf.Runtime)
@@ -433,6 +489,18 @@ func (ct *DeployRuntimeTask) Run(r runner.Runner, hcg *api.HostConfig) error {
 		return err
 	}
 
+	handlers := NodeRuntimeHandlers(ct.workerConfig.ContainerEngineConf, hcg.Name)
+	if err := prepareRuntimeHandlers(r, ct.runtime, handlers, ct.packageSrc, hcg); err != nil {
+		logrus.Errorf("prepare runtime handlers failed: %v", err)
+		return err
+	}
+
+	if err := loginRegistries(r, ct.runtime, ct.workerConfig.ContainerEngineConf); err != nil {
+		logrus.Errorf("login registries failed: %v", err)
+		return err
+	}
+
+	defer nodemanager.StartTiming(hcg, nodemanager.TimingImage)()
 	if err := dependency.InstallImageDependency(r, ct.workerInfra, ct.packageSrc, ct.runtime.GetRuntimeService(),
 		ct.runtime.GetRuntimeClient(), ct.runtime.GetRuntimeLoadImageCommand()); err != nil {
 		logrus.Errorf("load images failed: %v", err)
@@ -458,6 +526,16 @@ func (ct *DeployRuntimeTask) check(r runner.Runner) error {
 	return nil
 }
 
+// GetRuntimeByEndpoint return name of runtime whose default endpoint is endpoint, or empty if not found
+func GetRuntimeByEndpoint(endpoint string) string {
+	for name, rt := range mapRuntime {
+		if rt.GetRuntimeEndpoint() == endpoint {
+			return name
+		}
+	}
+	return ""
+}
+
 func GetRuntime(runtime string) Runtime {
 	if runtime == "" {
 		return mapRuntime["docker"]
@@ -42,4 +42,8 @@ const (
 
 	// default task wait time in minute
 	DefaultTaskWaitMinutes = 5
+	// default timeout of task running on one node
+	DefaultTaskTimeoutSeconds = 300
+	// max count of steps run at the same time when deploy or join nodes
+	DefaultStepConcurrency = 16
 )
@@ -0,0 +1,59 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: parse certificates to get expiration
+ ******************************************************************************/
+
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// ParseCertificate return the first certificate in PEM data
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// ParseKubeConfigCertificate return client certificate of current context in kubeconfig,
+// if certificate is not embedded, path of certificate file will be returned.
+func ParseKubeConfigCertificate(data []byte) (*x509.Certificate, string, error) {
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, "", err
+	}
+	ctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return nil, "", fmt.Errorf("current context %s not found", cfg.CurrentContext)
+	}
+	auth, ok := cfg.AuthInfos[ctx.AuthInfo]
+	if !ok {
+		return nil, "", fmt.Errorf("user %s not found", ctx.AuthInfo)
+	}
+	if len(auth.ClientCertificateData) == 0 {
+		if auth.ClientCertificate == "" {
+			return nil, "", fmt.Errorf("no client certificate found")
+		}
+		return nil, auth.ClientCertificate, nil
+	}
+
+	cert, err := ParseCertificate(auth.ClientCertificateData)
+	return cert, "", err
+}
@@ -16,6 +16,7 @@
 package dependency
 
 import (
+	"context"
 	"fmt"
 	"path"
 
@@ -37,7 +38,7 @@ func (ch *CopyHooksTask) Name() string {
 	return "CopyHooksTask"
 }
 
-func (ch *CopyHooksTask) Run(r runner.Runner, hcg *api.HostConfig) error {
+func (ch *CopyHooksTask) Run(ctx context.Context, r runner.Runner, hcg *api.HostConfig) error {
 	dstDir := path.Join(constants.DefaultPackagePath, constants.DefaultHookPath)
 
 	if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"test -d %s || mkdir -p %s\"", dstDir, dstDir)); err != nil {
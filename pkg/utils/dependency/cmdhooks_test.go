@@ -1,6 +1,7 @@
 package dependency
 
 import (
+	"context"
 	"testing"
 
 	"isula.org/eggo/pkg/api"
@@ -20,7 +21,7 @@ func TestCopyHooks(t *testing.T) {
 	node := &api.HostConfig{}
 
 	ct := &CopyHooksTask{hooks: hs}
-	if err := ct.Run(&mr, node); err != nil {
+	if err := ct.Run(context.Background(), &mr, node); err != nil {
 		t.Fatalf("run test failed: %v", err)
 	}
 }
@@ -16,6 +16,7 @@
 package dependency
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -27,6 +28,9 @@ import (
 )
 
 const (
+	// hook is killed if it is not finished in timeout
+	defaultHookTimeout = "30s"
+
 	PrmTest = "if [ x != x$(which apt 2>/dev/null) ]; then echo apt ; elif [ x != x$(which yum 2>/dev/null) ]; then echo yum ; fi"
 	PmTest  = "if [ x != x$(which dpkg 2>/dev/null) ]; then echo dpkg ; elif [ x != x$(which rpm 2>/dev/null) ]; then echo rpm ; fi"
 )
@@ -34,6 +38,7 @@ const (
 type managerCommand struct {
 	installCommand string
 	removeCommand  string
+	upgradeCommand string
 }
 
 func getPackageRepoManager(r runner.Runner) (*managerCommand, error) {
@@ -41,10 +46,12 @@ func getPackageRepoManager(r runner.Runner) (*managerCommand, error) {
 		"apt": {
 			installCommand: "apt install -y",
 			removeCommand:  "apt remove -y",
+			upgradeCommand: "apt install --only-upgrade -y",
 		},
 		"yum": {
 			installCommand: "yum install -y",
 			removeCommand:  "yum remove -y",
+			upgradeCommand: "yum upgrade -y",
 		},
 	}
 
@@ -99,6 +106,7 @@ type dependency interface {
 
 type dependencyRepo struct {
 	software []*api.PackageConfig
+	upgrade  bool
 }
 
 func (dr *dependencyRepo) Install(r runner.Runner) error {
@@ -119,6 +127,13 @@ func (dr *dependencyRepo) Install(r runner.Runner) error {
 		return fmt.Errorf("%s failed: %v", prManager.installCommand, err)
 	}
 
+	// install do nothing if software is already installed, so upgrade it to the newest version
+	if dr.upgrade {
+		if _, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"%s %s\"", prManager.upgradeCommand, join)); err != nil {
+			return fmt.Errorf("%s failed: %v", prManager.upgradeCommand, err)
+		}
+	}
+
 	return nil
 }
 
@@ -197,8 +212,10 @@ func (dp *dependencyPkg) Remove(r runner.Runner) error {
 // install file and dir
 type dependencyFileDir struct {
 	executable bool
-	srcPath    string
-	software   []*api.PackageConfig
+	// overwrite existed file and dir, used to upgrade
+	force    bool
+	srcPath  string
+	software []*api.PackageConfig
 }
 
 func (df *dependencyFileDir) Install(r runner.Runner) error {
@@ -217,15 +234,20 @@ chmod +x {{ $v.Name }}
 {{- end }}
 
 {{- range $i, $v := .software }}
+{{- if $.force }}
+mkdir -p {{ $v.Dst }} && cp -rf {{ $v.Name }} {{ $v.Dst }}
+{{- else }}
 if [ ! -e {{ JoinPath $v.Dst $v.Name }} ]; then
     mkdir -p {{ $v.Dst }} && cp -r {{ $v.Name }} {{ $v.Dst }}
 fi
 {{- end }}
+{{- end }}
 `
 	datastore := make(map[string]interface{})
 	datastore["srcPath"] = df.srcPath
 	datastore["software"] = df.software
 	datastore["executable"] = df.executable
+	datastore["force"] = df.force
 
 	shellStr, err := template.TemplateRender(shell, datastore)
 	if err != nil {
@@ -398,7 +420,7 @@ exit 0
 		shells = append(shells, fmt.Sprintf("%s/%s", ds.srcPath, s.Name))
 		timeout := s.TimeOut
 		if timeout == "" {
-			timeout = "30s"
+			timeout = defaultHookTimeout
 		}
 		timeouts = append(timeouts, timeout)
 	}
@@ -430,7 +452,7 @@ func (dt *DependencyTask) Name() string {
 	return "DependencyTask"
 }
 
-func (dt *DependencyTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (dt *DependencyTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	if err := dt.dp.Install(r); err != nil {
 		logrus.Errorf("install failed for %s: %v", hcf.Address, err)
 		return err
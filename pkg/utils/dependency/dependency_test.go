@@ -1,11 +1,14 @@
 package dependency
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/sirupsen/logrus"
 
 	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
 )
 
 type MockRunner struct {
@@ -26,6 +29,10 @@ func (m *MockRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (m *MockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
 func (m *MockRunner) Reconnect() error {
 	logrus.Infof("reconnect")
 	return nil
@@ -51,3 +58,40 @@ func TestNewDependencyShell(t *testing.T) {
 		t.Fatalf("run test failed: %v", err)
 	}
 }
+
+type shellRecorder struct {
+	MockRunner
+	shell string
+}
+
+func (s *shellRecorder) RunShell(shell string, name string) (string, error) {
+	s.shell = shell
+	return "", nil
+}
+
+func TestUpgradeFileDir(t *testing.T) {
+	software := []*api.PackageConfig{
+		{
+			Name: "kubectl",
+			Type: "bin",
+			Dst:  "/usr/bin",
+		},
+	}
+
+	sr := &shellRecorder{}
+	df := &dependencyFileDir{executable: true, srcPath: "/tmp", software: software}
+	if err := df.Install(sr); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if !strings.Contains(sr.shell, "if [ ! -e /usr/bin/kubectl ]") {
+		t.Fatalf("install should not overwrite existed file: %s", sr.shell)
+	}
+
+	df.force = true
+	if err := df.Install(sr); err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if strings.Contains(sr.shell, "if [ ! -e") || !strings.Contains(sr.shell, "cp -rf kubectl /usr/bin") {
+		t.Fatalf("upgrade should overwrite existed file: %s", sr.shell)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -31,7 +32,7 @@ import (
 	"isula.org/eggo/pkg/utils/task"
 )
 
-func newBaseDependency(roleInfra *api.RoleInfra, packagePath string) map[string]dependency {
+func newBaseDependency(roleInfra *api.RoleInfra, packagePath string, upgrade bool) map[string]dependency {
 	packages := map[string][]*api.PackageConfig{
 		"repo": {},
 		"pkg":  {},
@@ -50,6 +51,7 @@ func newBaseDependency(roleInfra *api.RoleInfra, packagePath string) map[string]
 	baseDependency := map[string]dependency{
 		"repo": &dependencyRepo{
 			software: packages["repo"],
+			upgrade:  upgrade,
 		},
 		"pkg": &dependencyPkg{
 			srcPath:  path.Join(packagePath, constants.DefaultPkgPath),
@@ -57,15 +59,18 @@ func newBaseDependency(roleInfra *api.RoleInfra, packagePath string) map[string]
 		},
 		"bin": &dependencyFileDir{
 			executable: true,
+			force:      upgrade,
 			srcPath:    path.Join(packagePath, constants.DefaultBinPath),
 			software:   packages["bin"],
 		},
 		"file": &dependencyFileDir{
 			executable: false,
+			force:      upgrade,
 			srcPath:    path.Join(packagePath, constants.DefaultFilePath),
 		},
 		"dir": &dependencyFileDir{
 			executable: false,
+			force:      upgrade,
 			srcPath:    path.Join(packagePath, constants.DefaultDirPath),
 			software:   packages["dir"],
 		},
@@ -76,7 +81,7 @@ func newBaseDependency(roleInfra *api.RoleInfra, packagePath string) map[string]
 
 // install base dependency, include repo, pkg, bin, file, dir
 func InstallBaseDependency(r runner.Runner, roleInfra *api.RoleInfra, hcf *api.HostConfig, packagePath string) error {
-	baseDependency := newBaseDependency(roleInfra, packagePath)
+	baseDependency := newBaseDependency(roleInfra, packagePath, false)
 
 	for _, dep := range baseDependency {
 		if err := dep.Install(r); err != nil {
@@ -88,8 +93,22 @@ func InstallBaseDependency(r runner.Runner, roleInfra *api.RoleInfra, hcf *api.H
 	return nil
 }
 
+// upgrade base dependency, existed softwares will be overwritten by new packages
+func UpgradeBaseDependency(r runner.Runner, roleInfra *api.RoleInfra, hcf *api.HostConfig, packagePath string) error {
+	baseDependency := newBaseDependency(roleInfra, packagePath, true)
+
+	for _, dep := range baseDependency {
+		if err := dep.Install(r); err != nil {
+			logrus.Errorf("upgrade failed for %s: %v", hcf.Address, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
 func RemoveBaseDependency(r runner.Runner, roleInfra *api.RoleInfra, hcf *api.HostConfig, packagePath string) {
-	baseDependency := newBaseDependency(roleInfra, packagePath)
+	baseDependency := newBaseDependency(roleInfra, packagePath, false)
 
 	for _, dep := range baseDependency {
 		if err := dep.Remove(r); err != nil {
@@ -151,6 +170,40 @@ func CheckDependency(r runner.Runner, softwares []string) error {
 	return nil
 }
 
+// InstallTimeout return timeout of task which install softwares,
+// default timeout is extended by timeouts of softwares
+func InstallTimeout(softwares []*api.PackageConfig) time.Duration {
+	timeout := time.Second * constants.DefaultTaskTimeoutSeconds
+	for _, s := range softwares {
+		if s.Type == "shell" {
+			// shell is run by hook task
+			continue
+		}
+		if d, err := time.ParseDuration(s.TimeOut); err == nil {
+			timeout += d
+		}
+	}
+	return timeout
+}
+
+// hooksTimeout return timeout of task which run hooks one by one
+func hooksTimeout(hooks []*api.PackageConfig) time.Duration {
+	timeout := time.Second * constants.DefaultTaskTimeoutSeconds
+	var total time.Duration
+	for _, h := range hooks {
+		d, err := time.ParseDuration(h.TimeOut)
+		if err != nil {
+			d, _ = time.ParseDuration(defaultHookTimeout)
+		}
+		total += d
+	}
+	if total > timeout {
+		// keep default timeout as margin to copy and prepare hooks
+		return total + timeout
+	}
+	return timeout
+}
+
 func getShell(roleInfra *api.RoleInfra, schedule api.ScheduleType) []*api.PackageConfig {
 	shell := []*api.PackageConfig{}
 	for _, s := range roleInfra.Softwares {
@@ -191,10 +244,11 @@ func ExecuteHooks(hookConf *api.HookRunConfig) error {
 	envs[8] = fmt.Sprintf("EGGO_OPERATOR=%s", hookConf.Operator)
 	dp.envs = envs
 
-	dependencyTask := task.NewTaskInstance(&DependencyTask{
+	dependencyTask := task.NewTaskRetryInstance(&DependencyTask{
 		dp: dp,
-	})
+	}, task.DefaultRetryPolicy())
 
+	task.SetTimeout(dependencyTask, hooksTimeout(hookConf.Hooks))
 	if api.IsCleanupSchedule(hookConf.Scheduler) {
 		task.SetIgnoreErrorFlag(dependencyTask)
 	}
@@ -132,23 +132,54 @@ var (
 	}
 )
 
+// baseTuning is kernel modules and sysctl required by kubernetes on all nodes
+func baseTuning() *api.NodeTuning {
+	return &api.NodeTuning{
+		Sysctl: map[string]string{
+			"net.bridge.bridge-nf-call-iptables":  "1",
+			"net.bridge.bridge-nf-call-ip6tables": "1",
+			"net.ipv4.ip_forward":                 "1",
+			"vm.swappiness":                       "0",
+		},
+		KernelModules: []string{"br_netfilter"},
+	}
+}
+
+// workerTuning settings of kernel.* and vm.overcommit_memory are values expected by kubelet
+// with --protect-kernel-defaults, and ip_vs modules are required by kube-proxy in ipvs mode
+func workerTuning() *api.NodeTuning {
+	return api.MergeNodeTuning(baseTuning(), &api.NodeTuning{
+		Sysctl: map[string]string{
+			"vm.overcommit_memory": "1",
+			"kernel.panic":         "10",
+			"kernel.panic_on_oops": "1",
+		},
+		KernelModules: []string{"overlay", "ip_vs", "ip_vs_rr", "ip_vs_wrr", "ip_vs_sh", "nf_conntrack"},
+		DisableSwap:   true,
+	})
+}
+
 func RegisterInfra() map[uint16]*api.RoleInfra {
 	return map[uint16]*api.RoleInfra{
 		api.Master: {
 			Softwares: []*api.PackageConfig{},
 			OpenPorts: MasterPorts,
+			Tuning:    baseTuning(),
 		},
 		api.Worker: {
 			Softwares: []*api.PackageConfig{},
 			OpenPorts: WorkerPorts,
+			Tuning:    workerTuning(),
 		},
 		api.ETCD: {
 			Softwares: []*api.PackageConfig{},
 			OpenPorts: EtcdPorts,
+			Tuning:    baseTuning(),
 		},
 		api.LoadBalance: {
 			Softwares: []*api.PackageConfig{},
 			OpenPorts: []*api.OpenPorts{},
+			Tuning:    baseTuning(),
 		},
 	}
 }
@@ -0,0 +1,133 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: provide kubectl functions to check health of cluster
+ ******************************************************************************/
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	k8scorev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+)
+
+const (
+	healthCheckTimeout = 10 * time.Second
+)
+
+func getClusterKubeClient(cluster string) (*kubernetes.Clientset, error) {
+	path := filepath.Join(api.GetClusterHomePath(cluster), constants.KubeConfigFileNameAdmin)
+	return GetKubeClient(path)
+}
+
+// CheckAPIServerReady request /readyz of apiserver through the endpoint in admin.conf,
+// which is the loadbalance if it is configured.
+func CheckAPIServerReady(cluster string) error {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	body, err := cs.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("apiserver is not ready: %v", err)
+	}
+	if strings.TrimSpace(string(body)) != "ok" {
+		return fmt.Errorf("apiserver is not ready: %s", string(body))
+	}
+
+	return nil
+}
+
+// GetNodesReady return ready condition of nodes, key is name of node
+func GetNodesReady(cluster string) (map[string]bool, error) {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	nodes, err := cs.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(nodes.Items))
+	for _, n := range nodes.Items {
+		ready := false
+		for _, c := range n.Status.Conditions {
+			if c.Type == k8scorev1.NodeReady {
+				ready = c.Status == k8scorev1.ConditionTrue
+				break
+			}
+		}
+		result[n.Name] = ready
+	}
+
+	return result, nil
+}
+
+// CheckDeploymentReady check all replicas of deployment are ready
+func CheckDeploymentReady(cluster string, namespace string, name string) error {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	d, err := cs.AppsV1().Deployments(namespace).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	var want int32 = 1
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	if d.Status.ReadyReplicas < want {
+		return fmt.Errorf("deployment %s/%s ready replicas %d/%d", namespace, name, d.Status.ReadyReplicas, want)
+	}
+
+	return nil
+}
+
+// WaitNodeReady wait until ready condition of node is true
+func WaitNodeReady(cluster string, name string, timeout time.Duration) error {
+	finish := time.After(timeout)
+	for {
+		ready, err := GetNodesReady(cluster)
+		if err == nil && ready[name] {
+			return nil
+		}
+		select {
+		case <-finish:
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("node %s is not ready", name)
+		case <-time.After(time.Second * 2):
+		}
+	}
+}
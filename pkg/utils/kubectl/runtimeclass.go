@@ -0,0 +1,73 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: provide kubectl functions to manage RuntimeClass of runtime handlers
+ ******************************************************************************/
+package kubectl
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyRuntimeClass create or update RuntimeClass, pods of it are scheduled to nodes matched nodeSelector
+func ApplyRuntimeClass(cluster string, name string, handler string, nodeSelector map[string]string) error {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	rc := &nodev1.RuntimeClass{
+		ObjectMeta: v1.ObjectMeta{Name: name},
+		Handler:    handler,
+	}
+	if len(nodeSelector) != 0 {
+		rc.Scheduling = &nodev1.Scheduling{NodeSelector: nodeSelector}
+	}
+
+	old, err := cs.NodeV1().RuntimeClasses().Get(context.TODO(), name, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err = cs.NodeV1().RuntimeClasses().Create(context.TODO(), rc, v1.CreateOptions{}); err != nil {
+			return err
+		}
+		logrus.Infof("create runtimeclass: %s success", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rc.ResourceVersion = old.ResourceVersion
+	if _, err = cs.NodeV1().RuntimeClasses().Update(context.TODO(), rc, v1.UpdateOptions{}); err != nil {
+		return err
+	}
+	logrus.Infof("update runtimeclass: %s success", name)
+	return nil
+}
+
+// DeleteRuntimeClass delete RuntimeClass, not found is ignored
+func DeleteRuntimeClass(cluster string, name string) error {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = cs.NodeV1().RuntimeClasses().Delete(context.TODO(), name, v1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
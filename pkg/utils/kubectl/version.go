@@ -0,0 +1,48 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: provide kubectl functions to get versions of cluster
+ ******************************************************************************/
+package kubectl
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetClusterVersions return version of apiserver, and kubelet versions of nodes, key is name of node
+func GetClusterVersions(cluster string) (string, map[string]string, error) {
+	cs, err := getClusterKubeClient(cluster)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sv, err := cs.Discovery().ServerVersion()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	nodes, err := cs.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	kubelets := make(map[string]string, len(nodes.Items))
+	for _, n := range nodes.Items {
+		kubelets[n.Name] = n.Status.NodeInfo.KubeletVersion
+	}
+
+	return sv.GitVersion, kubelets, nil
+}
@@ -0,0 +1,114 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: events of tasks and phases, used by machine-readable output
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"sync"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+const (
+	EventTaskStarted   = "task-started"
+	EventTaskFinished  = "task-finished"
+	EventTaskFailed    = "task-failed"
+	EventTaskRetry     = "task-retry"
+	EventPhaseStarted  = "phase-started"
+	EventPhaseFinished = "phase-finished"
+	EventPhaseFailed   = "phase-failed"
+	EventPhaseSkipped  = "phase-skipped"
+)
+
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Phase   string    `json:"phase,omitempty"`
+	Nodes   []string  `json:"nodes,omitempty"`
+	Node    string    `json:"node,omitempty"`
+	Address string    `json:"address,omitempty"`
+	Task    string    `json:"task,omitempty"`
+	Attempt int       `json:"attempt,omitempty"`
+	// elapsed time of finished task or phase
+	ElapsedSeconds float64 `json:"elapsedSeconds,omitempty"`
+	// task finished in previous run is not run again
+	Skipped bool `json:"skipped,omitempty"`
+	// failed task ignore error, node continue to run tasks
+	Ignored bool   `json:"ignored,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type EventHandler func(e *Event)
+
+var (
+	eventLock    sync.Mutex
+	eventHandler EventHandler
+)
+
+// SetEventHandler set handler to receive events, events are dropped if handler is nil.
+// Handler is called one by one, so it need not to be thread safe
+func SetEventHandler(h EventHandler) {
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	eventHandler = h
+}
+
+func emitEvent(e *Event) {
+	eventLock.Lock()
+	defer eventLock.Unlock()
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Error = secrets.Scrub(e.Error)
+	recordReportEvent(e)
+	if eventHandler != nil {
+		eventHandler(e)
+	}
+}
+
+func newTaskEvent(typ string, host *api.HostConfig, name string, attempt int, elapsed time.Duration, err error) *Event {
+	e := &Event{
+		Type:           typ,
+		Node:           host.Name,
+		Address:        host.Address,
+		Task:           name,
+		Attempt:        attempt,
+		ElapsedSeconds: elapsed.Seconds(),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// StartPhase report phase of operation started on nodes, and return function to report it finished
+func StartPhase(name string, nodes []string) func(err error) {
+	start := time.Now()
+	emitEvent(&Event{Type: EventPhaseStarted, Phase: name, Nodes: nodes})
+	return func(err error) {
+		finishPhase(name, nodes, time.Since(start), err)
+	}
+}
+
+func finishPhase(name string, nodes []string, elapsed time.Duration, err error) {
+	e := &Event{Type: EventPhaseFinished, Phase: name, Nodes: nodes, ElapsedSeconds: elapsed.Seconds()}
+	if err != nil {
+		e.Type = EventPhaseFailed
+		e.Error = err.Error()
+	}
+	emitEvent(e)
+}
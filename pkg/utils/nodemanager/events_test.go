@@ -0,0 +1,110 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of events
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+func collectEvents() (*[]Event, func()) {
+	var events []Event
+	SetEventHandler(func(e *Event) {
+		events = append(events, *e)
+	})
+	return &events, func() {
+		SetEventHandler(nil)
+	}
+}
+
+func TestTaskEvents(t *testing.T) {
+	hcf := &api.HostConfig{Name: "event-node", Address: "192.168.0.30"}
+	if err := RegisterNode(hcf, &MockRunner{}); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	defer UnRegisterAllNodes()
+	events, reset := collectEvents()
+	defer reset()
+
+	policy := &task.RetryPolicy{MaxAttempts: 2, Interval: 10 * time.Millisecond}
+	flaky := &flakyTask{failures: 1, err: fmt.Errorf("connection refused")}
+	if err := RunTaskOnNodes(task.NewTaskRetryInstance(flaky, policy), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	ignore := &funcTask{name: "ignoreTask", fn: func() error { return fmt.Errorf("ignore me") }}
+	if err := RunTaskOnNodes(task.NewTaskIgnoreErrInstance(ignore), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	if err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second); err != nil {
+		t.Fatalf("wait node failed: %v", err)
+	}
+	SetEventHandler(nil)
+
+	expects := []struct {
+		typ     string
+		task    string
+		attempt int
+	}{
+		{EventTaskStarted, "flakyTask", 1},
+		{EventTaskRetry, "flakyTask", 1},
+		{EventTaskStarted, "flakyTask", 2},
+		{EventTaskFinished, "flakyTask", 2},
+		{EventTaskStarted, "ignoreTask", 1},
+		{EventTaskFailed, "ignoreTask", 1},
+	}
+	if len(*events) != len(expects) {
+		t.Fatalf("expect %d events, get: %+v", len(expects), *events)
+	}
+	for i, exp := range expects {
+		e := (*events)[i]
+		if e.Type != exp.typ || e.Task != exp.task || e.Attempt != exp.attempt ||
+			e.Node != hcf.Name || e.Address != hcf.Address || e.Time.IsZero() {
+			t.Fatalf("event %d expect %+v, get: %+v", i, exp, e)
+		}
+	}
+	if last := (*events)[len(expects)-1]; !last.Ignored || last.Error != "ignore me" {
+		t.Fatalf("failed task should ignore error: %+v", last)
+	}
+}
+
+func TestPhaseEvents(t *testing.T) {
+	events, reset := collectEvents()
+	defer reset()
+
+	s := NewScheduler(1)
+	s.AddStep(&Step{Name: "first", Run: func() error { return fmt.Errorf("first failed") }})
+	s.AddStep(&Step{Name: "second", Requires: []Prerequisite{{Name: "first"}}, Run: ok})
+	if _, err := s.Run(); err != nil {
+		t.Fatalf("run scheduler failed: %v", err)
+	}
+
+	types := []string{EventPhaseStarted, EventPhaseFailed, EventPhaseSkipped}
+	if len(*events) != len(types) {
+		t.Fatalf("expect %d events, get: %+v", len(types), *events)
+	}
+	for i, typ := range types {
+		if (*events)[i].Type != typ {
+			t.Fatalf("event %d expect %s, get: %+v", i, typ, (*events)[i])
+		}
+	}
+	if (*events)[1].Phase != "first" || (*events)[1].Error != "first failed" || (*events)[2].Phase != "second" {
+		t.Fatalf("unexpected phase events: %+v", *events)
+	}
+}
@@ -0,0 +1,215 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: persistent journal of tasks run on nodes, used to resume operation
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+const (
+	JournalRunning = "running"
+	JournalFailed  = "failed"
+	JournalSuccess = "success"
+)
+
+type JournalTask struct {
+	Name string `json:"name"`
+	// task with same name maybe run more than once on one node
+	Seq     int       `json:"seq"`
+	Status  string    `json:"status"`
+	Elapsed string    `json:"elapsed"`
+	Skipped bool      `json:"skipped,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+type JournalNode struct {
+	Name  string         `json:"name"`
+	Tasks []*JournalTask `json:"tasks"`
+}
+
+type Journal struct {
+	Operation  string                  `json:"operation"`
+	Status     string                  `json:"status"`
+	Message    string                  `json:"message,omitempty"`
+	StartTime  time.Time               `json:"startTime"`
+	UpdateTime time.Time               `json:"updateTime"`
+	Nodes      map[string]*JournalNode `json:"nodes"`
+
+	path string
+	// tasks finished in the resumed journal, key is address of node
+	done map[string]map[string]bool
+	// count of tasks with same name on node in this run
+	seq map[string]map[string]int
+}
+
+var (
+	journalLock sync.Mutex
+	journal     *Journal
+)
+
+func journalTaskKey(name string, seq int) string {
+	return fmt.Sprintf("%s#%d", name, seq)
+}
+
+// LoadJournal read journal from file
+func LoadJournal(path string) (*Journal, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("parse journal %s failed: %v", path, err)
+	}
+	return j, nil
+}
+
+func (j *Journal) save() error {
+	j.UpdateTime = time.Now()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), constants.EggoHomeDirMode); err != nil {
+		return err
+	}
+	// write to temp file and rename, so that journal never be broken by interrupt
+	tmp := j.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, constants.DeployConfigFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// StartJournal record tasks run on nodes into path, if resume is set,
+// tasks succeeded in the existed journal will be skipped
+func StartJournal(path string, operation string, resume bool) error {
+	j := &Journal{
+		Operation: operation,
+		Status:    JournalRunning,
+		StartTime: time.Now(),
+		Nodes:     make(map[string]*JournalNode),
+		path:      path,
+		done:      make(map[string]map[string]bool),
+		seq:       make(map[string]map[string]int),
+	}
+
+	if resume {
+		old, err := LoadJournal(path)
+		if err != nil {
+			return fmt.Errorf("load journal to resume failed: %v", err)
+		}
+		if old.Operation != operation {
+			return fmt.Errorf("cannot resume %s with journal of %s", operation, old.Operation)
+		}
+		for addr, n := range old.Nodes {
+			j.done[addr] = make(map[string]bool)
+			for _, t := range n.Tasks {
+				if t.Status == task.SUCCESS {
+					j.done[addr][journalTaskKey(t.Name, t.Seq)] = true
+				}
+			}
+		}
+	}
+
+	if err := j.save(); err != nil {
+		return fmt.Errorf("save journal %s failed: %v", path, err)
+	}
+
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	journal = j
+	return nil
+}
+
+// FinishJournal save result of operation, and stop recording
+func FinishJournal(err error) {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	if journal == nil {
+		return
+	}
+	journal.Status = JournalSuccess
+	if err != nil {
+		journal.Status = JournalFailed
+		journal.Message = err.Error()
+	}
+	if serr := journal.save(); serr != nil {
+		logrus.Warnf("save journal %s failed: %v", journal.path, serr)
+	}
+	journal = nil
+}
+
+// nextJournalSeq return sequence of task on node, and whether task is finished in resumed journal
+func nextJournalSeq(hcf *api.HostConfig, t task.Task) (int, bool) {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	if journal == nil {
+		return 0, false
+	}
+	if _, ok := journal.seq[hcf.Address]; !ok {
+		journal.seq[hcf.Address] = make(map[string]int)
+	}
+	journal.seq[hcf.Address][t.Name()]++
+	seq := journal.seq[hcf.Address][t.Name()]
+
+	// task collect result for later tasks, must run again
+	if task.IsAlwaysRun(t) {
+		return seq, false
+	}
+	return seq, journal.done[hcf.Address][journalTaskKey(t.Name(), seq)]
+}
+
+func recordJournalTask(hcf *api.HostConfig, t task.Task, seq int, err error, useTime time.Duration, skipped bool) {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+	if journal == nil {
+		return
+	}
+	n, ok := journal.Nodes[hcf.Address]
+	if !ok {
+		n = &JournalNode{Name: hcf.Name}
+		journal.Nodes[hcf.Address] = n
+	}
+	jt := &JournalTask{
+		Name:    t.Name(),
+		Seq:     seq,
+		Status:  task.SUCCESS,
+		Elapsed: useTime.String(),
+		Skipped: skipped,
+		Time:    time.Now(),
+	}
+	if err != nil {
+		jt.Status = err.Error()
+	}
+	n.Tasks = append(n.Tasks, jt)
+
+	if serr := journal.save(); serr != nil {
+		logrus.Warnf("save journal %s failed: %v", journal.path, serr)
+	}
+}
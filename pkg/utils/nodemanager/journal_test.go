@@ -0,0 +1,136 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: nodemanager journal testcase
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+type countTask struct {
+	name string
+	fail bool
+
+	lock sync.Mutex
+	runs int
+}
+
+func (c *countTask) Name() string {
+	return c.name
+}
+
+func (c *countTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.runs++
+	if c.fail {
+		return fmt.Errorf("%s failed", c.name)
+	}
+	return nil
+}
+
+// run step1 twice and step2 on all nodes, and return count of runs
+func runJournalTasks(t *testing.T, nodes []string, failStep2 bool, alwaysRun bool) (int, int, error) {
+	if err := addNodes(); err != nil {
+		t.Fatalf("add nodes failed: %v", err)
+	}
+	defer releaseNodes(nodes)
+
+	step1 := &countTask{name: "step1"}
+	step2 := &countTask{name: "step2", fail: failStep2}
+	t2 := task.NewTaskInstance(step2)
+	if alwaysRun {
+		task.SetAlwaysRunFlag(t2)
+	}
+	tasks := []task.Task{task.NewTaskInstance(step1), task.NewTaskInstance(step1), t2}
+	if err := RunTasksOnNodes(tasks, nodes); err != nil {
+		t.Fatalf("run tasks failed: %v", err)
+	}
+	err := WaitNodesFinish(nodes, time.Second*30)
+	return step1.runs, step2.runs, err
+}
+
+func TestJournalResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eggo-journal-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal", "deploy.json")
+	nodes := []string{"192.168.0.1", "192.168.0.2"}
+
+	if err = StartJournal(path, "deploy", true); err == nil {
+		t.Fatalf("resume without journal should fail")
+	}
+
+	if err = StartJournal(path, "deploy", false); err != nil {
+		t.Fatalf("start journal failed: %v", err)
+	}
+	runs1, runs2, err := runJournalTasks(t, nodes, true, false)
+	if err == nil || runs1 != 4 || runs2 != 2 {
+		t.Fatalf("unexpect first run: %d, %d, %v", runs1, runs2, err)
+	}
+	FinishJournal(err)
+
+	j, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("load journal failed: %v", err)
+	}
+	if j.Status != JournalFailed || len(j.Nodes["192.168.0.1"].Tasks) != 3 || j.Nodes["192.168.0.1"].Tasks[1].Seq != 2 {
+		t.Fatalf("unexpect journal: %+v", j)
+	}
+
+	if err = StartJournal(path, "join", true); err == nil {
+		t.Fatalf("resume journal of other operation should fail")
+	}
+
+	// resume only run the failed step2
+	if err = StartJournal(path, "deploy", true); err != nil {
+		t.Fatalf("resume journal failed: %v", err)
+	}
+	runs1, runs2, err = runJournalTasks(t, nodes, false, false)
+	if err != nil || runs1 != 0 || runs2 != 2 {
+		t.Fatalf("unexpect resumed run: %d, %d, %v", runs1, runs2, err)
+	}
+	FinishJournal(nil)
+
+	if j, err = LoadJournal(path); err != nil || j.Status != JournalSuccess {
+		t.Fatalf("unexpect journal after resume: %+v, %v", j, err)
+	}
+	if !j.Nodes["192.168.0.2"].Tasks[0].Skipped || j.Nodes["192.168.0.2"].Tasks[2].Skipped {
+		t.Fatalf("unexpect tasks after resume: %+v", j.Nodes["192.168.0.2"].Tasks)
+	}
+
+	// task always run never be skipped
+	if err = StartJournal(path, "deploy", true); err != nil {
+		t.Fatalf("resume journal failed: %v", err)
+	}
+	runs1, runs2, err = runJournalTasks(t, nodes, false, true)
+	if err != nil || runs1 != 0 || runs2 != 2 {
+		t.Fatalf("unexpect run with always run task: %d, %d, %v", runs1, runs2, err)
+	}
+	FinishJournal(nil)
+}
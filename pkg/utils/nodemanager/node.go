@@ -16,6 +16,7 @@
 package nodemanager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -24,6 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
 	"isula.org/eggo/pkg/utils/runner"
 	"isula.org/eggo/pkg/utils/task"
 )
@@ -37,9 +39,8 @@ const (
 )
 
 const (
-	nodeQueueCapability  = 16
-	runTaskTimeOutSecond = 300
-	waitTaskMillisecond  = 200
+	nodeQueueCapability = 16
+	waitTaskMillisecond = 200
 )
 
 type NodeStatus struct {
@@ -77,6 +78,8 @@ type Node struct {
 	queue  chan task.Task
 	lock   sync.RWMutex
 	status NodeStatus
+	// deadline of running task, waiting node will not timeout before it
+	taskDeadline time.Time
 
 	tasksHistory []taskSummary
 }
@@ -92,14 +95,23 @@ func (n *Node) addHistory(t task.Task, err error, useTime time.Duration) {
 			ts.status = err.Error()
 		}
 	}
+	n.appendHistory(ts)
+}
+
+// appendHistory record task before update node status, so that it can be seen after node finished
+func (n *Node) appendHistory(ts taskSummary) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
 	n.tasksHistory = append(n.tasksHistory, ts)
 }
 
 func (n *Node) ShowTaskList() string {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("\n##################tasks on node: %s#################\n", n.host.Name))
-	for _, n := range n.tasksHistory {
-		sb.WriteString(fmt.Sprintf("name: %s, elapsed time: %s, message: %s\n", n.name, n.useTime.String(), n.status))
+	for _, ts := range n.tasksHistory {
+		sb.WriteString(fmt.Sprintf("name: %s, elapsed time: %s, message: %s\n", ts.name, ts.useTime.String(), ts.status))
 	}
 	sb.WriteString("#########################################\n")
 	return sb.String()
@@ -112,25 +124,24 @@ func (n *Node) GetStatus() NodeStatus {
 }
 
 func (n *Node) WaitNodeTasksFinish(timeout time.Duration) error {
-	finish := time.After(timeout)
+	finish := time.Now().Add(timeout)
 	for {
-		select {
-		case t := <-finish:
-			return fmt.Errorf("timeout %s for wait node: %s", t.String(), n.host.Name)
-		default:
-			n.lock.RLock()
-			s := n.status
-			msg := s.Message
-			n.lock.RUnlock()
-			if !s.TasksFinished() {
-				time.Sleep(time.Millisecond * waitTaskMillisecond)
-				continue
-			}
+		n.lock.RLock()
+		s := n.status
+		msg := s.Message
+		taskDeadline := n.taskDeadline
+		n.lock.RUnlock()
+		if s.TasksFinished() {
 			if s.HasError() {
 				return fmt.Errorf("%s", msg)
 			}
 			return nil
 		}
+		// running task is killed after its deadline, wait it for task with long timeout
+		if now := time.Now(); now.After(finish) && now.After(taskDeadline) {
+			return fmt.Errorf("timeout %s for wait node: %s", timeout, n.host.Name)
+		}
+		time.Sleep(time.Millisecond * waitTaskMillisecond)
 	}
 }
 
@@ -158,8 +169,8 @@ func (n *Node) updateNodeStatus(message string, status int) {
 
 func (n *Node) PushTask(t task.Task) bool {
 	// only run ignore error tasks to cleanup node
-	if n.status.HasError() && !task.IsIgnoreError(t) {
-		logrus.Debugf("node finished with error: %v", n.status.Message)
+	if s := n.GetStatus(); s.HasError() && !task.IsIgnoreError(t) {
+		logrus.Debugf("node finished with error: %v", s.Message)
 		return false
 	}
 
@@ -179,21 +190,90 @@ func (n *Node) Finish() {
 	logrus.Infof(n.ShowTaskList())
 }
 
+// runTaskOnce run task in timeout, task is killed if it is not finished in time
+func runTaskOnce(n *Node, t task.Task, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(manager.ctx, timeout)
+	defer cancel()
+
+	n.lock.Lock()
+	n.taskDeadline = time.Now().Add(timeout)
+	n.lock.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// commands of task are killed when ctx is done, so wait task return
+	err := t.Run(ctx, n.r.WithContext(ctx), n.host)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timeout %s to run task: %v", timeout, err)
+	}
+	return err
+}
+
 func doRunTask(n *Node, t task.Task) {
+	seq, done := nextJournalSeq(n.host, t)
+	if done {
+		t.AddLabel(n.host.Address, task.SUCCESS)
+		logrus.Infof("skip task: %s on %s, which finished in previous run\n", t.Name(), n.host.Address)
+		n.appendHistory(taskSummary{name: t.Name(), status: "skipped, finished in previous run"})
+		recordJournalTask(n.host, t, seq, nil, 0, true)
+		e := newTaskEvent(EventTaskFinished, n.host, t.Name(), 0, 0, nil)
+		e.Skipped = true
+		emitEvent(e)
+		n.updateNodeStatus("", FinishStatus)
+		return
+	}
+
+	timeout := task.GetTimeout(t)
+	if timeout == 0 {
+		timeout = time.Second * constants.DefaultTaskTimeoutSeconds
+	}
+	policy := task.GetRetryPolicy(t)
+
+	// time of task includes all attempts and backoff between them
 	start := time.Now()
-	echan := make(chan error)
-	go func(ec chan error) {
-		select {
-		// TODO: maybe we need get timeout from task
-		case <-time.After(time.Second * runTaskTimeOutSecond):
-			ec <- fmt.Errorf("timeout to run task")
-		case ec <- t.Run(n.r, n.host):
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		emitEvent(newTaskEvent(EventTaskStarted, n.host, t.Name(), attempt, 0, nil))
+		err = runTaskOnce(n, t, timeout)
+		if !policy.ShouldRetry(attempt, err) || manager.ctx.Err() != nil {
+			break
 		}
-	}(echan)
-
-	err := <-echan
+		backoff := policy.Backoff(attempt)
+		logrus.Warnf("run task: %s on node: %s failed: %v, retry after %s (%d/%d)", t.Name(), n.host.Address, err,
+			backoff, attempt+1, policy.MaxAttempts)
+		n.appendHistory(taskSummary{name: t.Name(), useTime: time.Since(start),
+			status: fmt.Sprintf("attempt %d failed, retry after %s: %v", attempt, backoff, err)})
+		emitEvent(newTaskEvent(EventTaskRetry, n.host, t.Name(), attempt, time.Since(start), err))
+		n.lock.Lock()
+		n.taskDeadline = time.Now().Add(backoff + timeout)
+		n.lock.Unlock()
+		if serr := task.Sleep(manager.ctx, backoff); serr != nil {
+			break
+		}
+	}
 	finish := time.Now()
 
+	if err != nil && manager.ctx.Err() != nil {
+		label := fmt.Sprintf("%s: task: %s on node: %s is cancelled", task.FAILED, t.Name(), n.host.Address)
+		t.AddLabel(n.host.Address, label)
+		logrus.Warnf("%s", label)
+		n.appendHistory(taskSummary{name: t.Name(), useTime: finish.UTC().Sub(start), status: "cancelled"})
+		recordJournalTask(n.host, t, seq, fmt.Errorf("cancelled"), finish.UTC().Sub(start), false)
+		emitEvent(newTaskEvent(EventTaskFailed, n.host, t.Name(), attempt, finish.UTC().Sub(start), fmt.Errorf("cancelled")))
+		// cancelled task always fail the node, even if it ignore error
+		n.updateNodeStatus(label, ErrorStatus)
+		return
+	}
+
+	n.addHistory(t, err, finish.UTC().Sub(start))
+	recordJournalTask(n.host, t, seq, err, finish.UTC().Sub(start), false)
+	e := newTaskEvent(EventTaskFinished, n.host, t.Name(), attempt, finish.UTC().Sub(start), err)
+	if err != nil {
+		e.Type = EventTaskFailed
+		e.Ignored = task.IsIgnoreError(t)
+	}
+	emitEvent(e)
 	if err != nil {
 		label := fmt.Sprintf("%s: run task: %s on node: %s fail: %v", task.FAILED, t.Name(), n.host.Address, err)
 		t.AddLabel(n.host.Address, label)
@@ -211,7 +291,6 @@ func doRunTask(n *Node, t task.Task) {
 		n.updateNodeStatus("", FinishStatus)
 		logrus.Infof("run task: %s success on %s\n", t.Name(), n.host.Address)
 	}
-	n.addHistory(t, err, finish.UTC().Sub(start))
 }
 
 func NewNode(hcf *api.HostConfig, r runner.Runner) (*Node, error) {
@@ -16,6 +16,7 @@
 package nodemanager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -32,10 +33,31 @@ type NodeManager struct {
 	// key is node.Address
 	nodes map[string]*Node
 	lock  sync.RWMutex
+	// parent context of all tasks, cancelled by CancelTasks
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-var manager = &NodeManager{
-	nodes: make(map[string]*Node, 2),
+var manager = newNodeManager()
+
+func newNodeManager() *NodeManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &NodeManager{
+		nodes:  make(map[string]*Node, 2),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// CancelTasks kill running tasks on all nodes, tasks pushed later are cancelled without running
+func CancelTasks() {
+	logrus.Warn("cancel tasks on all nodes")
+	manager.cancel()
+}
+
+// TasksCancelled return whether CancelTasks is called
+func TasksCancelled() bool {
+	return manager.ctx.Err() != nil
 }
 
 // return: key is node IP; value true is failed, false is success
@@ -266,18 +288,25 @@ outfor:
 }
 
 func WaitNodesFinish(nodes []string, timeout time.Duration) error {
-	manager.lock.RLock()
-	defer manager.lock.RUnlock()
 	var errmsg string
+	var waitNodes []*Node
 
+	// do not hold lock when wait, other steps maybe push tasks at the same time
+	manager.lock.RLock()
 	for _, id := range nodes {
 		n, ok := manager.nodes[id]
 		if !ok {
+			manager.lock.RUnlock()
 			return fmt.Errorf("unknown node %s", id)
 		}
+		waitNodes = append(waitNodes, n)
+	}
+	manager.lock.RUnlock()
+
+	for _, n := range waitNodes {
 		err := n.WaitNodeTasksFinish(timeout)
 		if err != nil {
-			errmsg = fmt.Sprintf("node: %s with error: %v\n%s", id, err, errmsg)
+			errmsg = fmt.Sprintf("node: %s with error: %v\n%s", n.host.Address, err, errmsg)
 		}
 	}
 	if errmsg != "" {
@@ -16,8 +16,11 @@
 package nodemanager
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -46,6 +49,10 @@ func (m *MockRunner) RunShell(shell string, name string) (string, error) {
 	return "", nil
 }
 
+func (m *MockRunner) WithContext(ctx context.Context) runner.Runner {
+	return m
+}
+
 func (m *MockRunner) Reconnect() error {
 	logrus.Infof("reconnect")
 	return nil
@@ -60,7 +67,7 @@ type MockTask struct {
 	name string
 }
 
-func (m *MockTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (m *MockTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	rand.Seed(time.Now().UnixNano())
 
 	err := r.Copy("/home/data", "/data")
@@ -182,7 +189,7 @@ type ErrorTask struct {
 	name string
 }
 
-func (m *ErrorTask) Run(r runner.Runner, hcf *api.HostConfig) error {
+func (m *ErrorTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
 	rand.Seed(time.Now().UnixNano())
 
 	time.Sleep(time.Duration(rand.Intn(1000)) * time.Millisecond)
@@ -194,3 +201,200 @@ func (m *ErrorTask) Run(r runner.Runner, hcf *api.HostConfig) error {
 func (m *ErrorTask) Name() string {
 	return m.name
 }
+
+type closeCountRunner struct {
+	MockRunner
+	closed int
+}
+
+func (m *closeCountRunner) Close() {
+	m.closed++
+}
+
+func TestUnRegisterNodeCloseRunner(t *testing.T) {
+	hcf := &api.HostConfig{
+		Name:    "close-node",
+		Address: "192.168.0.10",
+	}
+	r := &closeCountRunner{}
+	if err := RegisterNode(hcf, r); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	UnRegisterNode(hcf.Address)
+	if r.closed != 1 {
+		t.Fatalf("runner should be closed once after unregister node, closed: %d", r.closed)
+	}
+}
+
+// waitTask wait until it is cancelled or finished after d
+type waitTask struct {
+	d       time.Duration
+	started chan struct{}
+}
+
+func (w *waitTask) Name() string {
+	return "waitTask"
+}
+
+func (w *waitTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	if w.started != nil {
+		close(w.started)
+	}
+	return task.Sleep(ctx, w.d)
+}
+
+func TestTaskTimeout(t *testing.T) {
+	hcf := &api.HostConfig{Name: "timeout-node", Address: "192.168.0.20"}
+	if err := RegisterNode(hcf, &MockRunner{}); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	defer UnRegisterAllNodes()
+
+	// wait node until task finished in its own timeout
+	long := task.NewTaskInstance(&waitTask{d: 500 * time.Millisecond})
+	task.SetTimeout(long, time.Minute)
+	if err := RunTaskOnNodes(long, []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	if err := WaitNodesFinish([]string{hcf.Address}, 100*time.Millisecond); err != nil {
+		t.Fatalf("task with long timeout should success: %v", err)
+	}
+
+	short := task.NewTaskInstance(&waitTask{d: time.Minute})
+	task.SetTimeout(short, 200*time.Millisecond)
+	if err := RunTaskOnNodes(short, []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "timeout 200ms to run task") {
+		t.Fatalf("expect timeout of task, get: %v", err)
+	}
+	if !task.IsFailed(short.GetLabel(hcf.Address)) {
+		t.Fatalf("task should be failed: %s", short.GetLabel(hcf.Address))
+	}
+}
+
+func TestCancelTasks(t *testing.T) {
+	defer func() {
+		manager.ctx, manager.cancel = context.WithCancel(context.Background())
+	}()
+	hcf := &api.HostConfig{Name: "cancel-node", Address: "192.168.0.21"}
+	if err := RegisterNode(hcf, &MockRunner{}); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	defer UnRegisterAllNodes()
+
+	running := &waitTask{d: time.Minute, started: make(chan struct{})}
+	if err := RunTaskOnNodes(task.NewTaskInstance(running), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	<-running.started
+	CancelTasks()
+	if !TasksCancelled() {
+		t.Fatalf("tasks should be cancelled")
+	}
+	err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Fatalf("expect cancelled task, get: %v", err)
+	}
+
+	// task pushed after cancel is not run, even if it ignore error
+	later := &waitTask{started: make(chan struct{})}
+	lt := task.NewTaskIgnoreErrInstance(later)
+	if err = RunTaskOnNodes(lt, []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	if err = WaitNodesFinish([]string{hcf.Address}, 5*time.Second); err == nil {
+		t.Fatalf("expect cancelled task")
+	}
+	select {
+	case <-later.started:
+		t.Fatalf("task should not run after cancel")
+	default:
+	}
+
+	manager.lock.RLock()
+	history := manager.nodes[hcf.Address].ShowTaskList()
+	manager.lock.RUnlock()
+	if strings.Count(history, "message: cancelled") != 2 {
+		t.Fatalf("tasks should be recorded as cancelled: %s", history)
+	}
+}
+
+// flakyTask fail with err for the first failures times
+type flakyTask struct {
+	failures int
+	err      error
+	runs     int32
+}
+
+func (f *flakyTask) Name() string {
+	return "flakyTask"
+}
+
+func (f *flakyTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	if int(atomic.AddInt32(&f.runs, 1)) <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func TestTaskRetry(t *testing.T) {
+	hcf := &api.HostConfig{Name: "retry-node", Address: "192.168.0.22"}
+	if err := RegisterNode(hcf, &MockRunner{}); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	defer UnRegisterAllNodes()
+	policy := &task.RetryPolicy{MaxAttempts: 3, Interval: 10 * time.Millisecond, Retryable: task.IsTransientError}
+	transient := fmt.Errorf("dial tcp 192.168.0.1:6443: connect: connection refused")
+
+	// success after transient failures
+	flaky := &flakyTask{failures: 2, err: transient}
+	if err := RunTaskOnNodes(task.NewTaskRetryInstance(flaky, policy), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	if err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second); err != nil {
+		t.Fatalf("task should success after retry: %v", err)
+	}
+	manager.lock.RLock()
+	node := manager.nodes[hcf.Address]
+	manager.lock.RUnlock()
+	history := node.ShowTaskList()
+	if flaky.runs != 3 || !strings.Contains(history, "attempt 1 failed") || !strings.Contains(history, "attempt 2 failed") {
+		t.Fatalf("attempts should be recorded, runs: %d, history: %s", flaky.runs, history)
+	}
+	// elapsed time is measured from the first attempt, include backoff of 10ms and 20ms
+	node.lock.RLock()
+	last := node.tasksHistory[len(node.tasksHistory)-1]
+	node.lock.RUnlock()
+	if last.status != "success" || last.useTime < 30*time.Millisecond {
+		t.Fatalf("elapsed time should include all attempts: %s", history)
+	}
+
+	// do not retry permanent error
+	permanent := &flakyTask{failures: 1, err: fmt.Errorf("invalid yaml")}
+	if err := RunTaskOnNodes(task.NewTaskRetryInstance(permanent, policy), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	if err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second); err == nil || permanent.runs != 1 {
+		t.Fatalf("permanent error should not be retried, runs: %d, err: %v", permanent.runs, err)
+	}
+}
+
+func TestTaskRetryExhausted(t *testing.T) {
+	hcf := &api.HostConfig{Name: "retry-node", Address: "192.168.0.23"}
+	if err := RegisterNode(hcf, &MockRunner{}); err != nil {
+		t.Fatalf("register node failed: %v", err)
+	}
+	defer UnRegisterAllNodes()
+
+	flaky := &flakyTask{failures: 10, err: fmt.Errorf("connection reset by peer")}
+	policy := &task.RetryPolicy{MaxAttempts: 3, Interval: 10 * time.Millisecond}
+	if err := RunTaskOnNodes(task.NewTaskRetryInstance(flaky, policy), []string{hcf.Address}); err != nil {
+		t.Fatalf("run task failed: %v", err)
+	}
+	err := WaitNodesFinish([]string{hcf.Address}, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "connection reset by peer") || flaky.runs != 3 {
+		t.Fatalf("task should fail after 3 attempts, runs: %d, err: %v", flaky.runs, err)
+	}
+}
@@ -0,0 +1,439 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: timing report of tasks and phases run in operation
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+)
+
+const (
+	TimingInstall = "package-install"
+	TimingImage   = "image-load"
+	TimingService = "service-start"
+	// time of tasks not in other categories
+	TimingOther = "other"
+
+	reportTimeFormat   = "20060102-150405.000"
+	slowestTasksOfNode = 5
+)
+
+type ReportTask struct {
+	Name           string    `json:"name"`
+	Start          time.Time `json:"start"`
+	Finish         time.Time `json:"finish"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	Attempts       int       `json:"attempts,omitempty"`
+	Status         string    `json:"status"`
+}
+
+type ReportPhase struct {
+	Name           string    `json:"name"`
+	Nodes          []string  `json:"nodes,omitempty"`
+	Start          time.Time `json:"start"`
+	Finish         time.Time `json:"finish"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	Status         string    `json:"status"`
+	// node finished its last task of phase at the end
+	SlowestNode string `json:"slowestNode,omitempty"`
+}
+
+type ReportNode struct {
+	Name           string        `json:"name"`
+	Address        string        `json:"address"`
+	ElapsedSeconds float64       `json:"elapsedSeconds"`
+	SlowestTasks   []*ReportTask `json:"slowestTasks"`
+	// seconds spent in package install, image load, service start and others
+	Categories map[string]float64 `json:"categories"`
+
+	tasks []*ReportTask
+}
+
+// PhaseComparison compare wall time of phases with same name with previous run
+type PhaseComparison struct {
+	Name            string  `json:"name"`
+	ElapsedSeconds  float64 `json:"elapsedSeconds"`
+	PreviousSeconds float64 `json:"previousSeconds"`
+	DeltaSeconds    float64 `json:"deltaSeconds"`
+}
+
+type ReportComparison struct {
+	Report          string             `json:"report"`
+	StartTime       time.Time          `json:"startTime"`
+	PreviousSeconds float64            `json:"previousSeconds"`
+	DeltaSeconds    float64            `json:"deltaSeconds"`
+	Phases          []*PhaseComparison `json:"phases"`
+	Categories      []*PhaseComparison `json:"categories"`
+}
+
+type Report struct {
+	Cluster        string    `json:"cluster"`
+	Operation      string    `json:"operation"`
+	Status         string    `json:"status"`
+	Message        string    `json:"message,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	FinishTime     time.Time `json:"finishTime"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	// chain of phases, each one waited for the previous one, and the last one finished the operation
+	CriticalPath []*ReportPhase     `json:"criticalPath"`
+	Phases       []*ReportPhase     `json:"phases"`
+	Nodes        []*ReportNode      `json:"nodes"`
+	Categories   map[string]float64 `json:"categories"`
+	Previous     *ReportComparison  `json:"previous,omitempty"`
+
+	// running tasks, key is address of node and name of task
+	running map[string]*ReportTask
+	// running phases, key is name and nodes of phase
+	runPhases map[string]*ReportPhase
+	nodes     map[string]*ReportNode
+}
+
+var (
+	reportLock sync.Mutex
+	report     *Report
+)
+
+// StartReport record timing of tasks and phases run on nodes of cluster
+func StartReport(cluster string, operation string) {
+	reportLock.Lock()
+	defer reportLock.Unlock()
+	report = &Report{
+		Cluster:    cluster,
+		Operation:  operation,
+		StartTime:  time.Now(),
+		Categories: make(map[string]float64),
+		running:    make(map[string]*ReportTask),
+		runPhases:  make(map[string]*ReportPhase),
+		nodes:      make(map[string]*ReportNode),
+	}
+}
+
+// StartTiming record time spent in category by task on node, until returned function is called
+func StartTiming(hcf *api.HostConfig, category string) func() {
+	start := time.Now()
+	return func() {
+		reportLock.Lock()
+		defer reportLock.Unlock()
+		if report == nil || hcf == nil {
+			return
+		}
+		report.node(hcf.Name, hcf.Address).Categories[category] += time.Since(start).Seconds()
+	}
+}
+
+func (r *Report) node(name, address string) *ReportNode {
+	n, ok := r.nodes[address]
+	if !ok {
+		n = &ReportNode{Name: name, Address: address, Categories: make(map[string]float64)}
+		r.nodes[address] = n
+	}
+	return n
+}
+
+func phaseKey(name string, nodes []string) string {
+	return fmt.Sprintf("%s%v", name, nodes)
+}
+
+func recordReportEvent(e *Event) {
+	reportLock.Lock()
+	defer reportLock.Unlock()
+	if report == nil {
+		return
+	}
+
+	switch e.Type {
+	case EventTaskStarted:
+		key := e.Address + "/" + e.Task
+		if _, ok := report.running[key]; !ok {
+			report.running[key] = &ReportTask{Name: e.Task, Start: e.Time}
+		}
+	case EventTaskFinished, EventTaskFailed:
+		key := e.Address + "/" + e.Task
+		t, ok := report.running[key]
+		if !ok {
+			// skipped task is never started
+			t = &ReportTask{Name: e.Task, Start: e.Time}
+		}
+		delete(report.running, key)
+		t.Finish = e.Time
+		t.ElapsedSeconds = t.Finish.Sub(t.Start).Seconds()
+		t.Attempts = e.Attempt
+		t.Status = taskReportStatus(e)
+		n := report.node(e.Node, e.Address)
+		n.tasks = append(n.tasks, t)
+	case EventPhaseStarted:
+		report.runPhases[phaseKey(e.Phase, e.Nodes)] = &ReportPhase{Name: e.Phase, Nodes: e.Nodes, Start: e.Time}
+	case EventPhaseFinished, EventPhaseFailed:
+		key := phaseKey(e.Phase, e.Nodes)
+		p, ok := report.runPhases[key]
+		if !ok {
+			return
+		}
+		delete(report.runPhases, key)
+		p.Finish = e.Time
+		p.ElapsedSeconds = p.Finish.Sub(p.Start).Seconds()
+		p.Status = StepSuccess
+		if e.Type == EventPhaseFailed {
+			p.Status = StepFailed
+		}
+		report.Phases = append(report.Phases, p)
+	case EventPhaseSkipped:
+		report.Phases = append(report.Phases, &ReportPhase{Name: e.Phase, Nodes: e.Nodes, Start: e.Time,
+			Finish: e.Time, Status: StepSkipped})
+	}
+}
+
+func taskReportStatus(e *Event) string {
+	switch {
+	case e.Skipped:
+		return "skipped"
+	case e.Type == EventTaskFinished:
+		return "success"
+	case e.Ignored:
+		return "ignored: " + e.Error
+	default:
+		return "failed: " + e.Error
+	}
+}
+
+// analyze fill slowest tasks, categories and critical path after operation finished
+func (r *Report) analyze() {
+	for _, n := range r.nodes {
+		var total float64
+		for _, t := range n.tasks {
+			total += t.ElapsedSeconds
+		}
+		n.ElapsedSeconds = total
+		other := total
+		for c, s := range n.Categories {
+			other -= s
+			r.Categories[c] += s
+		}
+		if other > 0 {
+			n.Categories[TimingOther] = other
+			r.Categories[TimingOther] += other
+		}
+
+		n.SlowestTasks = append([]*ReportTask{}, n.tasks...)
+		sort.SliceStable(n.SlowestTasks, func(i, j int) bool {
+			return n.SlowestTasks[i].ElapsedSeconds > n.SlowestTasks[j].ElapsedSeconds
+		})
+		if len(n.SlowestTasks) > slowestTasksOfNode {
+			n.SlowestTasks = n.SlowestTasks[:slowestTasksOfNode]
+		}
+		r.Nodes = append(r.Nodes, n)
+	}
+	sort.Slice(r.Nodes, func(i, j int) bool {
+		return r.Nodes[i].ElapsedSeconds > r.Nodes[j].ElapsedSeconds
+	})
+
+	sort.SliceStable(r.Phases, func(i, j int) bool {
+		return r.Phases[i].Start.Before(r.Phases[j].Start)
+	})
+	for _, p := range r.Phases {
+		p.SlowestNode = r.slowestNode(p)
+	}
+	r.CriticalPath = criticalPath(r.Phases)
+}
+
+// slowestNode return node of phase which finished its last task of phase latest
+func (r *Report) slowestNode(p *ReportPhase) string {
+	var slowest string
+	var last time.Time
+	for _, addr := range p.Nodes {
+		n, ok := r.nodes[addr]
+		if !ok {
+			continue
+		}
+		for _, t := range n.tasks {
+			if t.Finish.Before(p.Start) || t.Finish.After(p.Finish) {
+				continue
+			}
+			if t.Finish.After(last) {
+				last = t.Finish
+				slowest = addr
+			}
+		}
+	}
+	return slowest
+}
+
+// criticalPath start from phase finished at last, and go back to the phase finished latest
+// before it started, which is the prerequisite it waited for
+func criticalPath(phases []*ReportPhase) []*ReportPhase {
+	var cur *ReportPhase
+	for _, p := range phases {
+		if p.Status != StepSkipped && (cur == nil || p.Finish.After(cur.Finish)) {
+			cur = p
+		}
+	}
+
+	var path []*ReportPhase
+	for cur != nil {
+		path = append([]*ReportPhase{cur}, path...)
+		var prev *ReportPhase
+		for _, p := range phases {
+			if p == cur || p.Status == StepSkipped || p.Finish.After(cur.Start) {
+				continue
+			}
+			if prev == nil || p.Finish.After(prev.Finish) {
+				prev = p
+			}
+		}
+		cur = prev
+	}
+	return path
+}
+
+// phaseSpans return wall time of phases with same name, from the first start to the last finish
+func phaseSpans(phases []*ReportPhase) (map[string]float64, []string) {
+	starts := make(map[string]time.Time)
+	finishes := make(map[string]time.Time)
+	var names []string
+	for _, p := range phases {
+		if p.Status == StepSkipped {
+			continue
+		}
+		if s, ok := starts[p.Name]; !ok || p.Start.Before(s) {
+			if !ok {
+				names = append(names, p.Name)
+			}
+			starts[p.Name] = p.Start
+		}
+		if f, ok := finishes[p.Name]; !ok || p.Finish.After(f) {
+			finishes[p.Name] = p.Finish
+		}
+	}
+	spans := make(map[string]float64)
+	for _, n := range names {
+		spans[n] = finishes[n].Sub(starts[n]).Seconds()
+	}
+	return spans, names
+}
+
+func compareReport(cur *Report, prev *Report, prevPath string) *ReportComparison {
+	c := &ReportComparison{
+		Report:          prevPath,
+		StartTime:       prev.StartTime,
+		PreviousSeconds: prev.ElapsedSeconds,
+		DeltaSeconds:    cur.ElapsedSeconds - prev.ElapsedSeconds,
+	}
+	curSpans, names := phaseSpans(cur.Phases)
+	prevSpans, _ := phaseSpans(prev.Phases)
+	for _, n := range names {
+		c.Phases = append(c.Phases, &PhaseComparison{Name: n, ElapsedSeconds: curSpans[n],
+			PreviousSeconds: prevSpans[n], DeltaSeconds: curSpans[n] - prevSpans[n]})
+	}
+	for _, n := range []string{TimingInstall, TimingImage, TimingService, TimingOther} {
+		c.Categories = append(c.Categories, &PhaseComparison{Name: n, ElapsedSeconds: cur.Categories[n],
+			PreviousSeconds: prev.Categories[n], DeltaSeconds: cur.Categories[n] - prev.Categories[n]})
+	}
+	return c
+}
+
+// previousReport return path of the latest report of operation in dir
+func previousReport(dir string, operation string) string {
+	matches, err := filepath.Glob(filepath.Join(dir, operation+"-*.json"))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	// timestamp in name is sortable
+	sort.Strings(matches)
+	return matches[len(matches)-1]
+}
+
+// LoadReport read timing report from file
+func LoadReport(path string) (*Report, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Report) save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, constants.EggoHomeDirMode); err != nil {
+		return "", err
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", r.Operation, r.StartTime.Format(reportTimeFormat)))
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".json", data, constants.DeployConfigFileMode); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := writeHTMLReport(&sb, r); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(base+".html", []byte(sb.String()), constants.DeployConfigFileMode); err != nil {
+		return "", err
+	}
+	return base, nil
+}
+
+// FinishReport analyze timing of operation, and write report into dir as json and html,
+// return path of report without extension. Report is dropped if dir is empty
+func FinishReport(dir string, err error) (string, error) {
+	reportLock.Lock()
+	r := report
+	report = nil
+	reportLock.Unlock()
+	if r == nil {
+		return "", fmt.Errorf("no report is started")
+	}
+	if dir == "" {
+		return "", nil
+	}
+
+	r.FinishTime = time.Now()
+	r.ElapsedSeconds = r.FinishTime.Sub(r.StartTime).Seconds()
+	r.Status = JournalSuccess
+	if err != nil {
+		r.Status = JournalFailed
+		r.Message = err.Error()
+	}
+	r.analyze()
+
+	if prevPath := previousReport(dir, r.Operation); prevPath != "" {
+		prev, lerr := LoadReport(prevPath)
+		if lerr != nil {
+			logrus.Warnf("load previous report %s failed: %v", prevPath, lerr)
+		} else {
+			r.Previous = compareReport(r, prev, prevPath)
+		}
+	}
+
+	return r.save(dir)
+}
@@ -0,0 +1,131 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: html page of timing report
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+const reportHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Operation}} of {{.Cluster}} at {{time .StartTime}}</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table { border-collapse: collapse; margin-bottom: 20px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.bar { position: relative; width: 600px; height: 14px; background: #eee; }
+.bar div { position: absolute; height: 14px; background: #4a90d9; }
+.bar div.failed { background: #d9534f; }
+.slower { color: #d9534f; }
+.faster { color: #2e8b57; }
+</style>
+</head>
+<body>
+<h1>{{.Operation}} of cluster {{.Cluster}}</h1>
+<p>status: {{.Status}}{{if .Message}} ({{.Message}}){{end}}, start: {{time .StartTime}}, elapsed: {{seconds .ElapsedSeconds}}</p>
+
+<h2>Critical path</h2>
+<table>
+<tr><th>phase</th><th>nodes</th><th>elapsed</th><th>slowest node</th><th>timeline</th></tr>
+{{range .CriticalPath}}<tr><td>{{.Name}}</td><td>{{nodes .Nodes}}</td><td>{{seconds .ElapsedSeconds}}</td><td>{{.SlowestNode}}</td><td>{{bar $ .}}</td></tr>
+{{end}}</table>
+
+<h2>Phases</h2>
+<table>
+<tr><th>phase</th><th>nodes</th><th>status</th><th>elapsed</th><th>timeline</th></tr>
+{{range .Phases}}<tr><td>{{.Name}}</td><td>{{nodes .Nodes}}</td><td>{{.Status}}</td><td>{{seconds .ElapsedSeconds}}</td><td>{{bar $ .}}</td></tr>
+{{end}}</table>
+
+<h2>Time by category</h2>
+<table>
+<tr><th>node</th>{{range categories}}<th>{{.}}</th>{{end}}</tr>
+<tr><td>all nodes</td>{{range categories}}<td>{{seconds (index $.Categories .)}}</td>{{end}}</tr>
+{{range $n := .Nodes}}<tr><td>{{$n.Name}} ({{$n.Address}})</td>{{range categories}}<td>{{seconds (index $n.Categories .)}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Slowest tasks per node</h2>
+{{range .Nodes}}<h3>{{.Name}} ({{.Address}}), tasks elapsed: {{seconds .ElapsedSeconds}}</h3>
+<table>
+<tr><th>task</th><th>elapsed</th><th>attempts</th><th>status</th></tr>
+{{range .SlowestTasks}}<tr><td>{{.Name}}</td><td>{{seconds .ElapsedSeconds}}</td><td>{{.Attempts}}</td><td>{{.Status}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Previous}}<h2>Compared with previous run</h2>
+<p>previous run at {{time .StartTime}}: {{seconds .PreviousSeconds}}, {{delta .DeltaSeconds}}</p>
+<table>
+<tr><th>phase or category</th><th>elapsed</th><th>previous</th><th>delta</th></tr>
+{{range .Phases}}<tr><td>{{.Name}}</td><td>{{seconds .ElapsedSeconds}}</td><td>{{seconds .PreviousSeconds}}</td><td>{{delta .DeltaSeconds}}</td></tr>
+{{end}}{{range .Categories}}<tr><td>{{.Name}}</td><td>{{seconds .ElapsedSeconds}}</td><td>{{seconds .PreviousSeconds}}</td><td>{{delta .DeltaSeconds}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.1fs", s)
+}
+
+// timelineBar show phase in time line of operation
+func timelineBar(r *Report, p *ReportPhase) template.HTML {
+	total := r.FinishTime.Sub(r.StartTime).Seconds()
+	if total <= 0 {
+		return ""
+	}
+	left := p.Start.Sub(r.StartTime).Seconds() / total * 100
+	width := p.ElapsedSeconds / total * 100
+	class := ""
+	if p.Status == StepFailed {
+		class = ` class="failed"`
+	}
+	// only numbers are formatted into html
+	return template.HTML(fmt.Sprintf(`<div class="bar"><div%s style="left: %.2f%%; width: %.2f%%"></div></div>`,
+		class, left, width))
+}
+
+var reportFuncs = template.FuncMap{
+	"seconds": seconds,
+	"time": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+	"nodes": func(nodes []string) string {
+		if len(nodes) == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%v", nodes)
+	},
+	"categories": func() []string {
+		return []string{TimingInstall, TimingImage, TimingService, TimingOther}
+	},
+	"bar": timelineBar,
+	"delta": func(d float64) template.HTML {
+		if d > 0 {
+			return template.HTML(fmt.Sprintf(`<span class="slower">+%s</span>`, seconds(d)))
+		}
+		return template.HTML(fmt.Sprintf(`<span class="faster">%s</span>`, seconds(d)))
+	},
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(reportFuncs).Parse(reportHTML))
+
+func writeHTMLReport(w io.Writer, r *Report) error {
+	return reportTemplate.Execute(w, r)
+}
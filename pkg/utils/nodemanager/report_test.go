@@ -0,0 +1,143 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of timing report
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+// timingTask sleep in category, and then sleep out of any category
+type timingTask struct {
+	name     string
+	category string
+	in       time.Duration
+	out      time.Duration
+}
+
+func (tt *timingTask) Name() string {
+	return tt.name
+}
+
+func (tt *timingTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	if tt.category != "" {
+		done := StartTiming(hcf, tt.category)
+		time.Sleep(tt.in)
+		done()
+	}
+	time.Sleep(tt.out)
+	return nil
+}
+
+func runReportOperation(t *testing.T, dir string, hosts []*api.HostConfig, install time.Duration) *Report {
+	StartReport("test-cluster", "deploy")
+	s := NewScheduler(2)
+	s.AddStep(&Step{
+		Name:  "infra",
+		Nodes: []string{hosts[0].Address},
+		Run: func() error {
+			return RunTaskOnNodes(task.NewTaskInstance(&timingTask{name: "SetupInfraTask", category: TimingInstall,
+				in: install, out: 10 * time.Millisecond}), []string{hosts[0].Address})
+		},
+	})
+	s.AddStep(&Step{
+		Name:  "quick",
+		Nodes: []string{hosts[1].Address},
+		Run: func() error {
+			return RunTaskOnNodes(task.NewTaskInstance(&timingTask{name: "QuickTask"}), []string{hosts[1].Address})
+		},
+	})
+	s.AddStep(&Step{
+		Name:     "services",
+		Nodes:    []string{hosts[0].Address},
+		Requires: []Prerequisite{{Name: "infra"}, {Name: "quick"}},
+		Run: func() error {
+			return RunTaskOnNodes(task.NewTaskInstance(&timingTask{name: "ServiceTask", category: TimingService,
+				in: 20 * time.Millisecond}), []string{hosts[0].Address})
+		},
+	})
+	if _, err := s.Run(); err != nil {
+		t.Fatalf("run scheduler failed: %v", err)
+	}
+
+	base, err := FinishReport(dir, nil)
+	if err != nil {
+		t.Fatalf("finish report failed: %v", err)
+	}
+	html, err := ioutil.ReadFile(base + ".html")
+	if err != nil || !strings.Contains(string(html), "Critical path") {
+		t.Fatalf("invalid html report: %v", err)
+	}
+	r, err := LoadReport(base + ".json")
+	if err != nil {
+		t.Fatalf("load report failed: %v", err)
+	}
+	return r
+}
+
+func TestTimingReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eggo-report-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	hosts := []*api.HostConfig{
+		{Name: "master0", Address: "192.168.0.40"},
+		{Name: "worker0", Address: "192.168.0.41"},
+	}
+	for _, h := range hosts {
+		if err := RegisterNode(h, &MockRunner{}); err != nil {
+			t.Fatalf("register node failed: %v", err)
+		}
+	}
+	defer UnRegisterAllNodes()
+
+	r := runReportOperation(t, dir, hosts, 200*time.Millisecond)
+	if r.Status != JournalSuccess || r.Previous != nil || len(r.Phases) != 3 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	// quick step finished before infra, so services waited for infra
+	if len(r.CriticalPath) != 2 || r.CriticalPath[0].Name != "infra" || r.CriticalPath[1].Name != "services" {
+		t.Fatalf("unexpected critical path: %+v", r.CriticalPath)
+	}
+	if r.CriticalPath[0].SlowestNode != hosts[0].Address {
+		t.Fatalf("slowest node of infra should be %s: %+v", hosts[0].Address, r.CriticalPath[0])
+	}
+	if r.Categories[TimingInstall] < 0.2 || r.Categories[TimingService] < 0.02 || r.Categories[TimingOther] < 0.01 {
+		t.Fatalf("unexpected categories: %v", r.Categories)
+	}
+	if len(r.Nodes) != 2 || r.Nodes[0].Address != hosts[0].Address || r.Nodes[0].SlowestTasks[0].Name != "SetupInfraTask" {
+		t.Fatalf("unexpected nodes: %+v", r.Nodes)
+	}
+
+	r = runReportOperation(t, dir, hosts, 10*time.Millisecond)
+	if r.Previous == nil || r.Previous.DeltaSeconds >= 0 {
+		t.Fatalf("second run should be faster than previous: %+v", r.Previous)
+	}
+	for _, c := range r.Previous.Categories {
+		if c.Name == TimingInstall && c.DeltaSeconds >= 0 {
+			t.Fatalf("package install should be faster than previous: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: schedule steps on nodes by their prerequisites
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/constants"
+)
+
+const (
+	StepSuccess = "success"
+	StepFailed  = "failed"
+	// step is not run, because its prerequisites failed
+	StepSkipped = "skipped"
+)
+
+// Prerequisite refer to steps with Name which run on any of Nodes,
+// all steps with Name are referred if Nodes is empty
+type Prerequisite struct {
+	Name  string
+	Nodes []string
+	// only wait steps finished, failure of them do not skip this step
+	AllowFailure bool
+}
+
+// Step is vertex of schedule graph. Run is called after all prerequisites success,
+// and step is finished after tasks pushed to Nodes by Run finished
+type Step struct {
+	Name     string
+	Nodes    []string
+	Requires []Prerequisite
+	Run      func() error
+	// timeout to wait tasks on Nodes, default is DefaultTaskWaitMinutes
+	Timeout time.Duration
+}
+
+func (s *Step) String() string {
+	if len(s.Nodes) == 0 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s%v", s.Name, s.Nodes)
+}
+
+type StepResult struct {
+	Step    *Step
+	Status  string
+	Err     error
+	Elapsed time.Duration
+}
+
+type dependency struct {
+	index        int
+	allowFailure bool
+}
+
+// Scheduler run independent steps concurrently, up to limit steps at a time,
+// and skip steps depend on failed steps
+type Scheduler struct {
+	steps []*Step
+	limit int
+}
+
+func NewScheduler(limit int) *Scheduler {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &Scheduler{limit: limit}
+}
+
+func (s *Scheduler) AddStep(step *Step) {
+	s.steps = append(s.steps, step)
+}
+
+func hasCommonNode(nodes []string, others []string) bool {
+	for _, n := range nodes {
+		for _, o := range others {
+			if n == o {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve find steps each step depends on, and check cycle of graph
+func (s *Scheduler) resolve() ([][]dependency, error) {
+	deps := make([][]dependency, len(s.steps))
+	for i, st := range s.steps {
+		for _, p := range st.Requires {
+			for j, o := range s.steps {
+				if i == j || o.Name != p.Name {
+					continue
+				}
+				if len(p.Nodes) != 0 && !hasCommonNode(o.Nodes, p.Nodes) {
+					continue
+				}
+				deps[i] = append(deps[i], dependency{index: j, allowFailure: p.AllowFailure})
+			}
+		}
+	}
+
+	// topological sort, steps left are in cycle
+	indegree := make([]int, len(s.steps))
+	dependents := make([][]int, len(s.steps))
+	for i, ds := range deps {
+		indegree[i] = len(ds)
+		for _, d := range ds {
+			dependents[d.index] = append(dependents[d.index], i)
+		}
+	}
+	var queue []int
+	for i, d := range indegree {
+		if d == 0 {
+			queue = append(queue, i)
+		}
+	}
+	sorted := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		sorted++
+		for _, j := range dependents[i] {
+			indegree[j]--
+			if indegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	if sorted != len(s.steps) {
+		var cycle []string
+		for i, d := range indegree {
+			if d != 0 {
+				cycle = append(cycle, s.steps[i].String())
+			}
+		}
+		return nil, fmt.Errorf("cycle in prerequisites of steps: %s", strings.Join(cycle, ", "))
+	}
+
+	return deps, nil
+}
+
+func runStep(st *Step) *StepResult {
+	logrus.Infof("[scheduler] start step: %s", st)
+	phaseDone := StartPhase(st.Name, st.Nodes)
+	start := time.Now()
+	var err error
+	if st.Run != nil {
+		err = st.Run()
+	}
+	if err == nil && len(st.Nodes) != 0 {
+		timeout := st.Timeout
+		if timeout == 0 {
+			timeout = time.Minute * constants.DefaultTaskWaitMinutes
+		}
+		err = WaitNodesFinish(st.Nodes, timeout)
+	}
+
+	res := &StepResult{Step: st, Status: StepSuccess, Err: err, Elapsed: time.Since(start)}
+	phaseDone(err)
+	if err != nil {
+		res.Status = StepFailed
+		logrus.Errorf("[scheduler] step: %s failed: %v", st, err)
+	} else {
+		logrus.Infof("[scheduler] step: %s success, elapsed time: %s", st, res.Elapsed)
+	}
+	return res
+}
+
+// state return whether step can run, or the failed prerequisite which skip the step
+func state(deps []dependency, steps []*Step, results []*StepResult) (bool, *Step) {
+	ready := true
+	for _, d := range deps {
+		r := results[d.index]
+		if r == nil {
+			ready = false
+			continue
+		}
+		if r.Status != StepSuccess && !d.allowFailure {
+			return false, steps[d.index]
+		}
+	}
+	return ready, nil
+}
+
+type finishedStep struct {
+	index  int
+	result *StepResult
+}
+
+// Run schedule all steps, and return results in order of steps added
+func (s *Scheduler) Run() ([]*StepResult, error) {
+	deps, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*StepResult, len(s.steps))
+	started := make([]bool, len(s.steps))
+	finished, running := 0, 0
+	done := make(chan finishedStep)
+
+	for finished < len(s.steps) {
+		// skipped step maybe make other steps skipped, so check until nothing changed
+		for changed := true; changed; {
+			changed = false
+			for i, st := range s.steps {
+				if started[i] {
+					continue
+				}
+				ready, failed := state(deps[i], s.steps, results)
+				if failed != nil {
+					started[i] = true
+					finished++
+					changed = true
+					results[i] = &StepResult{Step: st, Status: StepSkipped,
+						Err: fmt.Errorf("prerequisite %s is not success", failed)}
+					logrus.Warnf("[scheduler] skip step: %s, prerequisite %s is not success", st, failed)
+					emitEvent(&Event{Type: EventPhaseSkipped, Phase: st.Name, Nodes: st.Nodes,
+						Error: results[i].Err.Error()})
+					continue
+				}
+				if !ready || running >= s.limit {
+					continue
+				}
+				started[i] = true
+				running++
+				go func(i int, st *Step) {
+					done <- finishedStep{index: i, result: runStep(st)}
+				}(i, st)
+			}
+		}
+
+		if finished == len(s.steps) {
+			break
+		}
+		if running == 0 {
+			// never happen, graph without cycle always has runnable step
+			return results, fmt.Errorf("no step can be scheduled")
+		}
+		fs := <-done
+		results[fs.index] = fs.result
+		running--
+		finished++
+	}
+
+	return results, nil
+}
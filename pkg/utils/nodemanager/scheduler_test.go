@@ -0,0 +1,232 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of scheduler
+ ******************************************************************************/
+
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/runner"
+	"isula.org/eggo/pkg/utils/task"
+)
+
+type funcTask struct {
+	name string
+	fn   func() error
+}
+
+func (f *funcTask) Name() string {
+	return f.name
+}
+
+func (f *funcTask) Run(ctx context.Context, r runner.Runner, hcf *api.HostConfig) error {
+	return f.fn()
+}
+
+// orderRecorder record order of steps finished
+type orderRecorder struct {
+	lock  sync.Mutex
+	order []string
+}
+
+func (o *orderRecorder) step(name string, fn func() error) func() error {
+	return func() error {
+		err := fn()
+		o.lock.Lock()
+		o.order = append(o.order, name)
+		o.lock.Unlock()
+		return err
+	}
+}
+
+func (o *orderRecorder) before(t *testing.T, first, second string) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	fi, si := -1, -1
+	for i, n := range o.order {
+		if n == first {
+			fi = i
+		}
+		if n == second {
+			si = i
+		}
+	}
+	if fi == -1 || si == -1 || fi > si {
+		t.Fatalf("expect %s finished before %s, order: %v", first, second, o.order)
+	}
+}
+
+func ok() error {
+	return nil
+}
+
+func TestSchedulerPrune(t *testing.T) {
+	var o orderRecorder
+	s := NewScheduler(4)
+	s.AddStep(&Step{Name: "a", Run: o.step("a", func() error { return fmt.Errorf("a failed") })})
+	s.AddStep(&Step{Name: "b", Requires: []Prerequisite{{Name: "a"}}, Run: o.step("b", ok)})
+	s.AddStep(&Step{Name: "c", Requires: []Prerequisite{{Name: "b"}}, Run: o.step("c", ok)})
+	s.AddStep(&Step{Name: "d", Run: o.step("d", ok)})
+	s.AddStep(&Step{Name: "e", Requires: []Prerequisite{{Name: "a", AllowFailure: true}, {Name: "d"}}, Run: o.step("e", ok)})
+	// prerequisite without step is satisfied
+	s.AddStep(&Step{Name: "f", Requires: []Prerequisite{{Name: "unknown"}}, Run: o.step("f", ok)})
+
+	results, err := s.Run()
+	if err != nil {
+		t.Fatalf("run scheduler failed: %v", err)
+	}
+	expects := []string{StepFailed, StepSkipped, StepSkipped, StepSuccess, StepSuccess, StepSuccess}
+	for i, r := range results {
+		if r.Status != expects[i] {
+			t.Fatalf("expect step %s %s, get %s: %v", r.Step, expects[i], r.Status, r.Err)
+		}
+	}
+	if !strings.Contains(results[2].Err.Error(), "prerequisite b") {
+		t.Fatalf("invalid reason of skipped step: %v", results[2].Err)
+	}
+	o.before(t, "a", "e")
+	o.before(t, "d", "e")
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	const limit = 3
+	var running, max int32
+	run := func() error {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&max)
+			if cur <= old || atomic.CompareAndSwapInt32(&max, old, cur) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	var o orderRecorder
+	s := NewScheduler(limit)
+	for i := 0; i < 3*limit; i++ {
+		name := fmt.Sprintf("independent-%d", i)
+		s.AddStep(&Step{Name: name, Run: o.step(name, run)})
+	}
+	s.AddStep(&Step{Name: "last", Requires: []Prerequisite{{Name: "independent-0"}, {Name: "independent-8"}}, Run: o.step("last", ok)})
+
+	start := time.Now()
+	if _, err := s.Run(); err != nil {
+		t.Fatalf("run scheduler failed: %v", err)
+	}
+	if max != limit {
+		t.Fatalf("expect %d steps run concurrently, get %d", limit, max)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("independent steps should run concurrently")
+	}
+	o.before(t, "independent-8", "last")
+}
+
+func TestSchedulerCycle(t *testing.T) {
+	s := NewScheduler(2)
+	s.AddStep(&Step{Name: "a", Requires: []Prerequisite{{Name: "c"}}})
+	s.AddStep(&Step{Name: "b", Requires: []Prerequisite{{Name: "a"}}})
+	s.AddStep(&Step{Name: "c", Requires: []Prerequisite{{Name: "b"}}})
+	s.AddStep(&Step{Name: "d"})
+	_, err := s.Run()
+	if err == nil || !strings.Contains(err.Error(), "cycle") || strings.Contains(err.Error(), "d") {
+		t.Fatalf("expect cycle of a, b, c: %v", err)
+	}
+}
+
+func TestSchedulerNodes(t *testing.T) {
+	nodes := []string{"192.168.0.11", "192.168.0.12", "192.168.0.13"}
+	for i, n := range nodes {
+		if err := RegisterNode(&api.HostConfig{Name: fmt.Sprintf("node%d", i), Address: n}, &MockRunner{}); err != nil {
+			t.Fatalf("register node failed: %v", err)
+		}
+	}
+	defer UnRegisterAllNodes()
+
+	// infra on node 2 is slow, and failed on node 3
+	slow := make(chan struct{})
+	infra := map[string]func() error{
+		nodes[0]: ok,
+		nodes[1]: func() error {
+			<-slow
+			return nil
+		},
+		nodes[2]: func() error { return fmt.Errorf("infra failed") },
+	}
+
+	var o orderRecorder
+	s := NewScheduler(8)
+	for _, n := range nodes {
+		id := n
+		s.AddStep(&Step{
+			Name:  "infra",
+			Nodes: []string{id},
+			Run: func() error {
+				return RunTaskOnNodes(task.NewTaskInstance(&funcTask{name: "infra", fn: o.step("infra-"+id, infra[id])}), []string{id})
+			},
+		})
+		s.AddStep(&Step{
+			Name:     "join",
+			Nodes:    []string{id},
+			Requires: []Prerequisite{{Name: "infra", Nodes: []string{id}}, {Name: "etcd"}},
+			Run:      o.step("join-"+id, ok),
+		})
+	}
+	// etcd only wait infra on node 1, and release slow infra on node 2
+	s.AddStep(&Step{
+		Name:     "etcd",
+		Nodes:    nodes[:1],
+		Requires: []Prerequisite{{Name: "infra", Nodes: nodes[:1]}},
+		Run: o.step("etcd", func() error {
+			close(slow)
+			return nil
+		}),
+	})
+
+	results, err := s.Run()
+	if err != nil {
+		t.Fatalf("run scheduler failed: %v", err)
+	}
+	status := make(map[string]string)
+	for _, r := range results {
+		status[r.Step.String()] = r.Status
+	}
+	expects := map[string]string{
+		fmt.Sprintf("infra[%s]", nodes[0]): StepSuccess,
+		fmt.Sprintf("infra[%s]", nodes[1]): StepSuccess,
+		fmt.Sprintf("infra[%s]", nodes[2]): StepFailed,
+		fmt.Sprintf("join[%s]", nodes[0]):  StepSuccess,
+		fmt.Sprintf("join[%s]", nodes[1]):  StepSuccess,
+		fmt.Sprintf("join[%s]", nodes[2]):  StepSkipped,
+		fmt.Sprintf("etcd[%s]", nodes[0]):  StepSuccess,
+	}
+	for k, v := range expects {
+		if status[k] != v {
+			t.Fatalf("expect step %s %s, get %s", k, v, status[k])
+		}
+	}
+	o.before(t, "etcd", "infra-"+nodes[1])
+	o.before(t, "infra-"+nodes[1], "join-"+nodes[1])
+}
@@ -0,0 +1,91 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: fetch file from remote node
+ ******************************************************************************/
+
+package runner
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// output of each command is about 1.3MB after base64 encoded
+	fetchChunkSize = 1024 * 1024
+	// length of base64 lines, which is multiple of 4, so each line can be decoded alone
+	fetchLineWidth = 76
+)
+
+// Fetch copy file from node to local, runner only support copy from local to node,
+// so read file chunk by chunk with base64 encoded by command, and decode it line by line.
+func Fetch(r Runner, src, dst string) error {
+	output, err := r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"stat -c %%s %s\"", src))
+	if err != nil {
+		return fmt.Errorf("get size of %s failed: %v", src, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size of %s: %v", src, err)
+	}
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	for i := int64(0); i*fetchChunkSize < size; i++ {
+		output, err = r.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"dd if=%s bs=%d skip=%d count=1 2>/dev/null | base64 -w %d\"",
+			src, fetchChunkSize, i, fetchLineWidth))
+		if err != nil {
+			return fmt.Errorf("read %s failed: %v", src, err)
+		}
+		if err = writeBase64Lines(w, output); err != nil {
+			return fmt.Errorf("decode chunk %d of %s failed: %v", i, src, err)
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// writeBase64Lines decode output line by line and write to w, lines maybe end with \r\n in pty
+func writeBase64Lines(w io.Writer, output string) error {
+	buf := make([]byte, base64.StdEncoding.DecodedLen(fetchLineWidth))
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > fetchLineWidth {
+			return fmt.Errorf("invalid length of line: %d", len(line))
+		}
+		n, err := base64.StdEncoding.Decode(buf, []byte(line))
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(buf[:n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: fetch file from remote node testcase
+ ******************************************************************************/
+
+package runner
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestFetch(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-fetch-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	defer installFakeTools(t, tempdir)()
+
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	r, err := NewSSHRunner(&api.HostConfig{
+		Name:     "fetch-node",
+		Address:  target.address,
+		Port:     target.port,
+		UserName: target.user,
+		Password: target.password,
+	})
+	if err != nil {
+		t.Fatalf("create ssh runner failed: %v", err)
+	}
+	defer r.Close()
+
+	// file of multi chunks, and the last chunk is not full
+	content := make([]byte, 5*fetchChunkSize+1234)
+	if _, err = rand.Read(content); err != nil {
+		t.Fatalf("generate content failed: %v", err)
+	}
+	src, dst := filepath.Join(tempdir, "src"), filepath.Join(tempdir, "dst")
+	if err = ioutil.WriteFile(src, content, 0600); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	start := time.Now()
+	if err = Fetch(r, src, dst); err != nil {
+		t.Fatalf("fetch file failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Fatalf("fetch file of %d bytes is too slow: %v", len(content), elapsed)
+	}
+	data, err := ioutil.ReadFile(dst)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Fatalf("invalid fetched file: %v, size: %d, expect: %d", err, len(data), len(content))
+	}
+
+	if err = Fetch(r, filepath.Join(tempdir, "not-exist"), dst); err == nil {
+		t.Fatalf("fetch not existed file should fail")
+	}
+}
+
+func TestWriteBase64Lines(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeBase64Lines(&buf, "aGVsbG8g\r\nd29ybGQ=\r\n"); err != nil || buf.String() != "hello world" {
+		t.Fatalf("decode lines in pty failed: %v, %s", err, buf.String())
+	}
+	long := bytes.Repeat([]byte("a"), fetchLineWidth+4)
+	if err := writeBase64Lines(&buf, string(long)); err == nil {
+		t.Fatalf("line longer than %d should fail", fetchLineWidth)
+	}
+}
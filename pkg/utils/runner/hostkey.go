@@ -0,0 +1,264 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: verify host keys of nodes with known_hosts of cluster
+ ******************************************************************************/
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"isula.org/eggo/pkg/constants"
+)
+
+const (
+	// trust host key on first use, and refuse to connect if it changed
+	HostKeyCheckTOFU = "tofu"
+	// only trust host key in config or pinned by rescan, and refuse to connect if it changed
+	HostKeyCheckStrict = "strict"
+	// accept any host key
+	HostKeyCheckOff = "off"
+
+	fingerprintPrefix = "SHA256:"
+)
+
+var (
+	// known_hosts is shared by connections of all nodes
+	knownHostsLock sync.Mutex
+
+	errHostKeyScanned = errors.New("host key scanned")
+)
+
+// HostKeyChecker verify host keys with fingerprints in config and keys pinned in known_hosts
+type HostKeyChecker struct {
+	KnownHosts string
+	Mode       string
+}
+
+func ValidHostKeyCheckMode(mode string) bool {
+	return mode == "" || mode == HostKeyCheckTOFU || mode == HostKeyCheckStrict || mode == HostKeyCheckOff
+}
+
+func ValidFingerprint(fingerprint string) bool {
+	return strings.HasPrefix(fingerprint, fingerprintPrefix) && len(fingerprint) > len(fingerprintPrefix)
+}
+
+func NewHostKeyChecker(knownHosts string, mode string) (*HostKeyChecker, error) {
+	if !ValidHostKeyCheckMode(mode) {
+		return nil, fmt.Errorf("invalid host key checking mode: %s, support: %s, %s, %s", mode,
+			HostKeyCheckTOFU, HostKeyCheckStrict, HostKeyCheckOff)
+	}
+	if mode == "" {
+		mode = HostKeyCheckTOFU
+	}
+	return &HostKeyChecker{KnownHosts: knownHosts, Mode: mode}, nil
+}
+
+// callback return host key callback of ssh, fingerprint is set in config of host
+func (c *HostKeyChecker) callback(fingerprint string) ssh.HostKeyCallback {
+	if c == nil || c.Mode == HostKeyCheckOff {
+		return ssh.InsecureIgnoreHostKey()
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		return c.check(hostname, remote, key, fingerprint)
+	}
+}
+
+func (c *HostKeyChecker) lookup(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if _, err := os.Stat(c.KnownHosts); os.IsNotExist(err) {
+		return &knownhosts.KeyError{}
+	}
+	cb, err := knownhosts.New(c.KnownHosts)
+	if err != nil {
+		return fmt.Errorf("load known hosts %s failed: %v", c.KnownHosts, err)
+	}
+	return cb(hostname, remote, key)
+}
+
+func (c *HostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey, fingerprint string) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	actual := ssh.FingerprintSHA256(key)
+	if fingerprint != "" {
+		if fingerprint != actual {
+			return fmt.Errorf("host key of %s mismatch with fingerprint in config: expect %s, get %s", hostname, fingerprint, actual)
+		}
+		// fingerprint in config is trusted, keep known_hosts same as it
+		if err := c.lookup(hostname, remote, key); err == nil {
+			return nil
+		}
+		return c.pinLocked(hostname, key)
+	}
+
+	err := c.lookup(hostname, remote, key)
+	if err == nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return err
+	}
+	if len(keyErr.Want) == 0 {
+		if c.Mode == HostKeyCheckStrict {
+			return fmt.Errorf("host key of %s is unknown: %s, set fingerprint in config or run 'eggo hosts rescan' to trust it",
+				hostname, actual)
+		}
+		logrus.Infof("trust host key of %s on first use: %s", hostname, actual)
+		return c.pinLocked(hostname, key)
+	}
+
+	// pinned key is only replaced by rescan
+	return fmt.Errorf("host key of %s changed: expect %s, get %s, run 'eggo hosts rescan' if the host is reinstalled",
+		hostname, ssh.FingerprintSHA256(keyErr.Want[0].Key), actual)
+}
+
+// pinLocked replace key of hostname in known_hosts
+func (c *HostKeyChecker) pinLocked(hostname string, key ssh.PublicKey) error {
+	data, err := ioutil.ReadFile(c.KnownHosts)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	entry := knownhosts.Normalize(hostname)
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == entry {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, knownhosts.Line([]string{hostname}, key))
+
+	if err = os.MkdirAll(filepath.Dir(c.KnownHosts), constants.EggoHomeDirMode); err != nil {
+		return err
+	}
+	tmp := c.KnownHosts + ".tmp"
+	if err = ioutil.WriteFile(tmp, []byte(strings.Join(lines, "\n")+"\n"), constants.DeployConfigFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.KnownHosts)
+}
+
+// scanHostKey get host key of ssh server by handshake, without login
+func scanHostKey(conn net.Conn, hostname string) (ssh.PublicKey, error) {
+	defer conn.Close()
+	// deadline is not supported by tunnel of bastion, just ignore error
+	_ = conn.SetDeadline(time.Now().Add(sshDialTimeout))
+
+	var key ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "eggo",
+		HostKeyCallback: func(h string, remote net.Addr, k ssh.PublicKey) error {
+			key = k
+			return errHostKeyScanned
+		},
+	}
+	_, _, _, err := ssh.NewClientConn(conn, hostname, config)
+	if key == nil {
+		return nil, fmt.Errorf("scan host key of %s failed: %v", hostname, err)
+	}
+	return key, nil
+}
+
+// ScannedKey is host key get by rescan
+type ScannedKey struct {
+	Address     string `json:"address"`
+	Fingerprint string `json:"fingerprint"`
+	Bastion     bool   `json:"bastion,omitempty"`
+}
+
+func (c *HostKeyChecker) repin(e *sshEndpoint, key ssh.PublicKey) (*ScannedKey, error) {
+	actual := ssh.FingerprintSHA256(key)
+	if e.fingerprint != "" && e.fingerprint != actual {
+		return nil, fmt.Errorf("host key of %s mismatch with fingerprint in config: expect %s, get %s",
+			e.hostPort(), e.fingerprint, actual)
+	}
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+	if err := c.pinLocked(e.hostPort(), key); err != nil {
+		return nil, fmt.Errorf("pin host key of %s failed: %v", e.hostPort(), err)
+	}
+	return &ScannedKey{Address: e.hostPort(), Fingerprint: actual}, nil
+}
+
+// rescan get current host keys of target and its bastion, and replace keys pinned in known_hosts
+func (c *HostKeyChecker) rescan(target *sshEndpoint, bastion *sshEndpoint) ([]*ScannedKey, error) {
+	var result []*ScannedKey
+	if bastion == nil {
+		conn, err := net.DialTimeout("tcp", target.hostPort(), sshDialTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s failed: %v", target.hostPort(), err)
+		}
+		key, err := scanHostKey(conn, target.hostPort())
+		if err != nil {
+			return nil, err
+		}
+		sk, err := c.repin(target, key)
+		if err != nil {
+			return nil, err
+		}
+		return append(result, sk), nil
+	}
+
+	conn, err := net.DialTimeout("tcp", bastion.hostPort(), sshDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to bastion %s failed: %v", bastion.hostPort(), err)
+	}
+	key, err := scanHostKey(conn, bastion.hostPort())
+	if err != nil {
+		return nil, err
+	}
+	sk, err := c.repin(bastion, key)
+	if err != nil {
+		return nil, err
+	}
+	sk.Bastion = true
+	result = append(result, sk)
+
+	// login bastion with new pinned key, and scan host key of target through it
+	bconfig, err := bastion.clientConfig(c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bastion: %v", err)
+	}
+	bclient, err := ssh.Dial("tcp", bastion.hostPort(), bconfig)
+	if err != nil {
+		return nil, fmt.Errorf("connect to bastion %s failed: %v", bastion.hostPort(), err)
+	}
+	defer bclient.Close()
+	tconn, err := bclient.Dial("tcp", target.hostPort())
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s through bastion %s failed: %v", target.hostPort(), bastion.hostPort(), err)
+	}
+	key, err = scanHostKey(tconn, target.hostPort())
+	if err != nil {
+		return nil, err
+	}
+	sk, err = c.repin(target, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, sk), nil
+}
@@ -0,0 +1,231 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of host key verification
+ ******************************************************************************/
+
+package runner
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func newTestHostKeyChecker(t *testing.T, mode string) (*HostKeyChecker, func()) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-hostkey-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	checker, err := NewHostKeyChecker(filepath.Join(tempdir, "cluster", "known_hosts"), mode)
+	if err != nil {
+		t.Fatalf("create host key checker failed: %v", err)
+	}
+	return checker, func() { os.RemoveAll(tempdir) }
+}
+
+// pinOtherKey pin a random key for address, just like the host is reinstalled
+func pinOtherKey(t *testing.T, checker *HostKeyChecker, address string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("create signer failed: %v", err)
+	}
+	if err = checker.pinLocked(address, signer.PublicKey()); err != nil {
+		t.Fatalf("pin key failed: %v", err)
+	}
+}
+
+func connectOnce(target *sshEndpoint, bastion *sshEndpoint, checker *HostKeyChecker) error {
+	conn, err := NewSSHConnection(target, bastion, checker)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+func TestHostKeyCheckerMode(t *testing.T) {
+	if _, err := NewHostKeyChecker("/tmp/known_hosts", "invalid"); err == nil {
+		t.Fatalf("invalid mode should fail")
+	}
+	checker, err := NewHostKeyChecker("/tmp/known_hosts", "")
+	if err != nil || checker.Mode != HostKeyCheckTOFU {
+		t.Fatalf("default mode should be tofu: %v", err)
+	}
+	if ValidFingerprint("SHA256:") || ValidFingerprint("MD5:aa:bb") || !ValidFingerprint("SHA256:abc") {
+		t.Fatalf("invalid result of fingerprint validation")
+	}
+}
+
+func TestHostKeyTOFU(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	checker, clean := newTestHostKeyChecker(t, HostKeyCheckTOFU)
+	defer clean()
+
+	// pin host key on first use
+	if err := connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("connect on first use failed: %v", err)
+	}
+	data, err := ioutil.ReadFile(checker.KnownHosts)
+	if err != nil {
+		t.Fatalf("read known hosts failed: %v", err)
+	}
+	if !strings.Contains(string(data), strings.Fields(string(ssh.MarshalAuthorizedKey(node.hostKey)))[1]) {
+		t.Fatalf("host key is not pinned: %s", string(data))
+	}
+	if err = connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("connect with pinned key failed: %v", err)
+	}
+	if data2, _ := ioutil.ReadFile(checker.KnownHosts); string(data2) != string(data) {
+		t.Fatalf("known hosts should not change: %s", string(data2))
+	}
+
+	// changed key is refused in tofu mode
+	pinOtherKey(t, checker, target.hostPort())
+	err = connectOnce(target, nil, checker)
+	if err == nil || !strings.Contains(err.Error(), "eggo hosts rescan") {
+		t.Fatalf("connect with changed key in tofu mode should fail: %v", err)
+	}
+	if data2, _ := ioutil.ReadFile(checker.KnownHosts); strings.Contains(string(data2), strings.Fields(string(ssh.MarshalAuthorizedKey(node.hostKey)))[1]) {
+		t.Fatalf("changed key should not be pinned: %s", string(data2))
+	}
+
+	// and in strict mode
+	checker.Mode = HostKeyCheckStrict
+	err = connectOnce(target, nil, checker)
+	if err == nil || !strings.Contains(err.Error(), "eggo hosts rescan") {
+		t.Fatalf("connect with changed key in strict mode should fail: %v", err)
+	}
+
+	// all keys are accepted if checking is off
+	checker.Mode = HostKeyCheckOff
+	if err = connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("connect without checking failed: %v", err)
+	}
+}
+
+func TestHostKeyStrictUnknown(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	checker, clean := newTestHostKeyChecker(t, HostKeyCheckStrict)
+	defer clean()
+
+	// unknown host without fingerprint is refused in strict mode
+	err := connectOnce(target, nil, checker)
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("connect to unknown host in strict mode should fail: %v", err)
+	}
+	if _, err = os.Stat(checker.KnownHosts); !os.IsNotExist(err) {
+		t.Fatalf("unknown key should not be pinned in strict mode: %v", err)
+	}
+
+	// trust host key by rescan
+	hcfg := &api.HostConfig{
+		Name:     "node-strict",
+		Address:  target.address,
+		Port:     target.port,
+		UserName: target.user,
+		Password: target.password,
+	}
+	if _, err = RescanHostKeys(hcfg, checker); err != nil {
+		t.Fatalf("rescan host keys failed: %v", err)
+	}
+	if err = connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("connect after rescan in strict mode failed: %v", err)
+	}
+}
+
+func TestHostKeyFingerprint(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	checker, clean := newTestHostKeyChecker(t, HostKeyCheckTOFU)
+	defer clean()
+
+	target.fingerprint = "SHA256:invalid"
+	if err := connectOnce(target, nil, checker); err == nil {
+		t.Fatalf("connect with mismatched fingerprint should fail")
+	}
+	if _, err := os.Stat(checker.KnownHosts); !os.IsNotExist(err) {
+		t.Fatalf("mismatched key should not be pinned: %v", err)
+	}
+
+	// fingerprint in config override key pinned before
+	pinOtherKey(t, checker, target.hostPort())
+	checker.Mode = HostKeyCheckStrict
+	target.fingerprint = ssh.FingerprintSHA256(node.hostKey)
+	if err := connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("connect with fingerprint in config failed: %v", err)
+	}
+	target.fingerprint = ""
+	if err := connectOnce(target, nil, checker); err != nil {
+		t.Fatalf("key in config should be pinned: %v", err)
+	}
+}
+
+func TestRescanHostKeys(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	bastion, bep := newTestNode(t)
+	defer bastion.listener.Close()
+	checker, clean := newTestHostKeyChecker(t, HostKeyCheckStrict)
+	defer clean()
+
+	hcfg := &api.HostConfig{
+		Name:     "node-behind-bastion",
+		Address:  target.address,
+		Port:     target.port,
+		UserName: target.user,
+		Password: target.password,
+		Bastion: &api.BastionConfig{
+			Address: bep.address,
+			Port:    bep.port,
+		},
+	}
+
+	// keys of both node and bastion are changed
+	pinOtherKey(t, checker, target.hostPort())
+	pinOtherKey(t, checker, bep.hostPort())
+	if err := connectOnce(hostEndpoint(hcfg), bastionEndpoint(hcfg), checker); err == nil {
+		t.Fatalf("connect with changed keys in strict mode should fail")
+	}
+
+	keys, err := RescanHostKeys(hcfg, checker)
+	if err != nil {
+		t.Fatalf("rescan host keys failed: %v", err)
+	}
+	if len(keys) != 2 || !keys[0].Bastion || keys[0].Fingerprint != ssh.FingerprintSHA256(bastion.hostKey) ||
+		keys[1].Bastion || keys[1].Fingerprint != ssh.FingerprintSHA256(node.hostKey) {
+		t.Fatalf("invalid scanned keys: %+v", keys)
+	}
+	if err = connectOnce(hostEndpoint(hcfg), bastionEndpoint(hcfg), checker); err != nil {
+		t.Fatalf("connect after rescan failed: %v", err)
+	}
+
+	// rescan refuse key mismatched with fingerprint in config
+	hcfg.Fingerprint = "SHA256:invalid"
+	if _, err = RescanHostKeys(hcfg, checker); err == nil {
+		t.Fatalf("rescan with mismatched fingerprint should fail")
+	}
+}
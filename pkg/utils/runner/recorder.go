@@ -0,0 +1,238 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: recording runner for dry run, which never touch nodes
+ ******************************************************************************/
+
+package runner
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+const (
+	StepCommand = "command"
+	StepShell   = "shell"
+	StepCopy    = "copy"
+)
+
+// FileWrite is a file rendered by eggo, and written on node by 'echo <base64> | base64 -d > path'
+type FileWrite struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type Step struct {
+	Type string `json:"type"`
+	// command of command step, or name of shell step
+	Command string `json:"command,omitempty"`
+	// content of shell step
+	Shell string      `json:"shell,omitempty"`
+	Src   string      `json:"src,omitempty"`
+	Dst   string      `json:"dst,omitempty"`
+	Files []FileWrite `json:"files,omitempty"`
+}
+
+type NodePlan struct {
+	Name    string  `json:"name"`
+	Address string  `json:"address"`
+	Steps   []*Step `json:"steps"`
+}
+
+var (
+	planLock sync.Mutex
+	plans    = make(map[string]*NodePlan)
+	// order of nodes registered
+	planOrder []string
+
+	fileWriteRegexp = regexp.MustCompile(`echo ([A-Za-z0-9+/=]+) \| base64 -d > ([^\s"';&|]+)`)
+	memberAddRegexp = regexp.MustCompile(`member add (\S+) --peer-urls=(\S+)`)
+	md5sumRegexp    = regexp.MustCompile(`md5sum (\S+)`)
+)
+
+// GetPlan return recorded steps of all nodes, in order of registered
+func GetPlan() []*NodePlan {
+	planLock.Lock()
+	defer planLock.Unlock()
+	var result []*NodePlan
+	for _, addr := range planOrder {
+		result = append(result, plans[addr])
+	}
+	return result
+}
+
+// ResetPlan drop all recorded steps
+func ResetPlan() {
+	planLock.Lock()
+	defer planLock.Unlock()
+	plans = make(map[string]*NodePlan)
+	planOrder = nil
+}
+
+func getNodePlan(hcfg *api.HostConfig) *NodePlan {
+	planLock.Lock()
+	defer planLock.Unlock()
+	// node maybe registered again, such as rollback of join, keep steps in one plan
+	if p, ok := plans[hcfg.Address]; ok {
+		return p
+	}
+	p := &NodePlan{Name: hcfg.Name, Address: hcfg.Address}
+	plans[hcfg.Address] = p
+	planOrder = append(planOrder, hcfg.Address)
+	return p
+}
+
+// output of 'etcdctl member list' with all registered nodes
+func fakeMemberList() string {
+	planLock.Lock()
+	defer planLock.Unlock()
+	var sb strings.Builder
+	for i, addr := range planOrder {
+		sb.WriteString(fmt.Sprintf("%016x, started, %s, https://%s:2380, https://%s:2379, %v\n",
+			i+1, plans[addr].Name, addr, addr, i == 0))
+	}
+	return sb.String()
+}
+
+func localMD5(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", md5.Sum(data))
+}
+
+// cannedOutput return output of command which eggo parsed to make decision,
+// so that dry run walk the same path as a fresh node with yum and rpm
+func (r *RecordingRunner) cannedOutput(cmd string) string {
+	if m := md5sumRegexp.FindStringSubmatch(cmd); m != nil {
+		// file copied to node keep the same as local
+		r.lock.Lock()
+		src, ok := r.copied[m[1]]
+		r.lock.Unlock()
+		if ok {
+			return localMD5(src)
+		}
+		return ""
+	}
+
+	switch {
+	case strings.Contains(cmd, "which apt"):
+		return "yum"
+	case strings.Contains(cmd, "which dpkg"):
+		return "rpm"
+	case strings.Contains(cmd, "which nginx"):
+		return "/usr/sbin/nginx"
+	case strings.Contains(cmd, "modules-path"):
+		return "/usr/lib64/nginx/modules"
+	case strings.Contains(cmd, "member list"):
+		return fakeMemberList()
+	}
+	if m := memberAddRegexp.FindStringSubmatch(cmd); m != nil {
+		return fmt.Sprintf("ETCD_NAME=%s\nETCD_INITIAL_CLUSTER=%s=%s\n", m[1], m[1], m[2])
+	}
+	return ""
+}
+
+// parseFileWrites decode files written by 'echo <base64> | base64 -d > path'
+func parseFileWrites(content string) []FileWrite {
+	var files []FileWrite
+	for _, m := range fileWriteRegexp.FindAllStringSubmatch(content, -1) {
+		data, err := base64.StdEncoding.DecodeString(m[1])
+		if err != nil {
+			continue
+		}
+		files = append(files, FileWrite{Path: m[2], Content: string(data)})
+	}
+	return files
+}
+
+// ShortenFileWrites replace base64 content of written files with path, to make command readable
+func ShortenFileWrites(content string) string {
+	return fileWriteRegexp.ReplaceAllString(content, "echo <content of $2> | base64 -d > $2")
+}
+
+// RecordingRunner record what eggo will do on node, and never connect to it
+type RecordingRunner struct {
+	Host *api.HostConfig
+	plan *NodePlan
+
+	lock sync.Mutex
+	// destination to source of copied files
+	copied map[string]string
+}
+
+func NewRecordingRunner(hcfg *api.HostConfig) (Runner, error) {
+	return &RecordingRunner{
+		Host:   hcfg,
+		plan:   getNodePlan(hcfg),
+		copied: make(map[string]string),
+	}, nil
+}
+
+func (r *RecordingRunner) record(s *Step) {
+	// plan is saved in file, never keep secrets in it
+	s.Command, s.Shell = secrets.Scrub(s.Command), secrets.Scrub(s.Shell)
+	for i := range s.Files {
+		s.Files[i].Content = secrets.Scrub(s.Files[i].Content)
+	}
+	planLock.Lock()
+	defer planLock.Unlock()
+	r.plan.Steps = append(r.plan.Steps, s)
+}
+
+func (r *RecordingRunner) Copy(src, dst string) error {
+	r.record(&Step{Type: StepCopy, Src: src, Dst: dst})
+	r.lock.Lock()
+	r.copied[dst] = src
+	r.lock.Unlock()
+	logrus.Debugf("[%s] record copy %s to %s", r.Host.Name, src, dst)
+	return nil
+}
+
+func (r *RecordingRunner) RunCommand(cmd string) (string, error) {
+	r.record(&Step{Type: StepCommand, Command: cmd, Files: parseFileWrites(cmd)})
+	logrus.Debugf("[%s] record command: %s", r.Host.Name, cmd)
+	return r.cannedOutput(cmd), nil
+}
+
+func (r *RecordingRunner) RunShell(shell string, name string) (string, error) {
+	r.record(&Step{Type: StepShell, Command: name, Shell: shell, Files: parseFileWrites(shell)})
+	logrus.Debugf("[%s] record shell: %s", r.Host.Name, name)
+	return "", nil
+}
+
+// WithContext return r itself, nothing is run on node by RecordingRunner
+func (r *RecordingRunner) WithContext(ctx context.Context) Runner {
+	return r
+}
+
+func (r *RecordingRunner) Reconnect() error {
+	// nothing to do
+	return nil
+}
+
+func (r *RecordingRunner) Close() {
+	// nothing to do
+}
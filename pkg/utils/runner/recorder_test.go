@@ -0,0 +1,97 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: recording runner testcase
+ ******************************************************************************/
+
+package runner
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"isula.org/eggo/pkg/api"
+)
+
+func TestRecordingRunner(t *testing.T) {
+	ResetPlan()
+	defer ResetPlan()
+
+	dir, err := ioutil.TempDir("", "eggo-recorder-")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "pkg.tar.gz")
+	if err = ioutil.WriteFile(src, []byte("package"), 0600); err != nil {
+		t.Fatalf("write package failed: %v", err)
+	}
+
+	master := &api.HostConfig{Name: "master0", Address: "192.168.0.2"}
+	r, _ := NewRecordingRunner(master)
+	worker, _ := NewRecordingRunner(&api.HostConfig{Name: "worker0", Address: "192.168.0.3"})
+
+	unit := "[Unit]\nDescription=etcd\n"
+	cmd := fmt.Sprintf("sudo -E /bin/sh -c \"echo %s | base64 -d > /usr/lib/systemd/system/etcd.service\"",
+		base64.StdEncoding.EncodeToString([]byte(unit)))
+	if _, err = r.RunCommand(cmd); err != nil {
+		t.Fatalf("run command failed: %v", err)
+	}
+	if out, _ := r.RunCommand("md5sum /root/pkg.tar.gz"); out != "" {
+		t.Fatalf("file not copied should have empty md5, get: %s", out)
+	}
+	if err = r.Copy(src, "/root/pkg.tar.gz"); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+	if out, _ := r.RunCommand("md5sum /root/pkg.tar.gz"); out != localMD5(src) {
+		t.Fatalf("copied file should have local md5, get: %s", out)
+	}
+	out, _ := r.RunCommand("etcdctl member add master1 --peer-urls=https://192.168.0.4:2380")
+	if !strings.Contains(out, "\nETCD_INITIAL_CLUSTER=master1=https://192.168.0.4:2380\n") {
+		t.Fatalf("unexpect output of member add: %s", out)
+	}
+	if out, _ = worker.RunCommand("etcdctl member list"); strings.Count(out, "\n") != 2 || !strings.Contains(out, "worker0") {
+		t.Fatalf("unexpect output of member list: %s", out)
+	}
+	if _, err = worker.RunShell("#!/bin/bash\nexit 0\n", "noop"); err != nil {
+		t.Fatalf("run shell failed: %v", err)
+	}
+
+	// register again should reuse plan of node
+	r, _ = NewRecordingRunner(master)
+	if _, err = r.RunCommand("systemctl start etcd"); err != nil {
+		t.Fatalf("run command failed: %v", err)
+	}
+
+	plans := GetPlan()
+	if len(plans) != 2 || plans[0].Name != "master0" || plans[1].Name != "worker0" {
+		t.Fatalf("unexpect plans: %+v", plans)
+	}
+	if len(plans[0].Steps) != 6 || len(plans[1].Steps) != 2 {
+		t.Fatalf("unexpect steps of plans: %d, %d", len(plans[0].Steps), len(plans[1].Steps))
+	}
+	files := plans[0].Steps[0].Files
+	if len(files) != 1 || files[0].Path != "/usr/lib/systemd/system/etcd.service" || files[0].Content != unit {
+		t.Fatalf("unexpect files: %+v", files)
+	}
+	if plans[0].Steps[2].Type != StepCopy || plans[1].Steps[1].Type != StepShell || plans[1].Steps[1].Command != "noop" {
+		t.Fatalf("unexpect steps: %+v, %+v", plans[0].Steps[2], plans[1].Steps[1])
+	}
+	if ShortenFileWrites(cmd) != "sudo -E /bin/sh -c \"echo <content of /usr/lib/systemd/system/etcd.service> | base64 -d > /usr/lib/systemd/system/etcd.service\"" {
+		t.Fatalf("unexpect shorten command: %s", ShortenFileWrites(cmd))
+	}
+}
@@ -16,6 +16,8 @@
 package runner
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -24,10 +26,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
+	"syscall"
 
 	kkv1alpha1 "github.com/kubesphere/kubekey/apis/kubekey/v1alpha1"
-	"github.com/kubesphere/kubekey/pkg/util/ssh"
 	"github.com/sirupsen/logrus"
 
 	"isula.org/eggo/pkg/api"
@@ -45,13 +46,78 @@ type Runner interface {
 	RunShell(content string, name string) (string, error)
 	Reconnect() error
 	Close()
+	// WithContext return runner on the same node, whose commands are killed when ctx is done
+	WithContext(ctx context.Context) Runner
 }
 
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// LocalRunner run commands on the machine running eggo
 type LocalRunner struct {
+	ctx context.Context
+}
+
+func (r *LocalRunner) WithContext(ctx context.Context) Runner {
+	return &LocalRunner{ctx: ctx}
+}
+
+// run cmd in new process group, and kill the group when context is done
+func (r *LocalRunner) run(cmd string) ([]byte, error) {
+	ctx := contextOrBackground(r.ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := exec.Command("/bin/sh", "-c", cmd)
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		case <-done:
+		}
+	}()
+	err := c.Wait()
+	close(done)
+	if ctx.Err() != nil {
+		return out.Bytes(), fmt.Errorf("command is killed: %v", ctx.Err())
+	}
+	return out.Bytes(), err
+}
+
+// sudo run cmd by root, sudo is unnecessary if eggo is run by root
+func (r *LocalRunner) sudo(cmd string) string {
+	if os.Geteuid() == 0 {
+		return fmt.Sprintf("/bin/sh -c \"%s\"", cmd)
+	}
+	return fmt.Sprintf("sudo -E /bin/sh -c \"%s\"", cmd)
 }
 
+func (r *LocalRunner) copyFile(src, dst string) error {
+	output, err := r.run(r.sudo(fmt.Sprintf("cp -f %s %s", src, dst)))
+	if err != nil {
+		logrus.Errorf("[local] copy %s to %s failed: %v\noutput: %v\n", src, dst, err, string(output))
+		return err
+	}
+	logrus.Debugf("[local] copy %s to %s success", src, dst)
+	return nil
+}
+
+// copyDir copy files in srcDir into dstDir, same as SSHRunner
 func (r *LocalRunner) copyDir(srcDir, dstDir string) error {
-	output, err := exec.Command("/bin/sh", "-c", fmt.Sprintf("cp -rf %v %v", srcDir, dstDir)).CombinedOutput()
+	cmd := r.sudo(fmt.Sprintf("mkdir -p %s && cp -rf %s/. %s", dstDir, srcDir, dstDir))
+	output, err := r.run(cmd)
 	if err != nil {
 		logrus.Errorf("[local] copy %s to %s failed: %v\noutput: %v\n", srcDir, dstDir, err, string(output))
 		return err
@@ -68,19 +134,15 @@ func (r *LocalRunner) Copy(src, dst string) error {
 	}
 	if !fi.IsDir() {
 		// just copy file
-		return r.copyDir(src, dst)
+		return r.copyFile(src, dst)
 	}
-	output, err := exec.Command("/bin/sh", "-c", fmt.Sprintf("cp -f %v %v", src, dst)).CombinedOutput()
-	if err != nil {
-		logrus.Errorf("[local] copy %s to %s failed: %v\noutput: %v\n", src, dst, err, string(output))
-	} else {
-		logrus.Debugf("[local] copy %s to %s success", src, dst)
-	}
-	return err
+
+	// copy dir
+	return r.copyDir(src, dst)
 }
 
 func (r *LocalRunner) RunCommand(cmd string) (string, error) {
-	output, err := exec.Command("/bin/sh", "-c", cmd).CombinedOutput()
+	output, err := r.run(cmd)
 	if err != nil {
 		logrus.Errorf("[local] run command: %s, failed: %v", cmd, err)
 	} else {
@@ -90,7 +152,25 @@ func (r *LocalRunner) RunCommand(cmd string) (string, error) {
 }
 
 func (r *LocalRunner) RunShell(shell string, name string) (string, error) {
-	return "", nil
+	tmpDir, err := ioutil.TempDir("", RunnerShellPrefix)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("mkdir -p %s", tmpDir))
+	roleBase64 := base64.StdEncoding.EncodeToString([]byte(shell))
+	sb.WriteString(fmt.Sprintf(" && echo %s | base64 -d > %s/%s", roleBase64, tmpDir, name))
+	sb.WriteString(fmt.Sprintf(" && chmod +x %s/%s", tmpDir, name))
+	sb.WriteString(fmt.Sprintf(" && %s/%s > /dev/null", tmpDir, name))
+
+	output, err := r.RunCommand(r.sudo(sb.String()))
+	if err != nil {
+		logrus.Errorf("[local] run shell '%s' failed: %v\noutput: %v\n", name, err, output)
+		return "", err
+	}
+	logrus.Debugf("[local] run shell '%s' success, output: %s", name, output)
+	return output, nil
 }
 
 func (r *LocalRunner) Reconnect() error {
@@ -104,20 +184,16 @@ func (r *LocalRunner) Close() {
 
 type SSHRunner struct {
 	Host *kkv1alpha1.HostCfg
-	Conn ssh.Connection
+	Conn *SSHConnection
+	ctx  context.Context
+}
+
+func (ssh *SSHRunner) WithContext(ctx context.Context) Runner {
+	return &SSHRunner{Host: ssh.Host, Conn: ssh.Conn, ctx: ctx}
 }
 
-func connect(host *kkv1alpha1.HostCfg) (ssh.Connection, error) {
-	opts := ssh.Cfg{
-		Username:   host.User,
-		Port:       host.Port,
-		Address:    host.Address,
-		Password:   host.Password,
-		PrivateKey: host.PrivateKey,
-		KeyFile:    host.PrivateKeyPath,
-		Timeout:    30 * time.Minute,
-	}
-	return ssh.NewConnection(opts)
+func connect(hcfg *api.HostConfig, checker *HostKeyChecker) (*SSHConnection, error) {
+	return NewSSHConnection(hostEndpoint(hcfg), bastionEndpoint(hcfg), checker)
 }
 
 func HostConfigToKKCfg(hcfg *api.HostConfig) *kkv1alpha1.HostCfg {
@@ -132,34 +208,51 @@ func HostConfigToKKCfg(hcfg *api.HostConfig) *kkv1alpha1.HostCfg {
 	}
 }
 
+// NewSSHRunner connect to node without host key verification
 func NewSSHRunner(hcfg *api.HostConfig) (Runner, error) {
+	return NewVerifiedSSHRunner(hcfg, nil)
+}
+
+// NewVerifiedSSHRunner connect to node, and verify host keys of node and its bastion by checker
+func NewVerifiedSSHRunner(hcfg *api.HostConfig, checker *HostKeyChecker) (Runner, error) {
 	host := HostConfigToKKCfg(hcfg)
-	conn, err := connect(host)
+	conn, err := connect(hcfg, checker)
 	if err != nil {
 		return nil, err
 	}
 	if err = prepareUserTempDir(conn, host); err != nil {
 		logrus.Errorf("[%s] prepare user temp dir failed: %v", host.Name, err)
+		conn.Close()
 		return nil, err
 	}
 	return &SSHRunner{Host: host, Conn: conn}, nil
 }
 
 func (ssh *SSHRunner) Close() {
-	// TODO: wait kubekey support close for Connection
-	logrus.Debugf("TODO: wait kubekey support close for Connection")
+	if ssh.Conn == nil {
+		return
+	}
+	ssh.Conn.Close()
+	logrus.Debugf("[%s] close ssh connection", ssh.Host.Name)
 }
 
 func (ssh *SSHRunner) Reconnect() error {
-	conn, err := connect(ssh.Host)
-	if err != nil {
-		return nil
+	if ssh.Conn == nil {
+		return fmt.Errorf("[%s] SSH runner is not connected", ssh.Host.Name)
+	}
+	if err := ssh.Conn.Reconnect(); err != nil {
+		logrus.Errorf("[%s] reconnect failed: %v", ssh.Host.Name, err)
+		return err
 	}
-	ssh.Conn = conn
 	return nil
 }
 
-func prepareUserTempDir(conn ssh.Connection, host *kkv1alpha1.HostCfg) error {
+// RescanHostKeys replace host keys of node and its bastion pinned in known_hosts with current ones
+func RescanHostKeys(hcfg *api.HostConfig, checker *HostKeyChecker) ([]*ScannedKey, error) {
+	return checker.rescan(hostEndpoint(hcfg), bastionEndpoint(hcfg))
+}
+
+func prepareUserTempDir(conn *SSHConnection, host *kkv1alpha1.HostCfg) error {
 	// scp to tmp file
 	dir := api.GetUserTempDir(host.User)
 	var sb strings.Builder
@@ -168,7 +261,7 @@ func prepareUserTempDir(conn ssh.Connection, host *kkv1alpha1.HostCfg) error {
 	// chown .eggo dir
 	sb.WriteString(fmt.Sprintf(" && chown -R %s:%s %s", host.User, host.User, filepath.Dir(dir)))
 	sb.WriteString("\"")
-	_, err := conn.Exec(sb.String(), host)
+	_, err := conn.Exec(sb.String())
 	if err != nil {
 		logrus.Errorf("[%s] prepare temp dir: %s failed: %v", host.Name, dir, err)
 		return err
@@ -184,7 +277,7 @@ func (ssh *SSHRunner) copyFile(src, dst string) error {
 	tempDir := api.GetUserTempDir(ssh.Host.User)
 	// scp to tmp file
 	tempCpyFile := filepath.Join(tempDir, filepath.Base(src))
-	err := ssh.Conn.Scp(src, tempCpyFile)
+	err := ssh.Conn.ScpContext(contextOrBackground(ssh.ctx), src, tempCpyFile)
 	if err != nil {
 		logrus.Errorf("[%s] Copy %s to tempfile %s failed: %v", ssh.Host.Name, src, tempCpyFile, err)
 		return err
@@ -220,7 +313,7 @@ func (ssh *SSHRunner) copyDir(srcDir, dstDir string) error {
 		return err
 	}
 	tmpPkgFile := filepath.Join(tmpDir, "pkg.tar")
-	lr := &LocalRunner{}
+	lr := &LocalRunner{ctx: ssh.ctx}
 	// tar src dir
 	_, err = lr.RunCommand(fmt.Sprintf("sudo -E /bin/sh -c \"mkdir -p %s && cd %s && tar -cf %s *\"", tmpDir, srcDir, tmpPkgFile))
 	if err != nil {
@@ -249,7 +342,7 @@ func (ssh *SSHRunner) RunCommand(cmd string) (string, error) {
 	if ssh.Conn == nil {
 		return "", errors.New("SSH runner is not connected")
 	}
-	output, err := ssh.Conn.Exec(cmd, ssh.Host)
+	output, err := ssh.Conn.ExecContext(contextOrBackground(ssh.ctx), cmd)
 	if err != nil {
 		logrus.Errorf("[%s] run '%s' failed: %v\n", ssh.Host.Name, cmd, err)
 		return "", err
@@ -0,0 +1,114 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of local runner
+ ******************************************************************************/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLocalRunner(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-local-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	r := &LocalRunner{}
+
+	// run shell with special characters
+	out := filepath.Join(tempdir, "shell-output")
+	shell := fmt.Sprintf("#!/bin/bash\nval='a \"quoted\" $HOME'\necho \"$val\" > %s\necho ignored\n", out)
+	if _, err = r.RunShell(shell, "test.sh"); err != nil {
+		t.Fatalf("run shell failed: %v", err)
+	}
+	if data, rerr := ioutil.ReadFile(out); rerr != nil || string(data) != "a \"quoted\" $HOME\n" {
+		t.Fatalf("invalid output of shell: %v, %s", rerr, string(data))
+	}
+	if _, err = r.RunShell("#!/bin/bash\nexit 1\n", "fail.sh"); err == nil {
+		t.Fatalf("run failed shell should fail")
+	}
+
+	// copy file
+	src := filepath.Join(tempdir, "src")
+	if err = os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	files := map[string]string{
+		"file":         "content of file",
+		"sub/sub-file": "content of sub file",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	dst := filepath.Join(tempdir, "dst")
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err = r.Copy(filepath.Join(src, "file"), filepath.Join(dst, "copied-file")); err != nil {
+		t.Fatalf("copy file failed: %v", err)
+	}
+	if data, rerr := ioutil.ReadFile(filepath.Join(dst, "copied-file")); rerr != nil || string(data) != files["file"] {
+		t.Fatalf("invalid copied file: %v, content: %s", rerr, string(data))
+	}
+
+	// copy files in dir, but not dir itself
+	if err = r.Copy(src, dst); err != nil {
+		t.Fatalf("copy dir failed: %v", err)
+	}
+	for name, content := range files {
+		if data, rerr := ioutil.ReadFile(filepath.Join(dst, name)); rerr != nil || string(data) != content {
+			t.Fatalf("invalid copied file %s: %v, content: %s", name, rerr, string(data))
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dst, "src")); !os.IsNotExist(err) {
+		t.Fatalf("src dir should not be copied into dst: %v", err)
+	}
+}
+
+func TestLocalRunnerCancel(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-local-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	// children of command are killed too
+	marker := filepath.Join(tempdir, "marker")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := (&LocalRunner{}).WithContext(ctx)
+	start := time.Now()
+	if _, err = r.RunCommand(fmt.Sprintf("(sleep 1; touch %s) & wait", marker)); err == nil {
+		t.Fatalf("expect error of cancelled command")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("cancelled command should return at once")
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if _, err = os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("child of cancelled command should be killed: %v", err)
+	}
+	if _, err = r.RunCommand("true"); err == nil {
+		t.Fatalf("expect error of run command with done context")
+	}
+}
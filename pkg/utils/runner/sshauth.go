@@ -0,0 +1,161 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: signers to login ssh server, by private keys, certificates and ssh-agent
+ ******************************************************************************/
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+var (
+	agentLock sync.Mutex
+	// clients of ssh-agent, key is socket
+	agentClients = make(map[string]agent.ExtendedAgent)
+)
+
+// agentSigners return signers of keys in ssh-agent, connection to agent is shared by all nodes
+func agentSigners(sock string) ([]ssh.Signer, error) {
+	if sock == "" {
+		return nil, fmt.Errorf("socket of ssh-agent is not set, SSH_AUTH_SOCK is empty")
+	}
+	agentLock.Lock()
+	defer agentLock.Unlock()
+
+	if c, ok := agentClients[sock]; ok {
+		if signers, err := c.Signers(); err == nil {
+			return signers, nil
+		}
+		// agent maybe restarted, connect again
+		delete(agentClients, sock)
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("connect to ssh-agent %s failed: %v", sock, err)
+	}
+	c := agent.NewClient(conn)
+	signers, err := c.Signers()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if len(signers) == 0 {
+		conn.Close()
+		return nil, fmt.Errorf("no keys in ssh-agent %s", sock)
+	}
+	agentClients[sock] = c
+	return signers, nil
+}
+
+// parsePrivateKey parse private key named name, encrypted key is decrypted by passphrase
+// referenced by passphraseRef, or passphrase read from terminal if passphraseRef is not set
+func parsePrivateKey(key []byte, name string, passphraseRef string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(key)
+	if err == nil {
+		return signer, nil
+	}
+	if _, ok := err.(*ssh.PassphraseMissingError); !ok {
+		return nil, err
+	}
+
+	if passphraseRef != "" {
+		passphrase, err := secrets.Resolve(passphraseRef)
+		if err != nil {
+			return nil, fmt.Errorf("get passphrase of private key failed: %v", err)
+		}
+		if passphrase == "" {
+			return nil, fmt.Errorf("empty passphrase of private key")
+		}
+		return ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	}
+
+	promptName := "passphrase of " + name
+	passphrase, err := secrets.PromptSecret(promptName, fmt.Sprintf("Enter passphrase for key %s: ", name))
+	if err != nil {
+		return nil, fmt.Errorf("private key is encrypted, set private-key-passphrase or input it in terminal: %v", err)
+	}
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	if err != nil {
+		// ask again for next connection
+		secrets.ForgetSecret(promptName)
+		return nil, err
+	}
+	return signer, nil
+}
+
+// loadCertificate load openssh user certificate, such as id_rsa-cert.pub signed by ssh-keygen -s
+func loadCertificate(path string) (*ssh.Certificate, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, err
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a ssh certificate", path)
+	}
+	if cert.CertType != ssh.UserCert {
+		return nil, fmt.Errorf("%s is not a ssh user certificate", path)
+	}
+	now := uint64(time.Now().Unix())
+	if now < cert.ValidAfter {
+		return nil, fmt.Errorf("certificate %s is not valid before %s", path, time.Unix(int64(cert.ValidAfter), 0))
+	}
+	if cert.ValidBefore != ssh.CertTimeInfinity && now >= cert.ValidBefore {
+		return nil, fmt.Errorf("certificate %s is expired at %s", path, time.Unix(int64(cert.ValidBefore), 0))
+	}
+	return cert, nil
+}
+
+// certSigners add signer of certificate before signers, cert must certify key of one signer
+func certSigners(cert *ssh.Certificate, user string, signers []ssh.Signer) ([]ssh.Signer, error) {
+	if len(cert.ValidPrincipals) > 0 {
+		found := false
+		for _, p := range cert.ValidPrincipals {
+			if p == user {
+				found = true
+				break
+			}
+		}
+		if !found {
+			logrus.Warnf("user %s is not principal of certificate %s: %v", user, cert.KeyId, cert.ValidPrincipals)
+		}
+	}
+	key := cert.Key.Marshal()
+	for _, s := range signers {
+		if !bytes.Equal(s.PublicKey().Marshal(), key) {
+			continue
+		}
+		cs, err := ssh.NewCertSigner(cert, s)
+		if err != nil {
+			return nil, err
+		}
+		return append([]ssh.Signer{cs}, signers...), nil
+	}
+	return nil, fmt.Errorf("key of certificate %s is not private key or key in ssh-agent", cert.KeyId)
+}
@@ -0,0 +1,597 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: ssh connection of node, with keepalive, reconnect and bastion support
+ ******************************************************************************/
+
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/scp"
+	"golang.org/x/crypto/ssh"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/utils/secrets"
+)
+
+const (
+	defaultSSHPort = 22
+	sshDialTimeout = 30 * time.Second
+	// sshd allow 10 sessions on one connection by default (MaxSessions), keep some for others
+	sshMaxSessions = 8
+	// connection is dropped after keepalive missed for times
+	sshKeepaliveMaxMissed = 3
+	sshReconnectRetries   = 3
+	sshKeepaliveInterval  = 30 * time.Second
+	sshReconnectInterval  = 2 * time.Second
+	// lines longer than it are not password prompt of sudo
+	sudoPromptMaxLen = 256
+)
+
+// sshEndpoint is address and certificate to login a ssh server
+type sshEndpoint struct {
+	address        string
+	port           int
+	user           string
+	password       string
+	privateKey     string
+	privateKeyPath string
+	// reference of passphrase of encrypted private key
+	passphrase string
+	// openssh user certificate of private key or key in ssh-agent
+	certificatePath string
+	// fingerprint of host key set in config
+	fingerprint string
+}
+
+func (e *sshEndpoint) hostPort() string {
+	port := e.port
+	if port <= 0 {
+		port = defaultSSHPort
+	}
+	return net.JoinHostPort(e.address, strconv.Itoa(port))
+}
+
+func (e *sshEndpoint) clientConfig(checker *HostKeyChecker) (*ssh.ClientConfig, error) {
+	if e.user == "" {
+		return nil, fmt.Errorf("no username specified for ssh connection to %s", e.address)
+	}
+
+	var auths []ssh.AuthMethod
+	password, err := secrets.Resolve(e.password)
+	if err != nil {
+		return nil, fmt.Errorf("get password of %s failed: %v", e.address, err)
+	}
+	if password != "" {
+		auths = append(auths, ssh.Password(password))
+	}
+	signers, err := e.signers()
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) > 0 {
+		// client tries each method once, so all keys must be in one method
+		auths = append(auths, ssh.PublicKeys(signers...))
+	}
+	if len(auths) == 0 {
+		return nil, fmt.Errorf("no password or private key specified for ssh connection to %s", e.address)
+	}
+
+	return &ssh.ClientConfig{
+		User:            e.user,
+		Auth:            auths,
+		Timeout:         sshDialTimeout,
+		HostKeyCallback: checker.callback(e.fingerprint),
+	}, nil
+}
+
+// signers return signers of private key, ssh-agent and certificate of endpoint
+func (e *sshEndpoint) signers() ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	key, name := e.privateKey, "of "+e.address
+	if key == "" && e.privateKeyPath != "" {
+		content, err := ioutil.ReadFile(e.privateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("read private key %s failed: %v", e.privateKeyPath, err)
+		}
+		key, name = string(content), e.privateKeyPath
+	}
+	if key != "" {
+		signer, err := parsePrivateKey([]byte(key), name, e.passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key of %s failed: %v", e.address, err)
+		}
+		signers = append(signers, signer)
+	}
+	if sock, ok := secrets.AgentSocket(e.password); ok {
+		as, err := agentSigners(sock)
+		if err != nil {
+			return nil, fmt.Errorf("get keys of %s from ssh-agent failed: %v", e.address, err)
+		}
+		signers = append(signers, as...)
+	}
+	if e.certificatePath == "" {
+		return signers, nil
+	}
+
+	cert, err := loadCertificate(e.certificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("load certificate of %s failed: %v", e.address, err)
+	}
+	if signers, err = certSigners(cert, e.user, signers); err != nil {
+		return nil, fmt.Errorf("certificate of %s: %v", e.address, err)
+	}
+	return signers, nil
+}
+
+func dialSSH(target *sshEndpoint, bastion *sshEndpoint, checker *HostKeyChecker) (*ssh.Client, *ssh.Client, error) {
+	config, err := target.clientConfig(checker)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if bastion == nil {
+		client, err := ssh.Dial("tcp", target.hostPort(), config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("connect to %s failed: %v", target.hostPort(), err)
+		}
+		return client, nil, nil
+	}
+
+	bconfig, err := bastion.clientConfig(checker)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bastion: %v", err)
+	}
+	bclient, err := ssh.Dial("tcp", bastion.hostPort(), bconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to bastion %s failed: %v", bastion.hostPort(), err)
+	}
+	// tunnel to node by bastion
+	conn, err := bclient.Dial("tcp", target.hostPort())
+	if err != nil {
+		bclient.Close()
+		return nil, nil, fmt.Errorf("connect to %s through bastion %s failed: %v", target.hostPort(), bastion.hostPort(), err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, target.hostPort(), config)
+	if err != nil {
+		conn.Close()
+		bclient.Close()
+		return nil, nil, fmt.Errorf("connect to %s through bastion %s failed: %v", target.hostPort(), bastion.hostPort(), err)
+	}
+
+	return ssh.NewClient(c, chans, reqs), bclient, nil
+}
+
+func hostEndpoint(hcfg *api.HostConfig) *sshEndpoint {
+	return &sshEndpoint{
+		address:         hcfg.Address,
+		port:            hcfg.Port,
+		user:            hcfg.UserName,
+		password:        hcfg.Password,
+		privateKey:      hcfg.PrivateKey,
+		privateKeyPath:  hcfg.PrivateKeyPath,
+		passphrase:      hcfg.PrivateKeyPassphrase,
+		certificatePath: hcfg.CertificatePath,
+		fingerprint:     hcfg.Fingerprint,
+	}
+}
+
+// bastionEndpoint return endpoint of bastion, certificate not set for bastion is inherited from node
+func bastionEndpoint(hcfg *api.HostConfig) *sshEndpoint {
+	b := hcfg.Bastion
+	if b == nil || b.Address == "" {
+		return nil
+	}
+	e := &sshEndpoint{
+		address:         b.Address,
+		port:            b.Port,
+		user:            b.UserName,
+		password:        b.Password,
+		privateKey:      b.PrivateKey,
+		privateKeyPath:  b.PrivateKeyPath,
+		passphrase:      b.PrivateKeyPassphrase,
+		certificatePath: b.CertificatePath,
+		fingerprint:     b.Fingerprint,
+	}
+	if e.user == "" {
+		e.user = hcfg.UserName
+	}
+	if e.password == "" && e.privateKey == "" && e.privateKeyPath == "" {
+		e.password, e.privateKey, e.privateKeyPath = hcfg.Password, hcfg.PrivateKey, hcfg.PrivateKeyPath
+		e.passphrase, e.certificatePath = hcfg.PrivateKeyPassphrase, hcfg.CertificatePath
+	}
+	return e
+}
+
+// SSHConnection own ssh client of node, sessions are multiplexed on the client,
+// and the client is dialed again if it is broken
+type SSHConnection struct {
+	target  *sshEndpoint
+	bastion *sshEndpoint
+	checker *HostKeyChecker
+
+	lock          sync.Mutex
+	client        *ssh.Client
+	bastionClient *ssh.Client
+	// closed to stop keepalive of current client
+	stopKeepalive chan struct{}
+	closed        bool
+
+	sessions          chan struct{}
+	keepaliveInterval time.Duration
+	reconnectInterval time.Duration
+}
+
+// NewSSHConnection connect to target, through bastion if it is not nil,
+// host keys are not verified if checker is nil
+func NewSSHConnection(target *sshEndpoint, bastion *sshEndpoint, checker *HostKeyChecker) (*SSHConnection, error) {
+	return newSSHConnection(target, bastion, checker, sshKeepaliveInterval, sshReconnectInterval)
+}
+
+func newSSHConnection(target *sshEndpoint, bastion *sshEndpoint, checker *HostKeyChecker,
+	keepalive, reconnect time.Duration) (*SSHConnection, error) {
+	c := &SSHConnection{
+		target:            target,
+		bastion:           bastion,
+		checker:           checker,
+		sessions:          make(chan struct{}, sshMaxSessions),
+		keepaliveInterval: keepalive,
+		reconnectInterval: reconnect,
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.connectLocked(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *SSHConnection) releaseLocked() {
+	if c.stopKeepalive != nil {
+		close(c.stopKeepalive)
+		c.stopKeepalive = nil
+	}
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+	if c.bastionClient != nil {
+		c.bastionClient.Close()
+		c.bastionClient = nil
+	}
+}
+
+func (c *SSHConnection) connectLocked() error {
+	c.releaseLocked()
+	client, bclient, err := dialSSH(c.target, c.bastion, c.checker)
+	if err != nil {
+		return err
+	}
+	c.client, c.bastionClient = client, bclient
+	c.stopKeepalive = make(chan struct{})
+	go c.keepalive(client, c.stopKeepalive)
+	return nil
+}
+
+// reconnectLocked dial node again, at most sshReconnectRetries times
+func (c *SSHConnection) reconnectLocked() error {
+	var err error
+	for i := 0; i < sshReconnectRetries; i++ {
+		if i > 0 {
+			time.Sleep(c.reconnectInterval)
+		}
+		if err = c.connectLocked(); err == nil {
+			logrus.Debugf("reconnect to %s success", c.target.address)
+			return nil
+		}
+		logrus.Warnf("reconnect to %s failed: %v, retry: %d", c.target.address, err, i+1)
+	}
+	return err
+}
+
+// Reconnect drop current client and dial node again
+func (c *SSHConnection) Reconnect() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return fmt.Errorf("connection to %s is closed", c.target.address)
+	}
+	return c.reconnectLocked()
+}
+
+// drop close client if it is still in use, so that it will be dialed again for next session
+func (c *SSHConnection) drop(client *ssh.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.client == client {
+		c.releaseLocked()
+	}
+}
+
+func (c *SSHConnection) getClient() (*ssh.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("connection to %s is closed", c.target.address)
+	}
+	if c.client == nil {
+		if err := c.reconnectLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return c.client, nil
+}
+
+func ping(client *ssh.Client, timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timeout")
+	}
+}
+
+func (c *SSHConnection) keepalive(client *ssh.Client, stop chan struct{}) {
+	ticker := time.NewTicker(c.keepaliveInterval)
+	defer ticker.Stop()
+	missed := 0
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if err := ping(client, c.keepaliveInterval); err != nil {
+			missed++
+			logrus.Debugf("keepalive of %s missed %d times: %v", c.target.address, missed, err)
+			if missed >= sshKeepaliveMaxMissed {
+				logrus.Warnf("connection to %s is broken, drop it", c.target.address)
+				c.drop(client)
+				return
+			}
+			continue
+		}
+		missed = 0
+	}
+}
+
+// session open a new session, wait if too many sessions are running on node
+func (c *SSHConnection) session(ctx context.Context) (*ssh.Session, *ssh.Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	select {
+	case c.sessions <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	var lastErr error
+	for i := 0; i <= sshReconnectRetries; i++ {
+		client, err := c.getClient()
+		if err != nil {
+			<-c.sessions
+			return nil, nil, err
+		}
+		sess, err := client.NewSession()
+		if err == nil {
+			return sess, client, nil
+		}
+		// no new session on broken client
+		lastErr = err
+		c.drop(client)
+	}
+	<-c.sessions
+	return nil, nil, fmt.Errorf("open session on %s failed: %v", c.target.address, lastErr)
+}
+
+func (c *SSHConnection) closeSession(sess *ssh.Session) {
+	sess.Close()
+	<-c.sessions
+}
+
+// isBroken check whether err is caused by broken connection
+func isBroken(client *ssh.Client, err error) bool {
+	var missing *ssh.ExitMissingError
+	if errors.As(err, &missing) || errors.Is(err, io.EOF) {
+		return true
+	}
+	return ping(client, sshDialTimeout) != nil
+}
+
+// killOnCancel close sess when ctx is done, hang up of pty kill the remote command,
+// the returned function must be called before sess is closed
+func killOnCancel(ctx context.Context, sess *ssh.Session) func() {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			// signal is not supported by old sshd, closing session works for all
+			_ = sess.Signal(ssh.SIGKILL)
+			sess.Close()
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// isPasswordPrompt check whether line is password prompt of sudo
+func isPasswordPrompt(line []byte) bool {
+	return (bytes.HasPrefix(line, []byte("[sudo] password for ")) || bytes.HasPrefix(line, []byte("Password"))) &&
+		bytes.HasSuffix(line, []byte(": "))
+}
+
+// exec run command in pty, and answer password prompt of sudo,
+// started is false if command is not started on node
+func (c *SSHConnection) exec(ctx context.Context, cmd string) (output string, started bool, err error) {
+	sess, client, err := c.session(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer c.closeSession(sess)
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          0,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err = sess.RequestPty("xterm", 100, 50, modes); err != nil {
+		c.drop(client)
+		return "", false, err
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		return "", false, err
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		return "", false, err
+	}
+	if err = sess.Start(strings.TrimSpace(cmd)); err != nil {
+		c.drop(client)
+		return "", false, err
+	}
+	stopKill := killOnCancel(ctx, sess)
+	defer stopKill()
+
+	var out []byte
+	// only the head of current line is kept to detect password prompt
+	line := make([]byte, 0, sudoPromptMaxLen)
+	r := bufio.NewReader(stdout)
+	for {
+		b, rerr := r.ReadByte()
+		if rerr != nil {
+			break
+		}
+		out = append(out, b)
+		if b == byte('\n') {
+			line = line[:0]
+			continue
+		}
+		if len(line) >= sudoPromptMaxLen {
+			continue
+		}
+		line = append(line, b)
+		if isPasswordPrompt(line) {
+			// password is resolved when connected
+			password, _ := secrets.Resolve(c.target.password)
+			if _, werr := stdin.Write([]byte(password + "\n")); werr != nil {
+				break
+			}
+		}
+	}
+	err = sess.Wait()
+	output = strings.TrimSpace(strings.TrimPrefix(string(out), fmt.Sprintf("[sudo] password for %s:", c.target.user)))
+	if ctx.Err() != nil {
+		return output, true, fmt.Errorf("exec command: %s is killed: %v", cmd, ctx.Err())
+	}
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(err, &exitErr) && isBroken(client, err) {
+			// command maybe half done, do not run it again, just reconnect for later commands
+			c.drop(client)
+		}
+		return output, true, fmt.Errorf("exec command: %s failed: %v\n%s", cmd, err, output)
+	}
+	return output, true, nil
+}
+
+// Exec run command on node, command is run again on new connection
+// only if it is not started for broken connection
+func (c *SSHConnection) Exec(cmd string) (string, error) {
+	return c.ExecContext(context.Background(), cmd)
+}
+
+// ExecContext is same as Exec, and the command is killed when ctx is done
+func (c *SSHConnection) ExecContext(ctx context.Context, cmd string) (string, error) {
+	for i := 0; ; i++ {
+		output, started, err := c.exec(ctx, cmd)
+		if err == nil || started || i >= sshReconnectRetries || ctx.Err() != nil {
+			return output, err
+		}
+		logrus.Warnf("start command on %s failed: %v, retry: %d", c.target.address, err, i+1)
+	}
+}
+
+func (c *SSHConnection) scp(ctx context.Context, src, dst string) error {
+	sess, client, err := c.session(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.closeSession(sess)
+	stopKill := killOnCancel(ctx, sess)
+	defer stopKill()
+	if err = scp.CopyPath(src, dst, sess); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("copy %s is stopped: %v", src, ctx.Err())
+		}
+		if isBroken(client, err) {
+			c.drop(client)
+		}
+		return err
+	}
+	return nil
+}
+
+// Scp copy local file src to dst on node, copy again if connection is broken
+func (c *SSHConnection) Scp(src, dst string) error {
+	return c.ScpContext(context.Background(), src, dst)
+}
+
+// ScpContext is same as Scp, and the copy is stopped when ctx is done
+func (c *SSHConnection) ScpContext(ctx context.Context, src, dst string) error {
+	for i := 0; ; i++ {
+		err := c.scp(ctx, src, dst)
+		if err == nil || i >= sshReconnectRetries || ctx.Err() != nil {
+			return err
+		}
+		c.lock.Lock()
+		broken := c.client == nil && !c.closed
+		c.lock.Unlock()
+		if !broken {
+			return err
+		}
+		logrus.Warnf("copy %s to %s failed for broken connection: %v, retry: %d", src, c.target.address, err, i+1)
+	}
+}
+
+// Close release clients of node and bastion, the connection cannot be used any more
+func (c *SSHConnection) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+	c.releaseLocked()
+}
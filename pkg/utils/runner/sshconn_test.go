@@ -0,0 +1,824 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of ssh runner with bastion
+ ******************************************************************************/
+
+package runner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"isula.org/eggo/pkg/api"
+)
+
+// testSSHServer is a stand-in of sshd, run command of session by local shell,
+// and forward tcp connection if it is bastion
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+	// count of tcp connections forwarded
+	forwards int32
+	// count of commands executed
+	execs int32
+	// count of commands killed before finished
+	kills int32
+	// count of connections accepted
+	conns int32
+	// count of running and max concurrent sessions
+	sessions    int32
+	maxSessions int32
+	// do not reply keepalive, just like network is broken
+	ignoreKeepalive int32
+
+	lock   sync.Mutex
+	active map[net.Conn]bool
+}
+
+func newTestSSHServer(t *testing.T, config *ssh.ServerConfig) *testSSHServer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key failed: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("create host key signer failed: %v", err)
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	s := &testSSHServer{listener: l, config: config, hostKey: signer.PublicKey(), active: make(map[net.Conn]bool)}
+	go s.serve()
+	return s
+}
+
+func (s *testSSHServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// closeConns break all connections from client
+func (s *testSSHServer) closeConns() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for c := range s.active {
+		c.Close()
+	}
+}
+
+func (s *testSSHServer) activeConns() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.active)
+}
+
+func (s *testSSHServer) handleRequests(reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		if atomic.LoadInt32(&s.ignoreKeepalive) == 1 {
+			continue
+		}
+		if req.WantReply {
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *testSSHServer) handleConn(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	atomic.AddInt32(&s.conns, 1)
+	s.lock.Lock()
+	s.active[conn] = true
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.active, conn)
+		s.lock.Unlock()
+	}()
+	go s.handleRequests(reqs)
+	for nc := range chans {
+		switch nc.ChannelType() {
+		case "session":
+			go s.handleSession(nc)
+		case "direct-tcpip":
+			go s.handleForward(nc)
+		default:
+			nc.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+func (s *testSSHServer) handleForward(nc ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(nc.ExtraData(), &payload); err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+	if err != nil {
+		nc.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	atomic.AddInt32(&s.forwards, 1)
+	go ssh.DiscardRequests(reqs)
+	go func() {
+		io.Copy(target, ch)
+		target.Close()
+	}()
+	io.Copy(ch, target)
+	ch.Close()
+}
+
+func (s *testSSHServer) handleSession(nc ssh.NewChannel) {
+	ch, reqs, err := nc.Accept()
+	if err != nil {
+		return
+	}
+	defer ch.Close()
+	cur := atomic.AddInt32(&s.sessions, 1)
+	defer atomic.AddInt32(&s.sessions, -1)
+	for {
+		max := atomic.LoadInt32(&s.maxSessions)
+		if cur <= max || atomic.CompareAndSwapInt32(&s.maxSessions, max, cur) {
+			break
+		}
+	}
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req":
+			req.Reply(true, nil)
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			atomic.AddInt32(&s.execs, 1)
+			s.runSessionCommand(payload.Command, ch, reqs)
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func sendExitStatus(ch ssh.Channel, code int) {
+	status := make([]byte, 4)
+	binary.BigEndian.PutUint32(status, uint32(code))
+	ch.SendRequest("exit-status", false, status)
+}
+
+// runSessionCommand kill command if session is closed or signaled by client, just like hang up of pty
+func (s *testSSHServer) runSessionCommand(command string, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Stdout = ch
+	cmd.Stderr = ch.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		sendExitStatus(ch, 127)
+		return
+	}
+	// client maybe never close stdin, do not wait for it
+	go func() {
+		io.Copy(stdin, ch)
+		stdin.Close()
+	}()
+	done := make(chan int, 1)
+	go func() {
+		code := 0
+		if err := cmd.Wait(); err != nil {
+			code = 1
+			if ee, ok := err.(*exec.ExitError); ok {
+				code = ee.ExitCode()
+			}
+		}
+		done <- code
+	}()
+
+	for {
+		select {
+		case code := <-done:
+			sendExitStatus(ch, code)
+			return
+		case req, ok := <-reqs:
+			if ok && req.Type != "signal" {
+				req.Reply(false, nil)
+				continue
+			}
+			atomic.AddInt32(&s.kills, 1)
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			<-done
+			return
+		}
+	}
+}
+
+func writeTestPrivateKey(t *testing.T, dir string) (string, ssh.PublicKey) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key failed: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("convert public key failed: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("marshal private key failed: %v", err)
+	}
+	path := filepath.Join(dir, "id_ecdsa")
+	block := &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	if err = ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("write private key failed: %v", err)
+	}
+	return path, sshPub
+}
+
+// installFakeTools make 'sudo -E cmd' run cmd directly, and skip chown of home
+// directory of user, which is slow and useless for test
+func installFakeTools(t *testing.T, dir string) func() {
+	bin := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	tools := map[string]string{
+		"sudo":  "#!/bin/sh\nif [ \"$1\" = \"-E\" ]; then shift; fi\nexec \"$@\"\n",
+		"chown": "#!/bin/sh\nexit 0\n",
+	}
+	for name, script := range tools {
+		if err := ioutil.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatalf("write fake %s failed: %v", name, err)
+		}
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", bin+":"+path)
+	return func() {
+		os.Setenv("PATH", path)
+	}
+}
+
+func TestSSHRunnerWithBastion(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-bastion-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	defer installFakeTools(t, tempdir)()
+
+	u, err := user.Current()
+	if err != nil {
+		t.Fatalf("get current user failed: %v", err)
+	}
+
+	// node only accept private key of user
+	keyPath, pub := writeTestPrivateKey(t, tempdir)
+	node := newTestSSHServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == u.Username && string(key.Marshal()) == string(pub.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key of %s", c.User())
+		},
+	})
+	defer node.listener.Close()
+
+	// bastion only accept password of jump user
+	bastion := newTestSSHServer(t, &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "jump" && string(password) == "jump-password" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid password of %s", c.User())
+		},
+	})
+	defer bastion.listener.Close()
+
+	hcfg := &api.HostConfig{
+		Name:           "node-behind-bastion",
+		Address:        "127.0.0.1",
+		Port:           node.port(),
+		UserName:       u.Username,
+		PrivateKeyPath: keyPath,
+		Bastion: &api.BastionConfig{
+			Address:  "127.0.0.1",
+			Port:     bastion.port(),
+			UserName: "jump",
+			Password: "jump-password",
+		},
+	}
+
+	r, err := NewSSHRunner(hcfg)
+	if err != nil {
+		t.Fatalf("create ssh runner through bastion failed: %v", err)
+	}
+	defer r.Close()
+	if atomic.LoadInt32(&bastion.forwards) == 0 {
+		t.Fatalf("connection is not forwarded by bastion")
+	}
+	if atomic.LoadInt32(&bastion.execs) != 0 {
+		t.Fatalf("command should not run on bastion")
+	}
+
+	output, err := r.RunCommand("echo hello")
+	if err != nil || output != "hello" {
+		t.Fatalf("run command through bastion failed: %v, output: %s", err, output)
+	}
+
+	// copy file
+	src := filepath.Join(tempdir, "src")
+	if err = os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	files := map[string]string{
+		"file":         "content of file",
+		"sub/sub-file": "content of sub file",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	dst := filepath.Join(tempdir, "dst")
+	if err = os.MkdirAll(dst, 0755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err = r.Copy(filepath.Join(src, "file"), filepath.Join(dst, "copied-file")); err != nil {
+		t.Fatalf("copy file through bastion failed: %v", err)
+	}
+	if data, rerr := ioutil.ReadFile(filepath.Join(dst, "copied-file")); rerr != nil || string(data) != files["file"] {
+		t.Fatalf("invalid copied file: %v, content: %s", rerr, string(data))
+	}
+
+	// copy dir
+	if err = r.Copy(src, dst); err != nil {
+		t.Fatalf("copy dir through bastion failed: %v", err)
+	}
+	for name, content := range files {
+		if data, rerr := ioutil.ReadFile(filepath.Join(dst, name)); rerr != nil || string(data) != content {
+			t.Fatalf("invalid copied file %s: %v, content: %s", name, rerr, string(data))
+		}
+	}
+
+	// bastion refuse certificate of node
+	hcfg.Bastion.Password = "wrong-password"
+	if _, err = NewSSHRunner(hcfg); err == nil {
+		t.Fatalf("connect with invalid password of bastion should fail")
+	}
+}
+
+func newTestNode(t *testing.T) (*testSSHServer, *sshEndpoint) {
+	node := newTestSSHServer(t, &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "eggo" && string(password) == "eggo-password" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid password of %s", c.User())
+		},
+	})
+	return node, &sshEndpoint{address: "127.0.0.1", port: node.port(), user: "eggo", password: "eggo-password"}
+}
+
+func waitCondition(t *testing.T, msg string, cond func() bool) {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("timeout to wait: %s", msg)
+}
+
+func TestSSHConnection(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	conn, err := newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("connect to node failed: %v", err)
+	}
+	defer conn.Close()
+
+	// commands run concurrently on one connection
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*sshMaxSessions)
+	for i := 0; i < 2*sshMaxSessions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := conn.Exec(fmt.Sprintf("sleep 0.2; echo %d", i))
+			if err != nil || output != strconv.Itoa(i) {
+				errs <- fmt.Errorf("run command %d failed: %v, output: %s", i, err, output)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("%v", err)
+	}
+	if c := atomic.LoadInt32(&node.conns); c != 1 {
+		t.Fatalf("expect 1 connection, get %d", c)
+	}
+	if m := atomic.LoadInt32(&node.maxSessions); m <= 1 || m > sshMaxSessions {
+		t.Fatalf("invalid count of concurrent sessions: %d", m)
+	}
+
+	// reconnect transparently after connection broken
+	node.closeConns()
+	if output, err := conn.Exec("echo reconnect"); err != nil || output != "reconnect" {
+		t.Fatalf("run command after connection broken failed: %v, output: %s", err, output)
+	}
+	if c := atomic.LoadInt32(&node.conns); c != 2 {
+		t.Fatalf("expect 2 connections, get %d", c)
+	}
+
+	// connection without keepalive reply is dropped
+	atomic.StoreInt32(&node.ignoreKeepalive, 1)
+	waitCondition(t, "drop connection without keepalive", func() bool {
+		return node.activeConns() == 0
+	})
+	atomic.StoreInt32(&node.ignoreKeepalive, 0)
+	if output, err := conn.Exec("echo keepalive"); err != nil || output != "keepalive" {
+		t.Fatalf("run command after keepalive failed: %v, output: %s", err, output)
+	}
+	if c := atomic.LoadInt32(&node.conns); c != 3 {
+		t.Fatalf("expect 3 connections, get %d", c)
+	}
+
+	// password prompt of sudo is answered
+	if output, err := conn.Exec("printf '[sudo] password for eggo: '; read p; echo \"got $p\""); err != nil || output != "got eggo-password" {
+		t.Fatalf("answer password prompt failed: %v, output: %s", err, output)
+	}
+	// long line is read in linear time
+	output, err := conn.Exec("head -c 4194304 /dev/zero | tr '\\0' a")
+	if err != nil || len(output) != 4194304 || strings.Trim(output, "a") != "" {
+		t.Fatalf("read long line failed: %v, length of output: %d", err, len(output))
+	}
+
+	// failed command is not a broken connection
+	if _, err = conn.Exec("exit 3"); err == nil {
+		t.Fatalf("expect error of failed command")
+	}
+	if output, err := conn.Exec("echo ok"); err != nil || output != "ok" || atomic.LoadInt32(&node.conns) != 3 {
+		t.Fatalf("connection should be kept after failed command: %v", err)
+	}
+
+	// retry to connect is bounded when node is gone
+	node.listener.Close()
+	node.closeConns()
+	if _, err = conn.Exec("echo gone"); err == nil {
+		t.Fatalf("expect error of run command on gone node")
+	}
+	if err = conn.Reconnect(); err == nil {
+		t.Fatalf("expect error of reconnect to gone node")
+	}
+}
+
+func TestSSHConnectionClose(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	conn, err := NewSSHConnection(target, nil, nil)
+	if err != nil {
+		t.Fatalf("connect to node failed: %v", err)
+	}
+	r := &SSHRunner{Host: HostConfigToKKCfg(&api.HostConfig{Name: "node"}), Conn: conn}
+	if _, err = r.RunCommand("echo hello"); err != nil {
+		t.Fatalf("run command failed: %v", err)
+	}
+
+	r.Close()
+	waitCondition(t, "release connection", func() bool {
+		return node.activeConns() == 0
+	})
+	if _, err = r.RunCommand("echo hello"); err == nil {
+		t.Fatalf("expect error of run command on closed runner")
+	}
+	if err = r.Reconnect(); err == nil {
+		t.Fatalf("expect error of reconnect closed runner")
+	}
+	if c := atomic.LoadInt32(&node.conns); c != 1 {
+		t.Fatalf("closed runner should not connect again, connections: %d", c)
+	}
+}
+
+func TestSSHRunnerCancel(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+	conn, err := NewSSHConnection(target, nil, nil)
+	if err != nil {
+		t.Fatalf("connect to node failed: %v", err)
+	}
+	defer conn.Close()
+	base := &SSHRunner{Host: HostConfigToKKCfg(&api.HostConfig{Name: "node"}), Conn: conn}
+
+	// remote command is killed when context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err = base.WithContext(ctx).RunCommand("sleep 10"); err == nil {
+		t.Fatalf("expect error of cancelled command")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("cancelled command should return at once")
+	}
+	waitCondition(t, "kill remote command", func() bool {
+		return atomic.LoadInt32(&node.kills) == 1
+	})
+
+	// nothing is run with done context, and connection is still usable
+	execs := atomic.LoadInt32(&node.execs)
+	if _, err = base.WithContext(ctx).RunCommand("echo hello"); err == nil {
+		t.Fatalf("expect error of run command with done context")
+	}
+	if atomic.LoadInt32(&node.execs) != execs {
+		t.Fatalf("command should not run with done context")
+	}
+	if output, err := base.RunCommand("echo hello"); err != nil || output != "hello" {
+		t.Fatalf("run command after cancel failed: %v, output: %s", err, output)
+	}
+}
+
+func TestSSHConnectionWithReference(t *testing.T) {
+	node, target := newTestNode(t)
+	defer node.listener.Close()
+
+	os.Setenv("EGGO_TEST_SSH_PASSWORD", target.password)
+	defer os.Unsetenv("EGGO_TEST_SSH_PASSWORD")
+	target.password = "env:EGGO_TEST_SSH_PASSWORD"
+	conn, err := newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("connect to node with password in env failed: %v", err)
+	}
+	conn.Close()
+
+	target.password = "env:EGGO_TEST_SSH_NOT_EXIST"
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect with unset password should fail")
+	}
+}
+
+func TestSSHConnectionWithAgent(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-agent-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key failed: %v", err)
+	}
+	pub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("convert public key failed: %v", err)
+	}
+	keyring := agent.NewKeyring()
+	if err = keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatalf("add key to agent failed: %v", err)
+	}
+	sock := filepath.Join(tempdir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen agent socket failed: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, c)
+		}
+	}()
+
+	node := newTestSSHServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(pub.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key of %s", c.User())
+		},
+	})
+	defer node.listener.Close()
+
+	target := &sshEndpoint{address: "127.0.0.1", port: node.port(), user: "eggo", password: "agent:" + sock}
+	conn, err := newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("connect to node with ssh-agent failed: %v", err)
+	}
+	conn.Close()
+
+	target.password = "agent:" + filepath.Join(tempdir, "not-exist.sock")
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect with invalid ssh-agent should fail")
+	}
+}
+
+func writeECKey(t *testing.T, path string, priv *ecdsa.PrivateKey, passphrase string) {
+	der, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("marshal private key failed: %v", err)
+	}
+	block := &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	if passphrase != "" {
+		block, err = x509.EncryptPEMBlock(rand.Reader, block.Type, der, []byte(passphrase), x509.PEMCipherAES256)
+		if err != nil {
+			t.Fatalf("encrypt private key failed: %v", err)
+		}
+	}
+	if err = ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("write private key failed: %v", err)
+	}
+}
+
+func TestSSHConnectionWithEncryptedKey(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-key-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key failed: %v", err)
+	}
+	pub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("convert public key failed: %v", err)
+	}
+	keyPath := filepath.Join(tempdir, "id_ecdsa")
+	writeECKey(t, keyPath, priv, "key-passphrase")
+
+	node := newTestSSHServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(pub.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key of %s", c.User())
+		},
+	})
+	defer node.listener.Close()
+
+	os.Setenv("EGGO_TEST_KEY_PASSPHRASE", "key-passphrase")
+	defer os.Unsetenv("EGGO_TEST_KEY_PASSPHRASE")
+	target := &sshEndpoint{address: "127.0.0.1", port: node.port(), user: "eggo", privateKeyPath: keyPath,
+		passphrase: "env:EGGO_TEST_KEY_PASSPHRASE"}
+	conn, err := newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("connect to node with encrypted key failed: %v", err)
+	}
+	conn.Close()
+
+	os.Setenv("EGGO_TEST_KEY_PASSPHRASE", "wrong-passphrase")
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect with wrong passphrase should fail")
+	}
+}
+
+func TestSSHConnectionWithCertificate(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "eggo-runner-cert-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ca key failed: %v", err)
+	}
+	ca, err := ssh.NewSignerFromKey(caPriv)
+	if err != nil {
+		t.Fatalf("create ca signer failed: %v", err)
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key failed: %v", err)
+	}
+	pub, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatalf("convert public key failed: %v", err)
+	}
+	keyPath := filepath.Join(tempdir, "id_ecdsa")
+	writeECKey(t, keyPath, priv, "")
+
+	writeCert := func(name string, key ssh.PublicKey, before uint64) string {
+		cert := &ssh.Certificate{
+			Key:             key,
+			CertType:        ssh.UserCert,
+			KeyId:           name,
+			ValidPrincipals: []string{"eggo"},
+			ValidBefore:     before,
+		}
+		if err := cert.SignCert(rand.Reader, ca); err != nil {
+			t.Fatalf("sign certificate failed: %v", err)
+		}
+		path := filepath.Join(tempdir, name)
+		if err := ioutil.WriteFile(path, ssh.MarshalAuthorizedKey(cert), 0600); err != nil {
+			t.Fatalf("write certificate failed: %v", err)
+		}
+		return path
+	}
+
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(ca.PublicKey().Marshal())
+		},
+	}
+	node := newTestSSHServer(t, &ssh.ServerConfig{PublicKeyCallback: checker.Authenticate})
+	defer node.listener.Close()
+
+	target := &sshEndpoint{address: "127.0.0.1", port: node.port(), user: "eggo", privateKeyPath: keyPath}
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect without certificate should fail")
+	}
+
+	target.certificatePath = writeCert("id_ecdsa-cert.pub", pub, ssh.CertTimeInfinity)
+	conn, err := newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("connect to node with certificate failed: %v", err)
+	}
+	conn.Close()
+
+	target.certificatePath = writeCert("expired-cert.pub", pub, uint64(time.Now().Add(-time.Hour).Unix()))
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect with expired certificate should fail")
+	}
+
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate other key failed: %v", err)
+	}
+	other, err := ssh.NewSignerFromKey(otherPriv)
+	if err != nil {
+		t.Fatalf("create other signer failed: %v", err)
+	}
+	target.certificatePath = writeCert("other-cert.pub", other.PublicKey(), ssh.CertTimeInfinity)
+	if _, err = newSSHConnection(target, nil, nil, 100*time.Millisecond, 10*time.Millisecond); err == nil {
+		t.Fatalf("connect with certificate of other key should fail")
+	}
+}
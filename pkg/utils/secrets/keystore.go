@@ -0,0 +1,295 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: local keystore of secrets encrypted by passphrase
+ ******************************************************************************/
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"isula.org/eggo/pkg/api"
+	"isula.org/eggo/pkg/constants"
+)
+
+const (
+	// PassphraseEnv is environment variable of passphrase to unlock keystore
+	PassphraseEnv = "EGGO_KEYSTORE_PASSPHRASE"
+
+	keystoreFileMode os.FileMode = 0600
+	saltLength                   = 16
+	keyLength                    = 32
+	scryptN                      = 1 << 15
+	scryptR                      = 8
+	scryptP                      = 1
+)
+
+var (
+	// KeystorePath override path of keystore in eggo home
+	KeystorePath = ""
+
+	keystoreLock sync.Mutex
+	passphrase   string
+	// secrets decrypted, key is name in keystore
+	decrypted = make(map[string]string)
+)
+
+type keystoreEntry struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+type keystore struct {
+	Entries map[string]*keystoreEntry `json:"entries"`
+}
+
+func keystoreFile() string {
+	if KeystorePath != "" {
+		return KeystorePath
+	}
+	return api.GetKeystorePath()
+}
+
+// SetPassphrase set passphrase to unlock keystore, instead of environment or prompt
+func SetPassphrase(p string) {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+	passphrase = p
+	decrypted = make(map[string]string)
+	Register(p)
+}
+
+// PassphraseAvailable return whether passphrase of keystore is set or can be read from terminal
+func PassphraseAvailable() bool {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+	return passphrase != "" || os.Getenv(PassphraseEnv) != "" || terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// getPassphrase must be called with keystoreLock held
+func getPassphrase() (string, error) {
+	if passphrase != "" {
+		return passphrase, nil
+	}
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		passphrase = p
+		Register(p)
+		return p, nil
+	}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("passphrase of keystore is required, set it by %s", PassphraseEnv)
+	}
+	p, err := readTerminal("Enter passphrase of eggo keystore: ")
+	if err != nil {
+		return "", fmt.Errorf("read passphrase failed: %v", err)
+	}
+	if p == "" {
+		return "", fmt.Errorf("empty passphrase of keystore")
+	}
+	passphrase = p
+	Register(passphrase)
+	return passphrase, nil
+}
+
+func readKeystore() (*keystore, error) {
+	ks := &keystore{Entries: make(map[string]*keystoreEntry)}
+	data, err := ioutil.ReadFile(keystoreFile())
+	if os.IsNotExist(err) {
+		return ks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, ks); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %v", keystoreFile(), err)
+	}
+	if ks.Entries == nil {
+		ks.Entries = make(map[string]*keystoreEntry)
+	}
+	return ks, nil
+}
+
+func writeKeystore(ks *keystore) error {
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := keystoreFile()
+	if err = os.MkdirAll(filepath.Dir(path), constants.EggoHomeDirMode); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, keystoreFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func newGCM(pass string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(pass), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encrypt(pass string, secret string) (*keystoreEntry, error) {
+	e := &keystoreEntry{Salt: make([]byte, saltLength)}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(pass, e.Salt)
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(e.Nonce); err != nil {
+		return nil, err
+	}
+	e.Data = gcm.Seal(nil, e.Nonce, []byte(secret), nil)
+	return e, nil
+}
+
+func decrypt(pass string, e *keystoreEntry) (string, error) {
+	gcm, err := newGCM(pass, e.Salt)
+	if err != nil {
+		return "", err
+	}
+	data, err := gcm.Open(nil, e.Nonce, e.Data, nil)
+	if err != nil {
+		return "", fmt.Errorf("wrong passphrase of keystore")
+	}
+	return string(data), nil
+}
+
+// LoadKeystore return secret of name in keystore
+func LoadKeystore(name string) (string, error) {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+	if s, ok := decrypted[name]; ok {
+		return s, nil
+	}
+
+	ks, err := readKeystore()
+	if err != nil {
+		return "", err
+	}
+	e, ok := ks.Entries[name]
+	if !ok {
+		return "", fmt.Errorf("secret %s is not found in keystore", name)
+	}
+	pass, err := getPassphrase()
+	if err != nil {
+		return "", err
+	}
+	secret, err := decrypt(pass, e)
+	if err != nil {
+		return "", err
+	}
+	decrypted[name] = secret
+	Register(secret)
+	return secret, nil
+}
+
+// StoreKeystore encrypt secret and save it in keystore as name, all secrets in keystore
+// must be encrypted by the same passphrase
+func StoreKeystore(name string, secret string) error {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+
+	ks, err := readKeystore()
+	if err != nil {
+		return err
+	}
+	pass, err := getPassphrase()
+	if err != nil {
+		return err
+	}
+	// check passphrase by any secret already stored
+	for _, e := range ks.Entries {
+		if _, err = decrypt(pass, e); err != nil {
+			return err
+		}
+		break
+	}
+
+	e, err := encrypt(pass, secret)
+	if err != nil {
+		return fmt.Errorf("encrypt secret %s failed: %v", name, err)
+	}
+	ks.Entries[name] = e
+	if err = writeKeystore(ks); err != nil {
+		return fmt.Errorf("write keystore failed: %v", err)
+	}
+	decrypted[name] = secret
+	Register(secret)
+	return nil
+}
+
+// RemoveKeystore remove secrets whose name is name or starts with name/, passphrase is not required
+func RemoveKeystore(name string) error {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+
+	ks, err := readKeystore()
+	if err != nil {
+		return err
+	}
+	removed := false
+	for k := range ks.Entries {
+		if k == name || strings.HasPrefix(k, name+"/") {
+			delete(ks.Entries, k)
+			delete(decrypted, k)
+			removed = true
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return writeKeystore(ks)
+}
+
+// ListKeystore return names of secrets in keystore
+func ListKeystore() ([]string, error) {
+	keystoreLock.Lock()
+	defer keystoreLock.Unlock()
+
+	ks, err := readKeystore()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for k := range ks.Entries {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, nil
+}
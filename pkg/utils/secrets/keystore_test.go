@@ -0,0 +1,76 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of keystore
+ ******************************************************************************/
+
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "keystore-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+	KeystorePath = filepath.Join(tempdir, "keystore.json")
+	defer func() {
+		KeystorePath = ""
+		SetPassphrase("")
+	}()
+
+	SetPassphrase("test-passphrase")
+	if err = StoreKeystore("k8s-cluster/password", "cluster-secret"); err != nil {
+		t.Fatalf("store secret failed: %v", err)
+	}
+	if err = StoreKeystore("other", "other-secret"); err != nil {
+		t.Fatalf("store secret failed: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(KeystorePath)
+	if err != nil {
+		t.Fatalf("read keystore failed: %v", err)
+	}
+	if strings.Contains(string(data), "cluster-secret") {
+		t.Fatalf("secret is saved in plain text")
+	}
+
+	// decrypt from file, not from cache
+	SetPassphrase("test-passphrase")
+	if s, err := Resolve("keystore:k8s-cluster/password"); err != nil || s != "cluster-secret" {
+		t.Fatalf("load secret failed: %s, %v", s, err)
+	}
+
+	SetPassphrase("wrong-passphrase")
+	if _, err = LoadKeystore("other"); err == nil {
+		t.Fatalf("expect error of wrong passphrase")
+	}
+	if err = StoreKeystore("new", "new-secret"); err == nil {
+		t.Fatalf("expect error of storing with wrong passphrase")
+	}
+
+	if err = RemoveKeystore("k8s-cluster"); err != nil {
+		t.Fatalf("remove secrets of cluster failed: %v", err)
+	}
+	names, err := ListKeystore()
+	if err != nil || !reflect.DeepEqual(names, []string{"other"}) {
+		t.Fatalf("invalid secrets in keystore: %v, %v", names, err)
+	}
+}
@@ -0,0 +1,242 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: references of credentials and scrub of secrets in logs
+ ******************************************************************************/
+
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// RefEnv reference secret in environment variable, env:NAME
+	RefEnv = "env"
+	// RefFile reference secret in file, file:/path/to/secret
+	RefFile = "file"
+	// RefKeystore reference secret in encrypted keystore of eggo, keystore:NAME
+	RefKeystore = "keystore"
+	// RefAgent login by keys in ssh-agent instead of password, agent: or agent:/path/to/socket,
+	// default socket is $SSH_AUTH_SOCK
+	RefAgent = "agent"
+
+	scrubbedSecret = "******"
+	// secrets shorter than it are not scrubbed, they match too many normal words
+	minScrubLength = 4
+)
+
+var (
+	lock    sync.RWMutex
+	secrets = make(map[string]bool)
+
+	// promptLock serialize prompts, so that nodes connected concurrently ask once
+	promptLock sync.Mutex
+	// secrets read from terminal, key is name of secret
+	prompted = make(map[string]string)
+)
+
+type Reference struct {
+	Kind  string
+	Value string
+}
+
+// ParseReference return reference of s, ok is false if s is a secret written in plain text
+func ParseReference(s string) (*Reference, bool) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+	switch parts[0] {
+	case RefEnv, RefFile, RefKeystore, RefAgent:
+		return &Reference{Kind: parts[0], Value: parts[1]}, true
+	default:
+		return nil, false
+	}
+}
+
+// IsPlain return whether s is a secret written in plain text
+func IsPlain(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, ok := ParseReference(s)
+	return !ok
+}
+
+// ValidReference check format of s, secret in plain text is valid too
+func ValidReference(s string) error {
+	ref, ok := ParseReference(s)
+	if !ok {
+		return nil
+	}
+	switch ref.Kind {
+	case RefEnv, RefKeystore:
+		if ref.Value == "" {
+			return fmt.Errorf("empty name in reference: %s", s)
+		}
+	case RefFile:
+		if !filepath.IsAbs(ref.Value) {
+			return fmt.Errorf("path in reference: %s is not absolute", s)
+		}
+	case RefAgent:
+		if ref.Value != "" && !filepath.IsAbs(ref.Value) {
+			return fmt.Errorf("socket in reference: %s is not absolute", s)
+		}
+	}
+	return nil
+}
+
+// Resolve return secret referenced by s, empty for reference of ssh-agent
+func Resolve(s string) (string, error) {
+	ref, ok := ParseReference(s)
+	if !ok {
+		Register(s)
+		return s, nil
+	}
+
+	var secret string
+	switch ref.Kind {
+	case RefEnv:
+		v, exist := os.LookupEnv(ref.Value)
+		if !exist {
+			return "", fmt.Errorf("environment variable %s is not set", ref.Value)
+		}
+		secret = v
+	case RefFile:
+		content, err := ioutil.ReadFile(ref.Value)
+		if err != nil {
+			return "", fmt.Errorf("read secret file failed: %v", err)
+		}
+		secret = strings.TrimRight(string(content), "\r\n")
+	case RefKeystore:
+		v, err := LoadKeystore(ref.Value)
+		if err != nil {
+			return "", err
+		}
+		secret = v
+	case RefAgent:
+		return "", nil
+	}
+	Register(secret)
+	return secret, nil
+}
+
+// AgentSocket return socket of ssh-agent referenced by s
+func AgentSocket(s string) (string, bool) {
+	ref, ok := ParseReference(s)
+	if !ok || ref.Kind != RefAgent {
+		return "", false
+	}
+	if ref.Value != "" {
+		return ref.Value, true
+	}
+	return os.Getenv("SSH_AUTH_SOCK"), true
+}
+
+// readTerminal read secret from terminal without echo
+func readTerminal(prompt string) (string, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(p), nil
+}
+
+// PromptSecret read secret of name from terminal, it is asked once and cached
+func PromptSecret(name string, prompt string) (string, error) {
+	promptLock.Lock()
+	defer promptLock.Unlock()
+	if s, ok := prompted[name]; ok {
+		return s, nil
+	}
+	s, err := readTerminal(prompt)
+	if err != nil {
+		return "", fmt.Errorf("read %s failed: %v", name, err)
+	}
+	if s == "" {
+		return "", fmt.Errorf("empty %s", name)
+	}
+	prompted[name] = s
+	Register(s)
+	return s, nil
+}
+
+// ForgetSecret drop secret of name read from terminal, such as a wrong passphrase, to ask again
+func ForgetSecret(name string) {
+	promptLock.Lock()
+	delete(prompted, name)
+	promptLock.Unlock()
+}
+
+// Register add secret to be scrubbed
+func Register(secret string) {
+	if len(secret) < minScrubLength {
+		return
+	}
+	lock.Lock()
+	secrets[secret] = true
+	lock.Unlock()
+}
+
+// Scrub replace all registered secrets in s
+func Scrub(s string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+	if len(secrets) == 0 {
+		return s
+	}
+	// replace longer secrets first, which may contain shorter ones
+	var list []string
+	for k := range secrets {
+		list = append(list, k)
+	}
+	sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+	for _, k := range list {
+		s = strings.Replace(s, k, scrubbedSecret, -1)
+	}
+	return s
+}
+
+// ScrubHook scrub secrets in message and fields of logs
+type ScrubHook struct{}
+
+func (h *ScrubHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *ScrubHook) Fire(e *logrus.Entry) error {
+	e.Message = Scrub(e.Message)
+	for k, v := range e.Data {
+		switch val := v.(type) {
+		case string:
+			e.Data[k] = Scrub(val)
+		case error:
+			e.Data[k] = Scrub(val.Error())
+		}
+	}
+	return nil
+}
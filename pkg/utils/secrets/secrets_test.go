@@ -0,0 +1,139 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of secrets
+ ******************************************************************************/
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		s     string
+		plain bool
+		valid bool
+	}{
+		{"123456", true, true},
+		{"pass:word", true, true},
+		{"env:EGGO_PASSWORD", false, true},
+		{"env:", false, false},
+		{"file:/etc/eggo/password", false, true},
+		{"file:password", false, false},
+		{"keystore:k8s-cluster/password", false, true},
+		{"agent:", false, true},
+		{"agent:/run/agent.sock", false, true},
+		{"agent:agent.sock", false, false},
+	}
+	for _, c := range cases {
+		if IsPlain(c.s) != c.plain {
+			t.Fatalf("expect plain of %s is %v", c.s, c.plain)
+		}
+		if err := ValidReference(c.s); (err == nil) != c.valid {
+			t.Fatalf("expect valid of %s is %v, get %v", c.s, c.valid, err)
+		}
+	}
+	if IsPlain("") {
+		t.Fatalf("empty password is not plain secret")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tempdir, err := ioutil.TempDir("", "secrets-test-")
+	if err != nil {
+		t.Fatalf("create tempdir failed: %v", err)
+	}
+	defer os.RemoveAll(tempdir)
+
+	os.Setenv("EGGO_TEST_PASSWORD", "env-secret")
+	defer os.Unsetenv("EGGO_TEST_PASSWORD")
+	f := filepath.Join(tempdir, "password")
+	if err = ioutil.WriteFile(f, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatalf("write password file failed: %v", err)
+	}
+
+	for ref, expect := range map[string]string{
+		"env:EGGO_TEST_PASSWORD": "env-secret",
+		"file:" + f:              "file-secret",
+		"plain-secret":           "plain-secret",
+		"agent:":                 "",
+	} {
+		s, err := Resolve(ref)
+		if err != nil || s != expect {
+			t.Fatalf("resolve %s failed: %s, %v", ref, s, err)
+		}
+	}
+	if _, err = Resolve("env:EGGO_TEST_NOT_EXIST"); err == nil {
+		t.Fatalf("expect error of unset environment variable")
+	}
+
+	if sock, ok := AgentSocket("agent:/run/agent.sock"); !ok || sock != "/run/agent.sock" {
+		t.Fatalf("invalid agent socket: %s", sock)
+	}
+	if _, ok := AgentSocket("env:EGGO_TEST_PASSWORD"); ok {
+		t.Fatalf("env reference is not agent")
+	}
+
+	// resolved secrets are scrubbed
+	msg := Scrub("sshpass -p env-secret ssh; echo file-secret | sudo -S ls")
+	if msg != "sshpass -p ****** ssh; echo ****** | sudo -S ls" {
+		t.Fatalf("invalid scrubbed message: %s", msg)
+	}
+}
+
+func TestScrubHook(t *testing.T) {
+	Register("hook-secret")
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.AddHook(&ScrubHook{})
+	logger.WithField("error", fmt.Errorf("login with hook-secret failed")).Infof("run echo hook-secret")
+
+	if bytes.Contains(buf.Bytes(), []byte("hook-secret")) {
+		t.Fatalf("secret is not scrubbed: %s", buf.String())
+	}
+}
+
+func TestPromptSecret(t *testing.T) {
+	name := "passphrase of test key"
+	promptLock.Lock()
+	prompted[name] = "prompted-passphrase"
+	promptLock.Unlock()
+
+	s, err := PromptSecret(name, "")
+	if err != nil || s != "prompted-passphrase" {
+		t.Fatalf("get cached secret failed: %s, %v", s, err)
+	}
+
+	ForgetSecret(name)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("create pipe failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	if _, err = PromptSecret(name, ""); err == nil {
+		t.Fatalf("prompt secret without terminal should fail")
+	}
+}
@@ -0,0 +1,132 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: retry policy of task
+ ******************************************************************************/
+
+package task
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryAttempts    = 3
+	defaultRetryInterval    = 2 * time.Second
+	defaultRetryMaxInterval = 30 * time.Second
+)
+
+// errors caused by network, ssh connection, package mirror or apiserver not ready,
+// which maybe disappear if run task again. Connection lost while command is running,
+// such as EOF, is not included, because the command maybe half done and must not run again
+var transientErrors = []string{
+	"connection refused",
+	"connection reset by peer",
+	"broken pipe",
+	"i/o timeout",
+	"no route to host",
+	"TLS handshake timeout",
+	"open session on",
+	"Temporary failure in name resolution",
+	"Could not resolve host",
+	"Failed to download",
+	"Cannot find a valid baseurl",
+	"Cannot download repomd.xml",
+	"Unable to connect to the server",
+	"was refused - did you specify the right host or port",
+	"ServiceUnavailable",
+	"the server is currently unable to handle the request",
+	"etcdserver: request timed out",
+	"apiserver is not ready",
+}
+
+// IsTransientError return whether err maybe disappear if run task again
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, e := range transientErrors {
+		if strings.Contains(msg, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy run failed task again on the node, task must be idempotent
+type RetryPolicy struct {
+	// max times to run task, include the first run
+	MaxAttempts int
+	// wait Interval before the second run, and double it before each later run
+	Interval    time.Duration
+	MaxInterval time.Duration
+	// return whether failed task should run again, all errors are retried if it is nil
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy retry transient errors 2 times
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: defaultRetryAttempts,
+		Interval:    defaultRetryInterval,
+		MaxInterval: defaultRetryMaxInterval,
+		Retryable:   IsTransientError,
+	}
+}
+
+// ShouldRetry return whether run task again after attempt failed with err
+func (p *RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// Backoff return time to wait before run task again after attempt failed
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.Interval
+	for i := 1; i < attempt && (p.MaxInterval <= 0 || d < p.MaxInterval); i++ {
+		d *= 2
+	}
+	if p.MaxInterval > 0 && d > p.MaxInterval {
+		d = p.MaxInterval
+	}
+	return d
+}
+
+func NewTaskRetryInstance(t TaskRun, policy *RetryPolicy) *TaskInstance {
+	ti := NewTaskInstance(t)
+	ti.retry = policy
+	return ti
+}
+
+func (t *TaskInstance) SetRetryPolicy(policy *RetryPolicy) {
+	t.l.Lock()
+	defer t.l.Unlock()
+	t.retry = policy
+}
+
+func (t *TaskInstance) GetRetryPolicy() *RetryPolicy {
+	t.l.RLock()
+	defer t.l.RUnlock()
+	return t.retry
+}
+
+// GetRetryPolicy return retry policy of task, nil if task is not retried
+func GetRetryPolicy(t Task) *RetryPolicy {
+	if ti, ok := t.(*TaskInstance); ok {
+		return ti.GetRetryPolicy()
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/******************************************************************************
+ * Copyright (c) Huawei Technologies Co., Ltd. 2021. All rights reserved.
+ * eggo licensed under the Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *     http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+ * PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ * Author: agent
+ * Create: 2026-10-18
+ * Description: testcase of retry policy
+ ******************************************************************************/
+
+package task
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 5, Interval: time.Second, MaxInterval: 5 * time.Second}
+	expects := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, e := range expects {
+		if b := p.Backoff(i + 1); b != e {
+			t.Fatalf("expect backoff %s after attempt %d, get %s", e, i+1, b)
+		}
+	}
+
+	err := fmt.Errorf("exec command: yum install failed\nCurl error (7): Failed to download metadata")
+	if !p.ShouldRetry(4, err) || p.ShouldRetry(5, err) || p.ShouldRetry(1, nil) {
+		t.Fatalf("retry should stop after max attempts")
+	}
+	var nilPolicy *RetryPolicy
+	if nilPolicy.ShouldRetry(1, err) {
+		t.Fatalf("task without policy should not retry")
+	}
+
+	p = DefaultRetryPolicy()
+	if !p.ShouldRetry(1, err) {
+		t.Fatalf("download failure should be retried")
+	}
+	errs := []error{
+		fmt.Errorf("The connection to the server 192.168.0.1:6443 was refused - did you specify the right host or port?"),
+		fmt.Errorf("dial tcp 192.168.0.1:22: i/o timeout"),
+	}
+	for _, e := range errs {
+		if !IsTransientError(e) {
+			t.Fatalf("%v should be transient", e)
+		}
+	}
+	// command maybe half done when connection is lost, such as hooks which are not idempotent
+	errs = []error{
+		fmt.Errorf("exec command: sh hook.sh failed: wait: remote command exited without exit status or exit signal"),
+		fmt.Errorf("exec command: sh hook.sh failed: EOF"),
+	}
+	for _, e := range errs {
+		if IsTransientError(e) {
+			t.Fatalf("%v should not be transient", e)
+		}
+	}
+	if p.ShouldRetry(1, fmt.Errorf("error: unable to recognize \"calico.yaml\": no matches for kind")) {
+		t.Fatalf("invalid yaml should not be retried")
+	}
+}
+
+func TestRetryInstance(t *testing.T) {
+	ti := NewTaskInstance(nil)
+	if GetRetryPolicy(ti) != nil {
+		t.Fatalf("task is not retried by default")
+	}
+	p := DefaultRetryPolicy()
+	ti.SetRetryPolicy(p)
+	if GetRetryPolicy(ti) != p || GetRetryPolicy(NewTaskRetryInstance(nil, p)) != p {
+		t.Fatalf("invalid retry policy of task")
+	}
+}
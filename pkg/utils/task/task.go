@@ -16,8 +16,10 @@
 package task
 
 import (
+	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"isula.org/eggo/pkg/api"
 	"isula.org/eggo/pkg/utils/runner"
@@ -27,11 +29,15 @@ const (
 	SUCCESS   = "success"
 	FAILED    = "failed"
 	IgnoreErr = "task.IgnoreError"
+	AlwaysRun = "task.AlwaysRun"
+	Timeout   = "task.Timeout"
 )
 
 type TaskRun interface {
 	Name() string
-	Run(runner.Runner, *api.HostConfig) error
+	// ctx is cancelled when task timeout or eggo is interrupted,
+	// commands run by runner are killed at the same time
+	Run(context.Context, runner.Runner, *api.HostConfig) error
 }
 
 type Task interface {
@@ -41,8 +47,9 @@ type Task interface {
 }
 
 type TaskInstance struct {
-	data map[string]string
-	l    sync.RWMutex
+	data  map[string]string
+	l     sync.RWMutex
+	retry *RetryPolicy
 	TaskRun
 }
 
@@ -97,3 +104,37 @@ func IsIgnoreError(t Task) bool {
 	label := t.GetLabel(IgnoreErr)
 	return label != ""
 }
+
+// SetAlwaysRunFlag mark task which collect result for later tasks, it will not be skipped when resume
+func SetAlwaysRunFlag(t Task) {
+	t.AddLabel(AlwaysRun, "true")
+}
+
+func IsAlwaysRun(t Task) bool {
+	label := t.GetLabel(AlwaysRun)
+	return label != ""
+}
+
+// SetTimeout set timeout to run task on each node, default timeout is used if not set
+func SetTimeout(t Task, timeout time.Duration) {
+	t.AddLabel(Timeout, timeout.String())
+}
+
+// GetTimeout return timeout of task, 0 if not set
+func GetTimeout(t Task) time.Duration {
+	timeout, err := time.ParseDuration(t.GetLabel(Timeout))
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// Sleep pause current task, return error if task is cancelled before d
+func Sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}